@@ -432,9 +432,9 @@ func initAdminAPI(cfg config.Config, auth authenticator) (*AdminAPI, error) {
 		return nil, fmt.Errorf("failed to parse addr to url.URL: %w", err)
 	}
 
-	httpClient := restyutil.NewHTTPClient(cfg, auth)
-	if httpClient == nil {
-		return nil, fmt.Errorf("failed to initialize HTTP client - nil value")
+	httpClient, err := restyutil.NewHTTPClient(cfg, auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize HTTP client: %w", err)
 	}
 
 	return &AdminAPI{
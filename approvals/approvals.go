@@ -0,0 +1,461 @@
+// Package approvals implements the M-of-N approval of the draft transactions before they are signed.
+//
+// A Workflow submits a draft transaction as a pending approval Request and collects the decisions of the approvers
+// of its Quorum. Every decision is signed by the approver's key with the Bitcoin Signed Message (BSM), the same scheme
+// used to sign the requests to the SPV Wallet API, and verified against the approver's address. Only after the
+// quorum is reached, Execute signs the draft and records the transaction. The requests and the audit trail of
+// every step are kept in a pluggable Store:
+//
+//	workflow, err := approvals.New(userAPI, approvals.NewMemoryStore(), approvals.Quorum{
+//		Required:  2,
+//		Approvers: []approvals.Approver{{Name: "alice", Address: aliceAddress}, {Name: "bob", Address: bobAddress}, {Name: "carol", Address: carolAddress}},
+//	})
+//	req, err := workflow.Propose(ctx, &commands.DraftTransaction{Config: config})
+//	// Each approver signs the request with their key, e.g., with approvals.Sign, and the signatures are collected:
+//	req, err = workflow.Approve(ctx, req.ID, aliceAddress, aliceSignature)
+//	req, err = workflow.Approve(ctx, req.ID, bobAddress, bobSignature)
+//	tx, err := workflow.Execute(ctx, req.ID)
+package approvals
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	bsm "github.com/bitcoin-sv/go-sdk/compat/bsm"
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v1/queryparams"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// Status is the state of an approval request.
+type Status string
+
+// Statuses of the approval requests.
+const (
+	StatusPending  Status = "pending"  // Collecting the decisions of the approvers.
+	StatusApproved Status = "approved" // The quorum is reached; the draft can be executed.
+	StatusRejected Status = "rejected" // Too many approvers rejected the draft to ever reach the quorum.
+	StatusExpired  Status = "expired"  // The draft transaction expired before it was executed.
+	StatusExecuted Status = "executed" // The draft was signed and the transaction recorded.
+)
+
+// Decision is the decision of an approver.
+type Decision string
+
+// Decisions of the approvers.
+const (
+	DecisionApprove Decision = "approve"
+	DecisionReject  Decision = "reject"
+)
+
+// Approver is a party whose approval counts towards the quorum.
+type Approver struct {
+	Name    string // Name of the approver, recorded in the audit trail.
+	Address string // Address of the approver's key, against which the decisions are verified.
+}
+
+// Quorum is the number of approvals required out of the approvers.
+type Quorum struct {
+	Required  int
+	Approvers []Approver
+}
+
+func (q Quorum) approver(address string) (Approver, bool) {
+	for _, approver := range q.Approvers {
+		if approver.Address == address {
+			return approver, true
+		}
+	}
+	return Approver{}, false
+}
+
+func (q Quorum) validate() error {
+	if q.Required < 1 || q.Required > len(q.Approvers) {
+		return fmt.Errorf("%w: %d approvals required out of %d approvers", goclienterr.ErrInvalidQuorum, q.Required, len(q.Approvers))
+	}
+	seen := make(map[string]bool, len(q.Approvers))
+	for _, approver := range q.Approvers {
+		if approver.Address == "" {
+			return fmt.Errorf("%w: approver %q has no address", goclienterr.ErrInvalidQuorum, approver.Name)
+		}
+		if seen[approver.Address] {
+			return fmt.Errorf("%w: duplicated approver address %s", goclienterr.ErrInvalidQuorum, approver.Address)
+		}
+		seen[approver.Address] = true
+	}
+	return nil
+}
+
+// Signature is a decision of an approver with its BSM signature.
+type Signature struct {
+	Approver  string    // Address of the approver.
+	Decision  Decision  // Decision of the approver.
+	Signature string    // Base64-encoded BSM signature of the message returned by Message.
+	SignedAt  time.Time // Time when the decision was collected.
+}
+
+// Request is a draft transaction awaiting the approval.
+type Request struct {
+	ID            string                     // ID of the request, equal to the ID of the draft transaction.
+	Draft         *response.DraftTransaction // Draft transaction to sign once approved.
+	Digest        string                     // Hex-encoded SHA-256 of the draft transaction and the metadata, signed by the approvers.
+	Metadata      map[string]any             // Metadata recorded with the transaction.
+	Required      int                        // Number of approvals required, as configured when the request was submitted.
+	Status        Status
+	Signatures    []Signature // Decisions of the approvers, in the order they were collected.
+	TransactionID string      // ID of the recorded transaction, once executed.
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Count returns the number of the collected decisions of the given kind.
+func (r *Request) Count(decision Decision) int {
+	n := 0
+	for _, sig := range r.Signatures {
+		if sig.Decision == decision {
+			n++
+		}
+	}
+	return n
+}
+
+// Message returns the message an approver signs to make the decision on the request.
+// It binds the decision to the request ID and to the digest of the draft transaction and its metadata,
+// so a signature cannot be replayed for another draft or metadata, nor turned into another decision.
+func Message(r *Request, decision Decision) []byte {
+	return []byte(fmt.Sprintf("SPV Wallet approval\nrequest: %s\ndraft: %s\ndecision: %s", r.ID, r.Digest, decision))
+}
+
+// Sign signs the decision on the request with the approver's private key and returns the base64-encoded BSM signature.
+func Sign(key *ec.PrivateKey, r *Request, decision Decision) (string, error) {
+	sig, err := bsm.SignMessage(key, Message(r, decision))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign the %s decision: %w", decision, err)
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// Digest returns the hex-encoded SHA-256 of the draft transaction followed by a zero byte
+// and the canonical JSON of the metadata recorded with the transaction, with the keys sorted.
+// A nil metadata is digested as an empty object.
+func Digest(draft *response.DraftTransaction, metadata map[string]any) (string, error) {
+	raw, err := hex.DecodeString(draft.Hex)
+	if err != nil {
+		return "", errors.Join(goclienterr.ErrFailedToParseHex, err)
+	}
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	canonical, err := json.Marshal(metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	h := sha256.New()
+	h.Write(raw)
+	h.Write([]byte{0})
+	h.Write(canonical)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func verify(r *Request, sig Signature) error {
+	raw, err := base64.StdEncoding.DecodeString(sig.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature of %s: %w", goclienterr.ErrInvalidApproval, sig.Approver, err)
+	}
+	if err := bsm.VerifyMessage(sig.Approver, raw, Message(r, sig.Decision)); err != nil {
+		return fmt.Errorf("%w: signature of %s doesn't match the %s decision: %w", goclienterr.ErrInvalidApproval, sig.Approver, sig.Decision, err)
+	}
+	return nil
+}
+
+// Wallet drafts, signs and records the transactions. It is implemented by the *spvwallet.UserAPI.
+type Wallet interface {
+	DraftTransaction(ctx context.Context, cmd *commands.DraftTransaction) (*response.DraftTransaction, error)
+	FinalizeTransaction(draft *response.DraftTransaction) (string, error)
+	RecordTransaction(ctx context.Context, cmd *commands.RecordTransaction) (*response.Transaction, error)
+}
+
+// Workflow collects the approvals of the draft transactions and executes the approved ones.
+// The state transitions are serialized within the Workflow; a Store shared by several processes
+// must be used by a single Workflow at a time.
+type Workflow struct {
+	wallet Wallet
+	store  Store
+	quorum Quorum
+	now    func() time.Time
+	mu     sync.Mutex
+}
+
+// Option configures the Workflow.
+type Option func(*Workflow)
+
+// WithClock sets the function returning the current time; time.Now by default.
+func WithClock(now func() time.Time) Option {
+	return func(w *Workflow) {
+		w.now = now
+	}
+}
+
+// New creates a new Workflow requiring the quorum of approvals before executing a draft transaction.
+// It returns goclienterr.ErrInvalidQuorum if the quorum cannot be reached or the approvers are ambiguous.
+func New(wallet Wallet, store Store, quorum Quorum, opts ...Option) (*Workflow, error) {
+	if err := quorum.validate(); err != nil {
+		return nil, err
+	}
+	w := &Workflow{wallet: wallet, store: store, quorum: quorum, now: time.Now}
+	for _, o := range opts {
+		o(w)
+	}
+	return w, nil
+}
+
+// Propose creates a draft transaction and submits it for approval.
+func (w *Workflow) Propose(ctx context.Context, cmd *commands.DraftTransaction) (*Request, error) {
+	draft, err := w.wallet.DraftTransaction(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create draft transaction: %w", err)
+	}
+	return w.Submit(ctx, draft, cmd.Metadata)
+}
+
+// Submit creates a pending approval request for the draft transaction.
+// The metadata is recorded with the transaction once it is executed.
+func (w *Workflow) Submit(ctx context.Context, draft *response.DraftTransaction, metadata map[string]any) (*Request, error) {
+	digest, err := Digest(draft, metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	existing, err := w.load(ctx, draft.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: %s", goclienterr.ErrApprovalExists, draft.ID)
+	}
+
+	now := w.now()
+	r := &Request{
+		ID:        draft.ID,
+		Draft:     draft,
+		Digest:    digest,
+		Metadata:  metadata,
+		Required:  w.quorum.Required,
+		Status:    StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := w.save(ctx, r, Event{Type: EventCreated, Message: fmt.Sprintf("%d of %d approvals required", w.quorum.Required, len(w.quorum.Approvers))}); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Approve adds the approval signed by the approver to the request.
+// The request becomes approved once the approvals reach the quorum.
+func (w *Workflow) Approve(ctx context.Context, id, approver, signature string) (*Request, error) {
+	return w.decide(ctx, id, Signature{Approver: approver, Decision: DecisionApprove, Signature: signature})
+}
+
+// Reject adds the rejection signed by the approver to the request.
+// The request becomes rejected once the remaining approvers cannot reach the quorum.
+func (w *Workflow) Reject(ctx context.Context, id, approver, signature string) (*Request, error) {
+	return w.decide(ctx, id, Signature{Approver: approver, Decision: DecisionReject, Signature: signature})
+}
+
+func (w *Workflow) decide(ctx context.Context, id string, sig Signature) (*Request, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	r, err := w.open(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	approver, ok := w.quorum.approver(sig.Approver)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s isn't an approver", goclienterr.ErrInvalidApproval, sig.Approver)
+	}
+	for _, s := range r.Signatures {
+		if s.Approver == sig.Approver {
+			return nil, fmt.Errorf("%w: %s already decided to %s", goclienterr.ErrInvalidApproval, approver.Name, s.Decision)
+		}
+	}
+	if err := verify(r, sig); err != nil {
+		return nil, err
+	}
+
+	sig.SignedAt = w.now()
+	r.Signatures = append(r.Signatures, sig)
+	r.UpdatedAt = sig.SignedAt
+	events := []Event{{Type: eventOf(sig.Decision), Approver: approver.Name}}
+	approvals, rejections := r.Count(DecisionApprove), r.Count(DecisionReject)
+	switch {
+	case approvals >= r.Required:
+		r.Status = StatusApproved
+		events = append(events, Event{Type: EventQuorumReached, Message: fmt.Sprintf("%d of %d approvals", approvals, r.Required)})
+	case len(w.quorum.Approvers)-rejections < r.Required:
+		r.Status = StatusRejected
+		events = append(events, Event{Type: EventClosed, Message: fmt.Sprintf("rejected by %d approvers", rejections)})
+	}
+	if err := w.save(ctx, r, events...); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Execute signs the approved draft transaction and records it.
+// Before signing, the digest of the draft and the signatures of the approvals are verified again,
+// so a request tampered with in the store isn't executed. If signing or recording fails,
+// the request stays approved and the failure is added to the audit trail, so the execution can be retried.
+// It returns goclienterr.ErrApprovalQuorumNotReached for a pending request and goclienterr.ErrApprovalClosed
+// for a rejected, expired or already executed one.
+func (w *Workflow) Execute(ctx context.Context, id string) (*response.Transaction, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	r, err := w.open(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.verifyQuorum(r); err != nil {
+		return nil, err
+	}
+
+	signedHex, err := w.wallet.FinalizeTransaction(r.Draft)
+	if err == nil {
+		var tx *response.Transaction
+		tx, err = w.wallet.RecordTransaction(ctx, &commands.RecordTransaction{
+			Hex:         signedHex,
+			ReferenceID: r.Draft.ID,
+			Metadata:    queryparams.Metadata(r.Metadata),
+		})
+		if err == nil {
+			r.Status = StatusExecuted
+			r.TransactionID = tx.ID
+			r.UpdatedAt = w.now()
+			if err := w.save(ctx, r, Event{Type: EventExecuted, Message: "transaction " + tx.ID}); err != nil {
+				return tx, err
+			}
+			return tx, nil
+		}
+	}
+
+	if auditErr := w.audit(ctx, r.ID, Event{Type: EventExecutionFailed, Message: err.Error()}); auditErr != nil {
+		return nil, errors.Join(err, auditErr)
+	}
+	return nil, fmt.Errorf("failed to execute approved draft transaction %s: %w", r.ID, err)
+}
+
+func (w *Workflow) verifyQuorum(r *Request) error {
+	if r.Status == StatusPending {
+		return fmt.Errorf("%w: %d of %d approvals", goclienterr.ErrApprovalQuorumNotReached, r.Count(DecisionApprove), r.Required)
+	}
+
+	digest, err := Digest(r.Draft, r.Metadata)
+	if err != nil {
+		return err
+	}
+	if digest != r.Digest || r.Draft.ID != r.ID {
+		return fmt.Errorf("%w: draft transaction %s or its metadata doesn't match the approved digest", goclienterr.ErrInvalidApproval, r.ID)
+	}
+	approved := make(map[string]bool)
+	for _, sig := range r.Signatures {
+		if sig.Decision != DecisionApprove || approved[sig.Approver] {
+			continue
+		}
+		if _, ok := w.quorum.approver(sig.Approver); !ok {
+			continue
+		}
+		if err := verify(r, sig); err != nil {
+			return err
+		}
+		approved[sig.Approver] = true
+	}
+	if len(approved) < w.quorum.Required {
+		return fmt.Errorf("%w: %d valid approvals of %d required", goclienterr.ErrApprovalQuorumNotReached, len(approved), w.quorum.Required)
+	}
+	return nil
+}
+
+// Request returns the approval request or goclienterr.ErrApprovalNotFound.
+func (w *Workflow) Request(ctx context.Context, id string) (*Request, error) {
+	r, err := w.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("%w: %s", goclienterr.ErrApprovalNotFound, id)
+	}
+	return r, nil
+}
+
+// AuditTrail returns the events of the approval request in the order they happened.
+func (w *Workflow) AuditTrail(ctx context.Context, id string) ([]Event, error) {
+	events, err := w.store.Events(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrApprovalStore, err)
+	}
+	return events, nil
+}
+
+// open returns the request if it is still open to the decisions or the execution.
+// A pending or approved request whose draft expired is closed as expired.
+func (w *Workflow) open(ctx context.Context, id string) (*Request, error) {
+	r, err := w.Request(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status != StatusPending && r.Status != StatusApproved {
+		return nil, fmt.Errorf("%w: request %s is %s", goclienterr.ErrApprovalClosed, id, r.Status)
+	}
+
+	now := w.now()
+	if expiresAt := r.Draft.ExpiresAt; !expiresAt.IsZero() && now.After(expiresAt) {
+		r.Status = StatusExpired
+		r.UpdatedAt = now
+		if err := w.save(ctx, r, Event{Type: EventClosed, Message: "draft transaction expired at " + expiresAt.Format(time.RFC3339)}); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: request %s is %s", goclienterr.ErrApprovalClosed, id, r.Status)
+	}
+	return r, nil
+}
+
+func (w *Workflow) load(ctx context.Context, id string) (*Request, error) {
+	r, err := w.store.Request(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrApprovalStore, err)
+	}
+	return r, nil
+}
+
+func (w *Workflow) save(ctx context.Context, r *Request, events ...Event) error {
+	if err := w.store.SaveRequest(ctx, r); err != nil {
+		return fmt.Errorf("%w: %w", goclienterr.ErrApprovalStore, err)
+	}
+	return w.audit(ctx, r.ID, events...)
+}
+
+func (w *Workflow) audit(ctx context.Context, id string, events ...Event) error {
+	for _, e := range events {
+		e.RequestID = id
+		e.At = w.now()
+		if err := w.store.AppendEvent(ctx, e); err != nil {
+			return fmt.Errorf("%w: %w", goclienterr.ErrApprovalStore, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,334 @@
+package approvals_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/bitcoin-sv/go-sdk/script"
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/bitcoin-sv/spv-wallet-go-client/approvals"
+	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2024, 11, 20, 12, 0, 0, 0, time.UTC)
+
+func TestWorkflow(t *testing.T) {
+	t.Run("Draft executed once the quorum is reached", func(t *testing.T) {
+		// given:
+		alice, bob, carol := givenApprover(t, "alice"), givenApprover(t, "bob"), givenApprover(t, "carol")
+		wallet := &fakeWallet{draft: givenDraft(t)}
+		workflow := givenWorkflow(t, wallet, approvals.NewMemoryStore(), alice, bob, carol)
+
+		req, err := workflow.Propose(context.Background(), &commands.DraftTransaction{Metadata: map[string]any{"purpose": "payroll"}})
+		require.NoError(t, err)
+
+		// when:
+		first, err := workflow.Approve(context.Background(), req.ID, alice.address, alice.sign(t, req, approvals.DecisionApprove))
+		require.NoError(t, err)
+		_, earlyErr := workflow.Execute(context.Background(), req.ID)
+		second, err := workflow.Approve(context.Background(), req.ID, bob.address, bob.sign(t, req, approvals.DecisionApprove))
+		require.NoError(t, err)
+		tx, err := workflow.Execute(context.Background(), req.ID)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, approvals.StatusPending, first.Status)
+		require.ErrorIs(t, earlyErr, goclienterr.ErrApprovalQuorumNotReached)
+		require.Equal(t, approvals.StatusApproved, second.Status)
+		require.Equal(t, "tx-1", tx.ID)
+		require.Equal(t, 1, wallet.finalized)
+		require.Equal(t, &commands.RecordTransaction{Hex: "signed", ReferenceID: "draft-1", Metadata: map[string]any{"purpose": "payroll"}}, wallet.recorded)
+
+		executed, err := workflow.Request(context.Background(), req.ID)
+		require.NoError(t, err)
+		require.Equal(t, approvals.StatusExecuted, executed.Status)
+		require.Equal(t, "tx-1", executed.TransactionID)
+
+		events, err := workflow.AuditTrail(context.Background(), req.ID)
+		require.NoError(t, err)
+		require.Equal(t, []approvals.EventType{
+			approvals.EventCreated,
+			approvals.EventApproved,
+			approvals.EventApproved,
+			approvals.EventQuorumReached,
+			approvals.EventExecuted,
+		}, eventTypes(events))
+		require.Equal(t, "alice", events[1].Approver)
+		require.Equal(t, now, events[1].At)
+
+		_, err = workflow.Execute(context.Background(), req.ID)
+		require.ErrorIs(t, err, goclienterr.ErrApprovalClosed)
+		require.Equal(t, 1, wallet.finalized)
+	})
+
+	t.Run("Invalid approvals", func(t *testing.T) {
+		// given:
+		alice, bob, mallory := givenApprover(t, "alice"), givenApprover(t, "bob"), givenApprover(t, "mallory")
+		workflow := givenWorkflow(t, &fakeWallet{}, approvals.NewMemoryStore(), alice, bob)
+		req, err := workflow.Submit(context.Background(), givenDraft(t), nil)
+		require.NoError(t, err)
+		_, err = workflow.Approve(context.Background(), req.ID, alice.address, alice.sign(t, req, approvals.DecisionApprove))
+		require.NoError(t, err)
+
+		tests := map[string]struct {
+			approver  string
+			signature string
+		}{
+			"signed by another key":       {approver: bob.address, signature: mallory.sign(t, req, approvals.DecisionApprove)},
+			"signed for another decision": {approver: bob.address, signature: bob.sign(t, req, approvals.DecisionReject)},
+			"signed by a non-approver":    {approver: mallory.address, signature: mallory.sign(t, req, approvals.DecisionApprove)},
+			"approved twice":              {approver: alice.address, signature: alice.sign(t, req, approvals.DecisionApprove)},
+			"malformed signature":         {approver: bob.address, signature: "not base64"},
+		}
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				// when:
+				_, err := workflow.Approve(context.Background(), req.ID, tc.approver, tc.signature)
+
+				// then:
+				require.ErrorIs(t, err, goclienterr.ErrInvalidApproval)
+			})
+		}
+	})
+
+	t.Run("Request rejected once the quorum cannot be reached", func(t *testing.T) {
+		// given:
+		alice, bob, carol := givenApprover(t, "alice"), givenApprover(t, "bob"), givenApprover(t, "carol")
+		wallet := &fakeWallet{}
+		workflow := givenWorkflow(t, wallet, approvals.NewMemoryStore(), alice, bob, carol)
+		req, err := workflow.Submit(context.Background(), givenDraft(t), nil)
+		require.NoError(t, err)
+
+		// when:
+		first, err := workflow.Reject(context.Background(), req.ID, alice.address, alice.sign(t, req, approvals.DecisionReject))
+		require.NoError(t, err)
+		second, err := workflow.Reject(context.Background(), req.ID, bob.address, bob.sign(t, req, approvals.DecisionReject))
+		require.NoError(t, err)
+		_, approveErr := workflow.Approve(context.Background(), req.ID, carol.address, carol.sign(t, req, approvals.DecisionApprove))
+		_, executeErr := workflow.Execute(context.Background(), req.ID)
+
+		// then:
+		require.Equal(t, approvals.StatusPending, first.Status)
+		require.Equal(t, approvals.StatusRejected, second.Status)
+		require.ErrorIs(t, approveErr, goclienterr.ErrApprovalClosed)
+		require.ErrorIs(t, executeErr, goclienterr.ErrApprovalClosed)
+		require.Zero(t, wallet.finalized)
+	})
+
+	t.Run("Expired draft", func(t *testing.T) {
+		// given:
+		alice := givenApprover(t, "alice")
+		workflow := givenWorkflow(t, &fakeWallet{}, approvals.NewMemoryStore(), alice)
+		draft := givenDraft(t)
+		draft.ExpiresAt = now.Add(-time.Second)
+		req, err := workflow.Submit(context.Background(), draft, nil)
+		require.NoError(t, err)
+
+		// when:
+		_, err = workflow.Approve(context.Background(), req.ID, alice.address, alice.sign(t, req, approvals.DecisionApprove))
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrApprovalClosed)
+		expired, err := workflow.Request(context.Background(), req.ID)
+		require.NoError(t, err)
+		require.Equal(t, approvals.StatusExpired, expired.Status)
+	})
+
+	t.Run("Draft tampered with in the store isn't executed", func(t *testing.T) {
+		// given:
+		alice := givenApprover(t, "alice")
+		wallet := &fakeWallet{}
+		store := approvals.NewMemoryStore()
+		workflow := givenWorkflow(t, wallet, store, alice)
+		req, err := workflow.Submit(context.Background(), givenDraft(t), nil)
+		require.NoError(t, err)
+		_, err = workflow.Approve(context.Background(), req.ID, alice.address, alice.sign(t, req, approvals.DecisionApprove))
+		require.NoError(t, err)
+
+		tampered, err := store.Request(context.Background(), req.ID)
+		require.NoError(t, err)
+		tampered.Draft = &response.DraftTransaction{ID: req.ID, Hex: "00"}
+		require.NoError(t, store.SaveRequest(context.Background(), tampered))
+
+		// when:
+		_, err = workflow.Execute(context.Background(), req.ID)
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrInvalidApproval)
+		require.Zero(t, wallet.finalized)
+	})
+
+	t.Run("Metadata tampered with in the store isn't executed", func(t *testing.T) {
+		// given:
+		alice := givenApprover(t, "alice")
+		wallet := &fakeWallet{}
+		store := approvals.NewMemoryStore()
+		workflow := givenWorkflow(t, wallet, store, alice)
+		req, err := workflow.Submit(context.Background(), givenDraft(t), map[string]any{"payment_id": "invoice-1"})
+		require.NoError(t, err)
+		_, err = workflow.Approve(context.Background(), req.ID, alice.address, alice.sign(t, req, approvals.DecisionApprove))
+		require.NoError(t, err)
+
+		tampered, err := store.Request(context.Background(), req.ID)
+		require.NoError(t, err)
+		tampered.Metadata = map[string]any{"payment_id": "invoice-2"}
+		require.NoError(t, store.SaveRequest(context.Background(), tampered))
+
+		// when:
+		_, err = workflow.Execute(context.Background(), req.ID)
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrInvalidApproval)
+		require.Zero(t, wallet.finalized)
+	})
+
+	t.Run("Failed execution can be retried", func(t *testing.T) {
+		// given:
+		alice := givenApprover(t, "alice")
+		errUnavailable := errors.New("connection refused")
+		wallet := &fakeWallet{recordErr: errUnavailable}
+		workflow := givenWorkflow(t, wallet, approvals.NewMemoryStore(), alice)
+		req, err := workflow.Submit(context.Background(), givenDraft(t), nil)
+		require.NoError(t, err)
+		_, err = workflow.Approve(context.Background(), req.ID, alice.address, alice.sign(t, req, approvals.DecisionApprove))
+		require.NoError(t, err)
+
+		// when:
+		_, failed := workflow.Execute(context.Background(), req.ID)
+		wallet.recordErr = nil
+		tx, err := workflow.Execute(context.Background(), req.ID)
+
+		// then:
+		require.ErrorIs(t, failed, errUnavailable)
+		require.NoError(t, err)
+		require.Equal(t, "tx-1", tx.ID)
+		events, err := workflow.AuditTrail(context.Background(), req.ID)
+		require.NoError(t, err)
+		require.Equal(t, []approvals.EventType{
+			approvals.EventCreated,
+			approvals.EventApproved,
+			approvals.EventQuorumReached,
+			approvals.EventExecutionFailed,
+			approvals.EventExecuted,
+		}, eventTypes(events))
+	})
+
+	t.Run("Draft submitted twice", func(t *testing.T) {
+		// given:
+		workflow := givenWorkflow(t, &fakeWallet{}, approvals.NewMemoryStore(), givenApprover(t, "alice"))
+		_, err := workflow.Submit(context.Background(), givenDraft(t), nil)
+		require.NoError(t, err)
+
+		// when:
+		_, err = workflow.Submit(context.Background(), givenDraft(t), nil)
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrApprovalExists)
+	})
+}
+
+func TestNew_InvalidQuorum(t *testing.T) {
+	alice := approvals.Approver{Name: "alice", Address: "1BE8WfQkDDYE3zEgxBdRNuAxsnHkDcuPdT"}
+	tests := map[string]approvals.Quorum{
+		"no approvals required":         {Required: 0, Approvers: []approvals.Approver{alice}},
+		"more approvals than approvers": {Required: 2, Approvers: []approvals.Approver{alice}},
+		"duplicated approver":           {Required: 1, Approvers: []approvals.Approver{alice, alice}},
+		"approver without address":      {Required: 1, Approvers: []approvals.Approver{{Name: "bob"}}},
+	}
+	for name, quorum := range tests {
+		t.Run(name, func(t *testing.T) {
+			// when:
+			workflow, err := approvals.New(&fakeWallet{}, approvals.NewMemoryStore(), quorum)
+
+			// then:
+			require.ErrorIs(t, err, goclienterr.ErrInvalidQuorum)
+			require.Nil(t, workflow)
+		})
+	}
+}
+
+type approver struct {
+	name    string
+	key     *ec.PrivateKey
+	address string
+}
+
+func (a *approver) sign(t *testing.T, req *approvals.Request, decision approvals.Decision) string {
+	t.Helper()
+
+	sig, err := approvals.Sign(a.key, req, decision)
+	require.NoError(t, err)
+	return sig
+}
+
+func givenApprover(t *testing.T, name string) *approver {
+	t.Helper()
+
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	address, err := script.NewAddressFromPublicKey(key.PubKey(), true)
+	require.NoError(t, err)
+	return &approver{name: name, key: key, address: address.AddressString}
+}
+
+func givenWorkflow(t *testing.T, wallet approvals.Wallet, store approvals.Store, approvers ...*approver) *approvals.Workflow {
+	t.Helper()
+
+	quorum := approvals.Quorum{Required: min(2, len(approvers))}
+	for _, a := range approvers {
+		quorum.Approvers = append(quorum.Approvers, approvals.Approver{Name: a.name, Address: a.address})
+	}
+	workflow, err := approvals.New(wallet, store, quorum, approvals.WithClock(func() time.Time { return now }))
+	require.NoError(t, err)
+	return workflow
+}
+
+func givenDraft(t *testing.T) *response.DraftTransaction {
+	t.Helper()
+
+	lockingScript, err := script.NewFromHex("76a914000000000000000000000000000000000000000088ac")
+	require.NoError(t, err)
+	tx := trx.NewTransaction()
+	require.NoError(t, tx.AddInputFrom("3c8edde27cb9a9132c22038dac4391496be9db16fd21351565cc1006966fdad5", 0, lockingScript.String(), 1000, nil))
+	tx.AddOutput(&trx.TransactionOutput{Satoshis: 900, LockingScript: lockingScript})
+
+	return &response.DraftTransaction{ID: "draft-1", Hex: tx.Hex()}
+}
+
+func eventTypes(events []approvals.Event) []approvals.EventType {
+	types := make([]approvals.EventType, len(events))
+	for i, e := range events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+type fakeWallet struct {
+	draft     *response.DraftTransaction
+	recordErr error
+	finalized int
+	recorded  *commands.RecordTransaction
+}
+
+func (f *fakeWallet) DraftTransaction(context.Context, *commands.DraftTransaction) (*response.DraftTransaction, error) {
+	return f.draft, nil
+}
+
+func (f *fakeWallet) FinalizeTransaction(*response.DraftTransaction) (string, error) {
+	f.finalized++
+	return "signed", nil
+}
+
+func (f *fakeWallet) RecordTransaction(_ context.Context, cmd *commands.RecordTransaction) (*response.Transaction, error) {
+	if f.recordErr != nil {
+		return nil, f.recordErr
+	}
+	f.recorded = cmd
+	return &response.Transaction{ID: "tx-1"}, nil
+}
@@ -0,0 +1,114 @@
+package approvals
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// EventType is the type of an event in the audit trail.
+type EventType string
+
+// Types of the events in the audit trail.
+const (
+	EventCreated         EventType = "created"          // The request was submitted.
+	EventApproved        EventType = "approved"         // An approver approved the request.
+	EventRejected        EventType = "rejected"         // An approver rejected the request.
+	EventQuorumReached   EventType = "quorum_reached"   // The approvals reached the quorum.
+	EventClosed          EventType = "closed"           // The request was rejected or expired.
+	EventExecuted        EventType = "executed"         // The draft was signed and the transaction recorded.
+	EventExecutionFailed EventType = "execution_failed" // Signing or recording the approved draft failed.
+)
+
+func eventOf(decision Decision) EventType {
+	if decision == DecisionReject {
+		return EventRejected
+	}
+	return EventApproved
+}
+
+// Event is an entry of the audit trail of an approval request.
+type Event struct {
+	RequestID string
+	Type      EventType
+	Approver  string // Name of the approver, for the decisions.
+	Message   string // Details of the event.
+	At        time.Time
+}
+
+// Store keeps the approval requests and their audit trail.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Request returns the approval request, or nil if it doesn't exist.
+	Request(ctx context.Context, id string) (*Request, error)
+	// SaveRequest creates or replaces the approval request.
+	SaveRequest(ctx context.Context, r *Request) error
+	// AppendEvent appends the event to the audit trail of its request. The audit trail is append-only.
+	AppendEvent(ctx context.Context, e Event) error
+	// Events returns the audit trail of the request in the order the events were appended.
+	Events(ctx context.Context, requestID string) ([]Event, error)
+}
+
+// MemoryStore is an in-memory implementation of the Store interface.
+// It is safe for concurrent use, but the requests and the audit trail are lost when the process exits.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	requests map[string]*Request
+	events   map[string][]Event
+}
+
+// NewMemoryStore creates a new, empty MemoryStore instance.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		requests: make(map[string]*Request),
+		events:   make(map[string][]Event),
+	}
+}
+
+// Request returns a copy of the approval request, or nil if it doesn't exist.
+func (m *MemoryStore) Request(_ context.Context, id string) (*Request, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	r, ok := m.requests[id]
+	if !ok {
+		return nil, nil
+	}
+	return cloneRequest(r), nil
+}
+
+// SaveRequest stores a copy of the approval request.
+func (m *MemoryStore) SaveRequest(_ context.Context, r *Request) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[r.ID] = cloneRequest(r)
+	return nil
+}
+
+// AppendEvent appends the event to the audit trail of its request.
+func (m *MemoryStore) AppendEvent(_ context.Context, e Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.events[e.RequestID] = append(m.events[e.RequestID], e)
+	return nil
+}
+
+// Events returns a copy of the audit trail of the request.
+func (m *MemoryStore) Events(_ context.Context, requestID string) ([]Event, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return slices.Clone(m.events[requestID]), nil
+}
+
+// cloneRequest copies the request with its signatures and metadata. The draft transaction is shared, as it is never modified.
+func cloneRequest(r *Request) *Request {
+	c := *r
+	c.Signatures = slices.Clone(r.Signatures)
+	c.Metadata = maps.Clone(r.Metadata)
+	return &c
+}
@@ -0,0 +1,187 @@
+package beef
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// MaxUnminedAncestors is the maximum length of a chain of unmined ancestors resolved by Build.
+const MaxUnminedAncestors = 1000
+
+// AncestorSource provides the ancestor transactions, with the merkle paths of the mined ones.
+type AncestorSource interface {
+	// Ancestor returns the transaction with the ID; it must set the merkle path of a mined transaction.
+	Ancestor(ctx context.Context, txID string) (*trx.Transaction, error)
+}
+
+// AncestorFunc is an adapter to allow the use of ordinary functions as the AncestorSource.
+type AncestorFunc func(ctx context.Context, txID string) (*trx.Transaction, error)
+
+// Ancestor calls f(ctx, txID).
+func (f AncestorFunc) Ancestor(ctx context.Context, txID string) (*trx.Transaction, error) {
+	return f(ctx, txID)
+}
+
+// Build returns the transaction in the BEEF format, resolving the missing source transactions of its inputs
+// and of its unmined ancestors from the source, up to the mined ancestors with their merkle paths.
+// The source must provide the merkle paths of the mined ancestors, otherwise their own ancestors are resolved too.
+// The resolved ancestors are set as the source transactions of the inputs of the transaction.
+// It returns goclienterr.ErrMissingAncestor if an ancestor cannot be resolved.
+func Build(ctx context.Context, tx *trx.Transaction, source AncestorSource) ([]byte, error) {
+	r := &resolver{source: source, resolved: make(map[string]*trx.Transaction), visited: make(map[string]bool)}
+	if err := r.resolve(ctx, tx, 0); err != nil {
+		return nil, err
+	}
+	return Encode(tx)
+}
+
+type resolver struct {
+	source   AncestorSource
+	resolved map[string]*trx.Transaction
+	visited  map[string]bool
+}
+
+func (r *resolver) resolve(ctx context.Context, tx *trx.Transaction, depth int) error {
+	txID := tx.TxID().String()
+	if tx.MerklePath != nil || r.visited[txID] {
+		return nil
+	}
+	if depth > MaxUnminedAncestors {
+		return fmt.Errorf("%w: more than %d unmined ancestors of %s", goclienterr.ErrMissingAncestor, MaxUnminedAncestors, txID)
+	}
+	r.visited[txID] = true
+
+	for _, input := range tx.Inputs {
+		if input.SourceTransaction == nil {
+			ancestor, err := r.ancestor(ctx, input.SourceTXID.String())
+			if err != nil {
+				return err
+			}
+			input.SourceTransaction = ancestor
+		}
+		if err := r.resolve(ctx, input.SourceTransaction, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *resolver) ancestor(ctx context.Context, txID string) (*trx.Transaction, error) {
+	if ancestor, ok := r.resolved[txID]; ok {
+		return ancestor, nil
+	}
+	ancestor, err := r.source.Ancestor(ctx, txID)
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("%w: %s: %w", goclienterr.ErrMissingAncestor, txID, err)
+	case ancestor == nil:
+		return nil, fmt.Errorf("%w: %s", goclienterr.ErrMissingAncestor, txID)
+	case ancestor.TxID().String() != txID:
+		return nil, fmt.Errorf("%w: source returned %s for %s", goclienterr.ErrMissingAncestor, ancestor.TxID(), txID)
+	}
+	r.resolved[txID] = ancestor
+	return ancestor, nil
+}
+
+// TransactionSource provides the transactions of the wallet. It is implemented by the *spvwallet.UserAPI.
+type TransactionSource interface {
+	Transaction(ctx context.Context, ID string) (*response.Transaction, error)
+}
+
+// WalletAncestors is an AncestorSource resolving the ancestors from the transactions of the wallet.
+// The hex of a transaction is parsed as BEEF when the SPV Wallet stores it in that format, which carries
+// the merkle path of a mined transaction; the raw and extended hex carry none. A mined transaction returned
+// without its merkle path cannot be used as an ancestor, so it is resolved by the fallback source, if set,
+// e.g., the MemoryAncestors keeping the received BEEF transactions, or rejected otherwise.
+type WalletAncestors struct {
+	source   TransactionSource
+	fallback AncestorSource
+}
+
+// NewWalletAncestors creates a new WalletAncestors resolving the ancestors from the transactions of the source,
+// and the mined ancestors returned without merkle paths from the fallback, which may be nil.
+func NewWalletAncestors(source TransactionSource, fallback AncestorSource) *WalletAncestors {
+	return &WalletAncestors{source: source, fallback: fallback}
+}
+
+// Ancestor returns the transaction of the wallet with the ID.
+func (w *WalletAncestors) Ancestor(ctx context.Context, txID string) (*trx.Transaction, error) {
+	res, err := w.source.Transaction(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx *trx.Transaction
+	if IsBEEFHex(res.Hex) {
+		tx, err = DecodeHex(res.Hex)
+	} else {
+		tx, err = trx.NewTransactionFromHex(res.Hex)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse transaction %s: %w", txID, err)
+	}
+
+	if res.BlockHeight == 0 || tx.MerklePath != nil {
+		return tx, nil
+	}
+	if w.fallback == nil {
+		return nil, fmt.Errorf("transaction %s mined at height %d has no merkle path", txID, res.BlockHeight)
+	}
+	return w.fallback.Ancestor(ctx, txID)
+}
+
+// MemoryAncestors is an in-memory AncestorSource keeping the transactions added to it with their ancestors,
+// e.g., the received BEEF transactions, so the transactions spending their outputs can be encoded in BEEF.
+// It is safe for concurrent use, but the transactions are lost when the process exits.
+type MemoryAncestors struct {
+	mu           sync.RWMutex
+	transactions map[string]*trx.Transaction
+}
+
+// NewMemoryAncestors creates a new, empty MemoryAncestors instance.
+func NewMemoryAncestors() *MemoryAncestors {
+	return &MemoryAncestors{transactions: make(map[string]*trx.Transaction)}
+}
+
+// Add keeps the transaction and its ancestors linked through the source transactions of its inputs.
+// The transaction should be verified before, e.g., with Verify.
+func (m *MemoryAncestors) Add(tx *trx.Transaction) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	queue := []*trx.Transaction{tx}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		txID := current.TxID().String()
+		if known, ok := m.transactions[txID]; ok && (known.MerklePath != nil || current.MerklePath == nil) {
+			continue
+		}
+		m.transactions[txID] = current
+		if current.MerklePath != nil {
+			continue
+		}
+		for _, input := range current.Inputs {
+			if input.SourceTransaction != nil {
+				queue = append(queue, input.SourceTransaction)
+			}
+		}
+	}
+}
+
+// Ancestor returns the kept transaction with the ID, or an error if it is unknown.
+func (m *MemoryAncestors) Ancestor(_ context.Context, txID string) (*trx.Transaction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tx, ok := m.transactions[txID]
+	if !ok {
+		return nil, fmt.Errorf("transaction %s is unknown", txID)
+	}
+	return tx, nil
+}
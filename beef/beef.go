@@ -0,0 +1,189 @@
+// Package beef supports the transactions in the Background Evaluation Extended Format (BEEF, BRC-62).
+//
+// A BEEF transaction carries its unmined ancestors and the merkle paths (BUMPs) of its mined ancestors,
+// so it can be verified with SPV against the merkle roots synced from the SPV Wallet, without trusting the sender:
+//
+//	roots := beef.NewMemoryMerkleRoots()
+//	err := userAPI.SyncMerkleRoots(ctx, roots)
+//	tx, err := beef.DecodeHex(incomingBEEF)
+//	err = beef.Verify(tx, roots)
+//
+// Outgoing transactions are encoded in BEEF with Build, resolving their ancestors from an AncestorSource:
+// the WalletAncestors fetching the transactions of the wallet, or the MemoryAncestors keeping the ancestors
+// of the received BEEF transactions.
+package beef
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/bitcoin-sv/go-sdk/script/interpreter"
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/bitcoin-sv/go-sdk/transaction/chaintracker"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+)
+
+// versionHex is the hex-encoded version prefix of a BEEF transaction (BRC-62).
+var versionHex = func() string {
+	prefix := make([]byte, 4)
+	binary.LittleEndian.PutUint32(prefix, trx.BEEF_VERSION)
+	return hex.EncodeToString(prefix)
+}()
+
+// IsBEEF reports whether the bytes start with the BEEF version prefix.
+func IsBEEF(b []byte) bool {
+	return len(b) >= 4 && binary.LittleEndian.Uint32(b) == trx.BEEF_VERSION
+}
+
+// IsBEEFHex reports whether the hex starts with the BEEF version prefix, so it can be told apart from a raw transaction hex.
+func IsBEEFHex(s string) bool {
+	return len(s) >= len(versionHex) && strings.EqualFold(s[:len(versionHex)], versionHex)
+}
+
+// Decode parses the BEEF transaction and returns the transaction it is about, with its ancestors linked
+// through the source transactions of its inputs, and the merkle paths set on the mined ancestors.
+// It returns goclienterr.ErrInvalidBEEF if the BEEF is malformed. The transaction isn't verified; see Verify.
+func Decode(b []byte) (tx *trx.Transaction, err error) {
+	if !IsBEEF(b) {
+		return nil, fmt.Errorf("%w: missing BEEF version prefix", goclienterr.ErrInvalidBEEF)
+	}
+	defer func() {
+		// the parser of the SDK panics on a reference to a transaction missing from the BEEF
+		if r := recover(); r != nil {
+			tx, err = nil, fmt.Errorf("%w: %v", goclienterr.ErrInvalidBEEF, r)
+		}
+	}()
+
+	tx, err = trx.NewTransactionFromBEEF(b)
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrInvalidBEEF, err)
+	case tx == nil:
+		return nil, fmt.Errorf("%w: no transactions", goclienterr.ErrInvalidBEEF)
+	}
+	return tx, nil
+}
+
+// DecodeHex parses the hex-encoded BEEF transaction; see Decode.
+func DecodeHex(s string) (*trx.Transaction, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrInvalidBEEF, err)
+	}
+	return Decode(b)
+}
+
+// Encode returns the transaction in the BEEF format. The source transactions of the inputs of the transaction
+// and of its unmined ancestors must be set, up to the mined ancestors with their merkle paths; see Build.
+// It returns goclienterr.ErrMissingAncestor if an ancestor is missing.
+func Encode(tx *trx.Transaction) ([]byte, error) {
+	if err := checkAncestors(tx, make(map[string]bool)); err != nil {
+		return nil, err
+	}
+	b, err := tx.BEEF()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrInvalidBEEF, err)
+	}
+	return b, nil
+}
+
+// EncodeHex returns the transaction in the hex-encoded BEEF format; see Encode.
+func EncodeHex(tx *trx.Transaction) (string, error) {
+	b, err := Encode(tx)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func checkAncestors(tx *trx.Transaction, checked map[string]bool) error {
+	txID := tx.TxID().String()
+	if tx.MerklePath != nil || checked[txID] {
+		return nil
+	}
+	checked[txID] = true
+	for vin, input := range tx.Inputs {
+		if input.SourceTransaction == nil {
+			return fmt.Errorf("%w: %s spent by input %d of %s", goclienterr.ErrMissingAncestor, input.SourceTXID, vin, txID)
+		}
+		if err := checkAncestors(input.SourceTransaction, checked); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Verify verifies the decoded BEEF transaction with SPV: the merkle paths of its mined ancestors must lead
+// to the merkle roots known to the roots at their block heights, e.g., the MemoryMerkleRoots synced from the SPV Wallet,
+// and the scripts of the transaction and its unmined ancestors must unlock the spent outputs without creating satoshis.
+// It returns goclienterr.ErrBEEFVerification describing the first failure.
+func Verify(tx *trx.Transaction, roots chaintracker.ChainTracker) error {
+	if roots == nil {
+		return fmt.Errorf("%w: no merkle roots to verify against", goclienterr.ErrBEEFVerification)
+	}
+
+	verified := make(map[string]bool)
+	queue := []*trx.Transaction{tx}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		txID := current.TxID()
+		if verified[txID.String()] {
+			continue
+		}
+
+		if current.MerklePath != nil {
+			ok, err := current.MerklePath.Verify(txID, roots)
+			if err != nil {
+				return fmt.Errorf("%w: failed to verify merkle path of %s: %w", goclienterr.ErrBEEFVerification, txID, err)
+			}
+			if !ok {
+				return fmt.Errorf("%w: merkle path of %s doesn't lead to a known merkle root at height %d",
+					goclienterr.ErrBEEFVerification, txID, current.MerklePath.BlockHeight)
+			}
+			verified[txID.String()] = true
+			continue
+		}
+
+		var inputs uint64
+		for vin, input := range current.Inputs {
+			source := input.SourceTxOutput()
+			if input.SourceTransaction == nil || source == nil {
+				return fmt.Errorf("%w: input %d of unmined %s has no source transaction", goclienterr.ErrBEEFVerification, vin, txID)
+			}
+			inputs += source.Satoshis
+			err := interpreter.NewEngine().Execute(
+				interpreter.WithTx(current, vin, source),
+				interpreter.WithForkID(),
+				interpreter.WithAfterGenesis(),
+			)
+			if err != nil {
+				return fmt.Errorf("%w: input %d of %s doesn't unlock its source output: %w", goclienterr.ErrBEEFVerification, vin, txID, err)
+			}
+			queue = append(queue, input.SourceTransaction)
+		}
+		if outputs := current.TotalOutputSatoshis(); outputs > inputs {
+			return fmt.Errorf("%w: outputs of %s exceed its inputs: %d > %d", goclienterr.ErrBEEFVerification, txID, outputs, inputs)
+		}
+		verified[txID.String()] = true
+	}
+	return nil
+}
+
+// RawHex returns the raw hex of the transaction, given either as a raw transaction hex or as a BEEF hex
+// verified against the roots. It lets the BEEF transactions be passed where the raw hex is expected.
+func RawHex(s string, roots chaintracker.ChainTracker) (string, error) {
+	if !IsBEEFHex(s) {
+		return s, nil
+	}
+	tx, err := DecodeHex(s)
+	if err != nil {
+		return "", err
+	}
+	if err := Verify(tx, roots); err != nil {
+		return "", err
+	}
+	return tx.Hex(), nil
+}
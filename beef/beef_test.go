@@ -0,0 +1,388 @@
+package beef_test
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/bitcoin-sv/go-sdk/chainhash"
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/bitcoin-sv/go-sdk/script"
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/bitcoin-sv/go-sdk/transaction/template/p2pkh"
+	"github.com/bitcoin-sv/spv-wallet-go-client/beef"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet/models"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+	"github.com/stretchr/testify/require"
+)
+
+const blockHeight = 850000
+
+func TestBuild(t *testing.T) {
+	t.Run("encodes the transaction with its unmined and mined ancestors", func(t *testing.T) {
+		// given:
+		key := givenKey(t)
+		mined := givenMinedTransaction(t, key, 2000)
+		unmined := givenSpendingTransaction(t, key, mined, 1500)
+		tx := givenSpendingTransaction(t, key, unmined, 1000)
+		ancestors := beef.NewMemoryAncestors()
+		ancestors.Add(unmined)
+
+		// when:
+		b, err := beef.Build(context.Background(), detached(t, tx), ancestors)
+
+		// then:
+		require.NoError(t, err)
+		require.True(t, beef.IsBEEF(b))
+
+		decoded, err := beef.Decode(b)
+		require.NoError(t, err)
+		require.Equal(t, tx.TxID().String(), decoded.TxID().String())
+		parent := decoded.Inputs[0].SourceTransaction
+		require.Equal(t, unmined.TxID().String(), parent.TxID().String())
+		require.Nil(t, parent.MerklePath)
+		grandparent := parent.Inputs[0].SourceTransaction
+		require.Equal(t, mined.TxID().String(), grandparent.TxID().String())
+		require.NotNil(t, grandparent.MerklePath)
+	})
+
+	t.Run("fails on a missing ancestor", func(t *testing.T) {
+		// given:
+		key := givenKey(t)
+		mined := givenMinedTransaction(t, key, 2000)
+		tx := givenSpendingTransaction(t, key, mined, 1000)
+
+		// when:
+		b, err := beef.Build(context.Background(), detached(t, tx), beef.NewMemoryAncestors())
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrMissingAncestor)
+		require.Nil(t, b)
+	})
+
+	t.Run("fails on an ancestor with another ID", func(t *testing.T) {
+		// given:
+		key := givenKey(t)
+		mined := givenMinedTransaction(t, key, 2000)
+		other := givenMinedTransaction(t, key, 3000)
+		tx := givenSpendingTransaction(t, key, mined, 1000)
+		source := beef.AncestorFunc(func(context.Context, string) (*trx.Transaction, error) {
+			return other, nil
+		})
+
+		// when:
+		b, err := beef.Build(context.Background(), detached(t, tx), source)
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrMissingAncestor)
+		require.Nil(t, b)
+	})
+}
+
+func TestWalletAncestors(t *testing.T) {
+	t.Run("resolves the ancestors from the transactions of the wallet", func(t *testing.T) {
+		// given:
+		key := givenKey(t)
+		mined := givenMinedTransaction(t, key, 2000)
+		unmined := givenSpendingTransaction(t, key, mined, 1500)
+		tx := givenSpendingTransaction(t, key, unmined, 1000)
+		wallet := fakeTransactions{
+			mined.TxID().String():   {Hex: givenBEEFHex(t, mined), BlockHeight: blockHeight},
+			unmined.TxID().String(): {Hex: unmined.Hex()},
+		}
+
+		// when:
+		b, err := beef.Build(context.Background(), detached(t, tx), beef.NewWalletAncestors(wallet, nil))
+
+		// then:
+		require.NoError(t, err)
+		decoded, err := beef.Decode(b)
+		require.NoError(t, err)
+		grandparent := decoded.Inputs[0].SourceTransaction.Inputs[0].SourceTransaction
+		require.Equal(t, mined.TxID().String(), grandparent.TxID().String())
+		require.NotNil(t, grandparent.MerklePath)
+	})
+
+	t.Run("resolves a mined ancestor without merkle path from the fallback", func(t *testing.T) {
+		// given:
+		key := givenKey(t)
+		mined := givenMinedTransaction(t, key, 2000)
+		tx := givenSpendingTransaction(t, key, mined, 1000)
+		wallet := fakeTransactions{mined.TxID().String(): {Hex: mined.Hex(), BlockHeight: blockHeight}}
+		fallback := beef.NewMemoryAncestors()
+		fallback.Add(mined)
+
+		// when:
+		b, err := beef.Build(context.Background(), detached(t, tx), beef.NewWalletAncestors(wallet, fallback))
+
+		// then:
+		require.NoError(t, err)
+		require.True(t, beef.IsBEEF(b))
+	})
+
+	t.Run("fails on a mined ancestor without merkle path", func(t *testing.T) {
+		// given:
+		key := givenKey(t)
+		mined := givenMinedTransaction(t, key, 2000)
+		tx := givenSpendingTransaction(t, key, mined, 1000)
+		wallet := fakeTransactions{mined.TxID().String(): {Hex: mined.Hex(), BlockHeight: blockHeight}}
+
+		// when:
+		b, err := beef.Build(context.Background(), detached(t, tx), beef.NewWalletAncestors(wallet, nil))
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrMissingAncestor)
+		require.ErrorContains(t, err, "has no merkle path")
+		require.Nil(t, b)
+	})
+}
+
+func TestDecode(t *testing.T) {
+	tests := map[string]string{
+		"raw transaction hex": "0100000000000000000000",
+		"truncated BEEF":      "0100beef01fe",
+		"not hex":             "0100beefzz",
+	}
+	for name, s := range tests {
+		t.Run(name, func(t *testing.T) {
+			// when:
+			tx, err := beef.DecodeHex(s)
+
+			// then:
+			require.ErrorIs(t, err, goclienterr.ErrInvalidBEEF)
+			require.Nil(t, tx)
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Run("verifies the transaction against the known merkle roots", func(t *testing.T) {
+		// given:
+		key := givenKey(t)
+		mined := givenMinedTransaction(t, key, 2000)
+		tx := givenSpendingTransaction(t, key, mined, 1000)
+		decoded := givenDecoded(t, tx)
+
+		// when:
+		err := beef.Verify(decoded, givenMerkleRoots(t, mined))
+
+		// then:
+		require.NoError(t, err)
+	})
+
+	t.Run("fails on an unknown merkle root", func(t *testing.T) {
+		// given:
+		key := givenKey(t)
+		mined := givenMinedTransaction(t, key, 2000)
+		tx := givenSpendingTransaction(t, key, mined, 1000)
+		decoded := givenDecoded(t, tx)
+		roots := beef.NewMemoryMerkleRoots()
+		require.NoError(t, roots.SaveMerkleRoots([]models.MerkleRoot{{MerkleRoot: chainhash.Hash{1}.String(), BlockHeight: blockHeight}}))
+
+		// when:
+		err := beef.Verify(decoded, roots)
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrBEEFVerification)
+	})
+
+	t.Run("fails on an invalid signature", func(t *testing.T) {
+		// given:
+		key := givenKey(t)
+		mined := givenMinedTransaction(t, key, 2000)
+		tx := givenSpendingTransaction(t, givenKey(t), mined, 1000)
+		decoded := givenDecoded(t, tx)
+
+		// when:
+		err := beef.Verify(decoded, givenMerkleRoots(t, mined))
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrBEEFVerification)
+	})
+
+	t.Run("fails on outputs exceeding the inputs", func(t *testing.T) {
+		// given:
+		key := givenKey(t)
+		mined := givenMinedTransaction(t, key, 2000)
+		tx := givenSpendingTransaction(t, key, mined, 3000)
+		decoded := givenDecoded(t, tx)
+
+		// when:
+		err := beef.Verify(decoded, givenMerkleRoots(t, mined))
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrBEEFVerification)
+	})
+}
+
+func TestRawHex(t *testing.T) {
+	t.Run("returns the raw hex of a verified BEEF transaction", func(t *testing.T) {
+		// given:
+		key := givenKey(t)
+		mined := givenMinedTransaction(t, key, 2000)
+		tx := givenSpendingTransaction(t, key, mined, 1000)
+		beefHex, err := beef.EncodeHex(tx)
+		require.NoError(t, err)
+
+		// when:
+		raw, err := beef.RawHex(beefHex, givenMerkleRoots(t, mined))
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, tx.Hex(), raw)
+	})
+
+	t.Run("returns a raw hex unchanged", func(t *testing.T) {
+		// given:
+		key := givenKey(t)
+		tx := givenMinedTransaction(t, key, 2000)
+
+		// when:
+		raw, err := beef.RawHex(tx.Hex(), nil)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, tx.Hex(), raw)
+	})
+
+	t.Run("fails without merkle roots", func(t *testing.T) {
+		// given:
+		key := givenKey(t)
+		mined := givenMinedTransaction(t, key, 2000)
+		tx := givenSpendingTransaction(t, key, mined, 1000)
+		beefHex, err := beef.EncodeHex(tx)
+		require.NoError(t, err)
+
+		// when:
+		raw, err := beef.RawHex(beefHex, nil)
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrBEEFVerification)
+		require.Empty(t, raw)
+	})
+}
+
+func TestMemoryMerkleRoots(t *testing.T) {
+	// given:
+	roots := beef.NewMemoryMerkleRoots()
+	first, last := chainhash.Hash{1}, chainhash.Hash{2}
+
+	// when:
+	err := roots.SaveMerkleRoots([]models.MerkleRoot{
+		{MerkleRoot: first.String(), BlockHeight: 1},
+		{MerkleRoot: last.String(), BlockHeight: 2},
+	})
+
+	// then:
+	require.NoError(t, err)
+	require.Equal(t, last.String(), roots.GetLastMerkleRoot())
+
+	valid, err := roots.IsValidRootForHeight(&first, 1)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	valid, err = roots.IsValidRootForHeight(&first, 2)
+	require.NoError(t, err)
+	require.False(t, valid)
+}
+
+func givenKey(t *testing.T) *ec.PrivateKey {
+	t.Helper()
+
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	return key
+}
+
+func givenP2PKH(t *testing.T, key *ec.PrivateKey) *script.Script {
+	t.Helper()
+
+	address, err := script.NewAddressFromPublicKey(key.PubKey(), true)
+	require.NoError(t, err)
+	lockingScript, err := p2pkh.Lock(address)
+	require.NoError(t, err)
+	return lockingScript
+}
+
+// givenMinedTransaction returns a transaction paying the key, mined alone in a block at the blockHeight.
+func givenMinedTransaction(t *testing.T, key *ec.PrivateKey, satoshis uint64) *trx.Transaction {
+	t.Helper()
+
+	tx := trx.NewTransaction()
+	tx.AddInput(&trx.TransactionInput{
+		SourceTXID:       &chainhash.Hash{byte(satoshis)},
+		UnlockingScript:  &script.Script{script.OpTRUE},
+		SequenceNumber:   trx.DefaultSequenceNumber,
+		SourceTxOutIndex: 0,
+	})
+	tx.AddOutput(&trx.TransactionOutput{Satoshis: satoshis, LockingScript: givenP2PKH(t, key)})
+
+	isTxID := true
+	tx.MerklePath = trx.NewMerklePath(blockHeight, [][]*trx.PathElement{{{Offset: 0, Hash: tx.TxID(), Txid: &isTxID}}})
+	return tx
+}
+
+// givenSpendingTransaction returns a transaction spending the first output of the parent with the key.
+func givenSpendingTransaction(t *testing.T, key *ec.PrivateKey, parent *trx.Transaction, satoshis uint64) *trx.Transaction {
+	t.Helper()
+
+	unlocker, err := p2pkh.Unlock(key, nil)
+	require.NoError(t, err)
+	tx := trx.NewTransaction()
+	tx.AddInputFromTx(parent, 0, unlocker)
+	tx.AddOutput(&trx.TransactionOutput{Satoshis: satoshis, LockingScript: givenP2PKH(t, key)})
+	require.NoError(t, tx.Sign())
+	return tx
+}
+
+func givenDecoded(t *testing.T, tx *trx.Transaction) *trx.Transaction {
+	t.Helper()
+
+	b, err := beef.Encode(tx)
+	require.NoError(t, err)
+	decoded, err := beef.Decode(b)
+	require.NoError(t, err)
+	return decoded
+}
+
+func givenMerkleRoots(t *testing.T, mined ...*trx.Transaction) *beef.MemoryMerkleRoots {
+	t.Helper()
+
+	roots := beef.NewMemoryMerkleRoots()
+	for _, tx := range mined {
+		root, err := tx.MerklePath.ComputeRoot(tx.TxID())
+		require.NoError(t, err)
+		err = roots.SaveMerkleRoots([]models.MerkleRoot{{MerkleRoot: root.String(), BlockHeight: int(tx.MerklePath.BlockHeight)}})
+		require.NoError(t, err)
+	}
+	return roots
+}
+
+// detached returns the transaction parsed from its raw hex, without the source transactions of its inputs.
+func detached(t *testing.T, tx *trx.Transaction) *trx.Transaction {
+	t.Helper()
+
+	detached, err := trx.NewTransactionFromHex(tx.Hex())
+	require.NoError(t, err)
+	return detached
+}
+
+func givenBEEFHex(t *testing.T, tx *trx.Transaction) string {
+	t.Helper()
+
+	b, err := beef.Encode(tx)
+	require.NoError(t, err)
+	return hex.EncodeToString(b)
+}
+
+type fakeTransactions map[string]*response.Transaction
+
+func (f fakeTransactions) Transaction(_ context.Context, id string) (*response.Transaction, error) {
+	tx, ok := f[id]
+	if !ok {
+		return nil, errors.New("transaction not found")
+	}
+	return tx, nil
+}
@@ -0,0 +1,51 @@
+package beef
+
+import (
+	"sync"
+
+	"github.com/bitcoin-sv/go-sdk/chainhash"
+	"github.com/bitcoin-sv/spv-wallet/models"
+)
+
+// MemoryMerkleRoots is an in-memory merkle-root repository. It is filled by the UserAPI.SyncMerkleRoots
+// and verifies the merkle paths of the BEEF transactions as the chain tracker of Verify.
+// It is safe for concurrent use, but the merkle roots are lost when the process exits.
+type MemoryMerkleRoots struct {
+	mu    sync.RWMutex
+	roots map[uint32]string
+	last  string
+}
+
+// NewMemoryMerkleRoots creates a new, empty MemoryMerkleRoots instance.
+func NewMemoryMerkleRoots() *MemoryMerkleRoots {
+	return &MemoryMerkleRoots{roots: make(map[uint32]string)}
+}
+
+// GetLastMerkleRoot returns the merkle root with the highest block height, or an empty string if there are none.
+func (m *MemoryMerkleRoots) GetLastMerkleRoot() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.last
+}
+
+// SaveMerkleRoots stores the synced merkle roots, sorted in ascending order by block height.
+func (m *MemoryMerkleRoots) SaveMerkleRoots(syncedMerkleRoots []models.MerkleRoot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, root := range syncedMerkleRoots {
+		m.roots[uint32(root.BlockHeight)] = root.MerkleRoot //nolint: gosec // block heights fit in uint32
+		m.last = root.MerkleRoot
+	}
+	return nil
+}
+
+// IsValidRootForHeight reports whether the merkle root is the known merkle root of the block at the height.
+func (m *MemoryMerkleRoots) IsValidRootForHeight(root *chainhash.Hash, height uint32) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	known, ok := m.roots[height]
+	return ok && known == root.String(), nil
+}
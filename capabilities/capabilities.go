@@ -0,0 +1,255 @@
+// Package capabilities tells which features the SPV Wallet server enables, from its shared configuration,
+// so the methods depending on an unavailable feature fail fast with an *UnavailableError
+// instead of an opaque response of the server, e.g., 404 Not Found.
+// The features the shared configuration doesn't publish, such as the v2 API, are learned with a Probe.
+//
+// The shared configuration is retrieved lazily, on the first check, and cached with the results of the probes:
+//
+//	caps := capabilities.New(userAPI)
+//	if err := caps.Require(ctx, capabilities.FeaturePikeContacts); err != nil {
+//		// errors.Is(err, goclienterr.ErrFeatureUnavailable)
+//	}
+package capabilities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// Feature is the name of an experimental feature in the shared configuration of the SPV Wallet server,
+// or of a feature learned with a Probe.
+type Feature string
+
+const (
+	// FeaturePikeContacts is the PIKE contacts exchange, the invitations of the contacts.
+	FeaturePikeContacts Feature = "pikeContactsEnabled"
+	// FeaturePikePayments is the PIKE payments to the contacts.
+	FeaturePikePayments Feature = "pikePaymentEnabled"
+	// FeatureV2 is the v2 API: the transaction outlines, the operations, the users and the data records.
+	// The shared configuration doesn't publish it; the UserAPI probes the v2 current user endpoint instead,
+	// the servers without the v2 API responding with 404 Not Found.
+	FeatureV2 Feature = "v2"
+)
+
+// UnavailableError is returned when a feature is unavailable on the SPV Wallet server.
+type UnavailableError struct {
+	Feature Feature // Name of the unavailable feature.
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("%s: %s", goclienterr.ErrFeatureUnavailable, e.Feature)
+}
+
+// Unwrap returns goclienterr.ErrFeatureUnavailable.
+func (e *UnavailableError) Unwrap() error {
+	return goclienterr.ErrFeatureUnavailable
+}
+
+// Source retrieves the shared configuration of the SPV Wallet server, e.g., the UserAPI or the AdminAPI.
+type Source interface {
+	SharedConfig(ctx context.Context) (*response.SharedConfig, error)
+}
+
+// Probe reports whether a feature the shared configuration doesn't publish is available on the SPV Wallet server,
+// e.g., by requesting one of its endpoints.
+type Probe func(ctx context.Context) (bool, error)
+
+// Capabilities caches the shared configuration of the SPV Wallet server and checks the features against it,
+// or against the cached results of their probes. It is safe for concurrent use.
+type Capabilities struct {
+	source Source
+	mu     sync.Mutex
+	config *response.SharedConfig
+	probes map[Feature]*probeState
+	retry  time.Duration
+}
+
+// DefaultProbeRetryInterval is the time for which a failed probe is cached before the feature is probed again.
+const DefaultProbeRetryInterval = 30 * time.Second
+
+// probeState is the probe of a feature with its result.
+type probeState struct {
+	probe    Probe
+	done     bool // The probe succeeded, and enabled is its result.
+	enabled  bool
+	err      error // Error of the last failed probe, returned until the retry interval passes.
+	failedAt time.Time
+	running  chan struct{} // Closed when the running probe completes; nil if the probe isn't running.
+}
+
+// New creates the Capabilities retrieving the shared configuration from the source on the first check.
+func New(source Source) *Capabilities {
+	return &Capabilities{source: source, retry: DefaultProbeRetryInterval}
+}
+
+// NewFromConfig creates the Capabilities of the already retrieved shared configuration.
+func NewFromConfig(config *response.SharedConfig) *Capabilities {
+	return &Capabilities{config: config, retry: DefaultProbeRetryInterval}
+}
+
+// SetProbe sets the probe of the feature, checked instead of the shared configuration.
+// The result of a successful probe is cached until the next Load; the error of a failed probe
+// is cached for the probe retry interval, DefaultProbeRetryInterval unless set with SetProbeRetryInterval.
+func (c *Capabilities) SetProbe(feature Feature, probe Probe) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.probes == nil {
+		c.probes = make(map[Feature]*probeState)
+	}
+	c.probes[feature] = &probeState{probe: probe}
+}
+
+// SetProbeRetryInterval sets the time for which a failed probe is cached before the feature is probed again;
+// zero retries the probe on every check.
+func (c *Capabilities) SetProbeRetryInterval(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.retry = interval
+}
+
+// Load retrieves the shared configuration from the source, replacing the cached one, and forgets the results
+// of the probes, e.g., to learn the capabilities at construction or after the server is upgraded.
+func (c *Capabilities) Load(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for feature, state := range c.probes {
+		c.probes[feature] = &probeState{probe: state.probe}
+	}
+	return c.load(ctx)
+}
+
+// Config returns the shared configuration, retrieving it from the source if it isn't cached yet.
+// A failed retrieval isn't cached, so it is retried by the next call.
+func (c *Capabilities) Config(ctx context.Context) (*response.SharedConfig, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.config == nil {
+		if err := c.load(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return c.config, nil
+}
+
+func (c *Capabilities) load(ctx context.Context) error {
+	if c.source == nil {
+		return errors.New("no source of the shared configuration")
+	}
+	config, err := c.source.SharedConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve shared configuration: %w", err)
+	}
+	c.config = config
+	return nil
+}
+
+// Enabled reports whether the feature is enabled by the SPV Wallet server.
+// A feature with a probe is checked with the probe, on the first call, and isn't looked up in the shared configuration.
+// A failed probe is cached for the probe retry interval, so the feature isn't probed on every call while the probe fails.
+func (c *Capabilities) Enabled(ctx context.Context, feature Feature) (bool, error) {
+	if enabled, probed, err := c.probe(ctx, feature); probed || err != nil {
+		return enabled, err
+	}
+
+	config, err := c.Config(ctx)
+	if err != nil {
+		return false, err
+	}
+	return config.ExperimentalFeatures[string(feature)], nil
+}
+
+// probe returns the result of the probe of the feature, and whether the feature has a probe.
+// The probe runs without the lock held, once at a time per feature; the concurrent checks wait for its result.
+func (c *Capabilities) probe(ctx context.Context, feature Feature) (enabled, probed bool, err error) {
+	for {
+		c.mu.Lock()
+		state, ok := c.probes[feature]
+		switch {
+		case !ok:
+			c.mu.Unlock()
+			return false, false, nil
+		case state.done:
+			c.mu.Unlock()
+			return state.enabled, true, nil
+		case state.running != nil:
+			running := state.running
+			c.mu.Unlock()
+			select {
+			case <-running:
+				continue
+			case <-ctx.Done():
+				return false, true, fmt.Errorf("failed to probe %s: %w", feature, ctx.Err())
+			}
+		case state.err != nil && time.Since(state.failedAt) < c.retry:
+			c.mu.Unlock()
+			return false, true, state.err
+		}
+		running := make(chan struct{})
+		state.running = running
+		c.mu.Unlock()
+
+		enabled, err = state.probe(ctx)
+		if err != nil {
+			err = fmt.Errorf("failed to probe %s: %w", feature, err)
+		}
+
+		c.mu.Lock()
+		state.running = nil
+		state.done, state.enabled, state.err = err == nil, enabled, err
+		if err != nil {
+			state.failedAt = time.Now()
+		}
+		close(running)
+		c.mu.Unlock()
+		return enabled && err == nil, true, err
+	}
+}
+
+// Require returns an *UnavailableError for the first of the features which isn't enabled by the SPV Wallet server,
+// or the error of the retrieval of the shared configuration.
+func (c *Capabilities) Require(ctx context.Context, features ...Feature) error {
+	for _, feature := range features {
+		enabled, err := c.Enabled(ctx, feature)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			return &UnavailableError{Feature: feature}
+		}
+	}
+	return nil
+}
+
+// PaymailDomains returns the paymail domains handled by the SPV Wallet server.
+func (c *Capabilities) PaymailDomains(ctx context.Context) ([]string, error) {
+	config, err := c.Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(config.PaymailDomains), nil
+}
+
+// HandlesPaymail reports whether the domain of the paymail address is handled by the SPV Wallet server.
+func (c *Capabilities) HandlesPaymail(ctx context.Context, paymail string) (bool, error) {
+	_, domain, ok := strings.Cut(paymail, "@")
+	if !ok {
+		return false, nil
+	}
+	domains, err := c.PaymailDomains(ctx)
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(domains, func(d string) bool { return strings.EqualFold(d, domain) }), nil
+}
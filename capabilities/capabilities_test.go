@@ -0,0 +1,250 @@
+package capabilities_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/capabilities"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilities_Require(t *testing.T) {
+	t.Run("passes for the enabled features", func(t *testing.T) {
+		// given:
+		source := givenSource(map[string]bool{"pikeContactsEnabled": true, "pikePaymentEnabled": true})
+		caps := capabilities.New(source)
+
+		// when:
+		err := caps.Require(context.Background(), capabilities.FeaturePikeContacts, capabilities.FeaturePikePayments)
+
+		// then:
+		require.NoError(t, err)
+	})
+
+	t.Run("fails with the first unavailable feature", func(t *testing.T) {
+		// given:
+		source := givenSource(map[string]bool{"pikeContactsEnabled": true, "pikePaymentEnabled": false})
+		caps := capabilities.New(source)
+
+		// when:
+		err := caps.Require(context.Background(), capabilities.FeaturePikeContacts, capabilities.FeaturePikePayments)
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrFeatureUnavailable)
+		var unavailable *capabilities.UnavailableError
+		require.ErrorAs(t, err, &unavailable)
+		require.Equal(t, capabilities.FeaturePikePayments, unavailable.Feature)
+	})
+
+	t.Run("retrieves the shared configuration once", func(t *testing.T) {
+		// given:
+		source := givenSource(map[string]bool{"pikePaymentEnabled": true})
+		caps := capabilities.New(source)
+
+		// when:
+		for range 3 {
+			require.NoError(t, caps.Require(context.Background(), capabilities.FeaturePikePayments))
+		}
+
+		// then:
+		require.Equal(t, 1, source.calls)
+	})
+
+	t.Run("retries a failed retrieval", func(t *testing.T) {
+		// given:
+		source := givenSource(map[string]bool{"pikePaymentEnabled": true})
+		source.err = errors.New("unavailable")
+		caps := capabilities.New(source)
+
+		// when:
+		err := caps.Require(context.Background(), capabilities.FeaturePikePayments)
+
+		// then:
+		require.ErrorIs(t, err, source.err)
+		require.NotErrorIs(t, err, goclienterr.ErrFeatureUnavailable)
+
+		// when:
+		source.err = nil
+		err = caps.Require(context.Background(), capabilities.FeaturePikePayments)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, 2, source.calls)
+	})
+
+	t.Run("Load replaces the cached shared configuration", func(t *testing.T) {
+		// given:
+		source := givenSource(map[string]bool{"pikePaymentEnabled": false})
+		caps := capabilities.New(source)
+		require.ErrorIs(t, caps.Require(context.Background(), capabilities.FeaturePikePayments), goclienterr.ErrFeatureUnavailable)
+		source.config.ExperimentalFeatures["pikePaymentEnabled"] = true
+
+		// when:
+		err := caps.Load(context.Background())
+
+		// then:
+		require.NoError(t, err)
+		require.NoError(t, caps.Require(context.Background(), capabilities.FeaturePikePayments))
+	})
+}
+
+func TestCapabilities_Probe(t *testing.T) {
+	t.Run("checks the feature with its probe once", func(t *testing.T) {
+		// given:
+		source := givenSource(map[string]bool{"v2": true})
+		caps := capabilities.New(source)
+		calls := 0
+		caps.SetProbe(capabilities.FeatureV2, func(context.Context) (bool, error) {
+			calls++
+			return false, nil
+		})
+
+		// when:
+		first := caps.Require(context.Background(), capabilities.FeatureV2)
+		second := caps.Require(context.Background(), capabilities.FeatureV2)
+
+		// then:
+		require.ErrorIs(t, first, goclienterr.ErrFeatureUnavailable)
+		require.ErrorIs(t, second, goclienterr.ErrFeatureUnavailable)
+		require.Equal(t, 1, calls)
+		require.Zero(t, source.calls)
+	})
+
+	t.Run("retries a failed probe", func(t *testing.T) {
+		// given:
+		caps := capabilities.New(givenSource(nil))
+		errProbe := errors.New("unavailable")
+		caps.SetProbe(capabilities.FeatureV2, func(context.Context) (bool, error) { return false, errProbe })
+
+		// when:
+		err := caps.Require(context.Background(), capabilities.FeatureV2)
+
+		// then:
+		require.ErrorIs(t, err, errProbe)
+		require.NotErrorIs(t, err, goclienterr.ErrFeatureUnavailable)
+
+		// when:
+		caps.SetProbe(capabilities.FeatureV2, func(context.Context) (bool, error) { return true, nil })
+		err = caps.Require(context.Background(), capabilities.FeatureV2)
+
+		// then:
+		require.NoError(t, err)
+	})
+
+	t.Run("caches a failed probe for the retry interval", func(t *testing.T) {
+		// given:
+		caps := capabilities.New(givenSource(nil))
+		errProbe := errors.New("unauthorized")
+		calls := 0
+		caps.SetProbe(capabilities.FeatureV2, func(context.Context) (bool, error) {
+			calls++
+			return false, errProbe
+		})
+
+		// when:
+		first := caps.Require(context.Background(), capabilities.FeatureV2)
+		second := caps.Require(context.Background(), capabilities.FeatureV2)
+
+		// then:
+		require.ErrorIs(t, first, errProbe)
+		require.ErrorIs(t, second, errProbe)
+		require.Equal(t, 1, calls)
+
+		// when:
+		caps.SetProbeRetryInterval(0)
+		err := caps.Require(context.Background(), capabilities.FeatureV2)
+
+		// then:
+		require.ErrorIs(t, err, errProbe)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("doesn't block the other checks while probing", func(t *testing.T) {
+		// given:
+		caps := capabilities.New(givenSource(map[string]bool{"pikeContactsEnabled": true}))
+		started, release := make(chan struct{}), make(chan struct{})
+		var calls atomic.Int32
+		caps.SetProbe(capabilities.FeatureV2, func(context.Context) (bool, error) {
+			if calls.Add(1) == 1 {
+				close(started)
+			}
+			<-release
+			return true, nil
+		})
+		results := make(chan error, 2)
+		for range 2 {
+			go func() { results <- caps.Require(context.Background(), capabilities.FeatureV2) }()
+		}
+		<-started
+
+		// when:
+		err := caps.Require(context.Background(), capabilities.FeaturePikeContacts)
+		close(release)
+
+		// then:
+		require.NoError(t, err)
+		require.NoError(t, <-results)
+		require.NoError(t, <-results)
+		require.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("Load forgets the results of the probes", func(t *testing.T) {
+		// given:
+		caps := capabilities.New(givenSource(nil))
+		enabled := false
+		caps.SetProbe(capabilities.FeatureV2, func(context.Context) (bool, error) { return enabled, nil })
+		require.ErrorIs(t, caps.Require(context.Background(), capabilities.FeatureV2), goclienterr.ErrFeatureUnavailable)
+		enabled = true
+
+		// when:
+		err := caps.Load(context.Background())
+
+		// then:
+		require.NoError(t, err)
+		require.NoError(t, caps.Require(context.Background(), capabilities.FeatureV2))
+	})
+}
+
+func TestCapabilities_HandlesPaymail(t *testing.T) {
+	// given:
+	caps := capabilities.NewFromConfig(&response.SharedConfig{PaymailDomains: []string{"example.com"}})
+
+	tests := map[string]bool{
+		"alice@example.com": true,
+		"alice@EXAMPLE.com": true,
+		"alice@other.com":   false,
+		"alice":             false,
+	}
+	for paymail, expected := range tests {
+		t.Run(paymail, func(t *testing.T) {
+			// when:
+			handled, err := caps.HandlesPaymail(context.Background(), paymail)
+
+			// then:
+			require.NoError(t, err)
+			require.Equal(t, expected, handled)
+		})
+	}
+}
+
+type source struct {
+	config *response.SharedConfig
+	err    error
+	calls  int
+}
+
+func (s *source) SharedConfig(context.Context) (*response.SharedConfig, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.config, nil
+}
+
+func givenSource(features map[string]bool) *source {
+	return &source{config: &response.SharedConfig{ExperimentalFeatures: features}}
+}
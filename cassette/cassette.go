@@ -0,0 +1,150 @@
+// Package cassette records the HTTP interactions of the client with a real SPV Wallet server into cassette files
+// and replays them deterministically in the tests, instead of the fixtures written by hand for every endpoint.
+// Both the Recorder and the Replayer are http.RoundTripper instances passed to the client with config.WithTransport.
+package cassette
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet/models"
+)
+
+// Scrubbed is the value replacing the scrubbed header and JSON field values in the cassettes.
+const Scrubbed = "[SCRUBBED]"
+
+// DefaultScrubbedHeaders are the headers whose values are scrubbed from the cassettes: the auth headers,
+// which identify the user and contain the signature with its nonce and time, and the credentials of the HTTP.
+var DefaultScrubbedHeaders = []string{
+	models.AuthHeader,
+	models.AuthAccessKey,
+	models.AuthSignature,
+	models.AuthHeaderHash,
+	models.AuthHeaderNonce,
+	models.AuthHeaderTime,
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// DefaultScrubbedFields are the fields of the JSON bodies whose values are scrubbed from the cassettes:
+// the access keys, the extended keys and the tokens, at any depth of the bodies.
+var DefaultScrubbedFields = []string{"key", "xpub", "xpriv", "token"}
+
+// Cassette is a sequence of recorded HTTP interactions.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded HTTP request with its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded HTTP request.
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Load reads the cassette from the file.
+func Load(path string) (*Cassette, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrCassette, err)
+	}
+
+	var c Cassette
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("%w: invalid cassette %s: %w", goclienterr.ErrCassette, path, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to the file, creating its directory if needed.
+func (c *Cassette) Save(path string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: %w", goclienterr.ErrCassette, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("%w: %w", goclienterr.ErrCassette, err)
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0o600); err != nil {
+		return fmt.Errorf("%w: %w", goclienterr.ErrCassette, err)
+	}
+	return nil
+}
+
+// scrub returns a copy of the headers with the values of the scrubbed headers replaced by Scrubbed.
+func scrub(headers http.Header, scrubbed []string) http.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+	headers = headers.Clone()
+	for _, name := range scrubbed {
+		if values := headers.Values(name); len(values) > 0 {
+			headers[http.CanonicalHeaderKey(name)] = []string{Scrubbed}
+		}
+	}
+	return headers
+}
+
+// scrubBody returns the JSON body with the values of the scrubbed fields, named case-insensitively,
+// replaced by Scrubbed. The bodies which aren't JSON, or without any scrubbed field, are returned as they are.
+func scrubBody(body string, fields []string) string {
+	if body == "" || len(fields) == 0 {
+		return body
+	}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var v any
+	if decoder.Decode(&v) != nil || decoder.More() {
+		return body
+	}
+	if !scrubValue(v, fields) {
+		return body
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return string(b)
+}
+
+// scrubValue replaces the values of the scrubbed fields of the JSON value and reports whether any was replaced.
+func scrubValue(v any, fields []string) bool {
+	scrubbed := false
+	switch v := v.(type) {
+	case map[string]any:
+		for name, value := range v {
+			if slices.ContainsFunc(fields, func(field string) bool { return strings.EqualFold(field, name) }) {
+				v[name] = Scrubbed
+				scrubbed = true
+				continue
+			}
+			scrubbed = scrubValue(value, fields) || scrubbed
+		}
+	case []any:
+		for _, value := range v {
+			scrubbed = scrubValue(value, fields) || scrubbed
+		}
+	}
+	return scrubbed
+}
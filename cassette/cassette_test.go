@@ -0,0 +1,207 @@
+package cassette_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	spvwallet "github.com/bitcoin-sv/spv-wallet-go-client"
+	"github.com/bitcoin-sv/spv-wallet-go-client/cassette"
+	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
+	"github.com/bitcoin-sv/spv-wallet-go-client/config"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/cryptoutil"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/testutils"
+	"github.com/bitcoin-sv/spv-wallet-go-client/spvwallettest"
+	"github.com/bitcoin-sv/spv-wallet/models"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+	"github.com/stretchr/testify/require"
+)
+
+const bobPaymail = "bob@example.com"
+
+func TestRecorder(t *testing.T) {
+	// given:
+	path := filepath.Join(t.TempDir(), "cassettes", "send.json")
+	recorder := cassette.NewRecorder(path)
+
+	// when:
+	recorded := givenSend(t, givenServer(t).URL, recorder)
+	err := recorder.Save()
+
+	// then:
+	require.NoError(t, err)
+	c, err := cassette.Load(path)
+	require.NoError(t, err)
+	require.Len(t, c.Interactions, 3)
+	for _, interaction := range c.Interactions {
+		require.Equal(t, cassette.Scrubbed, interaction.Request.Headers.Get(models.AuthHeader))
+		require.Equal(t, cassette.Scrubbed, interaction.Request.Headers.Get(models.AuthSignature))
+		require.Equal(t, cassette.Scrubbed, interaction.Request.Headers.Get(models.AuthHeaderNonce))
+		require.Equal(t, cassette.Scrubbed, interaction.Request.Headers.Get(models.AuthHeaderTime))
+	}
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(b), testutils.AliceXPub)
+	require.NotEmpty(t, recorded.ID)
+}
+
+func TestRecorder_ScrubsFields(t *testing.T) {
+	// given:
+	key, err := cryptoutil.RandomHex(32)
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/users/current/keys" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response.AccessKey{ID: "access-key-1", Key: key})
+	}))
+	t.Cleanup(server.Close)
+	path := filepath.Join(t.TempDir(), "keys.json")
+	hideNote := cassette.WithScrubber(func(interaction *cassette.Interaction) {
+		interaction.Request.Body = strings.ReplaceAll(interaction.Request.Body, "hunter2", cassette.Scrubbed)
+	})
+	recorder := cassette.NewRecorder(path, hideNote)
+	cmd := &commands.GenerateAccessKey{Metadata: map[string]any{"note": "hunter2"}}
+
+	// when:
+	generated, err := givenUserAPI(t, server.URL, recorder).GenerateAccessKey(context.Background(), cmd)
+	require.NoError(t, err)
+	err = recorder.Save()
+
+	// then:
+	require.NoError(t, err)
+	require.Equal(t, key, generated.Key)
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(b), key)
+	require.NotContains(t, string(b), "hunter2")
+
+	// when:
+	replayer, err := cassette.NewReplayer(path, hideNote)
+	require.NoError(t, err)
+	replayed, err := givenUserAPI(t, testutils.TestAPIAddr, replayer).GenerateAccessKey(context.Background(), cmd)
+
+	// then:
+	require.NoError(t, err)
+	require.Equal(t, "access-key-1", replayed.ID)
+	require.Equal(t, cassette.Scrubbed, replayed.Key)
+	require.Zero(t, replayer.Remaining())
+}
+
+func TestReplayer(t *testing.T) {
+	t.Run("replays the recorded interactions with new signatures", func(t *testing.T) {
+		// given:
+		path := filepath.Join(t.TempDir(), "send.json")
+		recorder := cassette.NewRecorder(path)
+		recorded := givenSend(t, givenServer(t).URL, recorder)
+		require.NoError(t, recorder.Save())
+		replayer, err := cassette.NewReplayer(path)
+		require.NoError(t, err)
+
+		// when:
+		replayed := givenSend(t, testutils.TestAPIAddr, replayer)
+
+		// then:
+		require.Equal(t, recorded, replayed)
+		require.Zero(t, replayer.Remaining())
+	})
+
+	t.Run("fails on a request not recorded", func(t *testing.T) {
+		// given:
+		replayer := cassette.NewCassetteReplayer(&cassette.Cassette{})
+		alice := givenUserAPI(t, testutils.TestAPIAddr, replayer)
+
+		// when:
+		xPub, err := alice.XPub(context.Background())
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrInteractionNotFound)
+		require.Nil(t, xPub)
+	})
+
+	t.Run("fails on a request authenticated differently", func(t *testing.T) {
+		// given:
+		path := filepath.Join(t.TempDir(), "send.json")
+		recorder := cassette.NewRecorder(path)
+		givenSend(t, givenServer(t).URL, recorder)
+		require.NoError(t, recorder.Save())
+		replayer, err := cassette.NewReplayer(path)
+		require.NoError(t, err)
+		cfg := config.New(config.WithAddr(testutils.TestAPIAddr), config.WithTransport(replayer))
+		alice, err := spvwallet.NewUserAPIWithXPub(cfg, testutils.AliceXPub)
+		require.NoError(t, err)
+
+		// when:
+		xPub, err := alice.XPub(context.Background())
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrInteractionNotFound)
+		require.Nil(t, xPub)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	// given:
+	path := filepath.Join(t.TempDir(), "invalid.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+
+	// when:
+	c, err := cassette.Load(path)
+
+	// then:
+	require.ErrorIs(t, err, goclienterr.ErrCassette)
+	require.Nil(t, c)
+}
+
+// sent is the result of givenSend compared between the recording and the replay.
+type sent struct {
+	ID      string
+	Fee     uint64
+	Balance uint64
+}
+
+// givenSend sends satoshis from Alice to Bob with the transport and returns the result.
+func givenSend(t *testing.T, addr string, transport http.RoundTripper) sent {
+	t.Helper()
+
+	ctx := context.Background()
+	alice := givenUserAPI(t, addr, transport)
+	tx, err := alice.SendToRecipients(ctx, &commands.SendToRecipients{
+		Recipients: []*commands.Recipients{{To: bobPaymail, Satoshis: 1_000}},
+		Metadata:   map[string]any{"note": "lunch"},
+	})
+	require.NoError(t, err)
+	xPub, err := alice.XPub(ctx)
+	require.NoError(t, err)
+	return sent{ID: tx.ID, Fee: tx.Fee, Balance: xPub.CurrentBalance}
+}
+
+func givenServer(t *testing.T) *spvwallettest.Server {
+	t.Helper()
+
+	server := spvwallettest.NewServer()
+	t.Cleanup(server.Close)
+	require.NoError(t, server.AddUser(testutils.AliceXPub, "alice@example.com"))
+	require.NoError(t, server.AddUser(testutils.BobXPub, bobPaymail))
+	_, err := server.Fund(testutils.AliceXPub, 10_000)
+	require.NoError(t, err)
+	return server
+}
+
+func givenUserAPI(t *testing.T, addr string, transport http.RoundTripper) *spvwallet.UserAPI {
+	t.Helper()
+
+	cfg := config.New(config.WithAddr(addr), config.WithTimeout(5*time.Second), config.WithTransport(transport))
+	api, err := spvwallet.NewUserAPIWithXPriv(cfg, testutils.AliceXPriv)
+	require.NoError(t, err)
+	return api
+}
@@ -0,0 +1,148 @@
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// Option configures the Recorder or the Replayer.
+type Option func(*options)
+
+type options struct {
+	transport http.RoundTripper
+	scrubbed  []string
+	fields    []string
+	scrubbers []func(*Interaction)
+}
+
+// WithTransport sets the transport the Recorder sends the requests with; http.DefaultTransport if not set.
+// It has no effect on the Replayer.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = transport
+	}
+}
+
+// WithScrubbedHeaders scrubs the values of the headers in addition to the DefaultScrubbedHeaders.
+// The Replayer matches the scrubbed headers of the requests loosely, by their presence only.
+func WithScrubbedHeaders(names ...string) Option {
+	return func(o *options) {
+		o.scrubbed = append(o.scrubbed, names...)
+	}
+}
+
+// WithScrubbedFields scrubs the values of the fields of the JSON bodies in addition to the DefaultScrubbedFields.
+// The Replayer scrubs the bodies of the requests the same way before matching them.
+func WithScrubbedFields(names ...string) Option {
+	return func(o *options) {
+		o.fields = append(o.fields, names...)
+	}
+}
+
+// WithScrubber adds a function scrubbing the recorded interaction, after the headers and the fields are scrubbed,
+// e.g., to remove the secrets the other options don't cover. The Replayer calls it with the interaction holding
+// the request only, before matching the request, so the scrubber must handle the interactions without a response.
+func WithScrubber(scrub func(interaction *Interaction)) Option {
+	return func(o *options) {
+		o.scrubbers = append(o.scrubbers, scrub)
+	}
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		transport: http.DefaultTransport,
+		scrubbed:  slices.Clone(DefaultScrubbedHeaders),
+		fields:    slices.Clone(DefaultScrubbedFields),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Recorder is an http.RoundTripper sending the requests to a real server and recording the interactions,
+// with the scrubbed header and JSON field values, until they are saved into the cassette file with Save.
+// It is safe for concurrent use.
+type Recorder struct {
+	path    string
+	options *options
+
+	mu       sync.Mutex
+	cassette *Cassette
+}
+
+// NewRecorder creates a new Recorder saving the interactions into the cassette file at the path.
+func NewRecorder(path string, opts ...Option) *Recorder {
+	return &Recorder{path: path, options: newOptions(opts...), cassette: &Cassette{}}
+}
+
+// RoundTrip sends the request with the transport of the Recorder and records the interaction.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	out := req.Clone(req.Context())
+	if req.Body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	res, err := r.options.transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := readBody(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	interaction := &Interaction{
+		Request: r.options.request(req, body),
+		Response: Response{
+			StatusCode: res.StatusCode,
+			Headers:    scrub(res.Header, r.options.scrubbed),
+			Body:       scrubBody(string(resBody), r.options.fields),
+		},
+	}
+	for _, scrub := range r.options.scrubbers {
+		scrub(interaction)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	return res, nil
+}
+
+// request returns the recorded request with the scrubbed headers and JSON fields.
+func (o *options) request(req *http.Request, body []byte) Request {
+	return Request{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Headers: scrub(req.Header, o.scrubbed),
+		Body:    scrubBody(string(body), o.fields),
+	}
+}
+
+// Save writes the interactions recorded so far into the cassette file.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.cassette.Save(r.path)
+}
+
+func readBody(body io.ReadCloser) ([]byte, error) {
+	if body == nil || body == http.NoBody {
+		return nil, nil
+	}
+	defer body.Close()
+
+	return io.ReadAll(body)
+}
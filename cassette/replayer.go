@@ -0,0 +1,133 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+)
+
+// Replayer is an http.RoundTripper serving the responses recorded in a cassette without any network access.
+// A request is served with the first interaction not replayed yet with the same method, path, query and body;
+// the host is ignored, so the client may be configured with any address. The request is scrubbed like the recorded
+// ones before matching: the JSON bodies are compared semantically with their scrubbed fields,
+// and the scrubbed headers, e.g., the signature with its nonce and time, only by their presence.
+// It is safe for concurrent use.
+type Replayer struct {
+	options *options
+
+	mu       sync.Mutex
+	cassette *Cassette
+	replayed []bool
+}
+
+// NewReplayer creates a new Replayer serving the interactions of the cassette file at the path.
+func NewReplayer(path string, opts ...Option) (*Replayer, error) {
+	c, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewCassetteReplayer(c, opts...), nil
+}
+
+// NewCassetteReplayer creates a new Replayer serving the interactions of the cassette.
+func NewCassetteReplayer(c *Cassette, opts ...Option) *Replayer {
+	return &Replayer{options: newOptions(opts...), cassette: c, replayed: make([]bool, len(c.Interactions))}
+}
+
+// RoundTrip returns the recorded response of the request,
+// or an error wrapping goclienterr.ErrInteractionNotFound if none matches.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	live := &Interaction{Request: r.options.request(req, body)}
+	for _, scrub := range r.options.scrubbers {
+		scrub(live)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.replayed[i] || !r.matches(&live.Request, &interaction.Request) {
+			continue
+		}
+		r.replayed[i] = true
+		return interaction.Response.response(req), nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", goclienterr.ErrInteractionNotFound, req.Method, req.URL)
+}
+
+// Remaining returns the number of the recorded interactions not replayed yet.
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var remaining int
+	for _, replayed := range r.replayed {
+		if !replayed {
+			remaining++
+		}
+	}
+	return remaining
+}
+
+// matches reports whether the request, scrubbed like the recorded ones, matches the recorded request.
+func (r *Replayer) matches(req, recorded *Request) bool {
+	if req.Method != recorded.Method {
+		return false
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return false
+	}
+	recordedURL, err := url.Parse(recorded.URL)
+	if err != nil || recordedURL.Path != u.Path || !reflect.DeepEqual(recordedURL.Query(), u.Query()) {
+		return false
+	}
+	for _, name := range r.options.scrubbed {
+		if (req.Headers.Get(name) == "") != (recorded.Headers.Get(name) == "") {
+			return false
+		}
+	}
+	return equalBodies([]byte(req.Body), []byte(recorded.Body))
+}
+
+// equalBodies reports whether the bodies are equal, comparing the JSON bodies semantically.
+func equalBodies(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var aJSON, bJSON any
+	if json.Unmarshal(a, &aJSON) != nil || json.Unmarshal(b, &bJSON) != nil {
+		return false
+	}
+	return reflect.DeepEqual(aJSON, bJSON)
+}
+
+func (r *Response) response(req *http.Request) *http.Response {
+	headers := r.Headers.Clone()
+	if headers == nil {
+		headers = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        headers,
+		Body:          io.NopCloser(bytes.NewReader([]byte(r.Body))),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
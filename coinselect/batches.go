@@ -0,0 +1,34 @@
+package coinselect
+
+import (
+	"fmt"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// DefaultMaxTransactionSize is the default limit of the estimated size of a consolidation transaction in bytes.
+const DefaultMaxTransactionSize = 100_000
+
+// Batches splits the UTXOs into batches spendable by P2PKH transactions with the given number of outputs
+// whose estimated size doesn't exceed maxSize. Batches with no more inputs than outputs are dropped,
+// as spending them wouldn't reduce the number of UTXOs.
+func Batches(utxos []*response.Utxo, outputs, maxSize int) ([][]*response.Utxo, error) {
+	if outputs < 1 {
+		return nil, fmt.Errorf("%w: number of outputs must be positive, got %d", goclienterr.ErrInvalidUTXOOperation, outputs)
+	}
+
+	maxInputs := (maxSize - TxOverheadSize - outputs*P2PKHOutputSize) / P2PKHInputSize
+	if maxInputs <= outputs {
+		return nil, fmt.Errorf("%w: size limit of %d bytes is too low for %d outputs", goclienterr.ErrInvalidUTXOOperation, maxSize, outputs)
+	}
+
+	var batches [][]*response.Utxo
+	for start := 0; start < len(utxos); start += maxInputs {
+		batch := utxos[start:min(start+maxInputs, len(utxos))]
+		if len(batch) > outputs {
+			batches = append(batches, batch)
+		}
+	}
+	return batches, nil
+}
@@ -0,0 +1,195 @@
+package coinselect
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v1/queryparams"
+	"github.com/bitcoin-sv/spv-wallet-go-client/queries"
+	"github.com/bitcoin-sv/spv-wallet/models/filter"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// DefaultPageSize is the default number of UTXOs fetched from the SPV Wallet API per request.
+const DefaultPageSize = 100
+
+// DefaultFeeUnit is the fee unit used to estimate the fee of the selection if none is configured.
+// It matches the default fee unit of the SPV Wallet.
+var DefaultFeeUnit = response.FeeUnit{Satoshis: 1, Bytes: 1000}
+
+// UTXOSource fetches the pages of the user UTXOs. It is implemented by the *spvwallet.UserAPI.
+type UTXOSource interface {
+	UTXOs(ctx context.Context, opts ...queries.QueryOption[filter.UtxoFilter]) (*queries.UtxosPage, error)
+}
+
+// Builder builds draft transactions spending the UTXOs selected locally by the strategy.
+type Builder struct {
+	source   UTXOSource
+	strategy Strategy
+	filter   filter.UtxoFilter
+	excluded map[response.UtxoPointer]struct{}
+	feeUnit  *response.FeeUnit
+	pageSize int
+}
+
+// Option configures the Builder.
+type Option func(*Builder)
+
+// WithFilter narrows the candidate UTXOs fetched from the SPV Wallet API with the given filter.
+func WithFilter(f filter.UtxoFilter) Option {
+	return func(b *Builder) {
+		b.filter = f
+	}
+}
+
+// WithExcluded prevents the given UTXOs, e.g., tainted ones, from being selected.
+func WithExcluded(utxos ...response.UtxoPointer) Option {
+	return func(b *Builder) {
+		for _, utxo := range utxos {
+			b.excluded[utxo] = struct{}{}
+		}
+	}
+}
+
+// WithFeeUnit sets the fee unit used to estimate the fee of the selection.
+// The fee unit is also passed to the SPV Wallet in the draft transaction configuration.
+func WithFeeUnit(unit *response.FeeUnit) Option {
+	return func(b *Builder) {
+		b.feeUnit = unit
+	}
+}
+
+// WithPageSize sets the number of UTXOs fetched from the SPV Wallet API per request.
+func WithPageSize(size int) Option {
+	return func(b *Builder) {
+		if size > 0 {
+			b.pageSize = size
+		}
+	}
+}
+
+// NewBuilder creates a new Builder selecting the UTXOs fetched from the source with the given strategy.
+func NewBuilder(source UTXOSource, strategy Strategy, opts ...Option) *Builder {
+	b := &Builder{
+		source:   source,
+		strategy: strategy,
+		excluded: make(map[response.UtxoPointer]struct{}),
+		pageSize: DefaultPageSize,
+	}
+	for _, o := range opts {
+		o(b)
+	}
+	return b
+}
+
+// Build selects the UTXOs covering the outputs and the estimated fee and returns the draft transaction
+// command spending exactly the selected UTXOs. The SPV Wallet adds the change output when drafting it.
+func (b *Builder) Build(ctx context.Context, outputs []*response.TransactionOutput, metadata queryparams.Metadata) (*commands.DraftTransaction, error) {
+	if len(outputs) == 0 {
+		return nil, goclienterr.ErrNoOutputs
+	}
+
+	candidates, err := b.Candidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	selected, err := b.strategy.Select(candidates, b.Target(outputs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to select UTXOs: %w", err)
+	}
+
+	pointers := make([]*response.UtxoPointer, len(selected))
+	for i, utxo := range selected {
+		pointers[i] = &response.UtxoPointer{TransactionID: utxo.TransactionID, OutputIndex: utxo.OutputIndex}
+	}
+
+	return &commands.DraftTransaction{
+		Config: response.TransactionConfig{
+			Outputs:   outputs,
+			FromUtxos: pointers,
+			FeeUnit:   b.feeUnit,
+		},
+		Metadata: metadata,
+	}, nil
+}
+
+// Target returns the selection target for the outputs: their value and the estimated fee
+// of the transaction with a P2PKH change output, excluding the inputs.
+func (b *Builder) Target(outputs []*response.TransactionOutput) Target {
+	unit := b.feeUnit
+	if unit == nil {
+		unit = &DefaultFeeUnit
+	}
+
+	size := TxOverheadSize + P2PKHOutputSize
+	var sats uint64
+	for _, output := range outputs {
+		sats += output.Satoshis
+		size += outputSize(output)
+	}
+
+	return Target{
+		Satoshis:    sats + Fee(unit, size),
+		FeePerInput: Fee(unit, P2PKHInputSize),
+	}
+}
+
+// Candidates fetches all pages of the UTXOs matching the filter and returns the ones which can be spent:
+// not spent, not reserved by another draft transaction and not excluded.
+func (b *Builder) Candidates(ctx context.Context) ([]*response.Utxo, error) {
+	var candidates []*response.Utxo
+	for number := 1; ; number++ {
+		page, err := b.source.UTXOs(ctx,
+			queries.QueryWithFilter(b.filter),
+			queries.QueryWithPageFilter[filter.UtxoFilter](filter.Page{Number: number, Size: b.pageSize}),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch UTXOs page %d: %w", number, err)
+		}
+
+		for _, utxo := range page.Content {
+			if b.isCandidate(utxo) {
+				candidates = append(candidates, utxo)
+			}
+		}
+		if len(page.Content) < b.pageSize || number >= page.Page.TotalPages {
+			return candidates, nil
+		}
+	}
+}
+
+func (b *Builder) isCandidate(utxo *response.Utxo) bool {
+	if utxo == nil || utxo.SpendingTxID != "" || utxo.DraftID != "" {
+		return false
+	}
+	_, excluded := b.excluded[utxo.UtxoPointer]
+	return !excluded
+}
+
+// outputSize estimates the size of the output, assuming a P2PKH locking script if the script is not known yet.
+// The size of a script is the value (8), the script length (up to 3 for scripts shorter than 64 KB) and the script.
+func outputSize(output *response.TransactionOutput) int {
+	switch {
+	case output.Script != "":
+		return 8 + 3 + len(output.Script)/2
+	case output.OpReturn != nil:
+		return 8 + 3 + opReturnSize(output.OpReturn)
+	default:
+		return P2PKHOutputSize
+	}
+}
+
+// opReturnSize estimates the size of the OP_FALSE OP_RETURN script, with up to 5 bytes of push opcodes per part.
+func opReturnSize(opReturn *response.OpReturn) int {
+	size := 2 + len(opReturn.Hex)/2
+	for _, part := range opReturn.StringParts {
+		size += 5 + len(part)
+	}
+	for _, part := range opReturn.HexParts {
+		size += 5 + len(part)/2
+	}
+	return size
+}
@@ -0,0 +1,63 @@
+// Package coinselect provides client-side coin selection for transactions built with the SPV Wallet client.
+//
+// By default the SPV Wallet selects the inputs of a draft transaction on its own. The Builder instead fetches
+// the spendable UTXOs of the user, selects the inputs locally with a pluggable Strategy and produces
+// a commands.DraftTransaction with the FromUtxos populated, so that the SPV Wallet spends exactly
+// the selected UTXOs. This allows to spend specific UTXOs or to avoid the ones which must not be spent.
+package coinselect
+
+import (
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// Estimated sizes in bytes of the parts of a P2PKH transaction, used to estimate the fee of the selection.
+const (
+	// TxOverheadSize is the size of the version, the locktime and the input and output counters.
+	TxOverheadSize = 10
+
+	// P2PKHInputSize is the size of an input spending a P2PKH output:
+	// the outpoint (36), the script length (1), the unlocking script (107) and the sequence (4).
+	P2PKHInputSize = 148
+
+	// P2PKHOutputSize is the size of a P2PKH output: the value (8), the script length (1) and the locking script (25).
+	P2PKHOutputSize = 34
+)
+
+// Target describes the amount the selected UTXOs have to cover.
+type Target struct {
+	Satoshis    uint64 // Value of the outputs and the fee of the transaction without any inputs.
+	FeePerInput uint64 // Fee required for every selected input.
+}
+
+// EffectiveValue returns the value of the UTXO reduced by the fee required to spend it.
+// It returns zero for UTXOs which cost more to spend than they are worth.
+func (t Target) EffectiveValue(utxo *response.Utxo) uint64 {
+	if utxo.Satoshis <= t.FeePerInput {
+		return 0
+	}
+	return utxo.Satoshis - t.FeePerInput
+}
+
+// Strategy selects the UTXOs which cover the target from the candidate UTXOs.
+// Implementations must not modify the candidates slice.
+type Strategy interface {
+	Select(candidates []*response.Utxo, target Target) ([]*response.Utxo, error)
+}
+
+// StrategyFunc is an adapter which allows to use an ordinary function as a Strategy.
+type StrategyFunc func(candidates []*response.Utxo, target Target) ([]*response.Utxo, error)
+
+// Select calls f(candidates, target).
+func (f StrategyFunc) Select(candidates []*response.Utxo, target Target) ([]*response.Utxo, error) {
+	return f(candidates, target)
+}
+
+// Fee returns the fee required by the fee unit for a transaction of the given size, rounded up to the nearest satoshi.
+func Fee(unit *response.FeeUnit, size int) uint64 {
+	if unit == nil || unit.Bytes <= 0 || unit.Satoshis == 0 || size <= 0 {
+		return 0
+	}
+
+	sats, bytes := uint64(unit.Satoshis), uint64(unit.Bytes)
+	return (uint64(size)*sats + bytes - 1) / bytes
+}
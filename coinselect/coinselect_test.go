@@ -0,0 +1,237 @@
+package coinselect_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/coinselect"
+	"github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/testutils"
+	"github.com/bitcoin-sv/spv-wallet-go-client/queries"
+	"github.com/bitcoin-sv/spv-wallet/models/filter"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/require"
+)
+
+const utxosURL = "/api/v1/utxos"
+
+func TestStrategies_Select(t *testing.T) {
+	candidates := givenUTXOs(50, 10, 30, 20, 1)
+	target := coinselect.Target{Satoshis: 40, FeePerInput: 1}
+
+	tests := map[string]struct {
+		strategy    coinselect.Strategy
+		target      coinselect.Target
+		expected    []uint64
+		expectedErr error
+	}{
+		"largest first": {
+			strategy: coinselect.LargestFirst{},
+			target:   target,
+			expected: []uint64{50},
+		},
+		"smallest first skips uneconomic UTXOs": {
+			strategy: coinselect.SmallestFirst{},
+			target:   target,
+			expected: []uint64{10, 20, 30},
+		},
+		"branch and bound finds an exact match": {
+			strategy: coinselect.BranchAndBound{},
+			target:   coinselect.Target{Satoshis: 48, FeePerInput: 1},
+			expected: []uint64{30, 20},
+		},
+		"branch and bound accepts the cost of change": {
+			strategy: coinselect.BranchAndBound{CostOfChange: 2},
+			target:   coinselect.Target{Satoshis: 46, FeePerInput: 1},
+			expected: []uint64{30, 20},
+		},
+		"branch and bound without a match": {
+			strategy:    coinselect.BranchAndBound{},
+			target:      coinselect.Target{Satoshis: 36, FeePerInput: 1},
+			expectedErr: errors.ErrNoExactMatch,
+		},
+		"branch and bound falls back": {
+			strategy: coinselect.BranchAndBound{Fallback: coinselect.LargestFirst{}},
+			target:   coinselect.Target{Satoshis: 36, FeePerInput: 1},
+			expected: []uint64{50},
+		},
+		"explicit": {
+			strategy: coinselect.Explicit{{TransactionID: "tx-1", OutputIndex: 1}, {TransactionID: "tx-3", OutputIndex: 3}},
+			target:   coinselect.Target{Satoshis: 25, FeePerInput: 1},
+			expected: []uint64{10, 20},
+		},
+		"explicit with insufficient funds": {
+			strategy:    coinselect.Explicit{{TransactionID: "tx-1", OutputIndex: 1}},
+			target:      coinselect.Target{Satoshis: 25, FeePerInput: 1},
+			expectedErr: errors.ErrInsufficientFunds,
+		},
+		"explicit with unknown UTXO": {
+			strategy:    coinselect.Explicit{{TransactionID: "tx-9", OutputIndex: 9}},
+			target:      target,
+			expectedErr: errors.ErrUTXONotFound,
+		},
+		"insufficient funds": {
+			strategy:    coinselect.LargestFirst{},
+			target:      coinselect.Target{Satoshis: 200, FeePerInput: 1},
+			expectedErr: errors.ErrInsufficientFunds,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// when:
+			selected, err := tc.strategy.Select(candidates, tc.target)
+
+			// then:
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expected, satoshis(selected))
+		})
+	}
+}
+
+func TestBuilder_Build(t *testing.T) {
+	outputs := []*response.TransactionOutput{{To: "alice@example.com", Satoshis: 40}}
+
+	t.Run("Build spends the selected UTXOs", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		utxos := givenUTXOs(50, 10, 30, 20)
+		utxos[0].DraftID = "reserved"
+		transport.RegisterResponder(http.MethodGet, testutils.FullAPIURL(t, utxosURL), httpmock.ResponderFromMultipleResponses([]*http.Response{
+			utxosPageResponse(t, 1, 2, utxos[:2]...),
+			utxosPageResponse(t, 2, 2, utxos[2:]...),
+		}))
+		builder := coinselect.NewBuilder(wallet, coinselect.SmallestFirst{},
+			coinselect.WithPageSize(2),
+			coinselect.WithExcluded(utxos[1].UtxoPointer),
+			coinselect.WithFilter(filter.UtxoFilter{Type: testutils.Ptr("pubkeyhash")}),
+		)
+
+		// when:
+		cmd, err := builder.Build(context.Background(), outputs, map[string]any{"key": "value"})
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, outputs, cmd.Config.Outputs)
+		require.Equal(t, []*response.UtxoPointer{&utxos[3].UtxoPointer, &utxos[2].UtxoPointer}, cmd.Config.FromUtxos)
+		require.Equal(t, "value", cmd.Metadata["key"])
+		require.Equal(t, 2, transport.GetTotalCallCount())
+	})
+
+	t.Run("Build with insufficient funds", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodGet, testutils.FullAPIURL(t, utxosURL), httpmock.ResponderFromResponse(utxosPageResponse(t, 1, 1, givenUTXOs(10)...)))
+		builder := coinselect.NewBuilder(wallet, coinselect.LargestFirst{})
+
+		// when:
+		cmd, err := builder.Build(context.Background(), outputs, nil)
+
+		// then:
+		require.ErrorIs(t, err, errors.ErrInsufficientFunds)
+		require.Nil(t, cmd)
+	})
+
+	t.Run("Build without outputs", func(t *testing.T) {
+		// given:
+		wallet, _ := testutils.GivenSPVUserAPI(t)
+		builder := coinselect.NewBuilder(wallet, coinselect.LargestFirst{})
+
+		// when:
+		cmd, err := builder.Build(context.Background(), nil, nil)
+
+		// then:
+		require.ErrorIs(t, err, errors.ErrNoOutputs)
+		require.Nil(t, cmd)
+	})
+
+	t.Run("Build - UTXOs error", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodGet, testutils.FullAPIURL(t, utxosURL), testutils.NewInternalServerSPVErrorResponder())
+		builder := coinselect.NewBuilder(wallet, coinselect.LargestFirst{})
+
+		// when:
+		cmd, err := builder.Build(context.Background(), outputs, nil)
+
+		// then:
+		require.ErrorIs(t, err, testutils.NewInternalServerSPVError())
+		require.Nil(t, cmd)
+	})
+}
+
+func TestBatches(t *testing.T) {
+	utxos := givenUTXOs(1, 2, 3, 4, 5, 6, 7)
+
+	tests := map[string]struct {
+		outputs     int
+		maxSize     int
+		expected    [][]uint64
+		expectedErr error
+	}{
+		"batches under the size limit": {
+			outputs:  1,
+			maxSize:  coinselect.TxOverheadSize + coinselect.P2PKHOutputSize + 3*coinselect.P2PKHInputSize,
+			expected: [][]uint64{{1, 2, 3}, {4, 5, 6}},
+		},
+		"batches with multiple outputs": {
+			outputs:  2,
+			maxSize:  coinselect.TxOverheadSize + 2*coinselect.P2PKHOutputSize + 4*coinselect.P2PKHInputSize,
+			expected: [][]uint64{{1, 2, 3, 4}, {5, 6, 7}},
+		},
+		"size limit too low": {
+			outputs:     1,
+			maxSize:     coinselect.TxOverheadSize + coinselect.P2PKHOutputSize + coinselect.P2PKHInputSize,
+			expectedErr: errors.ErrInvalidUTXOOperation,
+		},
+		"no outputs": {
+			maxSize:     coinselect.DefaultMaxTransactionSize,
+			expectedErr: errors.ErrInvalidUTXOOperation,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// when:
+			batches, err := coinselect.Batches(utxos, tc.outputs, tc.maxSize)
+
+			// then:
+			require.ErrorIs(t, err, tc.expectedErr)
+			var got [][]uint64
+			for _, batch := range batches {
+				got = append(got, satoshis(batch))
+			}
+			require.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func givenUTXOs(values ...uint64) []*response.Utxo {
+	utxos := make([]*response.Utxo, len(values))
+	for i, value := range values {
+		utxos[i] = &response.Utxo{
+			UtxoPointer: response.UtxoPointer{TransactionID: fmt.Sprintf("tx-%d", i), OutputIndex: uint32(i)},
+			Satoshis:    value,
+		}
+	}
+	return utxos
+}
+
+func utxosPageResponse(t *testing.T, number, totalPages int, utxos ...*response.Utxo) *http.Response {
+	page := queries.UtxosPage{Content: utxos, Page: response.PageDescription{Number: number, Size: len(utxos), TotalPages: totalPages}}
+	res, err := httpmock.NewJsonResponse(http.StatusOK, page)
+	require.NoError(t, err)
+	return res
+}
+
+func satoshis(utxos []*response.Utxo) []uint64 {
+	if utxos == nil {
+		return nil
+	}
+	res := make([]uint64, len(utxos))
+	for i, utxo := range utxos {
+		res[i] = utxo.Satoshis
+	}
+	return res
+}
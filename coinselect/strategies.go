@@ -0,0 +1,186 @@
+package coinselect
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// LargestFirst selects the UTXOs with the highest value first, minimizing the number of inputs.
+type LargestFirst struct{}
+
+// Select implements the Strategy interface.
+func (LargestFirst) Select(candidates []*response.Utxo, target Target) ([]*response.Utxo, error) {
+	return accumulate(sortedBy(candidates, func(a, b *response.Utxo) int { return cmp.Compare(b.Satoshis, a.Satoshis) }), target)
+}
+
+// SmallestFirst selects the UTXOs with the lowest value first, consolidating the dust of the wallet.
+type SmallestFirst struct{}
+
+// Select implements the Strategy interface.
+func (SmallestFirst) Select(candidates []*response.Utxo, target Target) ([]*response.Utxo, error) {
+	return accumulate(sortedBy(candidates, func(a, b *response.Utxo) int { return cmp.Compare(a.Satoshis, b.Satoshis) }), target)
+}
+
+// DefaultBranchAndBoundTries is the default limit of the search steps made by the BranchAndBound strategy.
+const DefaultBranchAndBoundTries = 100_000
+
+// BranchAndBound searches for the set of UTXOs whose effective value matches the target exactly,
+// or exceeds it by no more than CostOfChange, so that the transaction doesn't need a change output.
+// Among the matching sets, the one with the lowest excess is selected.
+//
+// If there is no such set, the selection is delegated to the Fallback strategy or,
+// if the Fallback is nil, ErrNoExactMatch is returned.
+type BranchAndBound struct {
+	CostOfChange uint64   // Maximum excess over the target which is accepted instead of creating a change output.
+	MaxTries     int      // Limit of the search steps; DefaultBranchAndBoundTries is used if not set.
+	Fallback     Strategy // Strategy used when no matching set is found.
+}
+
+// Select implements the Strategy interface.
+func (b BranchAndBound) Select(candidates []*response.Utxo, target Target) ([]*response.Utxo, error) {
+	utxos := sortedBy(spendable(candidates, target), func(a, b *response.Utxo) int { return cmp.Compare(b.Satoshis, a.Satoshis) })
+	values := make([]uint64, len(utxos))
+	var total uint64
+	for i, utxo := range utxos {
+		values[i] = target.EffectiveValue(utxo)
+		total += values[i]
+	}
+	if total < target.Satoshis {
+		return nil, insufficientFunds(total, target)
+	}
+
+	s := bnbSearch{
+		values: values,
+		target: target.Satoshis,
+		upper:  target.Satoshis + b.CostOfChange,
+		tries:  b.MaxTries,
+	}
+	if s.tries <= 0 {
+		s.tries = DefaultBranchAndBoundTries
+	}
+	s.search(0, 0, total, nil)
+
+	if s.best == nil {
+		if b.Fallback != nil {
+			return b.Fallback.Select(candidates, target)
+		}
+		return nil, goclienterr.ErrNoExactMatch
+	}
+
+	selected := make([]*response.Utxo, len(s.best))
+	for i, idx := range s.best {
+		selected[i] = utxos[idx]
+	}
+	return selected, nil
+}
+
+type bnbSearch struct {
+	values    []uint64
+	target    uint64
+	upper     uint64
+	tries     int
+	best      []int
+	bestWaste uint64
+}
+
+// search explores the inclusion and the exclusion of the value at index i, where sum is the value
+// of the already included UTXOs and remaining is the value of the UTXOs which are not decided yet.
+func (s *bnbSearch) search(i int, sum, remaining uint64, selected []int) {
+	if s.tries <= 0 || sum > s.upper || (s.best != nil && s.bestWaste == 0) {
+		return
+	}
+	s.tries--
+
+	if sum >= s.target {
+		if waste := sum - s.target; s.best == nil || waste < s.bestWaste {
+			s.best = slices.Clone(selected)
+			s.bestWaste = waste
+		}
+		return
+	}
+	if i == len(s.values) || sum+remaining < s.target {
+		return
+	}
+
+	value := s.values[i]
+	s.search(i+1, sum+value, remaining-value, append(selected, i))
+
+	// Excluding a UTXO and including an equal one leads to the same sums, so the equal ones are skipped too.
+	next := i + 1
+	remaining -= value
+	for next < len(s.values) && s.values[next] == value {
+		remaining -= value
+		next++
+	}
+	s.search(next, sum, remaining, selected)
+}
+
+// Explicit selects exactly the listed UTXOs, in the listed order.
+// It returns ErrUTXONotFound if any of them is not among the candidates
+// and ErrInsufficientFunds if they don't cover the target.
+type Explicit []response.UtxoPointer
+
+// Select implements the Strategy interface.
+func (e Explicit) Select(candidates []*response.Utxo, target Target) ([]*response.Utxo, error) {
+	byPointer := make(map[response.UtxoPointer]*response.Utxo, len(candidates))
+	for _, utxo := range candidates {
+		byPointer[utxo.UtxoPointer] = utxo
+	}
+
+	selected := make([]*response.Utxo, 0, len(e))
+	var total uint64
+	for _, ptr := range e {
+		utxo, ok := byPointer[ptr]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s:%d", goclienterr.ErrUTXONotFound, ptr.TransactionID, ptr.OutputIndex)
+		}
+		selected = append(selected, utxo)
+		total += target.EffectiveValue(utxo)
+	}
+	if total < target.Satoshis {
+		return nil, insufficientFunds(total, target)
+	}
+	return selected, nil
+}
+
+// accumulate selects the UTXOs in the given order until their effective value covers the target.
+func accumulate(utxos []*response.Utxo, target Target) ([]*response.Utxo, error) {
+	var selected []*response.Utxo
+	var total uint64
+	for _, utxo := range spendable(utxos, target) {
+		if total >= target.Satoshis && len(selected) > 0 {
+			break
+		}
+		selected = append(selected, utxo)
+		total += target.EffectiveValue(utxo)
+	}
+	if total < target.Satoshis || len(selected) == 0 {
+		return nil, insufficientFunds(total, target)
+	}
+	return selected, nil
+}
+
+// spendable returns the UTXOs which are worth more than the fee required to spend them.
+func spendable(utxos []*response.Utxo, target Target) []*response.Utxo {
+	res := make([]*response.Utxo, 0, len(utxos))
+	for _, utxo := range utxos {
+		if target.EffectiveValue(utxo) > 0 {
+			res = append(res, utxo)
+		}
+	}
+	return res
+}
+
+func sortedBy(utxos []*response.Utxo, cmp func(a, b *response.Utxo) int) []*response.Utxo {
+	res := slices.Clone(utxos)
+	slices.SortStableFunc(res, cmp)
+	return res
+}
+
+func insufficientFunds(total uint64, target Target) error {
+	return fmt.Errorf("%w: available %d satoshis, required %d satoshis", goclienterr.ErrInsufficientFunds, total, target.Satoshis)
+}
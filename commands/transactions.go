@@ -2,6 +2,7 @@ package commands
 
 import (
 	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v1/queryparams"
+	"github.com/bitcoin-sv/spv-wallet/models/filter"
 	"github.com/bitcoin-sv/spv-wallet/models/response"
 )
 
@@ -41,3 +42,36 @@ type SendToRecipients struct {
 	Recipients []*Recipients        `json:"recipients"` // List of recipients for the transaction.
 	Metadata   queryparams.Metadata `json:"metadata"`   // Metadata associated with the transaction.
 }
+
+// IdempotentSendToRecipients holds the arguments required to send a transaction to multiple recipients exactly once.
+// The PaymentID is a client-supplied unique identifier of the payment. It is stored in the transaction metadata
+// and used as the key of the payment progress record, so that retrying the same payment never pays twice.
+type IdempotentSendToRecipients struct {
+	SendToRecipients
+	PaymentID string `json:"-"` // Client-supplied unique identifier of the payment.
+}
+
+// ConsolidateUTXOs holds the arguments required to merge the user UTXOs into fewer outputs sent back to the user.
+// The UTXOs are spent in batches, one transaction per batch, so that no transaction exceeds the size limit.
+type ConsolidateUTXOs struct {
+	Filter   filter.UtxoFilter    // Filter selecting the UTXOs to consolidate.
+	Outputs  int                  // Number of outputs of every consolidation transaction; 1 if not set.
+	MaxSize  int                  // Maximum estimated size of a transaction in bytes; coinselect.DefaultMaxTransactionSize if not set.
+	Metadata queryparams.Metadata // Metadata associated with every consolidation transaction.
+}
+
+// SplitUTXOs holds the arguments required to fan a single UTXO out into equal outputs sent back to the user.
+type SplitUTXOs struct {
+	UTXO     *response.UtxoPointer // UTXO to split; the largest spendable UTXO if not set.
+	Outputs  int                   // Number of outputs to create, at least 2.
+	Metadata queryparams.Metadata  // Metadata associated with the transaction.
+}
+
+// SweepPrivateKey holds the arguments required to move all funds locked to an external private key into the wallet.
+// The PrivateKey is never sent to the SPV Wallet API; it is only used to sign the sweeping transaction locally.
+type SweepPrivateKey struct {
+	PrivateKey string               `json:"-"`        // WIF or hex encoded private key holding the funds.
+	Testnet    bool                 `json:"-"`        // True if the hex encoded key's address belongs to the testnet; a WIF key carries its network.
+	FeeUnit    *response.FeeUnit    `json:"-"`        // Fee unit of the transaction; coinselect.DefaultFeeUnit if not set.
+	Metadata   queryparams.Metadata `json:"metadata"` // Metadata associated with the recorded transaction.
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 	"net/url"
@@ -15,19 +16,50 @@ type Config struct {
 	Addr      string            // The base address of the SPV Wallet API.
 	Timeout   time.Duration     // The HTTP requests timeout duration.
 	Transport http.RoundTripper // Custom HTTP transport, allowing optional customization of the HTTP client behavior.
+	TLS       *TLSConfig        // Optional TLS settings applied on top of the HTTP transport.
+	Retry     *RetryConfig      // Optional retry policy for failed idempotent HTTP requests.
+	ProxyURL  string            // Optional proxy server address applied on top of the HTTP transport.
+}
+
+// RetryConfig holds the retry policy used by the HTTP client.
+// Only idempotent requests (GET, HEAD, PUT, DELETE, OPTIONS) are retried, and only
+// when the transport fails or the SPV Wallet API responds with a 429 or 5xx status code.
+type RetryConfig struct {
+	Count       int           // The maximum number of retries after the initial attempt.
+	WaitTime    time.Duration // The initial wait time between retries.
+	MaxWaitTime time.Duration // The upper bound of the exponential backoff wait time.
 }
 
 // New creates a new Config instance with optional customizations.
+// It terminates the process if the resulting configuration is invalid;
+// use NewE to handle configuration problems without exiting.
 func New(options ...Option) Config {
+	cfg, err := NewE(options...)
+	if err != nil {
+		log.Fatalf("Error creating configuration: %v", err)
+	}
+	return cfg
+}
+
+// NewE creates a new Config instance with optional customizations.
+// Unlike New, it returns an error instead of terminating the process
+// when the resulting configuration is invalid.
+func NewE(options ...Option) (Config, error) {
 	cfg := Config{}
 	for _, opt := range options {
 		opt(&cfg)
 	}
 	cfg.setDefaultValues()
 	if err := cfg.Validate(); err != nil {
-		log.Fatalf("Error creating configuration: %v", err)
+		return Config{}, fmt.Errorf("failed to validate configuration: %w", err)
 	}
-	return cfg
+
+	transport, err := cfg.configureTransport()
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to configure HTTP transport: %w", err)
+	}
+	cfg.Transport = transport
+	return cfg, nil
 }
 
 // Validate checks the configuration for invalid or missing values.
@@ -44,5 +76,35 @@ func (cfg *Config) Validate() error {
 		return goclienterr.ErrConfigValidationInvalidTimeout
 	}
 
+	if cfg.ProxyURL != "" {
+		if _, err := url.ParseRequestURI(cfg.ProxyURL); err != nil {
+			return goclienterr.ErrConfigValidationInvalidProxy
+		}
+	}
+
+	if cfg.Retry != nil {
+		if err := cfg.Retry.validate(); err != nil {
+			return err
+		}
+	}
+
+	if cfg.TLS != nil {
+		if err := cfg.TLS.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *RetryConfig) validate() error {
+	if r.Count < 0 || r.WaitTime < 0 || r.MaxWaitTime < 0 {
+		return goclienterr.ErrConfigValidationInvalidRetry
+	}
+
+	if r.MaxWaitTime > 0 && r.MaxWaitTime < r.WaitTime {
+		return goclienterr.ErrConfigValidationInvalidRetry
+	}
+
 	return nil
 }
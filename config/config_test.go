@@ -1,7 +1,11 @@
 package config_test
 
 import (
+	"crypto/tls"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -129,3 +133,199 @@ func TestConfig_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_NewE(t *testing.T) {
+	tests := map[string]struct {
+		options     []config.Option
+		expectedErr error
+	}{
+		"Valid configuration": {
+			options: []config.Option{config.WithAddr("http://api.example.com")},
+		},
+		"Invalid Addr URL": {
+			options:     []config.Option{config.WithAddr("invalid-url")},
+			expectedErr: goclienterr.ErrConfigValidationInvalidAddress,
+		},
+		"Invalid proxy URL": {
+			options:     []config.Option{config.WithProxy("invalid-proxy")},
+			expectedErr: goclienterr.ErrConfigValidationInvalidProxy,
+		},
+		"Negative retry count": {
+			options:     []config.Option{config.WithRetry(-1, time.Second, time.Second)},
+			expectedErr: goclienterr.ErrConfigValidationInvalidRetry,
+		},
+		"Retry max wait time lower than wait time": {
+			options:     []config.Option{config.WithRetry(3, 2*time.Second, time.Second)},
+			expectedErr: goclienterr.ErrConfigValidationInvalidRetry,
+		},
+		"Client certificate without key": {
+			options:     []config.Option{config.WithTLS(config.TLSConfig{CertFile: "client.pem"})},
+			expectedErr: goclienterr.ErrConfigValidationIncompleteClientCert,
+		},
+		"Unsupported TLS version": {
+			options:     []config.Option{config.WithTLS(config.TLSConfig{MinVersion: 0x0200})},
+			expectedErr: goclienterr.ErrConfigValidationInvalidTLSVersion,
+		},
+		"TLS settings with custom non HTTP transport": {
+			options: []config.Option{
+				config.WithTransport(roundTripperFunc(func(*http.Request) (*http.Response, error) { return nil, nil })),
+				config.WithTLS(config.TLSConfig{MinVersion: tls.VersionTLS12}),
+			},
+			expectedErr: goclienterr.ErrConfigValidationInvalidTransport,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := config.NewE(tc.options...)
+			require.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestConfig_NewE_TransportCustomization(t *testing.T) {
+	// when:
+	cfg, err := config.NewE(
+		config.WithProxy("http://proxy.example.com:8080"),
+		config.WithTLS(config.TLSConfig{MinVersion: tls.VersionTLS12}),
+	)
+
+	// then:
+	require.NoError(t, err)
+	transport, ok := cfg.Transport.(*http.Transport)
+	require.True(t, ok)
+	require.NotSame(t, http.DefaultTransport, transport)
+	require.Equal(t, uint16(tls.VersionTLS12), transport.TLSClientConfig.MinVersion)
+
+	proxy, err := transport.Proxy(&http.Request{URL: &url.URL{Scheme: "http", Host: "api.example.com"}})
+	require.NoError(t, err)
+	require.Equal(t, "proxy.example.com:8080", proxy.Host)
+}
+
+func TestConfig_FromEnv(t *testing.T) {
+	t.Run("All values set", func(t *testing.T) {
+		// given:
+		t.Setenv("SPV_WALLET_ADDR", "http://env.example.com")
+		t.Setenv("SPV_WALLET_TIMEOUT", "15s")
+		t.Setenv("SPV_WALLET_RETRY_COUNT", "3")
+		t.Setenv("SPV_WALLET_RETRY_WAIT_TIME", "100ms")
+		t.Setenv("SPV_WALLET_RETRY_MAX_WAIT_TIME", "1s")
+		t.Setenv("SPV_WALLET_TLS_MIN_VERSION", "1.3")
+
+		// when:
+		cfg, err := config.FromEnv("spv_wallet")
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, "http://env.example.com", cfg.Addr)
+		require.Equal(t, 15*time.Second, cfg.Timeout)
+		require.Equal(t, &config.RetryConfig{Count: 3, WaitTime: 100 * time.Millisecond, MaxWaitTime: time.Second}, cfg.Retry)
+		require.Equal(t, &config.TLSConfig{MinVersion: tls.VersionTLS13}, cfg.TLS)
+	})
+
+	t.Run("Explicit options take precedence", func(t *testing.T) {
+		// given:
+		t.Setenv("SPV_WALLET_ADDR", "http://env.example.com")
+
+		// when:
+		cfg, err := config.FromEnv("SPV_WALLET", config.WithAddr("http://override.example.com"))
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, "http://override.example.com", cfg.Addr)
+		require.Equal(t, 1*time.Minute, cfg.Timeout)
+	})
+
+	t.Run("Invalid timeout", func(t *testing.T) {
+		// given:
+		t.Setenv("SPV_WALLET_TIMEOUT", "ten seconds")
+
+		// when:
+		_, err := config.FromEnv("SPV_WALLET")
+
+		// then:
+		require.Error(t, err)
+	})
+
+	t.Run("Invalid address", func(t *testing.T) {
+		// given:
+		t.Setenv("SPV_WALLET_ADDR", "invalid-url")
+
+		// when:
+		_, err := config.FromEnv("SPV_WALLET")
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrConfigValidationInvalidAddress)
+	})
+}
+
+func TestConfig_FromFile(t *testing.T) {
+	tests := map[string]struct {
+		fileName    string
+		content     string
+		expected    config.Config
+		expectedErr error
+	}{
+		"YAML file": {
+			fileName: "config.yaml",
+			content: `
+addr: http://yaml.example.com
+timeout: 20s
+retry:
+  count: 2
+  waitTime: 50ms
+`,
+			expected: config.Config{
+				Addr:      "http://yaml.example.com",
+				Timeout:   20 * time.Second,
+				Transport: http.DefaultTransport,
+				Retry:     &config.RetryConfig{Count: 2, WaitTime: 50 * time.Millisecond},
+			},
+		},
+		"JSON file": {
+			fileName: "config.json",
+			content:  `{"addr": "http://json.example.com", "timeout": "45s"}`,
+			expected: config.Config{
+				Addr:      "http://json.example.com",
+				Timeout:   45 * time.Second,
+				Transport: http.DefaultTransport,
+			},
+		},
+		"Unsupported file format": {
+			fileName:    "config.toml",
+			content:     `addr = "http://toml.example.com"`,
+			expectedErr: goclienterr.ErrConfigUnsupportedFileFormat,
+		},
+		"Invalid TLS version": {
+			fileName:    "config.yml",
+			content:     "tls:\n  minVersion: \"0.9\"\n",
+			expectedErr: goclienterr.ErrConfigValidationInvalidTLSVersion,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given:
+			path := filepath.Join(t.TempDir(), tc.fileName)
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			// when:
+			cfg, err := config.FromFile(path)
+
+			// then:
+			require.ErrorIs(t, err, tc.expectedErr)
+			if tc.expectedErr == nil {
+				require.Equal(t, tc.expected, cfg)
+			}
+		})
+	}
+
+	t.Run("Missing file", func(t *testing.T) {
+		_, err := config.FromFile(filepath.Join(t.TempDir(), "missing.yaml"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Environment variable names (without the prefix) recognized by FromEnv.
+const (
+	EnvAddr             = "ADDR"
+	EnvTimeout          = "TIMEOUT"
+	EnvProxyURL         = "PROXY_URL"
+	EnvTLSCAFile        = "TLS_CA_FILE"
+	EnvTLSCertFile      = "TLS_CERT_FILE"
+	EnvTLSKeyFile       = "TLS_KEY_FILE"
+	EnvTLSMinVersion    = "TLS_MIN_VERSION"
+	EnvTLSPinnedSPKI    = "TLS_PINNED_SPKI" // Comma-separated list of base64 encoded SHA-256 SPKI hashes.
+	EnvRetryCount       = "RETRY_COUNT"
+	EnvRetryWaitTime    = "RETRY_WAIT_TIME"
+	EnvRetryMaxWaitTime = "RETRY_MAX_WAIT_TIME"
+)
+
+// FromEnv creates a new Config instance from environment variables.
+// Each variable name is composed of the upper-cased prefix and one of the Env* names
+// joined with an underscore (e.g., for the "SPV_WALLET" prefix: SPV_WALLET_ADDR, SPV_WALLET_TIMEOUT).
+// Unset variables fall back to the defaults. Options passed explicitly are applied
+// after the environment variables and take precedence over them.
+// Returns an error if a variable cannot be parsed or the resulting configuration is invalid.
+func FromEnv(prefix string, options ...Option) (Config, error) {
+	env := func(name string) string {
+		if prefix != "" {
+			name = strings.ToUpper(strings.TrimSuffix(prefix, "_")) + "_" + name
+		}
+		return strings.TrimSpace(os.Getenv(name))
+	}
+
+	src := source{
+		Addr:     env(EnvAddr),
+		Timeout:  env(EnvTimeout),
+		ProxyURL: env(EnvProxyURL),
+	}
+
+	tlsSrc := tlsSource{
+		CAFile:     env(EnvTLSCAFile),
+		CertFile:   env(EnvTLSCertFile),
+		KeyFile:    env(EnvTLSKeyFile),
+		MinVersion: env(EnvTLSMinVersion),
+	}
+	for _, pin := range strings.Split(env(EnvTLSPinnedSPKI), ",") {
+		if pin = strings.TrimSpace(pin); pin != "" {
+			tlsSrc.PinnedSPKI = append(tlsSrc.PinnedSPKI, pin)
+		}
+	}
+	if tlsSrc.CAFile != "" || tlsSrc.CertFile != "" || tlsSrc.KeyFile != "" || tlsSrc.MinVersion != "" || len(tlsSrc.PinnedSPKI) > 0 {
+		src.TLS = &tlsSrc
+	}
+
+	retrySrc := retrySource{
+		WaitTime:    env(EnvRetryWaitTime),
+		MaxWaitTime: env(EnvRetryMaxWaitTime),
+	}
+	if count := env(EnvRetryCount); count != "" {
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to parse %s: %w", EnvRetryCount, err)
+		}
+		retrySrc.Count = n
+	}
+	if retrySrc != (retrySource{}) {
+		src.Retry = &retrySrc
+	}
+
+	opts, err := src.options()
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to load configuration from environment: %w", err)
+	}
+
+	return NewE(append(opts, options...)...)
+}
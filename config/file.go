@@ -0,0 +1,58 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// FromFile creates a new Config instance from a YAML (.yaml, .yml) or JSON (.json) file.
+// The format is selected based on the file extension. Example YAML file:
+//
+//	addr: https://spv-wallet.example.com
+//	timeout: 30s
+//	proxyUrl: http://proxy.internal:8080
+//	tls:
+//	  caFile: /etc/ssl/spv-wallet-ca.pem
+//	  minVersion: "1.2"
+//	  pinnedSpki:
+//	    - 47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=
+//	retry:
+//	  count: 3
+//	  waitTime: 100ms
+//	  maxWaitTime: 2s
+//
+// Missing values fall back to the defaults. Options passed explicitly are applied
+// after the file values and take precedence over them.
+// Returns an error if the file cannot be read or parsed, or the resulting configuration is invalid.
+func FromFile(path string, options ...Option) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read configuration file: %w", err)
+	}
+
+	var src source
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &src)
+	case ".json":
+		err = json.Unmarshal(data, &src)
+	default:
+		return Config{}, fmt.Errorf("%w: %s", goclienterr.ErrConfigUnsupportedFileFormat, path)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to decode configuration file: %w", err)
+	}
+
+	opts, err := src.options()
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to load configuration from file: %w", err)
+	}
+
+	return NewE(append(opts, options...)...)
+}
@@ -29,3 +29,84 @@ func WithTransport(transport http.RoundTripper) Option {
 		cfg.Transport = transport
 	}
 }
+
+// WithTLS sets the TLS settings in the configuration.
+func WithTLS(tls TLSConfig) Option {
+	return func(cfg *Config) {
+		cfg.TLS = &tls
+	}
+}
+
+// WithRetry sets the retry policy for failed idempotent HTTP requests in the configuration.
+func WithRetry(count int, waitTime, maxWaitTime time.Duration) Option {
+	return func(cfg *Config) {
+		cfg.Retry = &RetryConfig{
+			Count:       count,
+			WaitTime:    waitTime,
+			MaxWaitTime: maxWaitTime,
+		}
+	}
+}
+
+// WithProxy sets the proxy server address in the configuration.
+func WithProxy(proxyURL string) Option {
+	return func(cfg *Config) {
+		cfg.ProxyURL = strings.TrimSpace(proxyURL)
+	}
+}
+
+// WithCABundle adds a PEM encoded CA bundle used to verify the SPV Wallet API server certificate.
+func WithCABundle(pem []byte) Option {
+	return func(cfg *Config) {
+		cfg.tls().CAPEM = pem
+	}
+}
+
+// WithCABundleFile sets the path to a PEM encoded CA bundle used to verify the SPV Wallet API server certificate.
+func WithCABundleFile(path string) Option {
+	return func(cfg *Config) {
+		cfg.tls().CAFile = strings.TrimSpace(path)
+	}
+}
+
+// WithClientCertificate sets the PEM encoded client certificate and private key used for mutual TLS.
+func WithClientCertificate(certPEM, keyPEM []byte) Option {
+	return func(cfg *Config) {
+		t := cfg.tls()
+		t.CertPEM = certPEM
+		t.KeyPEM = keyPEM
+	}
+}
+
+// WithClientCertificateFiles sets the paths to the PEM encoded client certificate and private key used for mutual TLS.
+func WithClientCertificateFiles(certFile, keyFile string) Option {
+	return func(cfg *Config) {
+		t := cfg.tls()
+		t.CertFile = strings.TrimSpace(certFile)
+		t.KeyFile = strings.TrimSpace(keyFile)
+	}
+}
+
+// WithPinnedSPKI restricts the accepted server certificates to those whose chain contains
+// a certificate with one of the given base64 encoded SHA-256 SPKI hashes (see SPKIHash).
+func WithPinnedSPKI(hashes ...string) Option {
+	return func(cfg *Config) {
+		t := cfg.tls()
+		t.PinnedSPKI = append(t.PinnedSPKI, hashes...)
+	}
+}
+
+// WithMinTLSVersion sets the minimum accepted TLS version (e.g., tls.VersionTLS12).
+func WithMinTLSVersion(version uint16) Option {
+	return func(cfg *Config) {
+		cfg.tls().MinVersion = version
+	}
+}
+
+// tls returns the TLS settings of the configuration, initializing them if not set.
+func (cfg *Config) tls() *TLSConfig {
+	if cfg.TLS == nil {
+		cfg.TLS = &TLSConfig{}
+	}
+	return cfg.TLS
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// source is the serializable representation of Config shared by the
+// environment variables and file loaders. Durations are expressed in
+// the time.ParseDuration format (e.g., "30s") and the TLS version as "1.2" or "1.3".
+type source struct {
+	Addr     string       `json:"addr" yaml:"addr"`
+	Timeout  string       `json:"timeout" yaml:"timeout"`
+	ProxyURL string       `json:"proxyUrl" yaml:"proxyUrl"`
+	TLS      *tlsSource   `json:"tls" yaml:"tls"`
+	Retry    *retrySource `json:"retry" yaml:"retry"`
+}
+
+type tlsSource struct {
+	CAFile     string   `json:"caFile" yaml:"caFile"`
+	CertFile   string   `json:"certFile" yaml:"certFile"`
+	KeyFile    string   `json:"keyFile" yaml:"keyFile"`
+	MinVersion string   `json:"minVersion" yaml:"minVersion"`
+	PinnedSPKI []string `json:"pinnedSpki" yaml:"pinnedSpki"`
+}
+
+type retrySource struct {
+	Count       int    `json:"count" yaml:"count"`
+	WaitTime    string `json:"waitTime" yaml:"waitTime"`
+	MaxWaitTime string `json:"maxWaitTime" yaml:"maxWaitTime"`
+}
+
+// options converts the loaded values into configuration options.
+// Empty values are skipped, so that the defaults are applied instead.
+func (s *source) options() ([]Option, error) {
+	var opts []Option
+	if s.Addr != "" {
+		opts = append(opts, WithAddr(s.Addr))
+	}
+
+	if s.Timeout != "" {
+		timeout, err := time.ParseDuration(s.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse timeout: %w", err)
+		}
+		opts = append(opts, WithTimeout(timeout))
+	}
+
+	if s.ProxyURL != "" {
+		opts = append(opts, WithProxy(s.ProxyURL))
+	}
+
+	if s.TLS != nil {
+		minVersion, err := parseTLSVersion(s.TLS.MinVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse TLS min version: %w", err)
+		}
+		opts = append(opts, WithTLS(TLSConfig{
+			CAFile:     s.TLS.CAFile,
+			CertFile:   s.TLS.CertFile,
+			KeyFile:    s.TLS.KeyFile,
+			MinVersion: minVersion,
+			PinnedSPKI: s.TLS.PinnedSPKI,
+		}))
+	}
+
+	if s.Retry != nil {
+		waitTime, err := parseOptionalDuration(s.Retry.WaitTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse retry wait time: %w", err)
+		}
+		maxWaitTime, err := parseOptionalDuration(s.Retry.MaxWaitTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse retry max wait time: %w", err)
+		}
+		opts = append(opts, WithRetry(s.Retry.Count, waitTime, maxWaitTime))
+	}
+
+	return opts, nil
+}
+
+func parseOptionalDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse duration %q: %w", s, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+)
+
+// TLSConfig holds the TLS settings used when connecting to the SPV Wallet API.
+// CA certificates and the client certificate can be provided either as file paths or
+// as in-memory PEM encoded data. When both forms of the CA bundle are set, the
+// certificates from both sources are trusted.
+type TLSConfig struct {
+	CAFile     string   // Path to a PEM encoded CA bundle used to verify the server certificate.
+	CAPEM      []byte   // PEM encoded CA bundle used to verify the server certificate.
+	CertFile   string   // Path to a PEM encoded client certificate (mTLS).
+	KeyFile    string   // Path to the PEM encoded private key of the client certificate (mTLS).
+	CertPEM    []byte   // PEM encoded client certificate (mTLS).
+	KeyPEM     []byte   // PEM encoded private key of the client certificate (mTLS).
+	PinnedSPKI []string // Base64 encoded SHA-256 hashes of the trusted server SubjectPublicKeyInfo (see SPKIHash).
+	MinVersion uint16   // Minimum accepted TLS version (e.g., tls.VersionTLS12). Zero means the Go default.
+}
+
+// SPKIHash returns the base64 encoded SHA-256 hash of the certificate SubjectPublicKeyInfo,
+// in the format expected by TLSConfig.PinnedSPKI. The same value can be obtained with:
+//
+//	openssl x509 -in cert.pem -pubkey -noout | openssl pkey -pubin -outform der | openssl dgst -sha256 -binary | base64
+func SPKIHash(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func (t *TLSConfig) validate() error {
+	switch t.MinVersion {
+	case 0, tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12, tls.VersionTLS13:
+	default:
+		return goclienterr.ErrConfigValidationInvalidTLSVersion
+	}
+
+	if t.CertFile != "" && len(t.CertPEM) > 0 {
+		return goclienterr.ErrConfigValidationAmbiguousClientCert
+	}
+
+	hasCert := t.CertFile != "" || len(t.CertPEM) > 0
+	hasKey := t.KeyFile != "" || len(t.KeyPEM) > 0
+	if hasCert != hasKey {
+		return goclienterr.ErrConfigValidationIncompleteClientCert
+	}
+
+	for _, pin := range t.PinnedSPKI {
+		hash, err := base64.StdEncoding.DecodeString(pin)
+		if err != nil || len(hash) != sha256.Size {
+			return fmt.Errorf("%w: %q", goclienterr.ErrConfigValidationInvalidSPKIPin, pin)
+		}
+	}
+
+	if _, err := t.rootCAs(); err != nil {
+		return errors.Join(goclienterr.ErrConfigValidationInvalidTLSCertificate, err)
+	}
+
+	if _, err := t.clientCertificate(); err != nil {
+		return errors.Join(goclienterr.ErrConfigValidationInvalidTLSCertificate, err)
+	}
+
+	return nil
+}
+
+func (t *TLSConfig) clientConfig() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: t.MinVersion} //nolint: gosec // zero value falls back to the Go default minimum version
+
+	pool, err := t.rootCAs()
+	if err != nil {
+		return nil, err
+	}
+	cfg.RootCAs = pool
+
+	cert, err := t.clientCertificate()
+	if err != nil {
+		return nil, err
+	}
+	if cert != nil {
+		cfg.Certificates = []tls.Certificate{*cert}
+	}
+
+	if len(t.PinnedSPKI) > 0 {
+		cfg.VerifyConnection = t.verifyPinnedSPKI
+	}
+
+	return cfg, nil
+}
+
+// rootCAs returns the pool of trusted CA certificates or nil if the system pool should be used.
+func (t *TLSConfig) rootCAs() (*x509.CertPool, error) {
+	if t.CAFile == "" && len(t.CAPEM) == 0 {
+		return nil, nil
+	}
+
+	pool := x509.NewCertPool()
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, goclienterr.ErrConfigInvalidCABundle
+		}
+	}
+
+	if len(t.CAPEM) > 0 && !pool.AppendCertsFromPEM(t.CAPEM) {
+		return nil, goclienterr.ErrConfigInvalidCABundle
+	}
+
+	return pool, nil
+}
+
+// clientCertificate returns the mTLS client certificate or nil if none is configured.
+func (t *TLSConfig) clientCertificate() (*tls.Certificate, error) {
+	certPEM, keyPEM := t.CertPEM, t.KeyPEM
+	if t.CertFile != "" {
+		var err error
+		if certPEM, err = os.ReadFile(t.CertFile); err != nil {
+			return nil, fmt.Errorf("failed to read client certificate file: %w", err)
+		}
+	}
+
+	if t.KeyFile != "" {
+		var err error
+		if keyPEM, err = os.ReadFile(t.KeyFile); err != nil {
+			return nil, fmt.Errorf("failed to read client key file: %w", err)
+		}
+	}
+
+	if len(certPEM) == 0 {
+		return nil, nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate key pair: %w", err)
+	}
+
+	return &cert, nil
+}
+
+// verifyPinnedSPKI accepts the connection only if at least one certificate of the verified
+// chains matches one of the pinned SPKI hashes. It runs after the regular chain verification.
+// The presented certificates are never matched on their own: a server can append any
+// certificate to its chain, including a pinned one it doesn't chain to.
+func (t *TLSConfig) verifyPinnedSPKI(cs tls.ConnectionState) error {
+	for _, chain := range cs.VerifiedChains {
+		for _, cert := range chain {
+			hash := SPKIHash(cert)
+			for _, pin := range t.PinnedSPKI {
+				if hash == pin {
+					return nil
+				}
+			}
+		}
+	}
+
+	return goclienterr.ErrTLSCertificatePinMismatch
+}
+
+// parseTLSVersion converts a human-readable TLS version (e.g., "1.2" or "TLS1.3")
+// into its crypto/tls constant. An empty string yields zero.
+func parseTLSVersion(s string) (uint16, error) {
+	v := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "TLS")
+	switch strings.TrimSpace(v) {
+	case "":
+		return 0, nil
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", goclienterr.ErrConfigValidationInvalidTLSVersion, s)
+	}
+}
@@ -0,0 +1,252 @@
+package config_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/config"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_TLS(t *testing.T) {
+	ca := newTestCertificate(t, "Test CA", nil)
+	serverCert := newTestCertificate(t, "127.0.0.1", ca)
+	clientCert := newTestCertificate(t, "client", ca)
+	otherCA := newTestCertificate(t, "Other CA", nil)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert.tlsCertificate(t)},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS12,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	tests := map[string]struct {
+		options     []config.Option
+		expectedErr error
+		expectFail  bool
+	}{
+		"mTLS with CA bundle and client certificate": {
+			options: []config.Option{
+				config.WithCABundle(ca.certPEM),
+				config.WithClientCertificate(clientCert.certPEM, clientCert.keyPEM),
+			},
+		},
+		"mTLS with matching SPKI pin": {
+			options: []config.Option{
+				config.WithCABundle(ca.certPEM),
+				config.WithClientCertificate(clientCert.certPEM, clientCert.keyPEM),
+				config.WithPinnedSPKI(config.SPKIHash(ca.cert)),
+				config.WithMinTLSVersion(tls.VersionTLS12),
+			},
+		},
+		"mTLS with not matching SPKI pin": {
+			options: []config.Option{
+				config.WithCABundle(ca.certPEM),
+				config.WithClientCertificate(clientCert.certPEM, clientCert.keyPEM),
+				config.WithPinnedSPKI(config.SPKIHash(otherCA.cert)),
+			},
+			expectedErr: goclienterr.ErrTLSCertificatePinMismatch,
+		},
+		"Untrusted server certificate": {
+			options: []config.Option{
+				config.WithCABundle(otherCA.certPEM),
+				config.WithClientCertificate(clientCert.certPEM, clientCert.keyPEM),
+			},
+			expectFail: true,
+		},
+		"Missing client certificate": {
+			options: []config.Option{
+				config.WithCABundle(ca.certPEM),
+			},
+			expectFail: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given:
+			cfg, err := config.NewE(append([]config.Option{config.WithAddr(server.URL)}, tc.options...)...)
+			require.NoError(t, err)
+			client := &http.Client{Transport: cfg.Transport, Timeout: 5 * time.Second}
+
+			// when:
+			res, err := client.Get(server.URL)
+			if res != nil {
+				_ = res.Body.Close()
+			}
+
+			// then:
+			switch {
+			case tc.expectedErr != nil:
+				require.ErrorIs(t, err, tc.expectedErr)
+			case tc.expectFail:
+				require.Error(t, err)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, http.StatusOK, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestConfig_TLS_PinOutsideVerifiedChain(t *testing.T) {
+	// given:
+	ca := newTestCertificate(t, "Test CA", nil)
+	serverCert := newTestCertificate(t, "127.0.0.1", ca)
+	pinnedCA := newTestCertificate(t, "Pinned CA", nil)
+
+	// the pinned certificate is appended to a chain which doesn't chain to it
+	tlsCert := serverCert.tlsCertificate(t)
+	tlsCert.Certificate = append(tlsCert.Certificate, pinnedCA.cert.Raw)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{tlsCert}, MinVersion: tls.VersionTLS12}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	cfg, err := config.NewE(
+		config.WithAddr(server.URL),
+		config.WithCABundle(ca.certPEM),
+		config.WithPinnedSPKI(config.SPKIHash(pinnedCA.cert)),
+	)
+	require.NoError(t, err)
+	client := &http.Client{Transport: cfg.Transport, Timeout: 5 * time.Second}
+
+	// when:
+	res, err := client.Get(server.URL)
+	if res != nil {
+		_ = res.Body.Close()
+	}
+
+	// then:
+	require.ErrorIs(t, err, goclienterr.ErrTLSCertificatePinMismatch)
+}
+
+func TestConfig_TLS_Validate(t *testing.T) {
+	ca := newTestCertificate(t, "Test CA", nil)
+	clientCert := newTestCertificate(t, "client", ca)
+
+	tests := map[string]struct {
+		options     []config.Option
+		expectedErr error
+	}{
+		"Invalid CA bundle": {
+			options:     []config.Option{config.WithCABundle([]byte("not a certificate"))},
+			expectedErr: goclienterr.ErrConfigValidationInvalidTLSCertificate,
+		},
+		"Missing CA bundle file": {
+			options:     []config.Option{config.WithCABundleFile("/not/existing/ca.pem")},
+			expectedErr: goclienterr.ErrConfigValidationInvalidTLSCertificate,
+		},
+		"Client certificate with mismatched key": {
+			options:     []config.Option{config.WithClientCertificate(clientCert.certPEM, ca.keyPEM)},
+			expectedErr: goclienterr.ErrConfigValidationInvalidTLSCertificate,
+		},
+		"Client certificate provided as file and PEM": {
+			options: []config.Option{
+				config.WithClientCertificate(clientCert.certPEM, clientCert.keyPEM),
+				config.WithClientCertificateFiles("client.pem", "client.key"),
+			},
+			expectedErr: goclienterr.ErrConfigValidationAmbiguousClientCert,
+		},
+		"Client key without certificate": {
+			options:     []config.Option{config.WithClientCertificate(nil, clientCert.keyPEM)},
+			expectedErr: goclienterr.ErrConfigValidationIncompleteClientCert,
+		},
+		"Invalid SPKI pin": {
+			options:     []config.Option{config.WithPinnedSPKI("not-a-hash")},
+			expectedErr: goclienterr.ErrConfigValidationInvalidSPKIPin,
+		},
+		"Unsupported TLS version": {
+			options:     []config.Option{config.WithMinTLSVersion(0x0200)},
+			expectedErr: goclienterr.ErrConfigValidationInvalidTLSVersion,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := config.NewE(tc.options...)
+			require.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func (c *testCertificate) tlsCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	cert, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	require.NoError(t, err)
+	return cert
+}
+
+// newTestCertificate creates a certificate signed by the given parent or a self-signed CA certificate if parent is nil.
+func newTestCertificate(t *testing.T, commonName string, parent *testCertificate) *testCertificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	signerCert, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return &testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+)
+
+// configureTransport applies the TLS and proxy settings on top of the configured transport.
+// When neither is set, the transport is returned unchanged. Otherwise, the transport must be
+// an *http.Transport, which is cloned so that shared instances (e.g., http.DefaultTransport)
+// are never modified.
+func (cfg *Config) configureTransport() (http.RoundTripper, error) {
+	if cfg.TLS == nil && cfg.ProxyURL == "" {
+		return cfg.Transport, nil
+	}
+
+	base, ok := cfg.Transport.(*http.Transport)
+	if !ok {
+		return nil, goclienterr.ErrConfigValidationInvalidTransport
+	}
+
+	transport := base.Clone()
+	if cfg.TLS != nil {
+		tlsCfg, err := cfg.TLS.clientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build TLS client configuration: %w", err)
+		}
+		transport.TLSClientConfig = tlsCfg
+	}
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, goclienterr.ErrConfigValidationInvalidProxy
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return transport, nil
+}
+
+// HTTPTransport returns the transport of the configuration with the TLS and proxy settings applied,
+// falling back to http.DefaultTransport when none is set. Unlike New and NewE, a Config created
+// as a struct literal is never configured on its own, so the HTTP client resolves its transport
+// with HTTPTransport. Resolving the transport of a Config created by NewE again is harmless:
+// the already configured transport is cloned with the same settings.
+func (cfg Config) HTTPTransport() (http.RoundTripper, error) {
+	if cfg.Transport == nil {
+		cfg.Transport = http.DefaultTransport
+	}
+	return cfg.configureTransport()
+}
@@ -73,6 +73,36 @@ var (
 	// ErrConfigValidationInvalidTransport is returned when the transport is invalid.
 	ErrConfigValidationInvalidTransport = errors.New("configuration validation error: invalid transport")
 
+	// ErrConfigValidationInvalidProxy is returned when the proxy address is invalid.
+	ErrConfigValidationInvalidProxy = errors.New("configuration validation error: invalid proxy address")
+
+	// ErrConfigValidationInvalidRetry is returned when the retry policy is invalid.
+	ErrConfigValidationInvalidRetry = errors.New("configuration validation error: invalid retry policy, values must not be negative and max wait time must not be lower than wait time")
+
+	// ErrConfigValidationIncompleteClientCert is returned when only one of the client certificate and key is provided.
+	ErrConfigValidationIncompleteClientCert = errors.New("configuration validation error: client certificate and key must be provided together")
+
+	// ErrConfigValidationInvalidTLSVersion is returned when the minimum TLS version is not supported.
+	ErrConfigValidationInvalidTLSVersion = errors.New("configuration validation error: unsupported TLS version")
+
+	// ErrConfigValidationAmbiguousClientCert is returned when the client certificate is provided both as a file and as PEM data.
+	ErrConfigValidationAmbiguousClientCert = errors.New("configuration validation error: client certificate must be provided either as a file or as PEM data, not both")
+
+	// ErrConfigValidationInvalidSPKIPin is returned when a pinned SPKI hash is not a base64 encoded SHA-256 hash.
+	ErrConfigValidationInvalidSPKIPin = errors.New("configuration validation error: pinned SPKI hash must be a base64 encoded SHA-256 hash")
+
+	// ErrConfigValidationInvalidTLSCertificate is returned when the CA bundle or the client certificate cannot be loaded.
+	ErrConfigValidationInvalidTLSCertificate = errors.New("configuration validation error: invalid TLS certificate")
+
+	// ErrTLSCertificatePinMismatch is returned when none of the server certificates matches the pinned SPKI hashes.
+	ErrTLSCertificatePinMismatch = errors.New("TLS handshake error: server certificate doesn't match any pinned SPKI hash")
+
+	// ErrConfigInvalidCABundle is returned when the CA bundle doesn't contain any valid PEM encoded certificate.
+	ErrConfigInvalidCABundle = errors.New("configuration error: CA bundle doesn't contain any valid PEM encoded certificate")
+
+	// ErrConfigUnsupportedFileFormat is returned when the configuration file extension is not supported.
+	ErrConfigUnsupportedFileFormat = errors.New("configuration error: unsupported file format, expected .yaml, .yml or .json")
+
 	// ErrMaxUint32LimitExceeded is returned when the max uint32 value is exceeded.
 	ErrMaxUint32LimitExceeded = errors.New("max uint32 value exceeded")
 
@@ -81,4 +111,154 @@ var (
 
 	// ErrHexHashPartIntParse is returned when the hex hash part fails to parse to int64.
 	ErrHexHashPartIntParse = errors.New("parse hex hash part to int64 failed")
+
+	// ErrMissingPaymentID is returned when an idempotent payment is requested without a payment ID.
+	ErrMissingPaymentID = errors.New("payment ID is required to send an idempotent payment")
+
+	// ErrPaymentStore is returned when the idempotent payment progress cannot be loaded or saved.
+	ErrPaymentStore = errors.New("failed to access the payment store")
+
+	// ErrInvalidDraftTransaction is returned when the draft transaction returned by the SPV Wallet API is inconsistent.
+	ErrInvalidDraftTransaction = errors.New("invalid draft transaction")
+
+	// ErrInsufficientFunds is returned when the available UTXOs don't cover the value of the transaction.
+	ErrInsufficientFunds = errors.New("insufficient funds")
+
+	// ErrNoExactMatch is returned when the branch and bound coin selection finds no set of UTXOs matching the target.
+	ErrNoExactMatch = errors.New("no set of UTXOs matches the target without change")
+
+	// ErrUTXONotFound is returned when an explicitly selected UTXO is not among the spendable UTXOs.
+	ErrUTXONotFound = errors.New("UTXO not found among the spendable UTXOs")
+
+	// ErrNoOutputs is returned when a transaction is built without any outputs.
+	ErrNoOutputs = errors.New("transaction requires at least one output")
+
+	// ErrInvalidUTXOOperation is returned when the UTXOs consolidation or splitting is requested with invalid arguments.
+	ErrInvalidUTXOOperation = errors.New("invalid UTXO operation")
+
+	// ErrInvalidPrivateKey is returned when the private key is neither a valid WIF nor a valid hex encoded key.
+	ErrInvalidPrivateKey = errors.New("invalid private key, expected WIF or hex format")
+
+	// ErrNothingToSweep is returned when the UTXO source has no UTXOs for the swept private key.
+	ErrNothingToSweep = errors.New("no UTXOs found for the private key")
+
+	// ErrMissingDestination is returned when the destination resolver doesn't provide a locking script.
+	ErrMissingDestination = errors.New("destination locking script is required")
+
+	// ErrInvalidPayoutFile is returned when the payout file cannot be parsed.
+	ErrInvalidPayoutFile = errors.New("invalid payout file")
+
+	// ErrInvalidPayoutRow is returned when a row of the payout file fails the validation.
+	ErrInvalidPayoutRow = errors.New("invalid payout row")
+
+	// ErrInvalidPayoutBatch is returned when the payout batch is misconfigured.
+	ErrInvalidPayoutBatch = errors.New("invalid payout batch")
+
+	// ErrPayoutChunkChanged is returned when the rows of a payout chunk differ from the rows of its stored payment.
+	ErrPayoutChunkChanged = errors.New("payout chunk rows differ from the rows of its stored payment")
+
+	// ErrInvalidSchedule is returned when a scheduled payment job or its schedule is invalid.
+	ErrInvalidSchedule = errors.New("invalid payment schedule")
+
+	// ErrSchedulerStore is returned when the scheduled payment jobs cannot be loaded or saved.
+	ErrSchedulerStore = errors.New("failed to access the scheduler store")
+
+	// ErrInvalidInvoice is returned when an invoice is issued with invalid parameters.
+	ErrInvalidInvoice = errors.New("invalid invoice")
+
+	// ErrInvoiceNotFound is returned when the invoice with the given ID doesn't exist in the store.
+	ErrInvoiceNotFound = errors.New("invoice not found")
+
+	// ErrInvoiceStore is returned when the invoices cannot be loaded or saved.
+	ErrInvoiceStore = errors.New("failed to access the invoice store")
+
+	// ErrTransactionRejected is returned when the awaited transaction is rejected by the network.
+	ErrTransactionRejected = errors.New("transaction rejected")
+
+	// ErrTransactionWaitTimeout is returned when the awaited transaction doesn't reach the expected state in time.
+	ErrTransactionWaitTimeout = errors.New("timed out waiting for the transaction")
+
+	// ErrInvalidTransactionHex is returned when the transaction hex cannot be decoded.
+	ErrInvalidTransactionHex = errors.New("invalid transaction hex")
+
+	// ErrInvalidOpReturn is returned when the OP_RETURN data cannot be built or parsed.
+	ErrInvalidOpReturn = errors.New("invalid OP_RETURN data")
+
+	// ErrTransactionExport is returned when the transaction history cannot be written in the requested format.
+	ErrTransactionExport = errors.New("failed to export transactions")
+
+	// ErrBalanceDiscrepancy is returned when the balance reconciliation finds inconsistencies.
+	ErrBalanceDiscrepancy = errors.New("balance discrepancy")
+
+	// ErrLedgerStore is returned when the local ledger cannot be loaded or saved.
+	ErrLedgerStore = errors.New("failed to access the ledger store")
+
+	// ErrLedgerRecordNotFound is returned when the requested record isn't in the local ledger.
+	ErrLedgerRecordNotFound = errors.New("record not found in the ledger")
+
+	// ErrPolicyViolation is returned when a draft transaction violates a spending policy.
+	ErrPolicyViolation = errors.New("spending policy violation")
+
+	// ErrSpendingLimitExceeded is returned when a draft transaction exceeds a spending limit.
+	ErrSpendingLimitExceeded = errors.New("spending limit exceeded")
+
+	// ErrRecipientNotAllowed is returned when a draft transaction pays to a recipient outside of the allow-list.
+	ErrRecipientNotAllowed = errors.New("recipient not allowed")
+
+	// ErrFeeRateExceeded is returned when the fee rate of a draft transaction exceeds the allowed maximum.
+	ErrFeeRateExceeded = errors.New("fee rate exceeded")
+
+	// ErrPolicyStore is returned when the state of the spending policies cannot be loaded or saved.
+	ErrPolicyStore = errors.New("failed to access the spending policy store")
+
+	// ErrInvalidQuorum is returned when the approval quorum is misconfigured.
+	ErrInvalidQuorum = errors.New("invalid approval quorum")
+
+	// ErrApprovalNotFound is returned when the approval request doesn't exist.
+	ErrApprovalNotFound = errors.New("approval request not found")
+
+	// ErrApprovalExists is returned when an approval request for the draft transaction already exists.
+	ErrApprovalExists = errors.New("approval request already exists")
+
+	// ErrInvalidApproval is returned when an approval isn't signed by an approver of the quorum or its signature is invalid.
+	ErrInvalidApproval = errors.New("invalid approval")
+
+	// ErrApprovalQuorumNotReached is returned when a draft transaction is executed before its approval quorum is reached.
+	ErrApprovalQuorumNotReached = errors.New("approval quorum not reached")
+
+	// ErrApprovalClosed is returned when an approval request is no longer pending, e.g., it was rejected, executed or has expired.
+	ErrApprovalClosed = errors.New("approval request closed")
+
+	// ErrApprovalStore is returned when the approval requests or their audit trail cannot be loaded or saved.
+	ErrApprovalStore = errors.New("failed to access the approval store")
+
+	// ErrUnsupportedLockingScript is returned when no unlocking template is registered for the locking script of an input.
+	ErrUnsupportedLockingScript = errors.New("unsupported locking script")
+
+	// ErrMultisigIncomplete is returned when a multisig input doesn't have enough signatures to be unlocked.
+	ErrMultisigIncomplete = errors.New("not enough signatures to unlock multisig input")
+
+	// ErrInvalidPartialSignature is returned when a partial signature collected from another party is invalid.
+	ErrInvalidPartialSignature = errors.New("invalid partial signature")
+
+	// ErrInvalidSigningOptions is returned when the signing options select an input or a sighash flag which cannot be signed.
+	ErrInvalidSigningOptions = errors.New("invalid signing options")
+
+	// ErrInvalidBEEF is returned when a transaction in the BEEF format (BRC-62) cannot be parsed or encoded.
+	ErrInvalidBEEF = errors.New("invalid BEEF transaction")
+
+	// ErrBEEFVerification is returned when a BEEF transaction fails the SPV verification against the known merkle roots.
+	ErrBEEFVerification = errors.New("BEEF transaction verification failed")
+
+	// ErrMissingAncestor is returned when an ancestor transaction required to build a BEEF transaction is unknown.
+	ErrMissingAncestor = errors.New("missing ancestor transaction")
+
+	// ErrFeatureUnavailable is returned when a method depends on a feature the SPV Wallet server doesn't enable.
+	ErrFeatureUnavailable = errors.New("feature unavailable on the SPV Wallet server")
+
+	// ErrCassette is returned when a cassette of recorded HTTP interactions cannot be loaded or saved.
+	ErrCassette = errors.New("failed to access the cassette")
+
+	// ErrInteractionNotFound is returned when no unused interaction recorded in the cassette matches the request.
+	ErrInteractionNotFound = errors.New("no recorded interaction matches the request")
 )
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	wallet "github.com/bitcoin-sv/spv-wallet-go-client"
+	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
+	"github.com/bitcoin-sv/spv-wallet-go-client/examples"
+	"github.com/bitcoin-sv/spv-wallet-go-client/examples/exampleutil"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v1/queryparams"
+	"github.com/bitcoin-sv/spv-wallet-go-client/opreturn"
+	"github.com/bitcoin-sv/spv-wallet-go-client/walletkeys"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+func main() {
+	usersAPI, err := wallet.NewUserAPIWithXPriv(exampleutil.NewDefaultConfig(), examples.UserXPriv)
+	if err != nil {
+		log.Fatalf("Failed to initialize user API with XPriv: %v", err)
+	}
+
+	xPriv, err := walletkeys.XPrivFromString(examples.UserXPriv)
+	if err != nil {
+		log.Fatalf("Failed to parse XPriv: %v", err)
+	}
+	signer, err := opreturn.NewXPrivSigner(xPriv, 0, 0)
+	if err != nil {
+		log.Fatalf("Failed to create AIP signer: %v", err)
+	}
+
+	opReturn, err := opreturn.NewBuilder().
+		B(opreturn.BFile{Data: []byte("# Hello SPV Wallet"), MediaType: "text/markdown", Encoding: "UTF-8", Filename: "hello.md"}).
+		MAP(opreturn.MAPSet(
+			opreturn.KeyValue{Key: "app", Value: "spv-wallet-go-client"},
+			opreturn.KeyValue{Key: "type", Value: "post"},
+		)).
+		AIP(signer).
+		OpReturn()
+	if err != nil {
+		log.Fatalf("Failed to build OP_RETURN: %v", err)
+	}
+
+	ctx := context.Background()
+	draftTransaction, err := usersAPI.DraftTransaction(ctx, &commands.DraftTransaction{
+		Config: response.TransactionConfig{
+			Outputs: []*response.TransactionOutput{{OpReturn: opReturn}},
+		},
+		Metadata: queryparams.Metadata{},
+	})
+	if err != nil {
+		log.Fatalf("Failed to create draft transaction: %v", err)
+	}
+
+	finalized, err := usersAPI.FinalizeTransaction(draftTransaction)
+	if err != nil {
+		log.Fatalf("Failed to finalize draft transaction: %v", err)
+	}
+
+	transaction, err := usersAPI.RecordTransaction(ctx, &commands.RecordTransaction{
+		Hex:         finalized,
+		Metadata:    queryparams.Metadata{},
+		ReferenceID: draftTransaction.ID,
+	})
+	if err != nil {
+		log.Fatalf("Failed to record finalized transaction: %v", err)
+	}
+	exampleutil.PrettyPrint("Recorded transaction with structured OP_RETURN", transaction)
+
+	data, err := opreturn.ParseTransaction(transaction.Hex)
+	if err != nil {
+		log.Fatalf("Failed to parse OP_RETURN outputs: %v", err)
+	}
+	exampleutil.PrettyPrint("Parsed OP_RETURN outputs", data)
+}
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"testing"
 
+	spvwallet "github.com/bitcoin-sv/spv-wallet-go-client"
+	"github.com/bitcoin-sv/spv-wallet-go-client/capabilities"
 	"github.com/bitcoin-sv/spv-wallet-go-client/errors"
 	"github.com/bitcoin-sv/spv-wallet-go-client/internal/testutils"
 	"github.com/jarcoal/httpmock"
@@ -57,6 +59,38 @@ func TestInvitationsAPI_AcceptInvitation(t *testing.T) {
 	}
 }
 
+func TestInvitationsAPI_PikeContactsUnavailable(t *testing.T) {
+	url := testutils.FullAPIURL(t, invitationsURL, paymail, contactsURI)
+	tests := map[string]func(wallet *spvwallet.UserAPI) error{
+		"AcceptInvitation": func(wallet *spvwallet.UserAPI) error {
+			return wallet.AcceptInvitation(context.Background(), paymail)
+		},
+		"RejectInvitation": func(wallet *spvwallet.UserAPI) error {
+			return wallet.RejectInvitation(context.Background(), paymail)
+		},
+	}
+	for name, call := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given:
+			wallet, transport := testutils.GivenSPVUserAPI(t)
+			testutils.GivenSharedConfig(t, transport, map[string]bool{"pikeContactsEnabled": false})
+			transport.RegisterResponder(http.MethodPost, url, testutils.NewStringResponderStatusOK(http.StatusText(http.StatusOK)))
+			transport.RegisterResponder(http.MethodDelete, url, testutils.NewStringResponderStatusOK(http.StatusText(http.StatusOK)))
+
+			// when:
+			err := call(wallet)
+
+			// then:
+			var unavailable *capabilities.UnavailableError
+			require.ErrorAs(t, err, &unavailable)
+			require.Equal(t, capabilities.FeaturePikeContacts, unavailable.Feature)
+			require.ErrorIs(t, err, errors.ErrFeatureUnavailable)
+			require.Zero(t, transport.GetCallCountInfo()[http.MethodPost+" "+url])
+			require.Zero(t, transport.GetCallCountInfo()[http.MethodDelete+" "+url])
+		})
+	}
+}
+
 func TestInvitationsAPI_RejectInvitation(t *testing.T) {
 
 	tests := map[string]struct {
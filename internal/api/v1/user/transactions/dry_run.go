@@ -0,0 +1,80 @@
+package transactions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/bitcoin-sv/spv-wallet-go-client/coinselect"
+	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/payments"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// DryRunSendToRecipients drafts the transaction to the recipients and signs it locally to compute
+// its exact size and fee, without recording it. If the API has no xPriv to sign the transaction,
+// the size is estimated assuming P2PKH unlocking scripts for all inputs.
+func (a *API) DryRunSendToRecipients(ctx context.Context, r *commands.SendToRecipients) (*payments.FeeEstimate, error) {
+	draft, err := a.DraftToRecipients(ctx, r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send draft to recipients: %w", err)
+	}
+
+	return a.EstimateDraft(draft)
+}
+
+// EstimateDraft computes the size and fee of the draft transaction without recording it.
+func (a *API) EstimateDraft(draft *response.DraftTransaction) (*payments.FeeEstimate, error) {
+	// The dry run isn't authorized by the spending policy, as it is never recorded.
+	hex, err := a.sign(draft)
+	if err != nil {
+		return nil, fmt.Errorf("failed to finalize transaction: %w", err)
+	}
+
+	exact := hex != ""
+	if !exact {
+		hex = draft.Hex
+	}
+
+	tx, err := trx.NewTransactionFromHex(hex)
+	if err != nil {
+		return nil, errors.Join(goclienterr.ErrFailedToParseHex, err)
+	}
+
+	size := tx.Size()
+	if !exact {
+		for _, input := range tx.Inputs {
+			if input.UnlockingScript == nil || len(*input.UnlockingScript) == 0 {
+				size += payments.P2PKHUnlockingScriptSize
+			}
+		}
+	}
+
+	estimate := &payments.FeeEstimate{
+		DraftID:        draft.ID,
+		Inputs:         draft.Configuration.Inputs,
+		Outputs:        draft.Configuration.Outputs,
+		ChangeSatoshis: draft.Configuration.ChangeSatoshis,
+		Size:           size,
+		SizeExact:      exact,
+		FeeUnit:        draft.Configuration.FeeUnit,
+	}
+	for _, input := range draft.Configuration.Inputs {
+		estimate.InputSatoshis += input.Satoshis
+	}
+	for _, output := range tx.Outputs {
+		estimate.OutputSatoshis += output.Satoshis
+	}
+	if estimate.InputSatoshis < estimate.OutputSatoshis {
+		return nil, fmt.Errorf("%w: outputs value %d exceeds inputs value %d", goclienterr.ErrInvalidDraftTransaction, estimate.OutputSatoshis, estimate.InputSatoshis)
+	}
+
+	estimate.Fee = estimate.InputSatoshis - estimate.OutputSatoshis
+	if size > 0 {
+		estimate.FeePerByte = float64(estimate.Fee) / float64(size)
+	}
+	estimate.RequiredFee = coinselect.Fee(draft.Configuration.FeeUnit, size)
+	return estimate, nil
+}
@@ -0,0 +1,178 @@
+package transactions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v1/queryparams"
+	"github.com/bitcoin-sv/spv-wallet-go-client/payments"
+	"github.com/bitcoin-sv/spv-wallet-go-client/queries"
+	"github.com/bitcoin-sv/spv-wallet/models/filter"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// IdempotentSendToRecipients sends a transaction to the recipients exactly once per payment ID.
+// The progress of the payment is persisted in the store after every completed step, so that a retried
+// call resumes from the last completed step. Before drafting, and whenever recording fails, the SPV Wallet
+// is queried for a transaction already recorded for the payment, which is returned instead of paying again.
+func (a *API) IdempotentSendToRecipients(ctx context.Context, cmd *commands.IdempotentSendToRecipients, store payments.Store) (*response.Transaction, error) {
+	if cmd.PaymentID == "" {
+		return nil, goclienterr.ErrMissingPaymentID
+	}
+
+	record, err := store.Load(ctx, cmd.PaymentID)
+	if err != nil {
+		return nil, errors.Join(goclienterr.ErrPaymentStore, err)
+	}
+
+	metadata := paymentMetadata(cmd)
+	if record != nil {
+		if record.State == payments.StateDrafted && draftExpired(record.Draft) {
+			// The expired draft was never signed, so it couldn't have been recorded.
+			record = nil
+		} else {
+			tx, err := a.resumePayment(ctx, record, metadata, store)
+			if !errors.Is(err, errDraftExpired) {
+				return tx, err
+			}
+			// The expired draft wasn't recorded, so a new one can be safely created.
+		}
+	}
+
+	record, err = a.startPayment(ctx, cmd, metadata, store)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.resumePayment(ctx, record, metadata, store)
+}
+
+var errDraftExpired = errors.New("draft transaction expired before it was recorded")
+
+// resumePayment completes the remaining steps of the payment, starting after its last completed step.
+func (a *API) resumePayment(ctx context.Context, record *payments.Record, metadata queryparams.Metadata, store payments.Store) (*response.Transaction, error) {
+	switch record.State {
+	case payments.StateRecorded:
+		return a.Transaction(ctx, record.TransactionID)
+	case payments.StateDrafted:
+		if err := a.signPayment(ctx, record, store); err != nil {
+			return nil, err
+		}
+		return a.recordPayment(ctx, record, metadata, store)
+	case payments.StateSigned:
+		return a.recordPayment(ctx, record, metadata, store)
+	default:
+		return nil, fmt.Errorf("%w: unknown state %q of payment %s", goclienterr.ErrPaymentStore, record.State, record.PaymentID)
+	}
+}
+
+// startPayment returns the record of a transaction already recorded for the payment or creates a new draft.
+func (a *API) startPayment(ctx context.Context, cmd *commands.IdempotentSendToRecipients, metadata queryparams.Metadata, store payments.Store) (*payments.Record, error) {
+	tx, err := a.paymentTransaction(ctx, cmd.PaymentID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	record := &payments.Record{PaymentID: cmd.PaymentID}
+	if tx != nil {
+		record.State = payments.StateRecorded
+		record.TransactionID = tx.ID
+		return record, saveRecord(ctx, store, record)
+	}
+
+	draft, err := a.DraftToRecipients(ctx, &commands.SendToRecipients{
+		Recipients: cmd.Recipients,
+		Metadata:   metadata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send draft to recipients: %w", err)
+	}
+
+	record.State = payments.StateDrafted
+	record.Draft = draft
+	return record, saveRecord(ctx, store, record)
+}
+
+func (a *API) signPayment(ctx context.Context, record *payments.Record, store payments.Store) error {
+	hex, err := a.finalize(ctx, record.Draft)
+	if err != nil {
+		return fmt.Errorf("failed to finalize transaction: %w", err)
+	}
+
+	record.State = payments.StateSigned
+	record.Hex = hex
+	return saveRecord(ctx, store, record)
+}
+
+func (a *API) recordPayment(ctx context.Context, record *payments.Record, metadata queryparams.Metadata, store payments.Store) (*response.Transaction, error) {
+	tx, recordErr := a.RecordTransaction(ctx, &commands.RecordTransaction{
+		Metadata:    metadata,
+		Hex:         record.Hex,
+		ReferenceID: record.Draft.ID,
+	})
+	if recordErr != nil {
+		// The SPV Wallet might have recorded the transaction even though the request failed (e.g., timed out).
+		var err error
+		if tx, err = a.paymentTransaction(ctx, record.PaymentID, record.Draft.ID); err != nil {
+			return nil, errors.Join(recordErr, err)
+		}
+		if tx == nil {
+			if draftExpired(record.Draft) {
+				return nil, errors.Join(errDraftExpired, recordErr)
+			}
+			return nil, recordErr
+		}
+	}
+
+	record.State = payments.StateRecorded
+	record.TransactionID = tx.ID
+	if err := saveRecord(ctx, store, record); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// paymentTransaction looks up the transaction recorded for the payment, either by the draft ID, when known,
+// or by the payment ID stored in the transaction metadata. It returns nil if there is no such transaction.
+func (a *API) paymentTransaction(ctx context.Context, paymentID, draftID string) (*response.Transaction, error) {
+	opt := queries.QueryWithMetadataFilter[filter.TransactionFilter](map[string]any{payments.MetadataKey: paymentID})
+	if draftID != "" {
+		opt = queries.QueryWithFilter(filter.TransactionFilter{DraftID: &draftID})
+	}
+
+	page, err := a.Transactions(ctx, opt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up transaction of payment %s: %w", paymentID, err)
+	}
+
+	for i := range page.Content {
+		if tx := page.Content[i]; tx != nil && tx.Status != "REJECTED" {
+			return tx, nil
+		}
+	}
+	return nil, nil
+}
+
+func draftExpired(draft *response.DraftTransaction) bool {
+	return draft == nil || (!draft.ExpiresAt.IsZero() && time.Now().After(draft.ExpiresAt))
+}
+
+func paymentMetadata(cmd *commands.IdempotentSendToRecipients) queryparams.Metadata {
+	metadata := make(queryparams.Metadata, len(cmd.Metadata)+1)
+	maps.Copy(metadata, cmd.Metadata)
+	metadata[payments.MetadataKey] = cmd.PaymentID
+	return metadata
+}
+
+func saveRecord(ctx context.Context, store payments.Store, record *payments.Record) error {
+	record.UpdatedAt = time.Now()
+	if err := store.Save(ctx, record); err != nil {
+		return errors.Join(goclienterr.ErrPaymentStore, err)
+	}
+	return nil
+}
@@ -6,23 +6,21 @@ import (
 
 	bip32 "github.com/bitcoin-sv/go-sdk/compat/bip32"
 	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
-	"github.com/bitcoin-sv/go-sdk/script"
-	trx "github.com/bitcoin-sv/go-sdk/transaction"
-	sighash "github.com/bitcoin-sv/go-sdk/transaction/sighash"
-	"github.com/bitcoin-sv/go-sdk/transaction/template/p2pkh"
 	walleterrors "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/unlocking"
 	"github.com/bitcoin-sv/spv-wallet/models/response"
 )
 
 type noopTransactionSigner struct {
 }
 
-func (*noopTransactionSigner) TransactionSignedHex(dt *response.DraftTransaction) (string, error) {
+func (*noopTransactionSigner) TransactionSignedHex(dt *response.DraftTransaction, opts ...unlocking.SignOption) (string, error) {
 	return "", nil
 }
 
 type xPrivTransactionSigner struct {
-	xPriv *bip32.ExtendedKey
+	xPriv     *bip32.ExtendedKey
+	templates *unlocking.Registry
 }
 
 func NewXPrivTransactionSigner(xPriv string) (*xPrivTransactionSigner, error) {
@@ -31,40 +29,28 @@ func NewXPrivTransactionSigner(xPriv string) (*xPrivTransactionSigner, error) {
 		return nil, fmt.Errorf("failed to generate HD key from xPriv str: %w", err)
 	}
 
-	return &xPrivTransactionSigner{xPriv: hdKey}, nil
+	return &xPrivTransactionSigner{xPriv: hdKey, templates: unlocking.NewRegistry()}, nil
 }
 
-func (ts *xPrivTransactionSigner) TransactionSignedHex(dt *response.DraftTransaction) (string, error) {
-	// Create transaction from hex
-	tx, err := trx.NewTransactionFromHex(dt.Hex)
+func (ts *xPrivTransactionSigner) TransactionSignedHex(dt *response.DraftTransaction, opts ...unlocking.SignOption) (string, error) {
+	// Create transaction from hex with the inputs selected by the options enriched by their source outputs
+	tx, inputs, err := unlocking.NewTransaction(dt, opts...)
 	if err != nil {
-		return "", errors.Join(walleterrors.ErrFailedToParseHex, err)
+		return "", err
 	}
-	// we need to reset the inputs as we are going to add them via tx.AddInputFrom (ts-sdk method) and then sign
-	tx.Inputs = make([]*trx.TransactionInput, 0)
 
-	// Enrich inputs
-	for _, draftInput := range dt.Configuration.Inputs {
-		lockingScript, err := script.NewFromHex(draftInput.Destination.LockingScript)
-		if err != nil {
-			return "", errors.Join(walleterrors.ErrCreateLockingScript, err)
-		}
-
-		// prepare unlocking script
-		key, err := getDerivedKeyForDestination(ts.xPriv, &draftInput.Destination)
+	// Prepare unlocking scripts with the templates registered for the locking scripts of the inputs
+	for _, input := range inputs {
+		input.Key, err = getDerivedKeyForDestination(ts.xPriv, &input.Draft.Destination)
 		if err != nil {
 			return "", errors.Join(walleterrors.ErrGetDerivedKeyForDestination, err)
 		}
-		sigHashFlags := sighash.AllForkID
-		unlockScript, err := p2pkh.Unlock(key, &sigHashFlags)
-		if err != nil {
-			return "", errors.Join(walleterrors.ErrCreateUnlockingScript, err)
-		}
 
-		err = tx.AddInputFrom(draftInput.TransactionID, draftInput.OutputIndex, lockingScript.String(), draftInput.Satoshis, unlockScript)
+		unlocker, err := ts.templates.Unlocker(input)
 		if err != nil {
-			return "", errors.Join(walleterrors.ErrAddInputsToTransaction, err)
+			return "", errors.Join(walleterrors.ErrCreateUnlockingScript, err)
 		}
+		tx.Inputs[input.Index].UnlockingScriptTemplate = unlocker
 	}
 
 	err = tx.Sign()
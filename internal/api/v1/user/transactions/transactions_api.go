@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"net/url"
 
+	"github.com/bitcoin-sv/go-sdk/transaction/chaintracker"
+	"github.com/bitcoin-sv/spv-wallet-go-client/beef"
 	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
 	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v1/queryparams"
 	"github.com/bitcoin-sv/spv-wallet-go-client/queries"
+	"github.com/bitcoin-sv/spv-wallet-go-client/unlocking"
 	"github.com/bitcoin-sv/spv-wallet/models/filter"
 	"github.com/bitcoin-sv/spv-wallet/models/response"
 	"github.com/go-resty/resty/v2"
@@ -19,17 +22,70 @@ const (
 )
 
 type TransactionSigner interface {
-	TransactionSignedHex(dt *response.DraftTransaction) (string, error)
+	TransactionSignedHex(dt *response.DraftTransaction, opts ...unlocking.SignOption) (string, error)
+}
+
+// SpendingPolicy authorizes the draft transactions before they are signed.
+type SpendingPolicy interface {
+	Authorize(ctx context.Context, draft *response.DraftTransaction) error
 }
 
 type API struct {
 	url               *url.URL
 	httpClient        *resty.Client
 	transactionSigner TransactionSigner
+	spendingPolicy    SpendingPolicy
+	merkleRoots       chaintracker.ChainTracker
+}
+
+// SetMerkleRoots sets the merkle roots the BEEF transactions are verified against before they are recorded;
+// nil disables recording the BEEF transactions.
+func (a *API) SetMerkleRoots(roots chaintracker.ChainTracker) {
+	a.merkleRoots = roots
+}
+
+// SetSpendingPolicy sets the policy authorizing every draft transaction before it is signed; nil disables it.
+func (a *API) SetSpendingPolicy(policy SpendingPolicy) {
+	a.spendingPolicy = policy
+}
+
+// SetUnlockingTemplates sets the registry of the unlocking templates used to sign the inputs of the draft transactions;
+// nil restores the default registry. It has no effect on an API without an xPriv, which doesn't sign transactions.
+func (a *API) SetUnlockingTemplates(templates *unlocking.Registry) {
+	signer, ok := a.transactionSigner.(*xPrivTransactionSigner)
+	if !ok {
+		return
+	}
+	if templates == nil {
+		templates = unlocking.NewRegistry()
+	}
+	signer.templates = templates
 }
 
 func (a *API) FinalizeTransaction(draft *response.DraftTransaction) (string, error) {
-	hex, err := a.transactionSigner.TransactionSignedHex(draft)
+	return a.finalize(context.Background(), draft)
+}
+
+// SignTransaction authorizes the draft transaction with the spending policy and signs it according to the options,
+// e.g., only a subset of its inputs or with other sighash flags.
+func (a *API) SignTransaction(draft *response.DraftTransaction, opts ...unlocking.SignOption) (string, error) {
+	return a.finalize(context.Background(), draft, opts...)
+}
+
+// finalize authorizes the draft transaction with the spending policy and signs it.
+func (a *API) finalize(ctx context.Context, draft *response.DraftTransaction, opts ...unlocking.SignOption) (string, error) {
+	if a.spendingPolicy != nil {
+		if err := a.spendingPolicy.Authorize(ctx, draft); err != nil {
+			return "", fmt.Errorf("draft transaction %s not authorized: %w", draft.ID, err)
+		}
+	}
+
+	return a.sign(draft, opts...)
+}
+
+// sign signs the draft transaction without the spending policy.
+func (a *API) sign(draft *response.DraftTransaction, opts ...unlocking.SignOption) (string, error) {
+	hex, err := a.transactionSigner.TransactionSignedHex(draft, opts...)
 	if err != nil {
 		return "", fmt.Errorf("failed to finalize transaction: %w", err)
 	}
@@ -63,7 +119,7 @@ func (a *API) SendToRecipients(ctx context.Context, r *commands.SendToRecipients
 	}
 
 	var hex string
-	if hex, err = a.FinalizeTransaction(draft); err != nil {
+	if hex, err = a.finalize(ctx, draft); err != nil {
 		return nil, fmt.Errorf("failed to finalize transaction: %w", err)
 	}
 
@@ -89,7 +145,19 @@ func (a *API) DraftTransaction(ctx context.Context, r *commands.DraftTransaction
 	return &result, nil
 }
 
+// RecordTransaction records the transaction given either as a raw hex or as a BEEF hex;
+// a BEEF transaction is verified against the merkle roots and recorded as its raw hex.
 func (a *API) RecordTransaction(ctx context.Context, r *commands.RecordTransaction) (*response.Transaction, error) {
+	if beef.IsBEEFHex(r.Hex) {
+		raw, err := beef.RawHex(r.Hex, a.merkleRoots)
+		if err != nil {
+			return nil, err
+		}
+		cmd := *r
+		cmd.Hex = raw
+		r = &cmd
+	}
+
 	var result response.Transaction
 
 	_, err := a.httpClient.R().
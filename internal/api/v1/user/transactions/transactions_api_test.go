@@ -2,16 +2,33 @@ package transactions_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
-
+	"time"
+
+	"github.com/bitcoin-sv/go-sdk/chainhash"
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/bitcoin-sv/go-sdk/script"
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	sighash "github.com/bitcoin-sv/go-sdk/transaction/sighash"
+	"github.com/bitcoin-sv/go-sdk/transaction/template/p2pkh"
+	spvwallet "github.com/bitcoin-sv/spv-wallet-go-client"
+	"github.com/bitcoin-sv/spv-wallet-go-client/beef"
 	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
+	"github.com/bitcoin-sv/spv-wallet-go-client/config"
 	"github.com/bitcoin-sv/spv-wallet-go-client/errors"
 	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v1/queryparams"
 	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v1/user/transactions/transactionstest"
 	"github.com/bitcoin-sv/spv-wallet-go-client/internal/testutils"
+	"github.com/bitcoin-sv/spv-wallet-go-client/payments"
+	"github.com/bitcoin-sv/spv-wallet-go-client/policy"
 	"github.com/bitcoin-sv/spv-wallet-go-client/queries"
+	"github.com/bitcoin-sv/spv-wallet-go-client/sweep"
+	"github.com/bitcoin-sv/spv-wallet-go-client/txwait"
+	"github.com/bitcoin-sv/spv-wallet-go-client/unlocking"
+	"github.com/bitcoin-sv/spv-wallet/models"
 	"github.com/bitcoin-sv/spv-wallet/models/filter"
 	"github.com/bitcoin-sv/spv-wallet/models/response"
 	"github.com/jarcoal/httpmock"
@@ -21,6 +38,7 @@ import (
 const (
 	transactionsURL     = "/api/v1/transactions"
 	transactionDraftURL = "/api/v1/transactions/drafts"
+	utxosURL            = "/api/v1/utxos"
 )
 
 func TestTransactionsAPI_SendToRecipients(t *testing.T) {
@@ -109,6 +127,441 @@ func TestTransactionsAPI_SendToRecipients(t *testing.T) {
 		require.ErrorIs(t, err, testutils.NewBadRequestSPVError())
 		require.Nil(t, result)
 	})
+
+	t.Run("SendToRecipients - spending policy violation", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodPost, drafTransactionURL, testutils.NewJSONFileResponderWithStatusOK("transactionstest/transaction_draft_with_hex_200.json"))
+		transport.RegisterResponder(http.MethodPost, recordTransactionURL, testutils.NewJSONFileResponderWithStatusOK("transactionstest/transaction_send_to_recipients_200.json"))
+		wallet.SetSpendingPolicy(policy.NewEngine([]policy.Policy{policy.MaxPerTransaction(0)}))
+		ctx := context.Background()
+
+		// when:
+		result, err := wallet.SendToRecipients(ctx, &commands.SendToRecipients{
+			Recipients: []*commands.Recipients{
+				{
+					OpReturn: opReturn,
+				},
+			},
+		})
+
+		// then:
+		require.ErrorIs(t, err, errors.ErrSpendingLimitExceeded)
+		require.Nil(t, result)
+		require.Zero(t, transport.GetCallCountInfo()["POST "+recordTransactionURL])
+	})
+}
+
+func TestTransactionsAPI_SendToRecipientsIdempotent(t *testing.T) {
+	drafTransactionURL := testutils.FullAPIURL(t, transactionDraftURL)
+	recordTransactionURL := testutils.FullAPIURL(t, transactionsURL)
+	transactionsPageURL := testutils.FullAPIURL(t, transactionsURL)
+	expectedTransaction := transactionstest.ExpectedSendToRecipientsTransaction(t)
+	transactionURL := testutils.FullAPIURL(t, transactionsURL, expectedTransaction.ID)
+	emptyPageResponder := testutils.NewJSONBodyResponderWithStatusOK(response.PageModel[response.Transaction]{})
+	paymentPageResponder := testutils.NewJSONBodyResponderWithStatusOK(response.PageModel[response.Transaction]{
+		Content: []*response.Transaction{expectedTransaction},
+	})
+	newCmd := func() *commands.IdempotentSendToRecipients {
+		return &commands.IdempotentSendToRecipients{
+			PaymentID: "payment-1",
+			SendToRecipients: commands.SendToRecipients{
+				Recipients: []*commands.Recipients{{OpReturn: &response.OpReturn{StringParts: []string{"hello", "world"}}}},
+			},
+		}
+	}
+
+	t.Run("SendToRecipientsIdempotent success", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodGet, transactionsPageURL, emptyPageResponder)
+		transport.RegisterResponder(http.MethodPost, drafTransactionURL, testutils.NewJSONFileResponderWithStatusOK("transactionstest/transaction_draft_with_hex_200.json"))
+		transport.RegisterResponder(http.MethodPost, recordTransactionURL, testutils.NewJSONFileResponderWithStatusOK("transactionstest/transaction_send_to_recipients_200.json"))
+		store := payments.NewMemoryStore()
+
+		// when:
+		result, err := wallet.SendToRecipientsIdempotent(context.Background(), newCmd(), store)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, expectedTransaction, result)
+
+		record, err := store.Load(context.Background(), "payment-1")
+		require.NoError(t, err)
+		require.Equal(t, payments.StateRecorded, record.State)
+		require.Equal(t, expectedTransaction.ID, record.TransactionID)
+	})
+
+	t.Run("SendToRecipientsIdempotent retry after RecordTransaction failure resumes from signed state", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodGet, transactionsPageURL, emptyPageResponder)
+		transport.RegisterResponder(http.MethodPost, drafTransactionURL, testutils.NewJSONFileResponderWithStatusOK("transactionstest/transaction_draft_with_hex_200.json"))
+		transport.RegisterResponder(http.MethodPost, recordTransactionURL, testutils.NewInternalServerSPVErrorResponder())
+		store := payments.NewMemoryStore()
+
+		// when:
+		result, err := wallet.SendToRecipientsIdempotent(context.Background(), newCmd(), store)
+
+		// then:
+		require.ErrorIs(t, err, testutils.NewInternalServerSPVError())
+		require.Nil(t, result)
+
+		record, err := store.Load(context.Background(), "payment-1")
+		require.NoError(t, err)
+		require.Equal(t, payments.StateSigned, record.State)
+
+		// when:
+		transport.RegisterResponder(http.MethodPost, recordTransactionURL, testutils.NewJSONFileResponderWithStatusOK("transactionstest/transaction_send_to_recipients_200.json"))
+		result, err = wallet.SendToRecipientsIdempotent(context.Background(), newCmd(), store)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, expectedTransaction, result)
+		require.Equal(t, 1, transport.GetCallCountInfo()[http.MethodPost+" "+drafTransactionURL])
+	})
+
+	t.Run("SendToRecipientsIdempotent RecordTransaction failure after the transaction was recorded", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodGet, transactionsPageURL, httpmock.ResponderFromMultipleResponses([]*http.Response{
+			httpmock.NewStringResponse(http.StatusOK, `{"content":[]}`),
+		}).Then(paymentPageResponder))
+		transport.RegisterResponder(http.MethodPost, drafTransactionURL, testutils.NewJSONFileResponderWithStatusOK("transactionstest/transaction_draft_with_hex_200.json"))
+		transport.RegisterResponder(http.MethodPost, recordTransactionURL, testutils.NewInternalServerSPVErrorResponder())
+		store := payments.NewMemoryStore()
+
+		// when:
+		result, err := wallet.SendToRecipientsIdempotent(context.Background(), newCmd(), store)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, expectedTransaction, result)
+	})
+
+	t.Run("SendToRecipientsIdempotent payment already recorded", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodGet, transactionURL, testutils.NewJSONBodyResponderWithStatusOK(expectedTransaction))
+		store := payments.NewMemoryStore()
+		require.NoError(t, store.Save(context.Background(), &payments.Record{
+			PaymentID:     "payment-1",
+			State:         payments.StateRecorded,
+			TransactionID: expectedTransaction.ID,
+		}))
+
+		// when:
+		result, err := wallet.SendToRecipientsIdempotent(context.Background(), newCmd(), store)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, expectedTransaction, result)
+		require.Equal(t, 1, transport.GetTotalCallCount())
+	})
+
+	t.Run("SendToRecipientsIdempotent payment recorded but record lost", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodGet, transactionsPageURL, paymentPageResponder)
+		transport.RegisterResponder(http.MethodGet, transactionURL, testutils.NewJSONBodyResponderWithStatusOK(expectedTransaction))
+
+		// when:
+		result, err := wallet.SendToRecipientsIdempotent(context.Background(), newCmd(), payments.NewMemoryStore())
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, expectedTransaction, result)
+		require.Zero(t, transport.GetCallCountInfo()[http.MethodPost+" "+drafTransactionURL])
+	})
+
+	t.Run("SendToRecipientsIdempotent missing payment ID", func(t *testing.T) {
+		// given:
+		wallet, _ := testutils.GivenSPVUserAPI(t)
+		cmd := newCmd()
+		cmd.PaymentID = ""
+
+		// when:
+		result, err := wallet.SendToRecipientsIdempotent(context.Background(), cmd, payments.NewMemoryStore())
+
+		// then:
+		require.ErrorIs(t, err, errors.ErrMissingPaymentID)
+		require.Nil(t, result)
+	})
+}
+
+func TestTransactionsAPI_DryRunSendToRecipients(t *testing.T) {
+	drafTransactionURL := testutils.FullAPIURL(t, transactionDraftURL)
+	draft := transactionstest.ExpectedDraftTransactionWithHex(t)
+	cmd := &commands.SendToRecipients{
+		Recipients: []*commands.Recipients{{OpReturn: &response.OpReturn{StringParts: []string{"hello", "world"}}}},
+	}
+
+	t.Run("DryRunSendToRecipients with xPriv signs the transaction", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodPost, drafTransactionURL, testutils.NewJSONFileResponderWithStatusOK("transactionstest/transaction_draft_with_hex_200.json"))
+
+		// when:
+		result, err := wallet.DryRunSendToRecipients(context.Background(), cmd)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, draft.ID, result.DraftID)
+		require.Equal(t, uint64(9), result.InputSatoshis)
+		require.Equal(t, uint64(8), result.OutputSatoshis)
+		require.Equal(t, uint64(8), result.ChangeSatoshis)
+		require.Equal(t, uint64(1), result.Fee)
+		require.Equal(t, 214, result.Size)
+		require.True(t, result.SizeExact)
+		require.Equal(t, uint64(1), result.RequiredFee)
+
+		recordTransactionURL := testutils.FullAPIURL(t, transactionsURL)
+		require.Zero(t, transport.GetCallCountInfo()[http.MethodPost+" "+recordTransactionURL])
+	})
+
+	t.Run("DryRunSendToRecipients with xPub estimates the size", func(t *testing.T) {
+		// given:
+		transport := httpmock.NewMockTransport()
+		transport.RegisterResponder(http.MethodPost, drafTransactionURL, testutils.NewJSONFileResponderWithStatusOK("transactionstest/transaction_draft_with_hex_200.json"))
+		wallet, err := spvwallet.NewUserAPIWithXPub(config.Config{Addr: testutils.TestAPIAddr, Timeout: time.Second, Transport: transport}, testutils.UserXPub)
+		require.NoError(t, err)
+
+		// when:
+		result, err := wallet.DryRunSendToRecipients(context.Background(), cmd)
+
+		// then:
+		require.NoError(t, err)
+		require.False(t, result.SizeExact)
+		require.Equal(t, 215, result.Size)
+		require.Equal(t, uint64(1), result.Fee)
+	})
+
+	t.Run("EstimateFee returns the fee", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodPost, drafTransactionURL, testutils.NewJSONFileResponderWithStatusOK("transactionstest/transaction_draft_with_hex_200.json"))
+
+		// when:
+		fee, err := wallet.EstimateFee(context.Background(), cmd)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), fee)
+	})
+
+	t.Run("DryRunSendToRecipients - DraftToRecipients error", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodPost, drafTransactionURL, testutils.NewBadRequestSPVErrorResponder())
+
+		// when:
+		result, err := wallet.DryRunSendToRecipients(context.Background(), cmd)
+
+		// then:
+		require.ErrorIs(t, err, testutils.NewBadRequestSPVError())
+		require.Nil(t, result)
+	})
+}
+
+func TestTransactionsAPI_ConsolidateUTXOs(t *testing.T) {
+	drafTransactionURL := testutils.FullAPIURL(t, transactionDraftURL)
+	recordTransactionURL := testutils.FullAPIURL(t, transactionsURL)
+	utxosPageURL := testutils.FullAPIURL(t, utxosURL)
+	expectedTransaction := transactionstest.ExpectedSendToRecipientsTransaction(t)
+
+	t.Run("ConsolidateUTXOs spends the UTXOs in batches", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodGet, utxosPageURL, testutils.NewJSONBodyResponderWithStatusOK(givenUTXOsPage(1, 2, 3, 4, 5, 6, 7)))
+		var drafts []commands.DraftTransaction
+		transport.RegisterResponder(http.MethodPost, drafTransactionURL, recordingResponder(t, &drafts, testutils.NewJSONFileResponderWithStatusOK("transactionstest/transaction_draft_with_hex_200.json")))
+		transport.RegisterResponder(http.MethodPost, recordTransactionURL, testutils.NewJSONFileResponderWithStatusOK("transactionstest/transaction_send_to_recipients_200.json"))
+
+		// when:
+		result, err := wallet.ConsolidateUTXOs(context.Background(), &commands.ConsolidateUTXOs{
+			MaxSize: 10 + 34 + 3*148,
+		})
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, []*response.Transaction{expectedTransaction, expectedTransaction}, result)
+		require.Len(t, drafts, 2)
+		for _, draft := range drafts {
+			require.Len(t, draft.Config.FromUtxos, 3)
+			require.Equal(t, 1, draft.Config.ChangeNumberOfDestinations)
+			require.Empty(t, draft.Config.Outputs)
+		}
+	})
+
+	t.Run("ConsolidateUTXOs returns the transactions recorded before the failure", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodGet, utxosPageURL, testutils.NewJSONBodyResponderWithStatusOK(givenUTXOsPage(1, 2, 3, 4)))
+		transport.RegisterResponder(http.MethodPost, drafTransactionURL, testutils.NewJSONFileResponderWithStatusOK("transactionstest/transaction_draft_with_hex_200.json"))
+		transport.RegisterResponder(http.MethodPost, recordTransactionURL, testutils.NewJSONFileResponderWithStatusOK("transactionstest/transaction_send_to_recipients_200.json").
+			Then(testutils.NewInternalServerSPVErrorResponder()))
+
+		// when:
+		result, err := wallet.ConsolidateUTXOs(context.Background(), &commands.ConsolidateUTXOs{
+			MaxSize: 10 + 34 + 2*148,
+		})
+
+		// then:
+		require.ErrorIs(t, err, testutils.NewInternalServerSPVError())
+		require.Equal(t, []*response.Transaction{expectedTransaction}, result)
+	})
+
+	t.Run("ConsolidateUTXOs with too low size limit", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodGet, utxosPageURL, testutils.NewJSONBodyResponderWithStatusOK(givenUTXOsPage(1, 2)))
+
+		// when:
+		result, err := wallet.ConsolidateUTXOs(context.Background(), &commands.ConsolidateUTXOs{MaxSize: 200})
+
+		// then:
+		require.ErrorIs(t, err, errors.ErrInvalidUTXOOperation)
+		require.Nil(t, result)
+	})
+
+	t.Run("ConsolidateUTXOs - UTXOs error", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodGet, utxosPageURL, testutils.NewBadRequestSPVErrorResponder())
+
+		// when:
+		result, err := wallet.ConsolidateUTXOs(context.Background(), &commands.ConsolidateUTXOs{})
+
+		// then:
+		require.ErrorIs(t, err, testutils.NewBadRequestSPVError())
+		require.Nil(t, result)
+	})
+}
+
+func TestTransactionsAPI_SplitUTXOs(t *testing.T) {
+	drafTransactionURL := testutils.FullAPIURL(t, transactionDraftURL)
+	recordTransactionURL := testutils.FullAPIURL(t, transactionsURL)
+	utxosPageURL := testutils.FullAPIURL(t, utxosURL)
+	expectedTransaction := transactionstest.ExpectedSendToRecipientsTransaction(t)
+
+	t.Run("SplitUTXOs splits the largest UTXO", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		page := givenUTXOsPage(10, 500, 20)
+		transport.RegisterResponder(http.MethodGet, utxosPageURL, testutils.NewJSONBodyResponderWithStatusOK(page))
+		var drafts []commands.DraftTransaction
+		transport.RegisterResponder(http.MethodPost, drafTransactionURL, recordingResponder(t, &drafts, testutils.NewJSONFileResponderWithStatusOK("transactionstest/transaction_draft_with_hex_200.json")))
+		transport.RegisterResponder(http.MethodPost, recordTransactionURL, testutils.NewJSONFileResponderWithStatusOK("transactionstest/transaction_send_to_recipients_200.json"))
+
+		// when:
+		result, err := wallet.SplitUTXOs(context.Background(), &commands.SplitUTXOs{Outputs: 5})
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, expectedTransaction, result)
+		require.Len(t, drafts, 1)
+		require.Equal(t, []*response.UtxoPointer{&page.Content[1].UtxoPointer}, drafts[0].Config.FromUtxos)
+		require.Equal(t, 5, drafts[0].Config.ChangeNumberOfDestinations)
+	})
+
+	t.Run("SplitUTXOs with UTXO which is not spendable", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodGet, utxosPageURL+"?page=1&size=100&transactionId=tx-1", testutils.NewJSONBodyResponderWithStatusOK(givenUTXOsPage(500, 10)))
+
+		// when:
+		result, err := wallet.SplitUTXOs(context.Background(), &commands.SplitUTXOs{
+			UTXO:    &response.UtxoPointer{TransactionID: "tx-1", OutputIndex: 0},
+			Outputs: 5,
+		})
+
+		// then:
+		require.ErrorIs(t, err, errors.ErrUTXONotFound)
+		require.Nil(t, result)
+	})
+
+	t.Run("SplitUTXOs into a single output", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodGet, utxosPageURL, testutils.NewJSONBodyResponderWithStatusOK(givenUTXOsPage(500)))
+
+		// when:
+		result, err := wallet.SplitUTXOs(context.Background(), &commands.SplitUTXOs{Outputs: 1})
+
+		// then:
+		require.ErrorIs(t, err, errors.ErrInvalidUTXOOperation)
+		require.Nil(t, result)
+	})
+}
+
+func TestTransactionsAPI_SweepPrivateKey(t *testing.T) {
+	recordTransactionURL := testutils.FullAPIURL(t, transactionsURL)
+	expectedTransaction := transactionstest.ExpectedSendToRecipientsTransaction(t)
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	destination, err := script.NewAddressFromString("1MB8MfCyA5mGt3UBhxYr1exBfsFWgL1gCm")
+	require.NoError(t, err)
+	destinationLockingScript, err := p2pkh.Lock(destination)
+	require.NoError(t, err)
+
+	source := sweep.UTXOSourceFunc(func(context.Context, *script.Address) ([]*sweep.UTXO, error) {
+		return []*sweep.UTXO{{TransactionID: "f365f6973db944fdbd0dba8e94ca63f2f365f6973db944fdbd0dba8e94ca63f2", OutputIndex: 0, Satoshis: 1000}}, nil
+	})
+	resolver := sweep.DestinationResolverFunc(func(context.Context, uint64) (*script.Script, error) {
+		return destinationLockingScript, nil
+	})
+
+	t.Run("SweepPrivateKey records the signed transaction", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		var recorded commands.RecordTransaction
+		transport.RegisterResponder(http.MethodPost, recordTransactionURL, func(req *http.Request) (*http.Response, error) {
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&recorded))
+			return testutils.NewJSONFileResponderWithStatusOK("transactionstest/transaction_send_to_recipients_200.json")(req)
+		})
+
+		// when:
+		result, err := wallet.SweepPrivateKey(context.Background(), &commands.SweepPrivateKey{
+			PrivateKey: key.Wif(),
+			Metadata:   queryparams.Metadata{"source": "paper wallet"},
+		}, source, resolver)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, expectedTransaction, result)
+		require.Empty(t, recorded.ReferenceID)
+		require.Equal(t, "paper wallet", recorded.Metadata["source"])
+
+		tx, err := trx.NewTransactionFromHex(recorded.Hex)
+		require.NoError(t, err)
+		require.Len(t, tx.Inputs, 1)
+		require.Equal(t, destinationLockingScript, tx.Outputs[0].LockingScript)
+	})
+
+	t.Run("SweepPrivateKey with invalid private key", func(t *testing.T) {
+		// given:
+		wallet, _ := testutils.GivenSPVUserAPI(t)
+
+		// when:
+		result, err := wallet.SweepPrivateKey(context.Background(), &commands.SweepPrivateKey{PrivateKey: "invalid"}, source, resolver)
+
+		// then:
+		require.ErrorIs(t, err, errors.ErrInvalidPrivateKey)
+		require.Nil(t, result)
+	})
+
+	t.Run("SweepPrivateKey - RecordTransaction error", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodPost, recordTransactionURL, testutils.NewBadRequestSPVErrorResponder())
+
+		// when:
+		result, err := wallet.SweepPrivateKey(context.Background(), &commands.SweepPrivateKey{PrivateKey: key.Wif()}, source, resolver)
+
+		// then:
+		require.ErrorIs(t, err, testutils.NewBadRequestSPVError())
+		require.Nil(t, result)
+	})
 }
 
 func TestTransactionsAPI_FinalizeTransaction(t *testing.T) {
@@ -150,6 +603,39 @@ func TestTransactionsAPI_FinalizeTransaction(t *testing.T) {
 	}
 }
 
+func TestTransactionsAPI_SignTransaction(t *testing.T) {
+	t.Run("Sign Transaction with SINGLE|ANYONECANPAY sighash flag", func(t *testing.T) {
+		// given:
+		wallet, _ := testutils.GivenSPVUserAPI(t)
+		draft := transactionstest.ExpectedDraftTransactionWithHex(t)
+		flag := sighash.SingleForkID | sighash.AnyOneCanPay
+
+		// when:
+		hex, err := wallet.SignTransaction(draft, unlocking.WithInputSigHashFlag(0, flag))
+
+		// then:
+		require.NoError(t, err)
+		tx, err := trx.NewTransactionFromHex(hex)
+		require.NoError(t, err)
+		chunks, err := tx.Inputs[0].UnlockingScript.Chunks()
+		require.NoError(t, err)
+		signature := chunks[0].Data
+		require.Equal(t, byte(flag), signature[len(signature)-1])
+	})
+
+	t.Run("Sign Transaction with input outside of the draft", func(t *testing.T) {
+		// given:
+		wallet, _ := testutils.GivenSPVUserAPI(t)
+
+		// when:
+		hex, err := wallet.SignTransaction(transactionstest.ExpectedDraftTransactionWithHex(t), unlocking.WithInputs(1))
+
+		// then:
+		require.ErrorIs(t, err, errors.ErrInvalidSigningOptions)
+		require.Empty(t, hex)
+	})
+}
+
 func TestTransactionsAPI_UpdateTransactionMetadata(t *testing.T) {
 	id := "1024"
 	tests := map[string]struct {
@@ -239,6 +725,86 @@ func TestTransactionsAPI_RecordTransaction(t *testing.T) {
 	}
 }
 
+func TestTransactionsAPI_RecordBEEFTransaction(t *testing.T) {
+	url := testutils.FullAPIURL(t, transactionsURL)
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	address, err := script.NewAddressFromPublicKey(key.PubKey(), true)
+	require.NoError(t, err)
+	lockingScript, err := p2pkh.Lock(address)
+	require.NoError(t, err)
+
+	parent := trx.NewTransaction()
+	parent.AddInput(&trx.TransactionInput{SourceTXID: &chainhash.Hash{1}, UnlockingScript: &script.Script{script.OpTRUE}})
+	parent.AddOutput(&trx.TransactionOutput{Satoshis: 2000, LockingScript: lockingScript})
+	isTxID := true
+	parent.MerklePath = trx.NewMerklePath(850000, [][]*trx.PathElement{{{Offset: 0, Hash: parent.TxID(), Txid: &isTxID}}})
+	root, err := parent.MerklePath.ComputeRoot(parent.TxID())
+	require.NoError(t, err)
+
+	unlocker, err := p2pkh.Unlock(key, nil)
+	require.NoError(t, err)
+	tx := trx.NewTransaction()
+	tx.AddInputFromTx(parent, 0, unlocker)
+	tx.AddOutput(&trx.TransactionOutput{Satoshis: 1000, LockingScript: lockingScript})
+	require.NoError(t, tx.Sign())
+	beefHex, err := beef.EncodeHex(tx)
+	require.NoError(t, err)
+
+	t.Run("RecordTransaction records the raw hex of a verified BEEF transaction", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		var recorded commands.RecordTransaction
+		transport.RegisterResponder(http.MethodPost, url, func(req *http.Request) (*http.Response, error) {
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&recorded))
+			return testutils.NewJSONFileResponderWithStatusOK("transactionstest/post_transaction_record_201.json")(req)
+		})
+		roots := beef.NewMemoryMerkleRoots()
+		require.NoError(t, roots.SaveMerkleRoots([]models.MerkleRoot{{MerkleRoot: root.String(), BlockHeight: 850000}}))
+		wallet.SetMerkleRoots(roots)
+		cmd := &commands.RecordTransaction{Hex: beefHex, ReferenceID: "reference-1"}
+
+		// when:
+		got, err := wallet.RecordTransaction(context.Background(), cmd)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, transactionstest.ExpectedRecordTransaction(t), got)
+		require.Equal(t, tx.Hex(), recorded.Hex)
+		require.Equal(t, "reference-1", recorded.ReferenceID)
+		require.Equal(t, beefHex, cmd.Hex)
+	})
+
+	t.Run("RecordTransaction fails on a BEEF transaction with an unknown merkle root", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodPost, url, testutils.NewJSONFileResponderWithStatusOK("transactionstest/post_transaction_record_201.json"))
+		wallet.SetMerkleRoots(beef.NewMemoryMerkleRoots())
+
+		// when:
+		got, err := wallet.RecordTransaction(context.Background(), &commands.RecordTransaction{Hex: beefHex})
+
+		// then:
+		require.ErrorIs(t, err, errors.ErrBEEFVerification)
+		require.Nil(t, got)
+		require.Zero(t, transport.GetTotalCallCount())
+	})
+
+	t.Run("RecordTransaction fails on a malformed BEEF transaction", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodPost, url, testutils.NewJSONFileResponderWithStatusOK("transactionstest/post_transaction_record_201.json"))
+
+		// when:
+		got, err := wallet.RecordTransaction(context.Background(), &commands.RecordTransaction{Hex: beefHex[:20]})
+
+		// then:
+		require.ErrorIs(t, err, errors.ErrInvalidBEEF)
+		require.Nil(t, got)
+		require.Zero(t, transport.GetTotalCallCount())
+	})
+}
+
 func TestTransactionsAPI_DraftTransaction(t *testing.T) {
 	tests := map[string]struct {
 		responder        httpmock.Responder
@@ -325,6 +891,40 @@ func TestTransactionsAPI_Transaction(t *testing.T) {
 	}
 }
 
+func TestTransactionsAPI_WaitForTransaction(t *testing.T) {
+	id := "1024"
+	url := testutils.FullAPIURL(t, transactionsURL, id)
+
+	t.Run("WaitForTransaction retries until the transaction is mined", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodGet, url, testutils.NewInternalServerSPVErrorResponder().
+			Then(testutils.NewJSONFileResponderWithStatusOK("transactionstest/get_transaction_200.json")))
+
+		// when:
+		got, err := wallet.WaitForTransaction(context.Background(), id, txwait.Mined(), txwait.WithBackoff(time.Millisecond, time.Millisecond, 1))
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, transactionstest.ExpectedTransaction(t), got)
+		require.Equal(t, 2, transport.GetTotalCallCount())
+	})
+
+	t.Run("WaitForTransaction times out", func(t *testing.T) {
+		// given:
+		wallet, transport := testutils.GivenSPVUserAPI(t)
+		transport.RegisterResponder(http.MethodGet, url, testutils.NewJSONFileResponderWithStatusOK("transactionstest/get_transaction_200.json"))
+
+		// when:
+		got, err := wallet.WaitForTransaction(context.Background(), id, txwait.StatusIn("CONFIRMED"),
+			txwait.WithBackoff(time.Millisecond, time.Millisecond, 1), txwait.WithTimeout(20*time.Millisecond))
+
+		// then:
+		require.ErrorIs(t, err, errors.ErrTransactionWaitTimeout)
+		require.Nil(t, got)
+	})
+}
+
 func TestTransactionsAPI_Transactions(t *testing.T) {
 	tests := map[string]struct {
 		responder        httpmock.Responder
@@ -379,3 +979,24 @@ func TestTransactionsAPI_Transactions(t *testing.T) {
 		})
 	}
 }
+
+func givenUTXOsPage(values ...uint64) *queries.UtxosPage {
+	page := &queries.UtxosPage{Page: response.PageDescription{Number: 1, Size: len(values), TotalPages: 1}}
+	for i, value := range values {
+		page.Content = append(page.Content, &response.Utxo{
+			UtxoPointer: response.UtxoPointer{TransactionID: fmt.Sprintf("tx-%d", i), OutputIndex: uint32(i)},
+			Satoshis:    value,
+		})
+	}
+	return page
+}
+
+// recordingResponder decodes the draft transaction commands sent to the responder.
+func recordingResponder(t *testing.T, drafts *[]commands.DraftTransaction, responder httpmock.Responder) httpmock.Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		var cmd commands.DraftTransaction
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&cmd))
+		*drafts = append(*drafts, cmd)
+		return responder(req)
+	}
+}
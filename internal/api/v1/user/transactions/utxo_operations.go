@@ -0,0 +1,85 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/coinselect"
+	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v1/queryparams"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// ConsolidateUTXOs spends the UTXOs in batches, each batch merged by a separate transaction
+// into cmd.Outputs change outputs sent back to the user. It returns the transactions recorded
+// before the first failure, along with the error.
+func (a *API) ConsolidateUTXOs(ctx context.Context, cmd *commands.ConsolidateUTXOs, utxos []*response.Utxo) ([]*response.Transaction, error) {
+	outputs := cmd.Outputs
+	if outputs == 0 {
+		outputs = 1
+	}
+	maxSize := cmd.MaxSize
+	if maxSize == 0 {
+		maxSize = coinselect.DefaultMaxTransactionSize
+	}
+
+	batches, err := coinselect.Batches(utxos, outputs, maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]*response.Transaction, 0, len(batches))
+	for i, batch := range batches {
+		tx, err := a.sendToSelf(ctx, batch, outputs, cmd.Metadata)
+		if err != nil {
+			return txs, fmt.Errorf("failed to consolidate batch %d of %d: %w", i+1, len(batches), err)
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// SplitUTXOs spends the UTXO in a transaction with cmd.Outputs change outputs sent back to the user.
+// The SPV Wallet divides the value of the UTXO, reduced by the fee, equally between the outputs.
+func (a *API) SplitUTXOs(ctx context.Context, cmd *commands.SplitUTXOs, utxo *response.Utxo) (*response.Transaction, error) {
+	if cmd.Outputs < 2 {
+		return nil, fmt.Errorf("%w: UTXO must be split into at least 2 outputs, got %d", goclienterr.ErrInvalidUTXOOperation, cmd.Outputs)
+	}
+	if utxo.Satoshis < uint64(cmd.Outputs) {
+		return nil, fmt.Errorf("%w: UTXO of %d satoshis cannot be split into %d outputs", goclienterr.ErrInvalidUTXOOperation, utxo.Satoshis, cmd.Outputs)
+	}
+
+	return a.sendToSelf(ctx, []*response.Utxo{utxo}, cmd.Outputs, cmd.Metadata)
+}
+
+// sendToSelf drafts, signs and records the transaction spending exactly the given UTXOs
+// into the given number of change outputs.
+func (a *API) sendToSelf(ctx context.Context, utxos []*response.Utxo, outputs int, metadata queryparams.Metadata) (*response.Transaction, error) {
+	pointers := make([]*response.UtxoPointer, len(utxos))
+	for i, utxo := range utxos {
+		pointers[i] = &response.UtxoPointer{TransactionID: utxo.TransactionID, OutputIndex: utxo.OutputIndex}
+	}
+
+	draft, err := a.DraftTransaction(ctx, &commands.DraftTransaction{
+		Config: response.TransactionConfig{
+			FromUtxos:                  pointers,
+			ChangeNumberOfDestinations: outputs,
+		},
+		Metadata: metadata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create draft transaction: %w", err)
+	}
+
+	hex, err := a.finalize(ctx, draft)
+	if err != nil {
+		return nil, fmt.Errorf("failed to finalize transaction: %w", err)
+	}
+
+	return a.RecordTransaction(ctx, &commands.RecordTransaction{
+		Metadata:    metadata,
+		Hex:         hex,
+		ReferenceID: draft.ID,
+	})
+}
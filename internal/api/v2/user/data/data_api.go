@@ -0,0 +1,41 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/v2/response"
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	route = "api/v2/data"
+	api   = "User Data API v2"
+)
+
+type API struct {
+	url        *url.URL
+	httpClient *resty.Client
+}
+
+func (a *API) Data(ctx context.Context, id string) (*response.Data, error) {
+	var result response.Data
+
+	_, err := a.httpClient.R().
+		SetContext(ctx).
+		SetResult(&result).
+		Get(a.url.JoinPath(id).String())
+	if err != nil {
+		return nil, fmt.Errorf("HTTP response failure: %w", err)
+	}
+
+	return &result, nil
+}
+
+func NewAPI(url *url.URL, httpClient *resty.Client) *API {
+	return &API{
+		url:        url.JoinPath(route),
+		httpClient: httpClient,
+	}
+}
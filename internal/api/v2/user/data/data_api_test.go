@@ -0,0 +1,57 @@
+package data_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v2/user/data/datatest"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/testutils"
+	"github.com/bitcoin-sv/spv-wallet-go-client/v2/response"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/require"
+)
+
+const dataURL = "/api/v2/data"
+
+func TestDataAPI_Data(t *testing.T) {
+	tests := map[string]struct {
+		responder        httpmock.Responder
+		expectedResponse *response.Data
+		expectedErr      error
+	}{
+		"HTTP GET /api/v2/data/{id} response: 200": {
+			expectedResponse: datatest.ExpectedData(),
+			responder:        testutils.NewJSONFileResponderWithStatusOK("datatest/get_data_200.json"),
+		},
+		"HTTP GET /api/v2/data/{id} response: 404": {
+			expectedErr: testutils.NewResourceNotFoundSPVError(),
+			responder:   httpmock.NewJsonResponderOrPanic(http.StatusNotFound, testutils.NewResourceNotFoundSPVError()),
+		},
+		"HTTP GET /api/v2/data/{id} response: 500": {
+			expectedErr: testutils.NewInternalServerSPVError(),
+			responder:   testutils.NewInternalServerSPVErrorResponder(),
+		},
+		"HTTP GET /api/v2/data/{id} str response: 500": {
+			expectedErr: errors.ErrUnrecognizedAPIResponse,
+			responder:   testutils.NewInternalServerSPVErrorStringResponder("unexpected internal server failure"),
+		},
+	}
+
+	url := testutils.FullAPIURL(t, dataURL, datatest.DataID)
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given:
+			wallet, transport := testutils.GivenSPVUserAPI(t)
+			transport.RegisterResponder(http.MethodGet, url, tc.responder)
+
+			// when:
+			got, err := wallet.V2().Data(context.Background(), datatest.DataID)
+
+			// then:
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedResponse, got)
+		})
+	}
+}
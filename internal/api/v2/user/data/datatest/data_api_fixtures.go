@@ -0,0 +1,12 @@
+package datatest
+
+import "github.com/bitcoin-sv/spv-wallet-go-client/v2/response"
+
+const DataID = "a3d8e0f5c1c2b6f8d2e7a9b1c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3-1"
+
+func ExpectedData() *response.Data {
+	return &response.Data{
+		ID:   DataID,
+		Blob: "68656c6c6f20776f726c64",
+	}
+}
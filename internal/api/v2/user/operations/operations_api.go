@@ -0,0 +1,62 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/v2/queries"
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	route = "api/v2/operations/search"
+	api   = "User Operations API v2"
+)
+
+type API struct {
+	url        *url.URL
+	httpClient *resty.Client
+}
+
+func (a *API) Operations(ctx context.Context, opts ...queries.OperationsQueryOption) (*queries.OperationsPage, error) {
+	query := queries.NewOperationsQuery(opts...)
+
+	var result queries.OperationsPage
+	_, err := a.httpClient.R().
+		SetContext(ctx).
+		SetResult(&result).
+		SetQueryParams(params(query)).
+		Get(a.url.String())
+	if err != nil {
+		return nil, fmt.Errorf("HTTP response failure: %w", err)
+	}
+
+	return &result, nil
+}
+
+func params(query *queries.OperationsQuery) map[string]string {
+	params := make(map[string]string)
+	page := query.PageFilter
+	if page.Number > 0 {
+		params["page"] = strconv.Itoa(page.Number)
+	}
+	if page.Size > 0 {
+		params["size"] = strconv.Itoa(page.Size)
+	}
+	if page.Sort != "" {
+		params["sort"] = page.Sort
+	}
+	if page.SortBy != "" {
+		params["sortBy"] = page.SortBy
+	}
+	return params
+}
+
+func NewAPI(url *url.URL, httpClient *resty.Client) *API {
+	return &API{
+		url:        url.JoinPath(route),
+		httpClient: httpClient,
+	}
+}
@@ -0,0 +1,67 @@
+package operations_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v2/user/operations/operationstest"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/testutils"
+	"github.com/bitcoin-sv/spv-wallet-go-client/v2/queries"
+	"github.com/bitcoin-sv/spv-wallet/models/filter"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/require"
+)
+
+const operationsURL = "/api/v2/operations/search"
+
+func TestOperationsAPI_Operations(t *testing.T) {
+	tests := map[string]struct {
+		responder        httpmock.Responder
+		expectedResponse *queries.OperationsPage
+		expectedErr      error
+	}{
+		"HTTP GET /api/v2/operations/search response: 200": {
+			expectedResponse: operationstest.ExpectedOperationsPage(t),
+			responder:        testutils.NewJSONFileResponderWithStatusOK("operationstest/get_operations_200.json"),
+		},
+		"HTTP GET /api/v2/operations/search response: 400": {
+			expectedErr: testutils.NewBadRequestSPVError(),
+			responder:   testutils.NewBadRequestSPVErrorResponder(),
+		},
+		"HTTP GET /api/v2/operations/search response: 500": {
+			expectedErr: testutils.NewInternalServerSPVError(),
+			responder:   testutils.NewInternalServerSPVErrorResponder(),
+		},
+		"HTTP GET /api/v2/operations/search str response: 500": {
+			expectedErr: errors.ErrUnrecognizedAPIResponse,
+			responder:   testutils.NewInternalServerSPVErrorStringResponder("unexpected internal server failure"),
+		},
+	}
+
+	url := testutils.FullAPIURL(t, operationsURL)
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given:
+			opts := []queries.OperationsQueryOption{
+				queries.OperationsQueryWithPageFilter(filter.Page{
+					Number: 1,
+					Size:   2,
+					Sort:   "desc",
+					SortBy: "createdAt",
+				}),
+			}
+			params := "page=1&size=2&sort=desc&sortBy=createdAt"
+			wallet, transport := testutils.GivenSPVUserAPI(t)
+			transport.RegisterResponderWithQuery(http.MethodGet, url, params, tc.responder)
+
+			// when:
+			got, err := wallet.V2().Operations(context.Background(), opts...)
+
+			// then:
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedResponse, got)
+		})
+	}
+}
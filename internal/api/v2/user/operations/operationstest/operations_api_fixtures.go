@@ -0,0 +1,39 @@
+package operationstest
+
+import (
+	"testing"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/testutils"
+	"github.com/bitcoin-sv/spv-wallet-go-client/v2/queries"
+	v2response "github.com/bitcoin-sv/spv-wallet-go-client/v2/response"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+func ExpectedOperationsPage(t *testing.T) *queries.OperationsPage {
+	return &queries.OperationsPage{
+		Content: []*v2response.Operation{
+			{
+				CreatedAt:    testutils.ParseTime(t, "2025-01-15T10:12:31.412357Z"),
+				Value:        1000,
+				TxID:         "a3d8e0f5c1c2b6f8d2e7a9b1c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3",
+				Type:         "incoming",
+				Counterparty: "alice@example.com",
+				TxStatus:     "MINED",
+			},
+			{
+				CreatedAt:    testutils.ParseTime(t, "2025-01-16T08:45:02.918274Z"),
+				Value:        -501,
+				TxID:         "f1e2d3c4b5a6978899aabbccddeeff00112233445566778899aabbccddeeff00",
+				Type:         "outgoing",
+				Counterparty: "bob@example.com",
+				TxStatus:     "BROADCASTED",
+			},
+		},
+		Page: response.PageDescription{
+			Size:          2,
+			Number:        1,
+			TotalElements: 2,
+			TotalPages:    1,
+		},
+	}
+}
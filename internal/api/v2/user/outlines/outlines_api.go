@@ -0,0 +1,59 @@
+package outlines
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/v2/commands"
+	"github.com/bitcoin-sv/spv-wallet-go-client/v2/response"
+	"github.com/bitcoin-sv/spv-wallet/models/transaction"
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	route = "api/v2/transactions"
+	api   = "User Transactions API v2"
+)
+
+type API struct {
+	url        *url.URL
+	httpClient *resty.Client
+}
+
+func (a *API) TransactionOutline(ctx context.Context, cmd *commands.TransactionOutline) (*transaction.AnnotatedTransaction, error) {
+	var result transaction.AnnotatedTransaction
+
+	_, err := a.httpClient.R().
+		SetContext(ctx).
+		SetResult(&result).
+		SetBody(cmd).
+		Post(a.url.JoinPath("outlines").String())
+	if err != nil {
+		return nil, fmt.Errorf("HTTP response failure: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (a *API) RecordTransaction(ctx context.Context, cmd *commands.RecordTransaction) (*response.RecordedTransaction, error) {
+	var result response.RecordedTransaction
+
+	_, err := a.httpClient.R().
+		SetContext(ctx).
+		SetResult(&result).
+		SetBody(cmd).
+		Post(a.url.String())
+	if err != nil {
+		return nil, fmt.Errorf("HTTP response failure: %w", err)
+	}
+
+	return &result, nil
+}
+
+func NewAPI(url *url.URL, httpClient *resty.Client) *API {
+	return &API{
+		url:        url.JoinPath(route),
+		httpClient: httpClient,
+	}
+}
@@ -0,0 +1,169 @@
+package outlines_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v2/user/outlines/outlinestest"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/testutils"
+	"github.com/bitcoin-sv/spv-wallet-go-client/v2/commands"
+	"github.com/bitcoin-sv/spv-wallet-go-client/v2/response"
+	"github.com/bitcoin-sv/spv-wallet/models/transaction"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	transactionsURL = "/api/v2/transactions"
+	outlinesURL     = "/api/v2/transactions/outlines"
+)
+
+func TestOutlinesAPI_TransactionOutline(t *testing.T) {
+	tests := map[string]struct {
+		responder        httpmock.Responder
+		expectedResponse *transaction.AnnotatedTransaction
+		expectedErr      error
+	}{
+		"HTTP POST /api/v2/transactions/outlines response: 200": {
+			expectedResponse: outlinestest.ExpectedTransactionOutline(),
+			responder:        testutils.NewJSONFileResponderWithStatusOK("outlinestest/post_transaction_outline_200.json"),
+		},
+		"HTTP POST /api/v2/transactions/outlines response: 400": {
+			expectedErr: testutils.NewBadRequestSPVError(),
+			responder:   testutils.NewBadRequestSPVErrorResponder(),
+		},
+		"HTTP POST /api/v2/transactions/outlines response: 500": {
+			expectedErr: testutils.NewInternalServerSPVError(),
+			responder:   testutils.NewInternalServerSPVErrorResponder(),
+		},
+		"HTTP POST /api/v2/transactions/outlines str response: 500": {
+			expectedErr: errors.ErrUnrecognizedAPIResponse,
+			responder:   testutils.NewInternalServerSPVErrorStringResponder("unexpected internal server failure"),
+		},
+	}
+
+	url := testutils.FullAPIURL(t, outlinesURL)
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given:
+			wallet, transport := testutils.GivenSPVUserAPI(t)
+			var body string
+			transport.RegisterResponder(http.MethodPost, url, func(req *http.Request) (*http.Response, error) {
+				b, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				body = string(b)
+				return tc.responder(req)
+			})
+
+			// when:
+			got, err := wallet.V2().TransactionOutline(context.Background(), outlinestest.TransactionOutlineCommand())
+
+			// then:
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedResponse, got)
+			require.JSONEq(t, `{"outputs": [
+				{"type": "paymail", "to": "bob@example.com", "satoshis": 1000, "from": "alice@example.com"},
+				{"type": "op_return", "dataType": "strings", "data": ["hello", "world"]}
+			]}`, body)
+		})
+	}
+}
+
+func TestOutlinesAPI_RecordTransaction(t *testing.T) {
+	tests := map[string]struct {
+		responder        httpmock.Responder
+		expectedResponse *response.RecordedTransaction
+		expectedErr      error
+	}{
+		"HTTP POST /api/v2/transactions response: 201": {
+			expectedResponse: outlinestest.ExpectedRecordedTransaction(),
+			responder:        testutils.NewJSONFileResponderWithStatusOK("outlinestest/post_transaction_record_201.json"),
+		},
+		"HTTP POST /api/v2/transactions response: 400": {
+			expectedErr: testutils.NewBadRequestSPVError(),
+			responder:   testutils.NewBadRequestSPVErrorResponder(),
+		},
+		"HTTP POST /api/v2/transactions response: 500": {
+			expectedErr: testutils.NewInternalServerSPVError(),
+			responder:   testutils.NewInternalServerSPVErrorResponder(),
+		},
+		"HTTP POST /api/v2/transactions str response: 500": {
+			expectedErr: errors.ErrUnrecognizedAPIResponse,
+			responder:   testutils.NewInternalServerSPVErrorStringResponder("unexpected internal server failure"),
+		},
+	}
+
+	url := testutils.FullAPIURL(t, transactionsURL)
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given:
+			wallet, transport := testutils.GivenSPVUserAPI(t)
+			var recorded commands.RecordTransaction
+			transport.RegisterResponder(http.MethodPost, url, func(req *http.Request) (*http.Response, error) {
+				require.NoError(t, json.NewDecoder(req.Body).Decode(&recorded))
+				return tc.responder(req)
+			})
+			cmd := outlinestest.ExpectedTransactionOutline()
+
+			// when:
+			got, err := wallet.V2().RecordTransaction(context.Background(), cmd)
+
+			// then:
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedResponse, got)
+			require.Equal(t, *cmd, recorded)
+		})
+	}
+}
+
+func TestOutlinesAPI_V2Unavailable(t *testing.T) {
+	// given:
+	wallet, transport := testutils.GivenSPVUserAPI(t)
+	testutils.GivenSharedConfig(t, transport, map[string]bool{"pikeContactsEnabled": true})
+	currentUserURL := testutils.FullAPIURL(t, "/api/v2/users/current")
+	transport.RegisterResponder(http.MethodGet, currentUserURL, httpmock.NewStringResponder(http.StatusNotFound, "404 page not found"))
+	url := testutils.FullAPIURL(t, outlinesURL)
+	transport.RegisterResponder(http.MethodPost, url, testutils.NewJSONFileResponderWithStatusOK("outlinestest/post_transaction_outline_200.json"))
+
+	// when:
+	got, err := wallet.V2().TransactionOutline(context.Background(), outlinestest.TransactionOutlineCommand())
+	_, again := wallet.V2().TransactionOutline(context.Background(), outlinestest.TransactionOutlineCommand())
+
+	// then:
+	require.ErrorIs(t, err, errors.ErrFeatureUnavailable)
+	require.ErrorIs(t, again, errors.ErrFeatureUnavailable)
+	require.Nil(t, got)
+	require.Zero(t, transport.GetCallCountInfo()[http.MethodPost+" "+url])
+	require.Equal(t, 1, transport.GetCallCountInfo()[http.MethodGet+" "+currentUserURL])
+
+	// when:
+	transport.RegisterResponder(http.MethodGet, currentUserURL, testutils.NewJSONBodyResponderWithStatusOK(map[string]any{}))
+	require.NoError(t, wallet.LoadCapabilities(context.Background()))
+	got, err = wallet.V2().TransactionOutline(context.Background(), outlinestest.TransactionOutlineCommand())
+
+	// then:
+	require.NoError(t, err)
+	require.Equal(t, outlinestest.ExpectedTransactionOutline(), got)
+}
+
+func TestOutlinesAPI_V2FeatureCheckOff(t *testing.T) {
+	// given:
+	wallet, transport := testutils.GivenSPVUserAPI(t)
+	currentUserURL := testutils.FullAPIURL(t, "/api/v2/users/current")
+	transport.RegisterResponder(http.MethodGet, currentUserURL, httpmock.NewStringResponder(http.StatusNotFound, "404 page not found"))
+	url := testutils.FullAPIURL(t, outlinesURL)
+	transport.RegisterResponder(http.MethodPost, url, testutils.NewJSONFileResponderWithStatusOK("outlinestest/post_transaction_outline_200.json"))
+	wallet.V2().SetFeatureCheck(false)
+
+	// when:
+	got, err := wallet.V2().TransactionOutline(context.Background(), outlinestest.TransactionOutlineCommand())
+
+	// then:
+	require.NoError(t, err)
+	require.Equal(t, outlinestest.ExpectedTransactionOutline(), got)
+	require.Zero(t, transport.GetCallCountInfo()[http.MethodGet+" "+currentUserURL])
+}
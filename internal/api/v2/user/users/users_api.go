@@ -0,0 +1,58 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/v2/response"
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	route = "api/v2/users"
+	api   = "User Users API v2"
+)
+
+type API struct {
+	url        *url.URL
+	httpClient *resty.Client
+}
+
+func (a *API) CurrentUser(ctx context.Context) (*response.User, error) {
+	var result response.User
+
+	_, err := a.httpClient.R().
+		SetContext(ctx).
+		SetResult(&result).
+		Get(a.url.JoinPath("current").String())
+	if err != nil {
+		return nil, fmt.Errorf("HTTP response failure: %w", err)
+	}
+
+	return &result, nil
+}
+
+// Available reports whether the server provides the v2 users API: it requests the current user
+// and reports false if the server responds with 404 Not Found.
+func (a *API) Available(ctx context.Context) (bool, error) {
+	res, err := a.httpClient.R().
+		SetContext(ctx).
+		Get(a.url.JoinPath("current").String())
+	if res != nil && res.StatusCode() == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("HTTP response failure: %w", err)
+	}
+
+	return true, nil
+}
+
+func NewAPI(url *url.URL, httpClient *resty.Client) *API {
+	return &API{
+		url:        url.JoinPath(route),
+		httpClient: httpClient,
+	}
+}
@@ -0,0 +1,57 @@
+package users_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v2/user/users/userstest"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/testutils"
+	"github.com/bitcoin-sv/spv-wallet-go-client/v2/response"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/require"
+)
+
+const currentUserURL = "/api/v2/users/current"
+
+func TestUsersAPI_CurrentUser(t *testing.T) {
+	tests := map[string]struct {
+		responder        httpmock.Responder
+		expectedResponse *response.User
+		expectedErr      error
+	}{
+		"HTTP GET /api/v2/users/current response: 200": {
+			expectedResponse: userstest.ExpectedCurrentUser(t),
+			responder:        testutils.NewJSONFileResponderWithStatusOK("userstest/get_current_user_200.json"),
+		},
+		"HTTP GET /api/v2/users/current response: 401": {
+			expectedErr: testutils.NewUnauthorizedAccessSPVError(),
+			responder:   httpmock.NewJsonResponderOrPanic(http.StatusUnauthorized, testutils.NewUnauthorizedAccessSPVError()),
+		},
+		"HTTP GET /api/v2/users/current response: 500": {
+			expectedErr: testutils.NewInternalServerSPVError(),
+			responder:   testutils.NewInternalServerSPVErrorResponder(),
+		},
+		"HTTP GET /api/v2/users/current str response: 500": {
+			expectedErr: errors.ErrUnrecognizedAPIResponse,
+			responder:   testutils.NewInternalServerSPVErrorStringResponder("unexpected internal server failure"),
+		},
+	}
+
+	url := testutils.FullAPIURL(t, currentUserURL)
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given:
+			wallet, transport := testutils.GivenSPVUserAPI(t)
+			transport.RegisterResponder(http.MethodGet, url, tc.responder)
+
+			// when:
+			got, err := wallet.V2().CurrentUser(context.Background())
+
+			// then:
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedResponse, got)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package userstest
+
+import (
+	"testing"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/testutils"
+	"github.com/bitcoin-sv/spv-wallet-go-client/v2/response"
+)
+
+func ExpectedCurrentUser(t *testing.T) *response.User {
+	return &response.User{
+		ID:        "1Dhd4Yq3FbzqSRiSBN46DR9YXDbhLm5FxH",
+		CreatedAt: testutils.ParseTime(t, "2025-01-10T09:00:00.123456Z"),
+		UpdatedAt: testutils.ParseTime(t, "2025-01-16T08:45:02.918274Z"),
+		PubKey:    "034252e5359a1de3b8ec08e6c29b80594e88fb47e6ae9ce65ee5a94f0d371d2cde",
+		Paymails: []*response.Paymail{
+			{
+				ID:         1,
+				Alias:      "alice",
+				Domain:     "example.com",
+				Paymail:    "alice@example.com",
+				PublicName: "Alice",
+				Avatar:     "https://example.com/avatars/alice.png",
+			},
+		},
+		CurrentBalance: 499,
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"math"
 	"strconv"
 
+	base58 "github.com/bitcoin-sv/go-sdk/compat/base58"
 	bip32 "github.com/bitcoin-sv/go-sdk/compat/bip32"
 	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
 	goclienterrors "github.com/bitcoin-sv/spv-wallet-go-client/errors"
@@ -20,6 +21,12 @@ const (
 	ChainExternal = uint32(0)
 )
 
+const (
+	wifMainnetID        = 0x80
+	wifTestnetID        = 0xef
+	wifCompressedLength = 1 + 32 + 1 + 4 // network ID, key, compression flag and checksum
+)
+
 func Hash(s string) string {
 	bb := sha256.Sum256([]byte(s))
 	return hex.EncodeToString(bb[:])
@@ -99,10 +106,28 @@ func Int64ToUint32(value int64) (uint32, error) {
 	return uint32(value), nil
 }
 
-func PrivateKeyFromHexOrWIF(s string) (*ec.PrivateKey, error) {
-	pk, err1 := ec.PrivateKeyFromWif(s)
+// PrivateKey is a private key with the serialization of its public key and its network.
+type PrivateKey struct {
+	*ec.PrivateKey
+	CompressPubKey bool // True if the public key is serialized in the compressed form.
+	Mainnet        bool // True if the key belongs to the mainnet.
+}
+
+// PubKeyBytes returns the public key serialized in the form the key was imported with.
+func (k *PrivateKey) PubKeyBytes() []byte {
+	if k.CompressPubKey {
+		return k.PubKey().Compressed()
+	}
+	return k.PubKey().Uncompressed()
+}
+
+// PrivateKeyFromHexOrWIF decodes the WIF or hex encoded private key. A WIF key carries
+// the serialization of its public key and its network; a hex key is a compressed key
+// of the network indicated by mainnet.
+func PrivateKeyFromHexOrWIF(s string, mainnet bool) (*PrivateKey, error) {
+	key, err1 := privateKeyFromWIF(s)
 	if err1 == nil {
-		return pk, nil
+		return key, nil
 	}
 
 	pk, err2 := ec.PrivateKeyFromHex(s)
@@ -110,5 +135,28 @@ func PrivateKeyFromHexOrWIF(s string) (*ec.PrivateKey, error) {
 		return nil, errors.Join(err1, err2)
 	}
 
-	return pk, nil
+	return &PrivateKey{PrivateKey: pk, CompressPubKey: true, Mainnet: mainnet}, nil
+}
+
+func privateKeyFromWIF(s string) (*PrivateKey, error) {
+	pk, err := ec.PrivateKeyFromWif(s)
+	if err != nil {
+		return nil, err
+	}
+
+	// the length and the checksum are already verified by ec.PrivateKeyFromWif
+	decoded, err := base58.Decode(s)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &PrivateKey{PrivateKey: pk, CompressPubKey: len(decoded) == wifCompressedLength}
+	switch decoded[0] {
+	case wifMainnetID:
+		key.Mainnet = true
+	case wifTestnetID:
+	default:
+		return nil, fmt.Errorf("unknown WIF network ID: %#x", decoded[0])
+	}
+	return key, nil
 }
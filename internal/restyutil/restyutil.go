@@ -2,6 +2,7 @@ package restyutil
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/bitcoin-sv/spv-wallet-go-client/config"
 	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
@@ -13,9 +14,16 @@ type Authenticator interface {
 	Authenticate(r *resty.Request) error
 }
 
-func NewHTTPClient(cfg config.Config, auth Authenticator) *resty.Client {
-	return resty.New().
-		SetTransport(cfg.Transport).
+// NewHTTPClient creates the resty client of the SPV Wallet API with the transport, timeout, authentication,
+// error handling and retry policy of the configuration. It fails if the TLS or proxy settings can't be applied.
+func NewHTTPClient(cfg config.Config, auth Authenticator) (*resty.Client, error) {
+	transport, err := cfg.HTTPTransport()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure HTTP transport: %w", err)
+	}
+
+	client := resty.New().
+		SetTransport(transport).
 		SetBaseURL(cfg.Addr).
 		SetTimeout(cfg.Timeout).
 		OnBeforeRequest(func(_ *resty.Client, r *resty.Request) error {
@@ -33,4 +41,42 @@ func NewHTTPClient(cfg config.Config, auth Authenticator) *resty.Client {
 
 			return fmt.Errorf("%w: %s", goclienterr.ErrUnrecognizedAPIResponse, r.Body())
 		})
+
+	if cfg.Retry != nil && cfg.Retry.Count > 0 {
+		client.
+			SetRetryCount(cfg.Retry.Count).
+			AddRetryCondition(shouldRetry)
+		if cfg.Retry.WaitTime > 0 {
+			client.SetRetryWaitTime(cfg.Retry.WaitTime)
+		}
+		if cfg.Retry.MaxWaitTime > 0 {
+			client.SetRetryMaxWaitTime(cfg.Retry.MaxWaitTime)
+		}
+	}
+
+	return client, nil
+}
+
+// shouldRetry reports whether a failed request can be safely repeated. Non-idempotent
+// requests (e.g., recording a transaction) are never retried to avoid duplicated side effects.
+func shouldRetry(r *resty.Response, err error) bool {
+	if r == nil || r.Request == nil || !isIdempotent(r.Request.Method) {
+		return false
+	}
+
+	if r.RawResponse == nil {
+		return err != nil
+	}
+
+	code := r.StatusCode()
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
 }
@@ -1,9 +1,14 @@
 package restyutil_test
 
 import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/bitcoin-sv/spv-wallet-go-client/config"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
 	"github.com/bitcoin-sv/spv-wallet-go-client/internal/restyutil"
 	"github.com/bitcoin-sv/spv-wallet-go-client/internal/testutils"
 	"github.com/bitcoin-sv/spv-wallet/models"
@@ -63,6 +68,99 @@ func TestNewHTTPClient_OnAfterResponse(t *testing.T) {
 	}
 }
 
+// TestNewHTTPClient_Retry tests the retry policy of NewHTTPClient
+func TestNewHTTPClient_Retry(t *testing.T) {
+	tests := map[string]struct {
+		method            string
+		responder         httpmock.Responder
+		expectedCallCount int
+	}{
+		"GET request with 500 response is retried": {
+			method:            http.MethodGet,
+			responder:         testutils.NewInternalServerSPVErrorResponder(),
+			expectedCallCount: 3,
+		},
+		"GET request with 400 response is not retried": {
+			method:            http.MethodGet,
+			responder:         testutils.NewBadRequestSPVErrorResponder(),
+			expectedCallCount: 1,
+		},
+		"POST request with 500 response is not retried": {
+			method:            http.MethodPost,
+			responder:         testutils.NewInternalServerSPVErrorResponder(),
+			expectedCallCount: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given:
+			transport := httpmock.NewMockTransport()
+			transport.RegisterResponder(tc.method, "http://mock-api/test", tc.responder)
+			cfg := config.Config{
+				Addr:      "http://mock-api",
+				Timeout:   5 * time.Second,
+				Transport: transport,
+				Retry:     &config.RetryConfig{Count: 2, WaitTime: time.Millisecond, MaxWaitTime: time.Millisecond},
+			}
+			client, err := restyutil.NewHTTPClient(cfg, &mockAuthenticator{})
+			require.NoError(t, err)
+
+			// when:
+			_, err = client.R().Execute(tc.method, "/test")
+
+			// then:
+			require.Error(t, err)
+			require.Equal(t, tc.expectedCallCount, transport.GetTotalCallCount())
+		})
+	}
+}
+
+// TestNewHTTPClient_Transport tests that NewHTTPClient applies the TLS and proxy settings of a configuration created as a struct literal
+func TestNewHTTPClient_Transport(t *testing.T) {
+	t.Run("requests are sent through the proxy", func(t *testing.T) {
+		// given:
+		var proxied string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied = r.URL.String()
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(proxy.Close)
+		cfg := config.Config{
+			Addr:      "http://mock-api",
+			Timeout:   5 * time.Second,
+			Transport: &http.Transport{},
+			ProxyURL:  proxy.URL,
+		}
+		client, err := restyutil.NewHTTPClient(cfg, &mockAuthenticator{})
+		require.NoError(t, err)
+
+		// when:
+		_, err = client.R().Get("/test")
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, "http://mock-api/test", proxied)
+	})
+
+	t.Run("fails when the TLS settings can't be applied to the transport", func(t *testing.T) {
+		// given:
+		cfg := config.Config{
+			Addr:      "http://mock-api",
+			Timeout:   5 * time.Second,
+			Transport: httpmock.NewMockTransport(),
+			TLS:       &config.TLSConfig{MinVersion: tls.VersionTLS12},
+		}
+
+		// when:
+		client, err := restyutil.NewHTTPClient(cfg, &mockAuthenticator{})
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrConfigValidationInvalidTransport)
+		require.Nil(t, client)
+	})
+}
+
 // setupMockHTTPClient initializes an HTTP client with a mock configuration and authenticator
 func setupMockHTTPClient(t *testing.T) *resty.Client {
 	cfg := config.Config{
@@ -70,7 +168,8 @@ func setupMockHTTPClient(t *testing.T) *resty.Client {
 		Timeout:   5,
 		Transport: httpmock.DefaultTransport,
 	}
-	client := restyutil.NewHTTPClient(cfg, &mockAuthenticator{})
+	client, err := restyutil.NewHTTPClient(cfg, &mockAuthenticator{})
+	require.NoError(t, err)
 	httpmock.ActivateNonDefault(client.GetClient())
 	t.Cleanup(httpmock.DeactivateAndReset)
 	return client
@@ -2,6 +2,7 @@ package testutils
 
 import (
 	"encoding/hex"
+	"net/http"
 	"net/url"
 	"path"
 	"testing"
@@ -10,6 +11,7 @@ import (
 	bip32 "github.com/bitcoin-sv/go-sdk/compat/bip32"
 	spvwallet "github.com/bitcoin-sv/spv-wallet-go-client"
 	"github.com/bitcoin-sv/spv-wallet-go-client/config"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
 	"github.com/jarcoal/httpmock"
 )
 
@@ -45,6 +47,13 @@ func GivenSPVUserAPI(t *testing.T) (*spvwallet.UserAPI, *httpmock.MockTransport)
 	return spv, transport
 }
 
+// GivenSharedConfig registers the shared configuration of the SPV Wallet API enabling the experimental features.
+func GivenSharedConfig(t *testing.T, transport *httpmock.MockTransport, features map[string]bool) {
+	t.Helper()
+	config := response.SharedConfig{PaymailDomains: []string{"example.com"}, ExperimentalFeatures: features}
+	transport.RegisterResponder(http.MethodGet, FullAPIURL(t, "/api/v1/configs/shared"), NewJSONBodyResponderWithStatusOK(config))
+}
+
 func GivenSPVAdminAPI(t *testing.T) (*spvwallet.AdminAPI, *httpmock.MockTransport) {
 	t.Helper()
 	transport := httpmock.NewMockTransport()
@@ -0,0 +1,186 @@
+// Package invoices issues payment requests and reconciles them with the incoming transactions of the SPV Wallet user.
+//
+// An Invoice carries the requested amount, an optional expiry and a unique reference. The transaction metadata
+// is kept per user by the SPV Wallet, so the metadata set by the payer never reaches the payee. Instead, the payer
+// sends the invoice reference as the note of the paymail P2P payment (see Invoice.PaymentNote), which the payee's
+// SPV Wallet stores in the incoming transaction metadata under "p2p_tx_metadata", and the Reconciler matches
+// the incoming transactions to the invoices by that note. Invoices are reconciled either by polling the user transactions (Reconciler.Reconcile)
+// or by handling the transaction events delivered by the notifications webhook (Reconciler.HandleTransactionEvent):
+//
+//	notifications.RegisterHandler(webhook, func(event *models.TransactionEvent) {
+//		if _, err := reconciler.HandleTransactionEvent(ctx, event); err != nil {
+//			log.Printf("failed to reconcile transaction %s: %v", event.TransactionID, err)
+//		}
+//	})
+package invoices
+
+import (
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v1/queryparams"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// Transaction metadata keys of the invoice data which the payer may attach to its own paying transaction.
+const (
+	// MetadataReferenceKey is the key of the invoice reference.
+	MetadataReferenceKey = "invoice_reference"
+
+	// MetadataSatoshisKey is the key of the invoice amount, informative for the payer.
+	MetadataSatoshisKey = "invoice_satoshis"
+
+	// MetadataExpiresAtKey is the key of the invoice expiry in RFC 3339 format, informative for the payer.
+	MetadataExpiresAtKey = "invoice_expires_at"
+)
+
+// Transaction statuses, directions and metadata keys taken into account by the reconciliation.
+const (
+	transactionDirectionIncoming = "incoming"
+	transactionStatusRejected    = "REJECTED"
+
+	// p2pMetadataKey is the key of the paymail P2P metadata sent by the payer, stored by the SPV Wallet of the payee.
+	p2pMetadataKey = "p2p_tx_metadata"
+	p2pNoteKey     = "note"
+)
+
+// Status represents the reconciliation status of an invoice.
+type Status string
+
+// Invoice statuses.
+const (
+	// StatusPending indicates that no payment of the invoice was received yet.
+	StatusPending Status = "pending"
+
+	// StatusPartiallyPaid indicates that the payments received so far don't cover the invoice amount.
+	StatusPartiallyPaid Status = "partially_paid"
+
+	// StatusPaid indicates that the payments received before the expiry cover the invoice amount.
+	StatusPaid Status = "paid"
+
+	// StatusExpired indicates that the invoice expired before it was paid in full.
+	StatusExpired Status = "expired"
+)
+
+// Final reports whether the invoice no longer awaits payments, so it is skipped by Reconciler.Reconcile.
+func (s Status) Final() bool {
+	return s == StatusPaid || s == StatusExpired
+}
+
+// Payment is an incoming transaction matched to an invoice.
+type Payment struct {
+	TransactionID string    `json:"transactionId"` // ID of the paying transaction.
+	Satoshis      uint64    `json:"satoshis"`      // Value received by the user in the transaction.
+	ReceivedAt    time.Time `json:"receivedAt"`    // Time when the transaction was recorded by the SPV Wallet.
+	Late          bool      `json:"late"`          // True if the payment was received after the invoice expired; late payments don't count towards the invoice amount.
+}
+
+// Invoice is a request for a payment of the given amount.
+type Invoice struct {
+	ID           string               `json:"id"`                 // Unique reference of the invoice, sent by the payer as the note of the paymail P2P payment.
+	Satoshis     uint64               `json:"satoshis"`           // Requested amount.
+	ExpiresAt    time.Time            `json:"expiresAt"`          // Time after which the invoice can no longer be paid; zero if the invoice never expires.
+	Metadata     queryparams.Metadata `json:"metadata"`           // Metadata of the invoice, e.g., the order ID; not embedded in the paying transactions.
+	CreatedAt    time.Time            `json:"createdAt"`          // Time when the invoice was issued.
+	Status       Status               `json:"status"`             // Reconciliation status of the invoice.
+	PaidSatoshis uint64               `json:"paidSatoshis"`       // Sum of the payments received before the expiry.
+	PaidAt       time.Time            `json:"paidAt"`             // Time of the payment which completed the invoice; zero until the invoice is paid.
+	Payments     []Payment            `json:"payments,omitempty"` // Payments matched to the invoice, including the late ones.
+	UpdatedAt    time.Time            `json:"updatedAt"`          // Time of the last reconciliation which changed the invoice.
+}
+
+// PaymentNote returns the note which the payer should send in the metadata of the paymail P2P payment,
+// so that the Reconciler of the payee matches the payment to the invoice.
+func (i *Invoice) PaymentNote() string {
+	return i.ID
+}
+
+// PaymentMetadata returns the transaction metadata which the payer may attach to the paying transaction
+// for its own records. It isn't delivered to the payee, so it isn't used to match the payment; see PaymentNote.
+func (i *Invoice) PaymentMetadata() queryparams.Metadata {
+	metadata := queryparams.Metadata{
+		MetadataReferenceKey: i.ID,
+		MetadataSatoshisKey:  strconv.FormatUint(i.Satoshis, 10),
+	}
+	if !i.ExpiresAt.IsZero() {
+		metadata[MetadataExpiresAtKey] = i.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	return metadata
+}
+
+// Outstanding returns the amount which remains to be paid.
+func (i *Invoice) Outstanding() uint64 {
+	if i.PaidSatoshis >= i.Satoshis {
+		return 0
+	}
+	return i.Satoshis - i.PaidSatoshis
+}
+
+// Expired reports whether the invoice expiry passed at the given time.
+func (i *Invoice) Expired(now time.Time) bool {
+	return !i.ExpiresAt.IsZero() && now.After(i.ExpiresAt)
+}
+
+// apply matches the transaction to the invoice and reports whether it is a new payment of the invoice.
+// Outgoing, rejected and already matched transactions are ignored.
+func (i *Invoice) apply(tx *response.Transaction) bool {
+	if tx.TransactionDirection != transactionDirectionIncoming || tx.Status == transactionStatusRejected || tx.OutputValue <= 0 {
+		return false
+	}
+	if slices.ContainsFunc(i.Payments, func(p Payment) bool { return p.TransactionID == tx.ID }) {
+		return false
+	}
+
+	payment := Payment{
+		TransactionID: tx.ID,
+		Satoshis:      uint64(tx.OutputValue),
+		ReceivedAt:    tx.CreatedAt,
+		Late:          i.Expired(tx.CreatedAt),
+	}
+	i.Payments = append(i.Payments, payment)
+	if payment.Late {
+		return true
+	}
+
+	i.PaidSatoshis += payment.Satoshis
+	if i.PaidAt.IsZero() && i.PaidSatoshis >= i.Satoshis {
+		i.PaidAt = payment.ReceivedAt
+	}
+	return true
+}
+
+// refresh updates the status of the invoice at the given time and reports whether it changed.
+// A payment received before the expiry completes the invoice even if it was matched after the invoice expired.
+func (i *Invoice) refresh(now time.Time) bool {
+	var status Status
+	switch {
+	case i.PaidSatoshis >= i.Satoshis:
+		status = StatusPaid
+	case i.Expired(now):
+		status = StatusExpired
+	case i.PaidSatoshis > 0:
+		status = StatusPartiallyPaid
+	default:
+		status = StatusPending
+	}
+
+	changed := status != i.Status
+	i.Status = status
+	return changed
+}
+
+// referenceOf returns the invoice reference sent as the note of the paymail P2P payment, if any.
+func referenceOf(tx *response.Transaction) (string, bool) {
+	p2p, ok := tx.Metadata[p2pMetadataKey].(map[string]any)
+	if !ok {
+		return "", false
+	}
+	ref, ok := p2p[p2pNoteKey].(string)
+	return ref, ok && ref != ""
+}
+
+// referenceFilter returns the metadata filter of the transactions paying the invoice with the given reference.
+func referenceFilter(ref string) map[string]any {
+	return map[string]any{p2pMetadataKey: map[string]any{p2pNoteKey: ref}}
+}
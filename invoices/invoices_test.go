@@ -0,0 +1,309 @@
+package invoices_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v1/queryparams"
+	"github.com/bitcoin-sv/spv-wallet-go-client/invoices"
+	"github.com/bitcoin-sv/spv-wallet-go-client/queries"
+	"github.com/bitcoin-sv/spv-wallet/models"
+	"github.com/bitcoin-sv/spv-wallet/models/filter"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+	"github.com/stretchr/testify/require"
+)
+
+var issuedAt = time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	tests := map[string]struct {
+		txs          []*response.Transaction
+		now          time.Time
+		status       invoices.Status
+		paidSatoshis uint64
+		payments     int
+	}{
+		"pending invoice without payments": {
+			now:    issuedAt.Add(time.Minute),
+			status: invoices.StatusPending,
+		},
+		"partially paid invoice": {
+			txs:          []*response.Transaction{incomingTx("tx-1", 400, issuedAt.Add(time.Minute))},
+			now:          issuedAt.Add(2 * time.Minute),
+			status:       invoices.StatusPartiallyPaid,
+			paidSatoshis: 400,
+			payments:     1,
+		},
+		"invoice paid in two transactions": {
+			txs: []*response.Transaction{
+				incomingTx("tx-1", 400, issuedAt.Add(time.Minute)),
+				incomingTx("tx-2", 700, issuedAt.Add(2*time.Minute)),
+			},
+			now:          issuedAt.Add(3 * time.Minute),
+			status:       invoices.StatusPaid,
+			paidSatoshis: 1100,
+			payments:     2,
+		},
+		"expired invoice with a late payment": {
+			txs: []*response.Transaction{
+				incomingTx("tx-1", 400, issuedAt.Add(time.Minute)),
+				incomingTx("tx-2", 600, issuedAt.Add(2*time.Hour)),
+			},
+			now:          issuedAt.Add(3 * time.Hour),
+			status:       invoices.StatusExpired,
+			paidSatoshis: 400,
+			payments:     2,
+		},
+		"outgoing and rejected transactions are ignored": {
+			txs: []*response.Transaction{
+				{ID: "tx-1", OutputValue: -1000, TransactionDirection: "outgoing", Model: response.Model{CreatedAt: issuedAt}},
+				{ID: "tx-2", OutputValue: 1000, TransactionDirection: "incoming", Status: "REJECTED", Model: response.Model{CreatedAt: issuedAt}},
+			},
+			now:    issuedAt.Add(time.Minute),
+			status: invoices.StatusPending,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given:
+			clock := &fakeClock{now: issuedAt}
+			source := &fakeSource{txs: tc.txs}
+			r := invoices.New(source, invoices.NewMemoryStore(), invoices.WithClock(clock.Now))
+			require.NoError(t, r.Issue(context.Background(), newInvoice()))
+			clock.now = tc.now
+
+			// when:
+			_, err := r.Reconcile(context.Background())
+
+			// then:
+			require.NoError(t, err)
+			invoice, err := r.Invoice(context.Background(), "order-1")
+			require.NoError(t, err)
+			require.Equal(t, tc.status, invoice.Status)
+			require.Equal(t, tc.paidSatoshis, invoice.PaidSatoshis)
+			require.Len(t, invoice.Payments, tc.payments)
+			require.Equal(t, map[string]any{"p2p_tx_metadata": map[string]any{"note": "order-1"}}, source.metadata)
+		})
+	}
+
+	t.Run("Reconcile doesn't count the same transaction twice", func(t *testing.T) {
+		// given:
+		clock := &fakeClock{now: issuedAt}
+		source := &fakeSource{txs: []*response.Transaction{incomingTx("tx-1", 400, issuedAt)}}
+		r := invoices.New(source, invoices.NewMemoryStore(), invoices.WithClock(clock.Now))
+		require.NoError(t, r.Issue(context.Background(), newInvoice()))
+
+		// when:
+		changed, err := r.Reconcile(context.Background())
+
+		// then:
+		require.NoError(t, err)
+		require.Len(t, changed, 1)
+
+		// when:
+		changed, err = r.Reconcile(context.Background())
+
+		// then:
+		require.NoError(t, err)
+		require.Empty(t, changed)
+		invoice, err := r.Invoice(context.Background(), "order-1")
+		require.NoError(t, err)
+		require.Equal(t, uint64(400), invoice.PaidSatoshis)
+	})
+
+	t.Run("Reconcile returns the source error", func(t *testing.T) {
+		// given:
+		source := &fakeSource{err: errors.New("unavailable")}
+		r := invoices.New(source, invoices.NewMemoryStore(), invoices.WithClock((&fakeClock{now: issuedAt}).Now))
+		require.NoError(t, r.Issue(context.Background(), newInvoice()))
+
+		// when:
+		changed, err := r.Reconcile(context.Background())
+
+		// then:
+		require.ErrorIs(t, err, source.err)
+		require.Empty(t, changed)
+	})
+}
+
+func TestReconciler_HandleTransactionEvent(t *testing.T) {
+	t.Run("HandleTransactionEvent marks the invoice paid", func(t *testing.T) {
+		// given:
+		tx := incomingTx("tx-1", 1000, issuedAt.Add(time.Minute))
+		r := invoices.New(&fakeSource{txs: []*response.Transaction{tx}}, invoices.NewMemoryStore(), invoices.WithClock((&fakeClock{now: issuedAt}).Now))
+		require.NoError(t, r.Issue(context.Background(), newInvoice()))
+
+		// when:
+		invoice, err := r.HandleTransactionEvent(context.Background(), &models.TransactionEvent{TransactionID: "tx-1"})
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, invoices.StatusPaid, invoice.Status)
+		require.Equal(t, tx.CreatedAt, invoice.PaidAt)
+		require.Zero(t, invoice.Outstanding())
+
+		// when:
+		invoice, err = r.HandleTransactionEvent(context.Background(), &models.TransactionEvent{TransactionID: "tx-1"})
+
+		// then:
+		require.NoError(t, err)
+		require.Nil(t, invoice)
+	})
+
+	t.Run("HandleTransactionEvent ignores the invoice reference outside of the P2P metadata", func(t *testing.T) {
+		// given:
+		tx := incomingTx("tx-1", 1000, issuedAt.Add(time.Minute))
+		tx.Metadata = map[string]any{invoices.MetadataReferenceKey: "order-1"}
+		r := invoices.New(&fakeSource{txs: []*response.Transaction{tx}}, invoices.NewMemoryStore(), invoices.WithClock((&fakeClock{now: issuedAt}).Now))
+		require.NoError(t, r.Issue(context.Background(), newInvoice()))
+
+		// when:
+		invoice, err := r.HandleTransactionEvent(context.Background(), &models.TransactionEvent{TransactionID: "tx-1"})
+
+		// then:
+		require.NoError(t, err)
+		require.Nil(t, invoice)
+	})
+
+	t.Run("HandleTransactionEvent ignores transactions without the invoice reference", func(t *testing.T) {
+		// given:
+		tx := &response.Transaction{ID: "tx-1", OutputValue: 1000, TransactionDirection: "incoming"}
+		r := invoices.New(&fakeSource{txs: []*response.Transaction{tx}}, invoices.NewMemoryStore())
+
+		// when:
+		invoice, err := r.HandleTransactionEvent(context.Background(), &models.TransactionEvent{TransactionID: "tx-1"})
+
+		// then:
+		require.NoError(t, err)
+		require.Nil(t, invoice)
+	})
+}
+
+func TestReconciler_Issue(t *testing.T) {
+	tests := map[string]*invoices.Invoice{
+		"without ID":           {Satoshis: 1000},
+		"without amount":       {ID: "order-1"},
+		"expiring in the past": {ID: "order-1", Satoshis: 1000, ExpiresAt: issuedAt.Add(-time.Minute)},
+	}
+	for name, invoice := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given:
+			r := invoices.New(&fakeSource{}, invoices.NewMemoryStore(), invoices.WithClock((&fakeClock{now: issuedAt}).Now))
+
+			// when:
+			err := r.Issue(context.Background(), invoice)
+
+			// then:
+			require.ErrorIs(t, err, goclienterr.ErrInvalidInvoice)
+		})
+	}
+
+	t.Run("Issue of a duplicate invoice", func(t *testing.T) {
+		// given:
+		r := invoices.New(&fakeSource{}, invoices.NewMemoryStore(), invoices.WithClock((&fakeClock{now: issuedAt}).Now))
+		require.NoError(t, r.Issue(context.Background(), newInvoice()))
+
+		// when:
+		err := r.Issue(context.Background(), newInvoice())
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrInvalidInvoice)
+	})
+
+	t.Run("Invoice not found", func(t *testing.T) {
+		// given:
+		r := invoices.New(&fakeSource{}, invoices.NewMemoryStore())
+
+		// when:
+		invoice, err := r.Invoice(context.Background(), "order-1")
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrInvoiceNotFound)
+		require.Nil(t, invoice)
+	})
+}
+
+func TestInvoice_PaymentNote(t *testing.T) {
+	// when:
+	note := newInvoice().PaymentNote()
+
+	// then:
+	require.Equal(t, "order-1", note)
+}
+
+func TestInvoice_PaymentMetadata(t *testing.T) {
+	// when:
+	metadata := newInvoice().PaymentMetadata()
+
+	// then:
+	require.Equal(t, queryparams.Metadata{
+		invoices.MetadataReferenceKey: "order-1",
+		invoices.MetadataSatoshisKey:  "1000",
+		invoices.MetadataExpiresAtKey: "2025-01-01T13:00:00Z",
+	}, metadata)
+}
+
+func newInvoice() *invoices.Invoice {
+	return &invoices.Invoice{ID: "order-1", Satoshis: 1000, ExpiresAt: issuedAt.Add(time.Hour)}
+}
+
+// incomingTx returns an incoming paymail P2P transaction paying the invoice, with the metadata set by the SPV Wallet of the payee.
+func incomingTx(id string, satoshis int64, createdAt time.Time) *response.Transaction {
+	return &response.Transaction{
+		Model: response.Model{
+			CreatedAt: createdAt,
+			Metadata: map[string]any{
+				"domain":          "example.com",
+				"ip_address":      "127.0.0.1",
+				"user_agent":      "node-fetch",
+				"paymail_request": "HandleReceivedP2pTransaction",
+				"reference_id":    "1c2dcc61-f48f-44f2-aba2-9a759a514d49",
+				"p2p_tx_metadata": map[string]any{
+					"note":   "order-1",
+					"pubkey": "3fa7af5b-4568-4873-86da-0aa442ca91dd",
+					"sender": "payer@example.com",
+				},
+			},
+		},
+		ID:                   id,
+		OutputValue:          satoshis,
+		Status:               "SEEN_ON_NETWORK",
+		TransactionDirection: "incoming",
+	}
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+// fakeSource returns all transactions in a single page and records the metadata filter of the last query.
+type fakeSource struct {
+	txs      []*response.Transaction
+	metadata map[string]any
+	err      error
+}
+
+func (f *fakeSource) Transactions(_ context.Context, opts ...queries.QueryOption[filter.TransactionFilter]) (*queries.TransactionPage, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.metadata = queries.NewQuery(opts...).Metadata
+	return &queries.TransactionPage{
+		Content: f.txs,
+		Page:    response.PageDescription{Number: 1, TotalPages: 1, TotalElements: len(f.txs), Size: len(f.txs)},
+	}, nil
+}
+
+func (f *fakeSource) Transaction(_ context.Context, id string) (*response.Transaction, error) {
+	for _, tx := range f.txs {
+		if tx.ID == id {
+			return tx, nil
+		}
+	}
+	return nil, errors.New("transaction not found")
+}
@@ -0,0 +1,60 @@
+package invoices
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// MemoryStore is an in-memory implementation of the Store interface.
+// It is safe for concurrent use, but invoices are lost when the process exits.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	invoices map[string]Invoice
+}
+
+// NewMemoryStore creates a new, empty MemoryStore instance.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{invoices: make(map[string]Invoice)}
+}
+
+// Invoice returns a copy of the invoice with the given ID, or nil if there is no such invoice.
+func (m *MemoryStore) Invoice(_ context.Context, id string) (*Invoice, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	invoice, ok := m.invoices[id]
+	if !ok {
+		return nil, nil
+	}
+	return cloneInvoice(invoice), nil
+}
+
+// Invoices returns copies of all stored invoices, ordered by ID.
+func (m *MemoryStore) Invoices(_ context.Context) ([]*Invoice, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	invoices := make([]*Invoice, 0, len(m.invoices))
+	for _, invoice := range m.invoices {
+		invoices = append(invoices, cloneInvoice(invoice))
+	}
+	slices.SortFunc(invoices, func(a, b *Invoice) int { return strings.Compare(a.ID, b.ID) })
+	return invoices, nil
+}
+
+// SaveInvoice stores a copy of the invoice, replacing any previous invoice with the same ID.
+func (m *MemoryStore) SaveInvoice(_ context.Context, invoice *Invoice) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.invoices[invoice.ID] = *cloneInvoice(*invoice)
+	return nil
+}
+
+// cloneInvoice copies the invoice with its payments, so that the stored invoices aren't modified through the returned ones.
+func cloneInvoice(invoice Invoice) *Invoice {
+	invoice.Payments = slices.Clone(invoice.Payments)
+	return &invoice
+}
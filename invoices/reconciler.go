@@ -0,0 +1,222 @@
+package invoices
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/queries"
+	"github.com/bitcoin-sv/spv-wallet/models"
+	"github.com/bitcoin-sv/spv-wallet/models/filter"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// DefaultPageSize is the default size of the transaction pages fetched by Reconciler.Reconcile.
+const DefaultPageSize = 50
+
+// TransactionSource provides the transactions of the user. It is implemented by the *spvwallet.UserAPI.
+type TransactionSource interface {
+	Transactions(ctx context.Context, opts ...queries.QueryOption[filter.TransactionFilter]) (*queries.TransactionPage, error)
+	Transaction(ctx context.Context, ID string) (*response.Transaction, error)
+}
+
+// Store persists the invoices.
+// Implementations must be safe for concurrent use if the webhook events are handled concurrently.
+type Store interface {
+	// Invoice returns the invoice with the given ID, or nil if there is no such invoice.
+	Invoice(ctx context.Context, id string) (*Invoice, error)
+	// Invoices returns all stored invoices.
+	Invoices(ctx context.Context) ([]*Invoice, error)
+	// SaveInvoice creates or replaces the invoice with the same ID.
+	SaveInvoice(ctx context.Context, invoice *Invoice) error
+}
+
+// Reconciler issues invoices and matches them with the incoming transactions.
+type Reconciler struct {
+	source   TransactionSource
+	store    Store
+	now      func() time.Time
+	pageSize int
+}
+
+// Option configures the Reconciler.
+type Option func(*Reconciler)
+
+// WithClock sets the function returning the current time; time.Now by default.
+func WithClock(now func() time.Time) Option {
+	return func(r *Reconciler) {
+		r.now = now
+	}
+}
+
+// WithPageSize sets the size of the transaction pages fetched by Reconcile.
+func WithPageSize(size int) Option {
+	return func(r *Reconciler) {
+		if size > 0 {
+			r.pageSize = size
+		}
+	}
+}
+
+// New creates a new Reconciler matching the transactions of the source with the invoices of the store.
+func New(source TransactionSource, store Store, opts ...Option) *Reconciler {
+	r := &Reconciler{
+		source:   source,
+		store:    store,
+		now:      time.Now,
+		pageSize: DefaultPageSize,
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Issue validates the invoice and saves it as pending. The invoice ID must be unique.
+func (r *Reconciler) Issue(ctx context.Context, invoice *Invoice) error {
+	now := r.now()
+	switch {
+	case invoice.ID == "":
+		return fmt.Errorf("%w: invoice ID is required", goclienterr.ErrInvalidInvoice)
+	case invoice.Satoshis == 0:
+		return fmt.Errorf("%w: invoice %s has no amount", goclienterr.ErrInvalidInvoice, invoice.ID)
+	case invoice.Expired(now):
+		return fmt.Errorf("%w: invoice %s expires in the past", goclienterr.ErrInvalidInvoice, invoice.ID)
+	}
+
+	existing, err := r.load(ctx, invoice.ID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("%w: invoice %s already exists", goclienterr.ErrInvalidInvoice, invoice.ID)
+	}
+
+	invoice.CreatedAt = now
+	invoice.UpdatedAt = now
+	invoice.Status = StatusPending
+	invoice.PaidSatoshis = 0
+	invoice.PaidAt = time.Time{}
+	invoice.Payments = nil
+	return r.save(ctx, invoice)
+}
+
+// Invoice returns the invoice with the given ID.
+func (r *Reconciler) Invoice(ctx context.Context, id string) (*Invoice, error) {
+	invoice, err := r.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice == nil {
+		return nil, fmt.Errorf("%w: %s", goclienterr.ErrInvoiceNotFound, id)
+	}
+	return invoice, nil
+}
+
+// Reconcile matches the transactions carrying the references of the pending and partially paid invoices,
+// marks the invoices which expired in the meantime and returns the invoices changed by the reconciliation.
+func (r *Reconciler) Reconcile(ctx context.Context) ([]*Invoice, error) {
+	invoices, err := r.store.Invoices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrInvoiceStore, err)
+	}
+
+	var changed []*Invoice
+	for _, invoice := range invoices {
+		if invoice.Status.Final() {
+			continue
+		}
+
+		txs, err := r.transactionsOf(ctx, invoice.ID)
+		if err != nil {
+			return changed, err
+		}
+		updated := false
+		for _, tx := range txs {
+			updated = invoice.apply(tx) || updated
+		}
+		updated = invoice.refresh(r.now()) || updated
+		if !updated {
+			continue
+		}
+
+		if err := r.update(ctx, invoice); err != nil {
+			return changed, err
+		}
+		changed = append(changed, invoice)
+	}
+	return changed, nil
+}
+
+// HandleTransaction matches a single transaction with the invoice referenced in its P2P metadata.
+// It returns the updated invoice, or nil if the transaction doesn't pay any known invoice
+// or was already matched.
+func (r *Reconciler) HandleTransaction(ctx context.Context, tx *response.Transaction) (*Invoice, error) {
+	ref, ok := referenceOf(tx)
+	if !ok {
+		return nil, nil
+	}
+	invoice, err := r.load(ctx, ref)
+	if err != nil || invoice == nil {
+		return nil, err
+	}
+
+	if !invoice.apply(tx) {
+		return nil, nil
+	}
+	invoice.refresh(r.now())
+	if err := r.update(ctx, invoice); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// HandleTransactionEvent fetches the transaction of the webhook event and matches it with the invoice
+// referenced in its P2P metadata, as HandleTransaction does. Events carry no metadata, hence the extra request.
+func (r *Reconciler) HandleTransactionEvent(ctx context.Context, event *models.TransactionEvent) (*Invoice, error) {
+	tx, err := r.source.Transaction(ctx, event.TransactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transaction %s: %w", event.TransactionID, err)
+	}
+	return r.HandleTransaction(ctx, tx)
+}
+
+// transactionsOf fetches all transactions with the invoice reference in their P2P metadata.
+func (r *Reconciler) transactionsOf(ctx context.Context, ref string) ([]*response.Transaction, error) {
+	var txs []*response.Transaction
+	for number := 1; ; number++ {
+		page, err := r.source.Transactions(ctx,
+			queries.QueryWithMetadataFilter[filter.TransactionFilter](referenceFilter(ref)),
+			queries.QueryWithPageFilter[filter.TransactionFilter](filter.Page{Number: number, Size: r.pageSize}),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch transactions page %d of invoice %s: %w", number, ref, err)
+		}
+
+		txs = append(txs, page.Content...)
+		if len(page.Content) < r.pageSize || number >= page.Page.TotalPages {
+			return txs, nil
+		}
+	}
+}
+
+func (r *Reconciler) update(ctx context.Context, invoice *Invoice) error {
+	invoice.UpdatedAt = r.now()
+	return r.save(ctx, invoice)
+}
+
+func (r *Reconciler) load(ctx context.Context, id string) (*Invoice, error) {
+	invoice, err := r.store.Invoice(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrInvoiceStore, err)
+	}
+	return invoice, nil
+}
+
+func (r *Reconciler) save(ctx context.Context, invoice *Invoice) error {
+	if err := r.store.SaveInvoice(ctx, invoice); err != nil {
+		return fmt.Errorf("%w: %w", goclienterr.ErrInvoiceStore, err)
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+// Package ledger provides a local cache of the UTXOs and transactions of the user, synced incrementally with the SPV Wallet.
+//
+// The Cache serves the balance, the UTXOs and the transaction history from a Store, so that the reads don't require
+// a round trip to the SPV Wallet API and keep working while it is unreachable. Sync fetches only the records updated
+// since the previous sync, using the UpdatedRange of the filter.ModelFilter, and applies them to the Store atomically:
+//
+//	cache := ledger.New(userAPI, ledger.NewMemoryStore())
+//	if _, err := cache.Sync(ctx); err != nil {
+//		log.Printf("offline, showing the data synced at %s", cache.State(ctx).SyncedAt)
+//	}
+//	balance, err := cache.Balance(ctx)
+//
+// The MemoryStore keeps the ledger in memory; a persistent Store can be plugged in to keep it between restarts.
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/queries"
+	"github.com/bitcoin-sv/spv-wallet/models/filter"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// DefaultPageSize is the default size of the UTXO and transaction pages fetched by Sync.
+const DefaultPageSize = 100
+
+// Source provides the UTXOs and the transactions of the user. It is implemented by the *spvwallet.UserAPI.
+type Source interface {
+	UTXOs(ctx context.Context, opts ...queries.QueryOption[filter.UtxoFilter]) (*queries.UtxosPage, error)
+	Transactions(ctx context.Context, opts ...queries.QueryOption[filter.TransactionFilter]) (*queries.TransactionPage, error)
+}
+
+// Cursor is the position of the incremental sync of a single kind of records.
+type Cursor struct {
+	UpdatedAt time.Time // Latest update time of the synced records, as reported by the SPV Wallet.
+	Keys      []string  // Keys of the records updated exactly at UpdatedAt, skipped by the next sync.
+}
+
+// SyncState is the progress of the incremental sync.
+type SyncState struct {
+	Transactions Cursor    // Cursor of the transactions, keyed by their IDs.
+	UTXOs        Cursor    // Cursor of the UTXOs, keyed by "<transaction ID>:<output index>".
+	SyncedAt     time.Time // Local time of the last successful sync; zero if the ledger was never synced.
+}
+
+// Changes are the records fetched by a single sync, applied to the Store at once.
+type Changes struct {
+	Transactions        []*response.Transaction // Created or updated transactions.
+	DeletedTransactions []string                // IDs of the deleted transactions.
+	UTXOs               []*response.Utxo        // Created or updated UTXOs.
+	DeletedUTXOs        []response.UtxoPointer  // Pointers of the deleted UTXOs.
+	State               SyncState               // Sync state after the changes.
+}
+
+// Store persists the ledger.
+// Implementations must be safe for concurrent use, as the reads aren't serialized with Sync.
+type Store interface {
+	// State returns the sync state; the zero value if the ledger was never synced.
+	State(ctx context.Context) (SyncState, error)
+	// Apply upserts and deletes the changed records and saves the new sync state.
+	// It must apply all changes or none of them, so that the reads never observe a partial sync.
+	Apply(ctx context.Context, changes *Changes) error
+	// Transactions returns all transactions, ordered by creation time.
+	Transactions(ctx context.Context) ([]*response.Transaction, error)
+	// Transaction returns the transaction with the given ID, or nil if there is no such transaction.
+	Transaction(ctx context.Context, id string) (*response.Transaction, error)
+	// UTXOs returns all UTXOs, spent and unspent, ordered by creation time.
+	UTXOs(ctx context.Context) ([]*response.Utxo, error)
+}
+
+// Cache is a local ledger of the UTXOs and transactions of the user.
+type Cache struct {
+	source   Source
+	store    Store
+	now      func() time.Time
+	pageSize int
+	syncMu   sync.Mutex
+}
+
+// Option configures the Cache.
+type Option func(*Cache)
+
+// WithClock sets the function returning the current time; time.Now by default.
+func WithClock(now func() time.Time) Option {
+	return func(c *Cache) {
+		c.now = now
+	}
+}
+
+// WithPageSize sets the size of the UTXO and transaction pages fetched by Sync.
+func WithPageSize(size int) Option {
+	return func(c *Cache) {
+		if size > 0 {
+			c.pageSize = size
+		}
+	}
+}
+
+// New creates a new Cache of the source records, kept in the store.
+func New(source Source, store Store, opts ...Option) *Cache {
+	c := &Cache{
+		source:   source,
+		store:    store,
+		now:      time.Now,
+		pageSize: DefaultPageSize,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// SyncResult counts the records changed by Sync.
+type SyncResult struct {
+	Transactions        int
+	DeletedTransactions int
+	UTXOs               int
+	DeletedUTXOs        int
+}
+
+// Sync fetches the transactions and UTXOs updated since the previous sync, including the deleted ones,
+// and applies them to the store. The first sync fetches all records. Concurrent calls are serialized.
+// If any page cannot be fetched, nothing is applied and the store keeps serving the previous state.
+func (c *Cache) Sync(ctx context.Context) (*SyncResult, error) {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+
+	state, err := c.store.State(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrLedgerStore, err)
+	}
+	changes := &Changes{State: state}
+
+	changes.State.Transactions, err = syncPages(ctx, c.pageSize, state.Transactions,
+		func(ctx context.Context, modelFilter filter.ModelFilter, page filter.Page) ([]*response.Transaction, error) {
+			res, err := c.source.Transactions(ctx,
+				queries.QueryWithFilter(filter.TransactionFilter{ModelFilter: modelFilter}),
+				queries.QueryWithPageFilter[filter.TransactionFilter](page),
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch transactions page %d: %w", page.Number, err)
+			}
+			return res.Content, nil
+		},
+		func(tx *response.Transaction) (string, response.Model) { return tx.ID, tx.Model },
+		func(tx *response.Transaction, deleted bool) {
+			if deleted {
+				changes.DeletedTransactions = append(changes.DeletedTransactions, tx.ID)
+			} else {
+				changes.Transactions = append(changes.Transactions, tx)
+			}
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	changes.State.UTXOs, err = syncPages(ctx, c.pageSize, state.UTXOs,
+		func(ctx context.Context, modelFilter filter.ModelFilter, page filter.Page) ([]*response.Utxo, error) {
+			res, err := c.source.UTXOs(ctx,
+				queries.QueryWithFilter(filter.UtxoFilter{ModelFilter: modelFilter}),
+				queries.QueryWithPageFilter[filter.UtxoFilter](page),
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch UTXOs page %d: %w", page.Number, err)
+			}
+			return res.Content, nil
+		},
+		func(utxo *response.Utxo) (string, response.Model) { return utxoKey(utxo.UtxoPointer), utxo.Model },
+		func(utxo *response.Utxo, deleted bool) {
+			if deleted {
+				changes.DeletedUTXOs = append(changes.DeletedUTXOs, utxo.UtxoPointer)
+			} else {
+				changes.UTXOs = append(changes.UTXOs, utxo)
+			}
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	changes.State.SyncedAt = c.now()
+	if err := c.store.Apply(ctx, changes); err != nil {
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrLedgerStore, err)
+	}
+	return &SyncResult{
+		Transactions:        len(changes.Transactions),
+		DeletedTransactions: len(changes.DeletedTransactions),
+		UTXOs:               len(changes.UTXOs),
+		DeletedUTXOs:        len(changes.DeletedUTXOs),
+	}, nil
+}
+
+// syncPages fetches the records updated since the cursor, oldest update first, and returns the new cursor.
+// The pages are keyed by the update time rather than by the page number, so that the records updated
+// while paging don't shift the pages and get skipped. The records sharing the update time of the cursor
+// are deduplicated by their key; a page full of them is followed by the next page of the same cursor.
+func syncPages[T any](
+	ctx context.Context,
+	pageSize int,
+	cursor Cursor,
+	fetch func(ctx context.Context, modelFilter filter.ModelFilter, page filter.Page) ([]T, error),
+	key func(T) (string, response.Model),
+	apply func(record T, deleted bool),
+) (Cursor, error) {
+	includeDeleted := true
+	updatedAt := cursor.UpdatedAt
+	seen := make(map[string]struct{}, len(cursor.Keys))
+	for _, k := range cursor.Keys {
+		seen[k] = struct{}{}
+	}
+
+	for number := 1; ; {
+		modelFilter := filter.ModelFilter{IncludeDeleted: &includeDeleted}
+		if !updatedAt.IsZero() {
+			from := updatedAt
+			modelFilter.UpdatedRange = &filter.TimeRange{From: &from}
+		}
+		records, err := fetch(ctx, modelFilter, filter.Page{Number: number, Size: pageSize, SortBy: "updated_at", Sort: "asc"})
+		if err != nil {
+			return Cursor{}, err
+		}
+
+		progressed := false
+		for _, record := range records {
+			k, model := key(record)
+			recordUpdatedAt := updateTime(model)
+			if recordUpdatedAt.Before(updatedAt) {
+				continue
+			}
+			if _, ok := seen[k]; ok && recordUpdatedAt.Equal(updatedAt) {
+				continue
+			}
+			apply(record, model.DeletedAt != nil)
+
+			if recordUpdatedAt.After(updatedAt) {
+				updatedAt = recordUpdatedAt
+				clear(seen)
+				progressed = true
+			}
+			seen[k] = struct{}{}
+		}
+
+		if len(records) < pageSize {
+			keys := make([]string, 0, len(seen))
+			for k := range seen {
+				keys = append(keys, k)
+			}
+			slices.Sort(keys)
+			return Cursor{UpdatedAt: updatedAt, Keys: keys}, nil
+		}
+		if progressed {
+			number = 1
+		} else {
+			number++
+		}
+	}
+}
+
+func updateTime(model response.Model) time.Time {
+	updatedAt := model.UpdatedAt
+	if model.DeletedAt != nil && model.DeletedAt.After(updatedAt) {
+		updatedAt = *model.DeletedAt
+	}
+	if updatedAt.IsZero() {
+		return model.CreatedAt
+	}
+	return updatedAt
+}
+
+// State returns the sync state of the ledger. It returns the zero value if the state cannot be read.
+func (c *Cache) State(ctx context.Context) SyncState {
+	state, err := c.store.State(ctx)
+	if err != nil {
+		return SyncState{}
+	}
+	return state
+}
+
+// Transactions returns all cached transactions, ordered by creation time.
+func (c *Cache) Transactions(ctx context.Context) ([]*response.Transaction, error) {
+	txs, err := c.store.Transactions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrLedgerStore, err)
+	}
+	return txs, nil
+}
+
+// Transaction returns the cached transaction with the given ID.
+// It returns goclienterr.ErrLedgerRecordNotFound if the transaction isn't cached.
+func (c *Cache) Transaction(ctx context.Context, ID string) (*response.Transaction, error) {
+	tx, err := c.store.Transaction(ctx, ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrLedgerStore, err)
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("%w: transaction %s", goclienterr.ErrLedgerRecordNotFound, ID)
+	}
+	return tx, nil
+}
+
+// UTXOs returns all cached UTXOs, spent and unspent, ordered by creation time.
+func (c *Cache) UTXOs(ctx context.Context) ([]*response.Utxo, error) {
+	utxos, err := c.store.UTXOs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrLedgerStore, err)
+	}
+	return utxos, nil
+}
+
+// UnspentUTXOs returns the cached UTXOs which aren't spent, including the ones reserved by draft transactions.
+func (c *Cache) UnspentUTXOs(ctx context.Context) ([]*response.Utxo, error) {
+	utxos, err := c.UTXOs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	unspent := utxos[:0]
+	for _, utxo := range utxos {
+		if utxo.SpendingTxID == "" {
+			unspent = append(unspent, utxo)
+		}
+	}
+	return unspent, nil
+}
+
+// Balance returns the sum of the cached unspent UTXOs in satoshis.
+func (c *Cache) Balance(ctx context.Context) (uint64, error) {
+	unspent, err := c.UnspentUTXOs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var balance uint64
+	for _, utxo := range unspent {
+		balance += utxo.Satoshis
+	}
+	return balance, nil
+}
@@ -0,0 +1,237 @@
+package ledger_test
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/ledger"
+	"github.com/bitcoin-sv/spv-wallet-go-client/queries"
+	"github.com/bitcoin-sv/spv-wallet/models/filter"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	t0  = time.Date(2024, 11, 1, 10, 0, 0, 0, time.UTC)
+	now = time.Date(2024, 11, 20, 12, 0, 0, 0, time.UTC)
+)
+
+func TestCache_Sync(t *testing.T) {
+	t.Run("Sync of the whole ledger", func(t *testing.T) {
+		// given:
+		source := givenSource()
+		cache := ledger.New(source, ledger.NewMemoryStore(), ledger.WithPageSize(2), ledger.WithClock(func() time.Time { return now }))
+
+		// when:
+		res, err := cache.Sync(context.Background())
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, &ledger.SyncResult{Transactions: 2, UTXOs: 3}, res)
+		require.Equal(t, ledger.SyncState{
+			Transactions: ledger.Cursor{UpdatedAt: t0.Add(time.Hour), Keys: []string{"tx-out"}},
+			UTXOs:        ledger.Cursor{UpdatedAt: t0.Add(time.Hour), Keys: []string{"tx-in:0", "tx-out:1"}},
+			SyncedAt:     now,
+		}, cache.State(context.Background()))
+		require.Nil(t, source.txFilters[0].UpdatedRange)
+		require.True(t, *source.txFilters[0].IncludeDeleted)
+
+		balance, err := cache.Balance(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(1199), balance)
+
+		txs, err := cache.Transactions(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []string{"tx-in", "tx-out"}, transactionIDs(txs))
+	})
+
+	t.Run("Sync of the changes since the previous sync", func(t *testing.T) {
+		// given:
+		source := givenSource()
+		cache := ledger.New(source, ledger.NewMemoryStore())
+		_, err := cache.Sync(context.Background())
+		require.NoError(t, err)
+
+		later := t0.Add(2 * time.Hour)
+		source.utxos[2].SpendingTxID = "tx-out-2"
+		source.utxos[2].UpdatedAt = later
+		source.transactions = append(source.transactions, givenTransaction("tx-out-2", t0.Add(2*time.Hour), -200))
+		source.transactions[0].UpdatedAt = later
+		source.transactions[0].DeletedAt = &later
+		source.txFilters = nil
+
+		// when:
+		res, err := cache.Sync(context.Background())
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, &ledger.SyncResult{Transactions: 1, DeletedTransactions: 1, UTXOs: 1}, res)
+		require.Equal(t, t0.Add(time.Hour), *source.txFilters[0].UpdatedRange.From)
+
+		balance, err := cache.Balance(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(500), balance)
+
+		txs, err := cache.Transactions(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []string{"tx-out", "tx-out-2"}, transactionIDs(txs))
+	})
+
+	t.Run("Sync of more records updated at once than fit a page", func(t *testing.T) {
+		// given:
+		source := &fakeSource{}
+		for i := range 5 {
+			source.transactions = append(source.transactions, givenTransaction(fmt.Sprintf("tx-%d", i), t0, 1))
+		}
+		cache := ledger.New(source, ledger.NewMemoryStore(), ledger.WithPageSize(2))
+
+		// when:
+		res, err := cache.Sync(context.Background())
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, 5, res.Transactions)
+		require.Equal(t, []int{1, 1, 2, 3}, source.txPages)
+	})
+
+	t.Run("Sync while the SPV Wallet is unreachable", func(t *testing.T) {
+		// given:
+		source := givenSource()
+		cache := ledger.New(source, ledger.NewMemoryStore(), ledger.WithClock(func() time.Time { return now }))
+		_, err := cache.Sync(context.Background())
+		require.NoError(t, err)
+
+		source.transactions = append(source.transactions, givenTransaction("tx-new", t0.Add(3*time.Hour), 1))
+		source.err = errors.New("connection refused")
+
+		// when:
+		res, err := cache.Sync(context.Background())
+
+		// then:
+		require.ErrorIs(t, err, source.err)
+		require.Nil(t, res)
+		require.Equal(t, now, cache.State(context.Background()).SyncedAt)
+
+		txs, err := cache.Transactions(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []string{"tx-in", "tx-out"}, transactionIDs(txs))
+		balance, err := cache.Balance(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, uint64(1199), balance)
+	})
+}
+
+func TestCache_Transaction(t *testing.T) {
+	// given:
+	cache := ledger.New(givenSource(), ledger.NewMemoryStore())
+	_, err := cache.Sync(context.Background())
+	require.NoError(t, err)
+
+	t.Run("Transaction from the ledger", func(t *testing.T) {
+		// when:
+		tx, err := cache.Transaction(context.Background(), "tx-out")
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, int64(-301), tx.OutputValue)
+	})
+
+	t.Run("Transaction missing from the ledger", func(t *testing.T) {
+		// when:
+		tx, err := cache.Transaction(context.Background(), "tx-unknown")
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrLedgerRecordNotFound)
+		require.Nil(t, tx)
+	})
+}
+
+func givenSource() *fakeSource {
+	return &fakeSource{
+		transactions: []*response.Transaction{
+			givenTransaction("tx-in", t0, 1500),
+			givenTransaction("tx-out", t0.Add(time.Hour), -301),
+		},
+		utxos: []*response.Utxo{
+			givenUTXO("tx-in", 0, 1000, "tx-out", t0, t0.Add(time.Hour)),
+			givenUTXO("tx-in", 1, 500, "", t0, t0),
+			givenUTXO("tx-out", 1, 699, "", t0.Add(time.Hour), t0.Add(time.Hour)),
+		},
+	}
+}
+
+func givenTransaction(id string, at time.Time, value int64) *response.Transaction {
+	return &response.Transaction{Model: response.Model{CreatedAt: at, UpdatedAt: at}, ID: id, OutputValue: value}
+}
+
+func givenUTXO(txID string, index uint32, satoshis uint64, spendingTxID string, createdAt, updatedAt time.Time) *response.Utxo {
+	return &response.Utxo{
+		Model:        response.Model{CreatedAt: createdAt, UpdatedAt: updatedAt},
+		UtxoPointer:  response.UtxoPointer{TransactionID: txID, OutputIndex: index},
+		Satoshis:     satoshis,
+		SpendingTxID: spendingTxID,
+	}
+}
+
+func transactionIDs(txs []*response.Transaction) []string {
+	ids := make([]string, len(txs))
+	for i, tx := range txs {
+		ids[i] = tx.ID
+	}
+	return ids
+}
+
+// fakeSource serves the records like the SPV Wallet API: filtered by the update time and sorted by it.
+type fakeSource struct {
+	transactions []*response.Transaction
+	utxos        []*response.Utxo
+	err          error
+	txFilters    []filter.ModelFilter
+	txPages      []int
+}
+
+func (f *fakeSource) Transactions(_ context.Context, opts ...queries.QueryOption[filter.TransactionFilter]) (*queries.TransactionPage, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	query := queries.NewQuery(opts...)
+	f.txFilters = append(f.txFilters, query.Filter.ModelFilter)
+	f.txPages = append(f.txPages, query.PageFilter.Number)
+
+	content := page(f.transactions, query.Filter.ModelFilter, query.PageFilter, func(tx *response.Transaction) response.Model { return tx.Model })
+	return &queries.TransactionPage{Content: content}, nil
+}
+
+func (f *fakeSource) UTXOs(_ context.Context, opts ...queries.QueryOption[filter.UtxoFilter]) (*queries.UtxosPage, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	query := queries.NewQuery(opts...)
+
+	content := page(f.utxos, query.Filter.ModelFilter, query.PageFilter, func(utxo *response.Utxo) response.Model { return utxo.Model })
+	return &queries.UtxosPage{Content: content}, nil
+}
+
+func page[T any](records []T, modelFilter filter.ModelFilter, pageFilter filter.Page, model func(T) response.Model) []T {
+	var matching []T
+	for _, record := range records {
+		m := model(record)
+		if m.DeletedAt != nil && (modelFilter.IncludeDeleted == nil || !*modelFilter.IncludeDeleted) {
+			continue
+		}
+		if r := modelFilter.UpdatedRange; r != nil && r.From != nil && m.UpdatedAt.Before(*r.From) {
+			continue
+		}
+		matching = append(matching, record)
+	}
+	slices.SortStableFunc(matching, func(a, b T) int { return cmp.Compare(model(a).UpdatedAt.UnixNano(), model(b).UpdatedAt.UnixNano()) })
+
+	start := min((pageFilter.Number-1)*pageFilter.Size, len(matching))
+	return matching[start:min(start+pageFilter.Size, len(matching))]
+}
@@ -0,0 +1,121 @@
+package ledger
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// MemoryStore is an in-memory implementation of the Store interface.
+// It is safe for concurrent use, but the ledger is lost when the process exits.
+type MemoryStore struct {
+	mu           sync.RWMutex
+	state        SyncState
+	transactions map[string]response.Transaction
+	utxos        map[response.UtxoPointer]response.Utxo
+}
+
+// NewMemoryStore creates a new, empty MemoryStore instance.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		transactions: make(map[string]response.Transaction),
+		utxos:        make(map[response.UtxoPointer]response.Utxo),
+	}
+}
+
+// State returns the sync state.
+func (m *MemoryStore) State(_ context.Context) (SyncState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.state, nil
+}
+
+// Apply stores copies of the changed records, removes the deleted ones and saves the sync state under a single lock.
+func (m *MemoryStore) Apply(_ context.Context, changes *Changes) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, tx := range changes.Transactions {
+		m.transactions[tx.ID] = *cloneTransaction(*tx)
+	}
+	for _, id := range changes.DeletedTransactions {
+		delete(m.transactions, id)
+	}
+	for _, utxo := range changes.UTXOs {
+		m.utxos[utxo.UtxoPointer] = *cloneUTXO(*utxo)
+	}
+	for _, pointer := range changes.DeletedUTXOs {
+		delete(m.utxos, pointer)
+	}
+	m.state = changes.State
+	return nil
+}
+
+// Transactions returns copies of all stored transactions, ordered by creation time.
+func (m *MemoryStore) Transactions(_ context.Context) ([]*response.Transaction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	txs := make([]*response.Transaction, 0, len(m.transactions))
+	for _, tx := range m.transactions {
+		txs = append(txs, cloneTransaction(tx))
+	}
+	slices.SortFunc(txs, func(a, b *response.Transaction) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return txs, nil
+}
+
+// Transaction returns a copy of the transaction with the given ID, or nil if there is no such transaction.
+func (m *MemoryStore) Transaction(_ context.Context, id string) (*response.Transaction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tx, ok := m.transactions[id]
+	if !ok {
+		return nil, nil
+	}
+	return cloneTransaction(tx), nil
+}
+
+// UTXOs returns copies of all stored UTXOs, ordered by creation time.
+func (m *MemoryStore) UTXOs(_ context.Context) ([]*response.Utxo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	utxos := make([]*response.Utxo, 0, len(m.utxos))
+	for _, utxo := range m.utxos {
+		utxos = append(utxos, cloneUTXO(utxo))
+	}
+	slices.SortFunc(utxos, func(a, b *response.Utxo) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(utxoKey(a.UtxoPointer), utxoKey(b.UtxoPointer)))
+	})
+	return utxos, nil
+}
+
+// cloneTransaction copies the transaction with its maps and slices, so that the stored transactions
+// aren't modified through the returned ones.
+func cloneTransaction(tx response.Transaction) *response.Transaction {
+	tx.Metadata = maps.Clone(tx.Metadata)
+	tx.Outputs = maps.Clone(tx.Outputs)
+	tx.XpubInIDs = slices.Clone(tx.XpubInIDs)
+	tx.XpubOutIDs = slices.Clone(tx.XpubOutIDs)
+	return &tx
+}
+
+// cloneUTXO copies the UTXO with its metadata. The transaction embedded by the SPV Wallet API isn't stored.
+func cloneUTXO(utxo response.Utxo) *response.Utxo {
+	utxo.Metadata = maps.Clone(utxo.Metadata)
+	utxo.Transaction = nil
+	return &utxo
+}
+
+func utxoKey(pointer response.UtxoPointer) string {
+	return fmt.Sprintf("%s:%d", pointer.TransactionID, pointer.OutputIndex)
+}
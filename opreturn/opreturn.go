@@ -0,0 +1,152 @@
+// Package opreturn builds and parses the data outputs (OP_FALSE OP_RETURN scripts) of common Bitcoin data protocols.
+//
+// The Builder produces the response.OpReturn of a commands.Recipients or a response.TransactionOutput
+// from a sequence of raw pushes and protocol records: B:// files, MAP key/value records and AIP signatures.
+// Consecutive protocol records are separated by the "|" push, as defined by the Bitcom convention:
+//
+//	opReturn, err := opreturn.NewBuilder().
+//		B(opreturn.BFile{Data: []byte("# Hello"), MediaType: "text/markdown", Encoding: "UTF-8"}).
+//		MAP(opreturn.MAPSet(opreturn.KeyValue{Key: "app", Value: "myapp"}, opreturn.KeyValue{Key: "type", Value: "post"})).
+//		AIP(signer).
+//		OpReturn()
+//
+// Parse and ParseTransaction decode such outputs back, verifying the AIP signatures.
+package opreturn
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/bitcoin-sv/go-sdk/script"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// Protocol prefixes and the separator of the protocol records.
+const (
+	BPrefix   = "19HxigV4QyBv3tHpQVcUEQyq1pzZVdoAut"
+	MAPPrefix = "1PuQa7K62MiKCtssSLKy1kh56WWU7MtUR5"
+	AIPPrefix = "15PciHG22SNLQJXMoSUaWVi7WSqc7hCfva"
+	Separator = "|"
+)
+
+// Builder builds the pushes of a data output. The first error stops the building and is returned by the final call.
+type Builder struct {
+	pushes [][]byte
+	err    error
+}
+
+// NewBuilder creates a new, empty Builder.
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Push appends raw data pushes.
+func (b *Builder) Push(data ...[]byte) *Builder {
+	b.pushes = append(b.pushes, data...)
+	return b
+}
+
+// PushString appends text pushes.
+func (b *Builder) PushString(parts ...string) *Builder {
+	for _, part := range parts {
+		b.pushes = append(b.pushes, []byte(part))
+	}
+	return b
+}
+
+// B appends a B:// file record.
+func (b *Builder) B(file BFile) *Builder {
+	if b.err == nil {
+		b.record(file.pushes())
+	}
+	return b
+}
+
+// MAP appends a MAP record.
+func (b *Builder) MAP(m MAP) *Builder {
+	if b.err != nil {
+		return b
+	}
+	pushes, err := m.pushes()
+	if err != nil {
+		b.err = err
+		return b
+	}
+	b.record(pushes)
+	return b
+}
+
+// AIP appends an AIP record signing all pushes appended so far.
+func (b *Builder) AIP(signer Signer) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if len(b.pushes) == 0 {
+		b.err = fmt.Errorf("%w: AIP requires signed data", goclienterr.ErrInvalidOpReturn)
+		return b
+	}
+
+	sig, err := signAIP(signer, b.pushes)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	b.record(sig.pushes())
+	return b
+}
+
+// Pushes returns the built data pushes, excluding the OP_FALSE OP_RETURN prefix.
+func (b *Builder) Pushes() ([][]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.pushes) == 0 {
+		return nil, fmt.Errorf("%w: no data", goclienterr.ErrInvalidOpReturn)
+	}
+	return b.pushes, nil
+}
+
+// Script returns the OP_FALSE OP_RETURN locking script of the built data.
+func (b *Builder) Script() (*script.Script, error) {
+	pushes, err := b.Pushes()
+	if err != nil {
+		return nil, err
+	}
+	return dataScript(pushes)
+}
+
+// OpReturn returns the built data as the OP_RETURN of a transaction output, one hex part per push.
+func (b *Builder) OpReturn() (*response.OpReturn, error) {
+	pushes, err := b.Pushes()
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]string, len(pushes))
+	for i, push := range pushes {
+		parts[i] = hex.EncodeToString(push)
+	}
+	return &response.OpReturn{HexParts: parts}, nil
+}
+
+// record appends the pushes of a protocol record, separated from the previous data.
+func (b *Builder) record(pushes [][]byte) {
+	if len(b.pushes) > 0 {
+		b.pushes = append(b.pushes, []byte(Separator))
+	}
+	b.pushes = append(b.pushes, pushes...)
+}
+
+func dataScript(pushes [][]byte) (*script.Script, error) {
+	s := &script.Script{}
+	if err := s.AppendOpcodes(script.OpFALSE, script.OpRETURN); err != nil {
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrInvalidOpReturn, err)
+	}
+	for _, push := range pushes {
+		if err := s.AppendPushData(push); err != nil {
+			return nil, fmt.Errorf("%w: %w", goclienterr.ErrInvalidOpReturn, err)
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,190 @@
+package opreturn_test
+
+import (
+	"testing"
+
+	"github.com/bitcoin-sv/go-sdk/script"
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/opreturn"
+	"github.com/bitcoin-sv/spv-wallet-go-client/walletkeys"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	file = opreturn.BFile{Data: []byte("# Hello"), MediaType: "text/markdown", Encoding: "UTF-8", Filename: "hello.md"}
+	post = opreturn.MAPSet(opreturn.KeyValue{Key: "app", Value: "myapp"}, opreturn.KeyValue{Key: "type", Value: "post"})
+)
+
+func TestBuilder(t *testing.T) {
+	t.Run("Builder of multi-push data", func(t *testing.T) {
+		// when:
+		opReturn, err := opreturn.NewBuilder().PushString("hello", "world").Push([]byte{0x00, 0xff}).OpReturn()
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, []string{"68656c6c6f", "776f726c64", "00ff"}, opReturn.HexParts)
+	})
+
+	t.Run("Builder of B, MAP and AIP records", func(t *testing.T) {
+		// given:
+		signer := givenSigner(t)
+
+		// when:
+		s, err := opreturn.NewBuilder().B(file).MAP(post).AIP(signer).Script()
+
+		// then:
+		require.NoError(t, err)
+		data, err := opreturn.Parse(s)
+		require.NoError(t, err)
+		require.Equal(t, &file, data.B)
+		require.Equal(t, &post, data.MAP)
+		require.True(t, data.AIP.Valid)
+		require.Equal(t, signer.Address(), data.AIP.Address)
+		require.Equal(t, []string{
+			opreturn.BPrefix, "# Hello", "text/markdown", "UTF-8", "hello.md", "|",
+			opreturn.MAPPrefix, "SET", "app", "myapp", "type", "post", "|",
+			opreturn.AIPPrefix, opreturn.AIPAlgorithmBitcoinECDSA, signer.Address(), data.AIP.Signature,
+		}, data.Strings())
+	})
+
+	t.Run("Builder with B default encoding", func(t *testing.T) {
+		// when:
+		s, err := opreturn.NewBuilder().B(opreturn.BFile{Data: []byte{0x89, 0x50}, MediaType: "image/png"}).Script()
+
+		// then:
+		require.NoError(t, err)
+		data, err := opreturn.Parse(s)
+		require.NoError(t, err)
+		require.Equal(t, &opreturn.BFile{Data: []byte{0x89, 0x50}, MediaType: "image/png", Encoding: opreturn.DefaultBEncoding}, data.B)
+	})
+
+	t.Run("Builder OpReturn hex parts match the script", func(t *testing.T) {
+		// given:
+		builder := opreturn.NewBuilder().PushString("prefix").MAP(post)
+
+		// when:
+		opReturn, err := builder.OpReturn()
+		require.NoError(t, err)
+		s, err := builder.Script()
+		require.NoError(t, err)
+
+		// then:
+		expected := &script.Script{}
+		require.NoError(t, expected.AppendOpcodes(script.OpFALSE, script.OpRETURN))
+		for _, part := range opReturn.HexParts {
+			require.NoError(t, expected.AppendPushDataHex(part))
+		}
+		require.Equal(t, expected.String(), s.String())
+	})
+
+	tests := map[string]*opreturn.Builder{
+		"Builder without data":          opreturn.NewBuilder(),
+		"Builder of MAP without pairs":  opreturn.NewBuilder().MAP(opreturn.MAPSet()),
+		"Builder of MAP with empty key": opreturn.NewBuilder().MAP(opreturn.MAPSet(opreturn.KeyValue{Value: "value"})),
+		"Builder of AIP without data":   opreturn.NewBuilder().AIP(givenSigner(t)),
+	}
+	for name, builder := range tests {
+		t.Run(name, func(t *testing.T) {
+			// when:
+			opReturn, err := builder.OpReturn()
+
+			// then:
+			require.ErrorIs(t, err, goclienterr.ErrInvalidOpReturn)
+			require.Nil(t, opReturn)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Run("Parse detects tampered AIP data", func(t *testing.T) {
+		// given:
+		pushes, err := opreturn.NewBuilder().MAP(post).AIP(givenSigner(t)).Pushes()
+		require.NoError(t, err)
+		pushes[3] = []byte("someone else's app")
+		s := givenDataScript(t, pushes)
+
+		// when:
+		data, err := opreturn.Parse(s)
+
+		// then:
+		require.NoError(t, err)
+		require.False(t, data.AIP.Valid)
+		value, ok := data.MAP.Get("app")
+		require.True(t, ok)
+		require.Equal(t, "someone else's app", value)
+	})
+
+	t.Run("Parse of OP_RETURN without OP_FALSE", func(t *testing.T) {
+		// when:
+		data, err := opreturn.ParseHex("6a0568656c6c6f")
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, []string{"hello"}, data.Strings())
+	})
+
+	t.Run("Parse of the MAP record with odd number of fields", func(t *testing.T) {
+		// given:
+		s := givenDataScript(t, [][]byte{[]byte(opreturn.MAPPrefix), []byte("SET"), []byte("app")})
+
+		// when:
+		data, err := opreturn.Parse(s)
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrInvalidOpReturn)
+		require.Nil(t, data)
+	})
+
+	t.Run("Parse of non-data script", func(t *testing.T) {
+		// when:
+		data, err := opreturn.ParseHex("76a914000000000000000000000000000000000000000088ac")
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrInvalidOpReturn)
+		require.Nil(t, data)
+	})
+}
+
+func TestParseTransaction(t *testing.T) {
+	// given:
+	s, err := opreturn.NewBuilder().B(file).MAP(post).AIP(givenSigner(t)).Script()
+	require.NoError(t, err)
+	p2pkh, err := script.NewFromHex("76a914000000000000000000000000000000000000000088ac")
+	require.NoError(t, err)
+
+	tx := trx.NewTransaction()
+	tx.AddOutput(&trx.TransactionOutput{Satoshis: 1, LockingScript: p2pkh})
+	tx.AddOutput(&trx.TransactionOutput{LockingScript: s})
+
+	// when:
+	data, err := opreturn.ParseTransaction(tx.Hex())
+
+	// then:
+	require.NoError(t, err)
+	require.Len(t, data, 1)
+	require.Equal(t, 1, data[0].OutputIndex)
+	require.Equal(t, "hello.md", data[0].B.Filename)
+	require.True(t, data[0].AIP.Valid)
+}
+
+func givenSigner(t *testing.T) *opreturn.KeySigner {
+	t.Helper()
+
+	xPriv, err := walletkeys.RandomXPriv()
+	require.NoError(t, err)
+	signer, err := opreturn.NewXPrivSigner(xPriv, 0, 0)
+	require.NoError(t, err)
+	return signer
+}
+
+func givenDataScript(t *testing.T, pushes [][]byte) *script.Script {
+	t.Helper()
+
+	s := &script.Script{}
+	require.NoError(t, s.AppendOpcodes(script.OpFALSE, script.OpRETURN))
+	for _, push := range pushes {
+		require.NoError(t, s.AppendPushData(push))
+	}
+	return s
+}
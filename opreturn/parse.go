@@ -0,0 +1,169 @@
+package opreturn
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/bitcoin-sv/go-sdk/script"
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/bitcoin-sv/spv-wallet-go-client/beef"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+)
+
+// Data is a parsed data output.
+type Data struct {
+	OutputIndex int      // Index of the output in the transaction; set by ParseTransaction.
+	Pushes      [][]byte // All data pushes following the OP_RETURN.
+	B           *BFile   // The first B:// record, if any.
+	MAP         *MAP     // The first MAP record, if any.
+	AIP         *AIP     // The first AIP record, if any.
+}
+
+// Strings returns the data pushes as strings.
+func (d *Data) Strings() []string {
+	parts := make([]string, len(d.Pushes))
+	for i, push := range d.Pushes {
+		parts[i] = string(push)
+	}
+	return parts
+}
+
+// ParseTransaction parses all data outputs of a transaction hex, either raw, in the Extended Format or in the BEEF format.
+func ParseTransaction(txHex string) ([]*Data, error) {
+	tx, err := parseHex(txHex)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*Data
+	for i, out := range tx.Outputs {
+		if out.LockingScript == nil || !out.LockingScript.IsData() {
+			continue
+		}
+		data, err := Parse(out.LockingScript)
+		if err != nil {
+			return nil, fmt.Errorf("output %d: %w", i, err)
+		}
+		data.OutputIndex = i
+		res = append(res, data)
+	}
+	return res, nil
+}
+
+// ParseHex parses the hex of a data output locking script.
+func ParseHex(scriptHex string) (*Data, error) {
+	s, err := script.NewFromHex(scriptHex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrInvalidOpReturn, err)
+	}
+	return Parse(s)
+}
+
+// Parse parses a data output locking script, starting with either OP_FALSE OP_RETURN or OP_RETURN,
+// and decodes its B://, MAP and AIP records. The AIP signatures are verified, but an invalid signature
+// isn't an error: it is reported by the AIP.Valid field.
+func Parse(lockingScript *script.Script) (*Data, error) {
+	pushes, err := dataPushes(lockingScript)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &Data{Pushes: pushes}
+	start := 0
+	for end := 0; end <= len(pushes); end++ {
+		if end < len(pushes) && !bytes.Equal(pushes[end], []byte(Separator)) {
+			continue
+		}
+		if err := data.parseRecord(pushes, start, end); err != nil {
+			return nil, err
+		}
+		start = end + 1
+	}
+	return data, nil
+}
+
+// parseRecord decodes the record spanning pushes[start:end]. The pushes preceding it are the data signed by an AIP record.
+func (d *Data) parseRecord(pushes [][]byte, start, end int) error {
+	if start >= end {
+		return nil
+	}
+	record := pushes[start:end]
+	fields := record[1:]
+
+	switch string(record[0]) {
+	case BPrefix:
+		if d.B != nil {
+			return nil
+		}
+		if len(fields) < 2 {
+			return fmt.Errorf("%w: B:// record requires data and media type", goclienterr.ErrInvalidOpReturn)
+		}
+		d.B = &BFile{Data: fields[0], MediaType: string(fields[1])}
+		if len(fields) > 2 {
+			d.B.Encoding = string(fields[2])
+		}
+		if len(fields) > 3 {
+			d.B.Filename = string(fields[3])
+		}
+
+	case MAPPrefix:
+		if d.MAP != nil {
+			return nil
+		}
+		if len(fields) < 1 || len(fields[1:])%2 != 0 {
+			return fmt.Errorf("%w: MAP record requires a command and key/value pairs", goclienterr.ErrInvalidOpReturn)
+		}
+		d.MAP = &MAP{Command: string(fields[0])}
+		for i := 1; i < len(fields); i += 2 {
+			d.MAP.Pairs = append(d.MAP.Pairs, KeyValue{Key: string(fields[i]), Value: string(fields[i+1])})
+		}
+
+	case AIPPrefix:
+		if d.AIP != nil {
+			return nil
+		}
+		if len(fields) < 3 {
+			return fmt.Errorf("%w: AIP record requires algorithm, address and signature", goclienterr.ErrInvalidOpReturn)
+		}
+		d.AIP = &AIP{Algorithm: string(fields[0]), Address: string(fields[1]), Signature: string(fields[2])}
+		signed := pushes[:max(start-1, 0)]
+		d.AIP.Valid = len(signed) > 0 && verifyAIP(d.AIP, signed)
+	}
+	return nil
+}
+
+// dataPushes returns the pushes following the OP_RETURN of the data output script.
+func dataPushes(lockingScript *script.Script) ([][]byte, error) {
+	if lockingScript == nil || !lockingScript.IsData() {
+		return nil, fmt.Errorf("%w: not a data output script", goclienterr.ErrInvalidOpReturn)
+	}
+	b := []byte(*lockingScript)
+	if b[0] == script.OpFALSE {
+		b = b[1:]
+	}
+	s := script.Script(b[1:])
+
+	var pushes [][]byte
+	for pos := 0; pos < len(s); {
+		op, err := s.ReadOp(&pos)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", goclienterr.ErrInvalidOpReturn, err)
+		}
+		if op.Op > script.OpPUSHDATA4 {
+			return nil, fmt.Errorf("%w: unexpected opcode %s", goclienterr.ErrInvalidOpReturn, op.String())
+		}
+		pushes = append(pushes, op.Data)
+	}
+	return pushes, nil
+}
+
+func parseHex(txHex string) (*trx.Transaction, error) {
+	if beef.IsBEEFHex(txHex) {
+		return beef.DecodeHex(txHex)
+	}
+	tx, err := trx.NewTransactionFromHex(txHex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrInvalidTransactionHex, err)
+	}
+	return tx, nil
+}
@@ -0,0 +1,174 @@
+package opreturn
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+
+	bip32 "github.com/bitcoin-sv/go-sdk/compat/bip32"
+	bsm "github.com/bitcoin-sv/go-sdk/compat/bsm"
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/bitcoin-sv/go-sdk/script"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+)
+
+// DefaultBEncoding is the encoding of a B:// file without an explicit encoding.
+const DefaultBEncoding = "binary"
+
+// BFile is a file stored with the B:// protocol.
+type BFile struct {
+	Data      []byte // Content of the file.
+	MediaType string // Media type of the content, e.g., "text/markdown" or "image/png".
+	Encoding  string // Encoding of the content, e.g., "UTF-8"; DefaultBEncoding if not set.
+	Filename  string // Optional name of the file.
+}
+
+func (f BFile) pushes() [][]byte {
+	encoding := f.Encoding
+	if encoding == "" {
+		encoding = DefaultBEncoding
+	}
+	pushes := [][]byte{[]byte(BPrefix), f.Data, []byte(f.MediaType), []byte(encoding)}
+	if f.Filename != "" {
+		pushes = append(pushes, []byte(f.Filename))
+	}
+	return pushes
+}
+
+// MAPCommandSet is the MAP command setting the key/value pairs.
+const MAPCommandSet = "SET"
+
+// KeyValue is a single key/value pair of a MAP record.
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// MAP is a Magic Attribute Protocol record.
+type MAP struct {
+	Command string     // MAP command; MAPCommandSet if not set.
+	Pairs   []KeyValue // Key/value pairs in the order of their pushes.
+}
+
+// MAPSet returns a MAP record setting the key/value pairs.
+func MAPSet(pairs ...KeyValue) MAP {
+	return MAP{Command: MAPCommandSet, Pairs: pairs}
+}
+
+// Get returns the value of the first pair with the given key.
+func (m *MAP) Get(key string) (string, bool) {
+	for _, pair := range m.Pairs {
+		if pair.Key == key {
+			return pair.Value, true
+		}
+	}
+	return "", false
+}
+
+func (m MAP) pushes() ([][]byte, error) {
+	if len(m.Pairs) == 0 {
+		return nil, fmt.Errorf("%w: MAP record has no key/value pairs", goclienterr.ErrInvalidOpReturn)
+	}
+	command := m.Command
+	if command == "" {
+		command = MAPCommandSet
+	}
+
+	pushes := [][]byte{[]byte(MAPPrefix), []byte(command)}
+	for _, pair := range m.Pairs {
+		if pair.Key == "" {
+			return nil, fmt.Errorf("%w: MAP key is required", goclienterr.ErrInvalidOpReturn)
+		}
+		pushes = append(pushes, []byte(pair.Key), []byte(pair.Value))
+	}
+	return pushes, nil
+}
+
+// AIPAlgorithmBitcoinECDSA is the AIP signing algorithm of the Bitcoin Signed Messages.
+const AIPAlgorithmBitcoinECDSA = "BITCOIN_ECDSA"
+
+// AIP is an Author Identity Protocol signature of the data preceding it.
+type AIP struct {
+	Algorithm string // Signing algorithm, AIPAlgorithmBitcoinECDSA.
+	Address   string // Address of the signing key.
+	Signature string // Base64 encoded signature.
+	Valid     bool   // Set by the parser: true if the signature matches the address and the signed data.
+}
+
+func (a *AIP) pushes() [][]byte {
+	return [][]byte{[]byte(AIPPrefix), []byte(a.Algorithm), []byte(a.Address), []byte(a.Signature)}
+}
+
+// Signer signs the AIP messages.
+type Signer interface {
+	// SignMessage returns the address of the signing key and the Bitcoin Signed Message signature of the message.
+	SignMessage(message []byte) (address string, signature []byte, err error)
+}
+
+// KeySigner signs the AIP messages with a private key.
+type KeySigner struct {
+	key     *ec.PrivateKey
+	address string
+}
+
+// NewKeySigner creates a new KeySigner of the private key.
+func NewKeySigner(key *ec.PrivateKey) (*KeySigner, error) {
+	addr, err := script.NewAddressFromPublicKey(key.PubKey(), true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive the signing address: %w", err)
+	}
+	return &KeySigner{key: key, address: addr.AddressString}, nil
+}
+
+// NewXPrivSigner creates a new KeySigner of the wallet key derived from the xPriv at the chain/num path,
+// so that the signatures are attributable to the wallet without revealing its xPub.
+func NewXPrivSigner(xPriv *bip32.ExtendedKey, chain, num uint32) (*KeySigner, error) {
+	key, err := bip32.GetPrivateKeyByPath(xPriv, chain, num)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive the signing key: %w", err)
+	}
+	return NewKeySigner(key)
+}
+
+// Address returns the address of the signing key.
+func (k *KeySigner) Address() string {
+	return k.address
+}
+
+// SignMessage signs the message with the Bitcoin Signed Message scheme.
+func (k *KeySigner) SignMessage(message []byte) (string, []byte, error) {
+	sig, err := bsm.SignMessage(k.key, message)
+	if err != nil {
+		return "", nil, err
+	}
+	return k.address, sig, nil
+}
+
+// aipMessage returns the message signed by an AIP record: the OP_RETURN opcode followed by all data pushes
+// preceding the AIP record, without the separator directly preceding it.
+func aipMessage(pushes [][]byte) []byte {
+	return append([]byte{script.OpRETURN}, bytes.Join(pushes, nil)...)
+}
+
+func signAIP(signer Signer, pushes [][]byte) (*AIP, error) {
+	address, sig, err := signer.SignMessage(aipMessage(pushes))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to sign AIP: %w", goclienterr.ErrInvalidOpReturn, err)
+	}
+	return &AIP{
+		Algorithm: AIPAlgorithmBitcoinECDSA,
+		Address:   address,
+		Signature: base64.StdEncoding.EncodeToString(sig),
+	}, nil
+}
+
+func verifyAIP(a *AIP, pushes [][]byte) bool {
+	if a.Algorithm != AIPAlgorithmBitcoinECDSA {
+		return false
+	}
+	sig, err := base64.StdEncoding.DecodeString(a.Signature)
+	if err != nil {
+		return false
+	}
+	return bsm.VerifyMessage(a.Address, sig, aipMessage(pushes)) == nil
+}
@@ -0,0 +1,24 @@
+package payments
+
+import "github.com/bitcoin-sv/spv-wallet/models/response"
+
+// P2PKHUnlockingScriptSize is the maximum size in bytes of a P2PKH unlocking script:
+// a push of a DER signature with the sighash flag (up to 73 bytes) and a push of a compressed public key (34 bytes).
+// It is used to estimate the size of a transaction which cannot be signed locally.
+const P2PKHUnlockingScriptSize = 107
+
+// FeeEstimate holds the preview of a payment built by the SPV Wallet without recording it.
+type FeeEstimate struct {
+	DraftID        string                        `json:"draftId"`        // ID of the draft transaction created for the preview.
+	Inputs         []*response.TransactionInput  `json:"inputs"`         // Inputs selected by the SPV Wallet to fund the payment.
+	Outputs        []*response.TransactionOutput `json:"outputs"`        // Outputs of the payment, including the change outputs.
+	InputSatoshis  uint64                        `json:"inputSatoshis"`  // Total value of the inputs.
+	OutputSatoshis uint64                        `json:"outputSatoshis"` // Total value of the outputs, including the change.
+	ChangeSatoshis uint64                        `json:"changeSatoshis"` // Value returned to the wallet as change.
+	Fee            uint64                        `json:"fee"`            // Fee paid by the transaction (inputs minus outputs).
+	Size           int                           `json:"size"`           // Size of the signed transaction in bytes.
+	SizeExact      bool                          `json:"sizeExact"`      // False if the size was estimated because the transaction couldn't be signed locally.
+	FeeUnit        *response.FeeUnit             `json:"feeUnit"`        // Fee unit used by the SPV Wallet to build the draft.
+	FeePerByte     float64                       `json:"feePerByte"`     // Effective fee rate in satoshis per byte.
+	RequiredFee    uint64                        `json:"requiredFee"`    // Minimum fee required by the fee unit for the transaction size.
+}
@@ -0,0 +1,40 @@
+package payments
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore is an in-memory implementation of the Store interface.
+// It is safe for concurrent use, but records are lost when the process exits,
+// so it only protects against retries made within the same process.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records map[string]Record
+}
+
+// NewMemoryStore creates a new, empty MemoryStore instance.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]Record)}
+}
+
+// Load returns a copy of the payment record with the given payment ID, or nil if there is no such record.
+func (m *MemoryStore) Load(_ context.Context, paymentID string) (*Record, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	record, ok := m.records[paymentID]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+// Save stores a copy of the payment record, replacing any previous record with the same payment ID.
+func (m *MemoryStore) Save(_ context.Context, record *Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.records[record.PaymentID] = *record
+	return nil
+}
@@ -0,0 +1,51 @@
+// Package payments provides building blocks for exactly-once payments made with the SPV Wallet client.
+//
+// An idempotent payment is identified by a client-supplied payment ID. The progress of the payment
+// (drafted, signed, recorded) is persisted in a Store, so that a payment interrupted at any step,
+// e.g., by a timeout of the record request, can be safely retried: the retry resumes from the last
+// completed step or returns the already recorded transaction instead of paying twice.
+package payments
+
+import (
+	"context"
+	"time"
+
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// MetadataKey is the transaction metadata key under which the payment ID is stored.
+// It allows to find the transaction of a payment on the SPV Wallet side even if the
+// local payment record was lost.
+const MetadataKey = "payment_id"
+
+// State represents the last completed step of an idempotent payment.
+type State string
+
+const (
+	// StateDrafted indicates that the draft transaction was created by the SPV Wallet API.
+	StateDrafted State = "drafted"
+	// StateSigned indicates that the draft transaction was signed locally and is ready to be recorded.
+	StateSigned State = "signed"
+	// StateRecorded indicates that the transaction was recorded by the SPV Wallet API.
+	StateRecorded State = "recorded"
+)
+
+// Record holds the persisted progress of an idempotent payment.
+type Record struct {
+	PaymentID     string                     `json:"paymentId"`             // Client-supplied unique identifier of the payment.
+	State         State                      `json:"state"`                 // The last completed step of the payment.
+	Draft         *response.DraftTransaction `json:"draft"`                 // The draft transaction created for the payment.
+	Hex           string                     `json:"hex"`                   // The signed transaction hex, set once the payment is signed.
+	TransactionID string                     `json:"transactionId"`         // The recorded transaction ID, set once the payment is recorded.
+	UpdatedAt     time.Time                  `json:"updatedAt"`             // The time of the last state change.
+	Fingerprint   string                     `json:"fingerprint,omitempty"` // Optional hash of the payment content set by the caller, e.g., the rows of a payout chunk.
+}
+
+// Store is an interface responsible for persisting the progress of idempotent payments.
+// Implementations must be safe for concurrent use if payments are sent concurrently.
+type Store interface {
+	// Load should return the payment record with the given payment ID, or nil if there is no such record.
+	Load(ctx context.Context, paymentID string) (*Record, error)
+	// Save should create or replace the payment record identified by its payment ID.
+	Save(ctx context.Context, record *Record) error
+}
@@ -0,0 +1,210 @@
+// Package payouts provides a batch payout engine for paying many paymails with the SPV Wallet client.
+//
+// The rows of a payout file (paymail, amount and an optional memo) are validated and chunked into
+// multi-output transactions, each sent as an idempotent payment (see the payments package). The payment
+// ID of a chunk is derived from the batch ID and the position of the chunk, so running the same batch again
+// after a crash, with the same payments.Store, resumes the unfinished chunks and reports the already recorded
+// ones as paid instead of paying anyone twice. The hash of the chunk's rows is stored with its payment,
+// and a chunk whose rows were changed since is refused instead of being resumed.
+package payouts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v1/queryparams"
+	"github.com/bitcoin-sv/spv-wallet-go-client/payments"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// DefaultMaxOutputs is the default limit of recipients paid by a single transaction.
+const DefaultMaxOutputs = 100
+
+// Transaction metadata keys set on every payout transaction.
+const (
+	// MetadataBatchIDKey is the key of the batch ID, which allows to find all transactions of a batch.
+	MetadataBatchIDKey = "payout_batch_id"
+
+	// MetadataMemosKey is the key of the memos of the rows paid by the transaction, keyed by the row's line number.
+	MetadataMemosKey = "payout_memos"
+)
+
+// Sender sends idempotent payments. It is implemented by the *spvwallet.UserAPI.
+type Sender interface {
+	SendToRecipientsIdempotent(ctx context.Context, cmd *commands.IdempotentSendToRecipients, store payments.Store) (*response.Transaction, error)
+}
+
+// Batch describes a payout batch.
+//
+// The rows of a batch must not be changed between the runs of the same batch; the rows of a changed chunk
+// are reported as failed with goclienterr.ErrPayoutChunkChanged. Rows which failed the validation should
+// be corrected and paid in a new batch, with a new ID.
+type Batch struct {
+	ID         string               // Unique identifier of the batch, used to derive the payment IDs of the chunks.
+	Rows       []*Row               // Rows of the payout file.
+	MaxOutputs int                  // Limit of recipients per transaction; DefaultMaxOutputs if not set.
+	Metadata   queryparams.Metadata // Metadata associated with every transaction of the batch.
+}
+
+// Status is the outcome of a payout row.
+type Status string
+
+// Statuses of the payout rows.
+const (
+	StatusPaid    Status = "paid"    // The row was paid by a recorded transaction.
+	StatusFailed  Status = "failed"  // The transaction paying the row failed; running the batch again retries it.
+	StatusInvalid Status = "invalid" // The row failed the validation and was not paid.
+)
+
+// RowResult is the outcome of a single payout row.
+type RowResult struct {
+	Row           *Row   // The payout row.
+	Status        Status // Outcome of the row.
+	PaymentID     string // Payment ID of the chunk the row belongs to; empty for invalid rows.
+	TransactionID string // ID of the transaction which paid the row; empty unless the row was paid.
+	Err           error  // Reason of the failure; nil for paid rows.
+}
+
+// Report holds the outcome of every row of the batch, in the order of the rows.
+type Report struct {
+	BatchID string
+	Results []*RowResult
+}
+
+// Count returns the number of rows with the given status.
+func (r *Report) Count(status Status) int {
+	var n int
+	for _, res := range r.Results {
+		if res.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Run pays the valid rows of the batch in chunks of at most batch.MaxOutputs recipients, one idempotent
+// payment per chunk, and reports the outcome of every row. A failed chunk doesn't stop the batch; its rows
+// are reported as failed and the remaining chunks are still sent. Running the batch again with the same
+// store retries only the chunks which haven't been recorded.
+// Run returns an error only if the batch itself is invalid.
+func Run(ctx context.Context, sender Sender, store payments.Store, batch *Batch) (*Report, error) {
+	if batch.ID == "" {
+		return nil, fmt.Errorf("%w: batch ID is required", goclienterr.ErrInvalidPayoutBatch)
+	}
+	maxOutputs := batch.MaxOutputs
+	if maxOutputs == 0 {
+		maxOutputs = DefaultMaxOutputs
+	}
+	if maxOutputs < 0 {
+		return nil, fmt.Errorf("%w: max outputs must be positive, got %d", goclienterr.ErrInvalidPayoutBatch, maxOutputs)
+	}
+
+	report := &Report{BatchID: batch.ID, Results: make([]*RowResult, len(batch.Rows))}
+	for i, row := range batch.Rows {
+		report.Results[i] = &RowResult{Row: row}
+		if err := row.Validate(); err != nil {
+			report.Results[i].Status = StatusInvalid
+			report.Results[i].Err = err
+		}
+	}
+
+	// Chunks are built from the positions of all rows, so that the chunks stay the same between the runs.
+	for number, start := 0, 0; start < len(batch.Rows); number, start = number+1, start+maxOutputs {
+		var chunk []*RowResult
+		for _, res := range report.Results[start:min(start+maxOutputs, len(batch.Rows))] {
+			if res.Status != StatusInvalid {
+				chunk = append(chunk, res)
+			}
+		}
+		if len(chunk) > 0 {
+			payChunk(ctx, sender, store, batch, number, chunk)
+		}
+	}
+	return report, nil
+}
+
+func payChunk(ctx context.Context, sender Sender, store payments.Store, batch *Batch, number int, chunk []*RowResult) {
+	cmd := chunkPayment(batch, number, chunk)
+	hash := rowsHash(chunk)
+	tx, err := sendChunk(ctx, sender, &fingerprintStore{Store: store, fingerprint: hash}, cmd)
+	for _, res := range chunk {
+		res.PaymentID = cmd.PaymentID
+		if err != nil {
+			res.Status = StatusFailed
+			res.Err = err
+			continue
+		}
+		res.Status = StatusPaid
+		res.TransactionID = tx.ID
+	}
+}
+
+// sendChunk sends the payment of the chunk, unless its stored payment was made for different rows.
+func sendChunk(ctx context.Context, sender Sender, store *fingerprintStore, cmd *commands.IdempotentSendToRecipients) (*response.Transaction, error) {
+	record, err := store.Load(ctx, cmd.PaymentID)
+	if err != nil {
+		return nil, errors.Join(goclienterr.ErrPaymentStore, err)
+	}
+	if record != nil && record.Fingerprint != store.fingerprint {
+		return nil, fmt.Errorf("%w: payment %s", goclienterr.ErrPayoutChunkChanged, cmd.PaymentID)
+	}
+	return sender.SendToRecipientsIdempotent(ctx, cmd, store)
+}
+
+// chunkPayment returns the idempotent payment of the chunk. Its payment ID depends only on the batch ID
+// and the chunk number, so a chunk whose rows were changed is found and refused rather than paid again.
+func chunkPayment(batch *Batch, number int, chunk []*RowResult) *commands.IdempotentSendToRecipients {
+	recipients := make([]*commands.Recipients, len(chunk))
+	memos := make(map[string]any)
+	for i, res := range chunk {
+		row := res.Row
+		recipients[i] = &commands.Recipients{To: row.Paymail, Satoshis: row.Satoshis}
+		if row.Memo != "" {
+			memos[strconv.Itoa(row.Line)] = row.Memo
+		}
+	}
+
+	metadata := make(queryparams.Metadata, len(batch.Metadata)+2)
+	maps.Copy(metadata, batch.Metadata)
+	metadata[MetadataBatchIDKey] = batch.ID
+	if len(memos) > 0 {
+		metadata[MetadataMemosKey] = memos
+	}
+
+	return &commands.IdempotentSendToRecipients{
+		PaymentID: fmt.Sprintf("%s-%d", batch.ID, number),
+		SendToRecipients: commands.SendToRecipients{
+			Recipients: recipients,
+			Metadata:   metadata,
+		},
+	}
+}
+
+// rowsHash returns the hex encoded hash of the content of the chunk's rows.
+func rowsHash(chunk []*RowResult) string {
+	hash := sha256.New()
+	for _, res := range chunk {
+		row := res.Row
+		fmt.Fprintf(hash, "%d\x00%s\x00%d\x00%s\x00", row.Line, row.Paymail, row.Satoshis, row.Memo)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// fingerprintStore stores the payment records of a chunk with the hash of its rows.
+type fingerprintStore struct {
+	payments.Store
+	fingerprint string
+}
+
+// Save saves the record with the fingerprint of the store.
+func (s *fingerprintStore) Save(ctx context.Context, record *payments.Record) error {
+	record.Fingerprint = s.fingerprint
+	return s.Store.Save(ctx, record)
+}
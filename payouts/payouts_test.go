@@ -0,0 +1,185 @@
+package payouts_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/payments"
+	"github.com/bitcoin-sv/spv-wallet-go-client/payouts"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+	"github.com/stretchr/testify/require"
+)
+
+const payoutsCSV = `paymail,amount,memo
+alice@example.com,100,March salary
+bob@example.com,-5,
+carol@example.com,300,
+not-a-paymail,400,
+dave@example.com,500,bonus
+`
+
+func TestParseCSV(t *testing.T) {
+	// when:
+	rows, err := payouts.ParseCSV(strings.NewReader(payoutsCSV))
+
+	// then:
+	require.NoError(t, err)
+	require.Len(t, rows, 5)
+	require.Equal(t, &payouts.Row{Line: 2, Paymail: "alice@example.com", Satoshis: 100, Memo: "March salary"}, rows[0])
+	require.ErrorIs(t, rows[1].Validate(), goclienterr.ErrInvalidPayoutRow)
+	require.NoError(t, rows[2].Validate())
+	require.ErrorIs(t, rows[3].Validate(), goclienterr.ErrInvalidPayoutRow)
+	require.Equal(t, 6, rows[4].Line)
+}
+
+func TestParseCSV_MissingColumn(t *testing.T) {
+	// when:
+	rows, err := payouts.ParseCSV(strings.NewReader("paymail,memo\nalice@example.com,hello\n"))
+
+	// then:
+	require.ErrorIs(t, err, goclienterr.ErrInvalidPayoutFile)
+	require.Nil(t, rows)
+}
+
+func TestParseJSON(t *testing.T) {
+	// given:
+	const file = `[
+		{"paymail": "alice@example.com", "amount": 100, "memo": "March salary"},
+		{"paymail": "bob@example.com", "amount": 1.5}
+	]`
+
+	// when:
+	rows, err := payouts.ParseJSON(strings.NewReader(file))
+
+	// then:
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.Equal(t, &payouts.Row{Line: 1, Paymail: "alice@example.com", Satoshis: 100, Memo: "March salary"}, rows[0])
+	require.ErrorIs(t, rows[1].Validate(), goclienterr.ErrInvalidPayoutRow)
+}
+
+func TestRun(t *testing.T) {
+	rows, err := payouts.ParseCSV(strings.NewReader(payoutsCSV))
+	require.NoError(t, err)
+	batch := &payouts.Batch{ID: "march", Rows: rows, MaxOutputs: 2}
+
+	t.Run("Run pays the valid rows in chunks", func(t *testing.T) {
+		// given:
+		sender := &fakeSender{}
+
+		// when:
+		report, err := payouts.Run(context.Background(), sender, payments.NewMemoryStore(), batch)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, 3, report.Count(payouts.StatusPaid))
+		require.Equal(t, 2, report.Count(payouts.StatusInvalid))
+		require.Len(t, sender.sent, 3)
+
+		require.Equal(t, []string{"alice@example.com"}, recipients(sender.sent[0]))
+		require.Equal(t, []string{"carol@example.com"}, recipients(sender.sent[1]))
+		require.Equal(t, []string{"dave@example.com"}, recipients(sender.sent[2]))
+		require.Equal(t, "march", sender.sent[0].Metadata[payouts.MetadataBatchIDKey])
+		require.Equal(t, map[string]any{"2": "March salary"}, sender.sent[0].Metadata[payouts.MetadataMemosKey])
+		require.Equal(t, "tx-"+sender.sent[0].PaymentID, report.Results[0].TransactionID)
+	})
+
+	t.Run("Run after a failure retries only the failed chunks", func(t *testing.T) {
+		// given:
+		store := payments.NewMemoryStore()
+		sender := &fakeSender{fail: map[string]bool{"carol@example.com": true}}
+
+		// when:
+		report, err := payouts.Run(context.Background(), sender, store, batch)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, payouts.StatusFailed, report.Results[2].Status)
+		require.Equal(t, 2, report.Count(payouts.StatusPaid))
+
+		// when:
+		sender.fail = nil
+		report, err = payouts.Run(context.Background(), sender, store, batch)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, 3, report.Count(payouts.StatusPaid))
+		require.Len(t, sender.sent, 3)
+		require.Equal(t, []string{"carol@example.com"}, recipients(sender.sent[2]))
+	})
+
+	t.Run("Run refuses to resume a chunk whose rows were changed", func(t *testing.T) {
+		// given:
+		store := payments.NewMemoryStore()
+		sender := &fakeSender{}
+		_, err := payouts.Run(context.Background(), sender, store, batch)
+		require.NoError(t, err)
+
+		changed := make([]*payouts.Row, len(rows))
+		copy(changed, rows)
+		changed[2] = &payouts.Row{Line: rows[2].Line, Paymail: "mallory@example.com", Satoshis: rows[2].Satoshis}
+
+		// when:
+		report, err := payouts.Run(context.Background(), sender, store, &payouts.Batch{ID: batch.ID, Rows: changed, MaxOutputs: batch.MaxOutputs})
+
+		// then:
+		require.NoError(t, err)
+		require.Len(t, sender.sent, 3)
+		require.Equal(t, "march-1", report.Results[2].PaymentID)
+		require.Equal(t, payouts.StatusFailed, report.Results[2].Status)
+		require.ErrorIs(t, report.Results[2].Err, goclienterr.ErrPayoutChunkChanged)
+		require.Equal(t, 2, report.Count(payouts.StatusPaid))
+	})
+
+	t.Run("Run without batch ID", func(t *testing.T) {
+		// when:
+		report, err := payouts.Run(context.Background(), &fakeSender{}, payments.NewMemoryStore(), &payouts.Batch{Rows: rows})
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrInvalidPayoutBatch)
+		require.Nil(t, report)
+	})
+}
+
+// fakeSender records the payments in the store, like the idempotent payments of the SPV Wallet client,
+// and sends each payment ID only once.
+type fakeSender struct {
+	sent []*commands.IdempotentSendToRecipients
+	fail map[string]bool
+}
+
+func (f *fakeSender) SendToRecipientsIdempotent(ctx context.Context, cmd *commands.IdempotentSendToRecipients, store payments.Store) (*response.Transaction, error) {
+	record, err := store.Load(ctx, cmd.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+	if record != nil && record.State == payments.StateRecorded {
+		return &response.Transaction{ID: record.TransactionID}, nil
+	}
+
+	for _, r := range cmd.Recipients {
+		if f.fail[r.To] {
+			return nil, errors.New("payment failed")
+		}
+	}
+
+	f.sent = append(f.sent, cmd)
+	txID := fmt.Sprintf("tx-%s", cmd.PaymentID)
+	if err := store.Save(ctx, &payments.Record{PaymentID: cmd.PaymentID, State: payments.StateRecorded, TransactionID: txID}); err != nil {
+		return nil, err
+	}
+	return &response.Transaction{ID: txID}, nil
+}
+
+func recipients(cmd *commands.IdempotentSendToRecipients) []string {
+	res := make([]string, len(cmd.Recipients))
+	for i, r := range cmd.Recipients {
+		res[i] = r.To
+	}
+	return res
+}
@@ -0,0 +1,122 @@
+package payouts
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+)
+
+// Row is a single row of a payout file.
+type Row struct {
+	Line     int    // Line of the CSV file or position of the JSON array element, starting from 1.
+	Paymail  string // Paymail address of the recipient.
+	Satoshis uint64 // Amount to pay, in satoshis.
+	Memo     string // Optional memo stored in the metadata of the transaction.
+	Err      error  // Error of parsing the row; nil if the row was parsed.
+}
+
+var paymailRegexp = regexp.MustCompile(`^[a-zA-Z0-9._+\-]+@[a-zA-Z0-9\-]+(\.[a-zA-Z0-9\-]+)+$`)
+
+// Validate checks that the row was parsed, has a valid paymail address and a positive amount.
+func (r *Row) Validate() error {
+	switch {
+	case r.Err != nil:
+		return r.Err
+	case !paymailRegexp.MatchString(r.Paymail):
+		return fmt.Errorf("%w: line %d: invalid paymail address %q", goclienterr.ErrInvalidPayoutRow, r.Line, r.Paymail)
+	case r.Satoshis == 0:
+		return fmt.Errorf("%w: line %d: amount must be positive", goclienterr.ErrInvalidPayoutRow, r.Line)
+	default:
+		return nil
+	}
+}
+
+// CSV column names. The header row is required; the columns may be in any order and the memo column is optional.
+const (
+	ColumnPaymail = "paymail"
+	ColumnAmount  = "amount"
+	ColumnMemo    = "memo"
+)
+
+// ParseCSV reads the payout rows from a CSV file with a header row naming the paymail, amount and,
+// optionally, memo columns. The amounts are integers in satoshis. Rows with invalid values are returned
+// with the Err field set, so that they can be reported; an error is returned only if the file is malformed.
+func ParseCSV(r io.Reader) ([]*Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Join(goclienterr.ErrInvalidPayoutFile, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{ColumnPaymail, ColumnAmount} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing %q column", goclienterr.ErrInvalidPayoutFile, name)
+		}
+	}
+
+	var rows []*Row
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, errors.Join(goclienterr.ErrInvalidPayoutFile, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		row := &Row{Line: line, Paymail: field(ColumnPaymail), Memo: field(ColumnMemo)}
+		row.Satoshis, row.Err = parseAmount(line, field(ColumnAmount))
+		rows = append(rows, row)
+	}
+}
+
+// ParseJSON reads the payout rows from a JSON array of objects with the paymail, amount and,
+// optionally, memo fields. The amounts are integers in satoshis. Rows with invalid values are returned
+// with the Err field set, so that they can be reported; an error is returned only if the file is malformed.
+func ParseJSON(r io.Reader) ([]*Row, error) {
+	var records []struct {
+		Paymail string      `json:"paymail"`
+		Amount  json.Number `json:"amount"`
+		Memo    string      `json:"memo"`
+	}
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	if err := decoder.Decode(&records); err != nil {
+		return nil, errors.Join(goclienterr.ErrInvalidPayoutFile, err)
+	}
+
+	rows := make([]*Row, len(records))
+	for i, record := range records {
+		rows[i] = &Row{Line: i + 1, Paymail: strings.TrimSpace(record.Paymail), Memo: record.Memo}
+		rows[i].Satoshis, rows[i].Err = parseAmount(i+1, record.Amount.String())
+	}
+	return rows, nil
+}
+
+func parseAmount(line int, s string) (uint64, error) {
+	amount, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: line %d: invalid amount %q, expected a positive integer in satoshis", goclienterr.ErrInvalidPayoutRow, line, s)
+	}
+	return amount, nil
+}
@@ -0,0 +1,169 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// Names of the built-in policies, reported by the violations.
+const (
+	NameMaxPerTransaction  = "max per transaction"
+	NameRecipientAllowlist = "recipient allow-list"
+	NameMaxFeeRate         = "max fee rate"
+)
+
+type maxPerTransaction struct {
+	satoshis uint64
+}
+
+// MaxPerTransaction limits the satoshis leaving the wallet in a single transaction, including the fee.
+func MaxPerTransaction(satoshis uint64) Policy {
+	return &maxPerTransaction{satoshis: satoshis}
+}
+
+func (m *maxPerTransaction) Check(_ context.Context, spend *Spend) error {
+	if spend.Total() <= m.satoshis {
+		return nil
+	}
+	return &Violation{
+		Policy:  NameMaxPerTransaction,
+		Err:     goclienterr.ErrSpendingLimitExceeded,
+		Message: fmt.Sprintf("spend of %d satoshis exceeds the limit of %d satoshis", spend.Total(), m.satoshis),
+	}
+}
+
+// RollingLimit limits the satoshis leaving the wallet, including the fees, within a rolling time window.
+// The authorized spends are kept in the store under the name of the limit, so that the window survives
+// restarts with a persistent store, and is shared by the processes sharing the store.
+type RollingLimit struct {
+	Name     string        // Name of the limit, used as the key of the store.
+	Window   time.Duration // Length of the rolling window.
+	Satoshis uint64        // Maximum sum of the spends within the window.
+	Store    WindowStore
+}
+
+// DailyLimit returns the RollingLimit of the satoshis spent within the last 24 hours.
+func DailyLimit(satoshis uint64, store WindowStore) *RollingLimit {
+	return &RollingLimit{Name: "daily limit", Window: 24 * time.Hour, Satoshis: satoshis, Store: store}
+}
+
+// Check reports a violation if the spend together with the spends recorded within the window exceeds the limit.
+// A draft already recorded, e.g. when the signing of a payment is resumed, is allowed and isn't counted twice.
+func (r *RollingLimit) Check(ctx context.Context, spend *Spend) error {
+	recorded, err := r.Store.Recorded(ctx, r.Name, spend.DraftID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", goclienterr.ErrPolicyStore, err)
+	}
+	if recorded {
+		return nil
+	}
+
+	spent, err := r.Store.Spent(ctx, r.Name, spend.At.Add(-r.Window))
+	if err != nil {
+		return fmt.Errorf("%w: %w", goclienterr.ErrPolicyStore, err)
+	}
+	if spent+spend.Total() <= r.Satoshis {
+		return nil
+	}
+	return &Violation{
+		Policy: r.Name,
+		Err:    goclienterr.ErrSpendingLimitExceeded,
+		Message: fmt.Sprintf("spend of %d satoshis with %d satoshis spent within %s exceeds the limit of %d satoshis",
+			spend.Total(), spent, r.Window, r.Satoshis),
+	}
+}
+
+// Record adds the spend to the window. A draft is recorded only once.
+func (r *RollingLimit) Record(ctx context.Context, spend *Spend) error {
+	if err := r.Store.Record(ctx, r.Name, spend.DraftID, spend.At, spend.Total()); err != nil {
+		return fmt.Errorf("%w: %w", goclienterr.ErrPolicyStore, err)
+	}
+	return nil
+}
+
+// ContactSource provides the contacts of the user. It is implemented by the *spvwallet.UserAPI.
+type ContactSource interface {
+	ContactWithPaymail(ctx context.Context, paymail string) (*response.Contact, error)
+}
+
+// RecipientAllowlist allows paying only to the listed paymails and addresses, or to the confirmed contacts.
+// Data outputs without satoshis are always allowed.
+type RecipientAllowlist struct {
+	Paymails  []string      // Allowed paymails, compared case-insensitively.
+	Addresses []string      // Allowed addresses.
+	Contacts  ContactSource // If set, the paymails of the confirmed contacts are allowed.
+}
+
+// Check reports a violation for the first recipient which isn't allowed.
+// A contact which cannot be fetched isn't allowed.
+func (a *RecipientAllowlist) Check(ctx context.Context, spend *Spend) error {
+	for _, recipient := range spend.Recipients {
+		if recipient.Data && recipient.Satoshis == 0 {
+			continue
+		}
+		if reason := a.disallowed(ctx, recipient); reason != "" {
+			return &Violation{Policy: NameRecipientAllowlist, Err: goclienterr.ErrRecipientNotAllowed, Message: reason}
+		}
+	}
+	return nil
+}
+
+func (a *RecipientAllowlist) disallowed(ctx context.Context, recipient Recipient) string {
+	if recipient.Paymail == "" {
+		for _, address := range a.Addresses {
+			if recipient.To == address {
+				return ""
+			}
+		}
+		return fmt.Sprintf("address %q isn't allowed", recipient.To)
+	}
+
+	for _, paymail := range a.Paymails {
+		if strings.EqualFold(recipient.Paymail, paymail) {
+			return ""
+		}
+	}
+	if a.Contacts == nil {
+		return fmt.Sprintf("paymail %q isn't allowed", recipient.Paymail)
+	}
+
+	contact, err := a.Contacts.ContactWithPaymail(ctx, recipient.Paymail)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("paymail %q isn't allowed and its contact cannot be verified: %s", recipient.Paymail, err)
+	case contact == nil || contact.Status != response.ContactConfirmed:
+		return fmt.Sprintf("paymail %q isn't allowed nor a confirmed contact", recipient.Paymail)
+	}
+	return ""
+}
+
+type maxFeeRate struct {
+	unit response.FeeUnit
+}
+
+// MaxFeeRate limits the fee of the transaction to the fee unit, e.g., 1 satoshi per 1000 bytes,
+// applied to the estimated size of the signed transaction.
+func MaxFeeRate(unit response.FeeUnit) Policy {
+	return &maxFeeRate{unit: unit}
+}
+
+func (m *maxFeeRate) Check(_ context.Context, spend *Spend) error {
+	if m.unit.Bytes <= 0 || spend.Size <= 0 {
+		return nil
+	}
+	// fee / size > satoshis / bytes, compared without the rounding of the division.
+	if spend.Fee*uint64(m.unit.Bytes) <= uint64(m.unit.Satoshis)*uint64(spend.Size) { //nolint: gosec // the fee unit and the size are positive
+		return nil
+	}
+	return &Violation{
+		Policy: NameMaxFeeRate,
+		Err:    goclienterr.ErrFeeRateExceeded,
+		Message: fmt.Sprintf("fee of %d satoshis for %d bytes exceeds %d satoshis per %d bytes",
+			spend.Fee, spend.Size, m.unit.Satoshis, m.unit.Bytes),
+	}
+}
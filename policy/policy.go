@@ -0,0 +1,314 @@
+// Package policy checks the draft transactions against client-side spending policies before they are signed.
+//
+// The Engine evaluates a set of policies, such as per-transaction and rolling daily limits, recipient allow-lists
+// and a maximum fee rate, and rejects the drafts violating any of them with a *Violation error.
+// Set on the UserAPI, it guards every transaction signed by FinalizeTransaction and the sending methods,
+// protecting hot wallets driven by automated services from runaway bugs:
+//
+//	engine := policy.NewEngine([]policy.Policy{
+//		policy.MaxPerTransaction(100_000),
+//		policy.DailyLimit(1_000_000, policy.NewMemoryWindowStore()),
+//		&policy.RecipientAllowlist{Contacts: userAPI},
+//		policy.MaxFeeRate(response.FeeUnit{Satoshis: 1, Bytes: 1000}),
+//	})
+//	userAPI.SetSpendingPolicy(engine)
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/go-sdk/script"
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/bitcoin-sv/go-sdk/transaction/template/p2pkh"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// p2pkhUnlockingScriptSize is the size of a P2PKH unlocking script with its length prefix,
+// missing from the inputs of an unsigned draft.
+const p2pkhUnlockingScriptSize = 107
+
+// Recipient is a single output of the spend which isn't a change output.
+type Recipient struct {
+	To       string // Destination of the output as requested: a paymail or an address; for outputs not named by the draft, the P2PKH address or the locking script in hex.
+	Paymail  string // Paymail of the recipient, if the output is paid to a paymail.
+	Satoshis uint64
+	Data     bool // True for the data outputs (OP_RETURN).
+}
+
+// Spend describes a draft transaction evaluated by the policies.
+type Spend struct {
+	DraftID    string
+	At         time.Time   // Time of the evaluation.
+	Satoshis   uint64      // Sum of the recipient outputs, excluding the change.
+	Fee        uint64      // Fee of the draft transaction: the inputs minus the outputs.
+	Size       int         // Estimated size of the signed transaction in bytes, assuming P2PKH inputs.
+	Recipients []Recipient // Recipient outputs, excluding the change.
+}
+
+// Total returns the satoshis leaving the wallet: the recipient outputs and the fee.
+func (s *Spend) Total() uint64 {
+	return s.Satoshis + s.Fee
+}
+
+// NewSpend describes the draft transaction evaluated at the given time.
+// The spend is computed from the outputs of the transaction in draft.Hex, which is the transaction
+// being signed, rather than from the draft configuration reported by the SPV Wallet. Only the outputs
+// paying the change destinations of the draft count as change, regardless of the UseForChange flags,
+// and the fee is the value of the inputs minus the value of all outputs. The configured outputs are
+// used only to name the recipients of the matching outputs.
+func NewSpend(draft *response.DraftTransaction, at time.Time) (*Spend, error) {
+	tx, err := trx.NewTransactionFromHex(draft.Hex)
+	if err != nil {
+		return nil, errors.Join(goclienterr.ErrFailedToParseHex, err)
+	}
+
+	size := tx.Size()
+	for _, input := range tx.Inputs {
+		if input.UnlockingScript == nil || len(*input.UnlockingScript) == 0 {
+			size += p2pkhUnlockingScriptSize
+		}
+	}
+
+	inputs, err := inputsValue(tx, draft)
+	if err != nil {
+		return nil, err
+	}
+
+	change := make(map[string]bool, len(draft.Configuration.ChangeDestinations))
+	for _, destination := range draft.Configuration.ChangeDestinations {
+		if destination != nil {
+			change[strings.ToLower(destination.LockingScript)] = true
+		}
+	}
+	recipients := recipientsByScript(draft.Configuration.Outputs)
+
+	spend := &Spend{DraftID: draft.ID, At: at, Size: size}
+	var outputs uint64
+	for _, output := range tx.Outputs {
+		outputs += output.Satoshis
+		lockingScript := output.LockingScript.String()
+		if change[lockingScript] {
+			continue
+		}
+
+		recipient, ok := recipients[lockingScript]
+		switch {
+		case output.LockingScript.IsData():
+			recipient = Recipient{Data: true}
+		case !ok:
+			recipient = Recipient{To: scriptDestination(output.LockingScript)}
+		}
+		recipient.Satoshis = output.Satoshis
+		spend.Satoshis += output.Satoshis
+		spend.Recipients = append(spend.Recipients, recipient)
+	}
+	if inputs < outputs {
+		return nil, fmt.Errorf("%w: outputs value %d exceeds inputs value %d", goclienterr.ErrInvalidDraftTransaction, outputs, inputs)
+	}
+	spend.Fee = inputs - outputs
+	return spend, nil
+}
+
+// inputsValue returns the value of the transaction inputs, taken from the inputs of the extended transaction format
+// or from the inputs of the draft configuration.
+func inputsValue(tx *trx.Transaction, draft *response.DraftTransaction) (uint64, error) {
+	configured := make(map[string]uint64, len(draft.Configuration.Inputs))
+	for _, input := range draft.Configuration.Inputs {
+		if input != nil {
+			configured[fmt.Sprintf("%s:%d", input.TransactionID, input.OutputIndex)] = input.Satoshis
+		}
+	}
+
+	var value uint64
+	for _, input := range tx.Inputs {
+		if satoshis := input.SourceTxSatoshis(); satoshis != nil {
+			value += *satoshis
+			continue
+		}
+		satoshis, ok := configured[fmt.Sprintf("%s:%d", input.SourceTXID, input.SourceTxOutIndex)]
+		if !ok {
+			return 0, fmt.Errorf("%w: unknown value of input %s:%d", goclienterr.ErrInvalidDraftTransaction, input.SourceTXID, input.SourceTxOutIndex)
+		}
+		value += satoshis
+	}
+	return value, nil
+}
+
+// recipientsByScript names the recipients of the configured outputs by their locking scripts in hex.
+// An output to an address names only the P2PKH script of that address.
+func recipientsByScript(outputs []*response.TransactionOutput) map[string]Recipient {
+	recipients := make(map[string]Recipient)
+	for _, output := range outputs {
+		if output == nil || output.OpReturn != nil {
+			continue
+		}
+
+		recipient := Recipient{To: output.To}
+		switch {
+		case output.PaymailP4 != nil && output.PaymailP4.Alias != "":
+			recipient.Paymail = output.PaymailP4.Alias + "@" + output.PaymailP4.Domain
+		case strings.Contains(output.To, "@"):
+			recipient.Paymail = output.To
+		}
+
+		if recipient.Paymail == "" {
+			if lockingScript, err := addressScript(output.To); err == nil {
+				recipients[lockingScript] = recipient
+			}
+			continue
+		}
+		for _, lockingScript := range append([]string{output.Script}, scriptsOf(output)...) {
+			if lockingScript != "" {
+				recipients[strings.ToLower(lockingScript)] = recipient
+			}
+		}
+	}
+	return recipients
+}
+
+func scriptsOf(output *response.TransactionOutput) []string {
+	scripts := make([]string, 0, len(output.Scripts))
+	for _, s := range output.Scripts {
+		if s != nil {
+			scripts = append(scripts, s.Script)
+		}
+	}
+	return scripts
+}
+
+// scriptDestination returns the address of a P2PKH script, or the script in hex.
+func scriptDestination(lockingScript *script.Script) string {
+	if lockingScript.IsP2PKH() {
+		if addr, err := lockingScript.Address(); err == nil {
+			return addr.AddressString
+		}
+	}
+	return lockingScript.String()
+}
+
+func addressScript(address string) (string, error) {
+	addr, err := script.NewAddressFromString(address)
+	if err != nil {
+		return "", err
+	}
+	lockingScript, err := p2pkh.Lock(addr)
+	if err != nil {
+		return "", err
+	}
+	return lockingScript.String(), nil
+}
+
+// Policy checks a spend. Check returns a *Violation if the spend isn't allowed,
+// or any other error if the policy cannot be evaluated.
+type Policy interface {
+	Check(ctx context.Context, spend *Spend) error
+}
+
+// Recorder is implemented by the policies which keep track of the authorized spends, e.g., the rolling limits.
+type Recorder interface {
+	Record(ctx context.Context, spend *Spend) error
+}
+
+// Violation is the error returned for a spend violating a policy.
+// It wraps goclienterr.ErrPolicyViolation and the error of the specific violation,
+// e.g., goclienterr.ErrSpendingLimitExceeded.
+type Violation struct {
+	Policy  string // Name of the violated policy.
+	Err     error  // Error of the specific violation.
+	Message string // Details of the violation.
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s: %s: %s: %s", goclienterr.ErrPolicyViolation, v.Policy, v.Err, v.Message)
+}
+
+// Unwrap returns goclienterr.ErrPolicyViolation and the error of the specific violation.
+func (v *Violation) Unwrap() []error {
+	return []error{goclienterr.ErrPolicyViolation, v.Err}
+}
+
+// Engine evaluates the policies.
+type Engine struct {
+	policies []Policy
+	now      func() time.Time
+	mu       sync.Mutex
+}
+
+// Option configures the Engine.
+type Option func(*Engine)
+
+// WithClock sets the function returning the current time; time.Now by default.
+func WithClock(now func() time.Time) Option {
+	return func(e *Engine) {
+		e.now = now
+	}
+}
+
+// NewEngine creates a new Engine evaluating all of the policies.
+func NewEngine(policies []Policy, opts ...Option) *Engine {
+	e := &Engine{policies: policies, now: time.Now}
+	for _, o := range opts {
+		o(e)
+	}
+	return e
+}
+
+// Check evaluates the draft transaction against all policies without recording it.
+// It returns the violations of all violated policies joined, or the first error of a policy which cannot be evaluated.
+func (e *Engine) Check(ctx context.Context, draft *response.DraftTransaction) error {
+	spend, err := NewSpend(draft, e.now())
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.check(ctx, spend)
+}
+
+// Authorize evaluates the draft transaction against all policies and, if it is allowed, records it
+// with the Recorder policies. The evaluation and the recording are atomic with respect to other calls,
+// so that concurrent drafts cannot exceed a rolling limit together. The spend is recorded before
+// the transaction is signed, so it counts towards the limits even if the transaction is never broadcast.
+func (e *Engine) Authorize(ctx context.Context, draft *response.DraftTransaction) error {
+	spend, err := NewSpend(draft, e.now())
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.check(ctx, spend); err != nil {
+		return err
+	}
+	for _, p := range e.policies {
+		if r, ok := p.(Recorder); ok {
+			if err := r.Record(ctx, spend); err != nil {
+				return fmt.Errorf("failed to record spend of draft %s: %w", spend.DraftID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (e *Engine) check(ctx context.Context, spend *Spend) error {
+	var violations []error
+	for _, p := range e.policies {
+		err := p.Check(ctx, spend)
+		var violation *Violation
+		switch {
+		case err == nil:
+		case errors.As(err, &violation):
+			violations = append(violations, err)
+		default:
+			return fmt.Errorf("failed to evaluate spending policy: %w", err)
+		}
+	}
+	return errors.Join(violations...)
+}
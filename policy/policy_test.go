@@ -0,0 +1,333 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/go-sdk/script"
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/bitcoin-sv/go-sdk/transaction/template/p2pkh"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/policy"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	address      = "1BE8WfQkDDYE3zEgxBdRNuAxsnHkDcuPdT"
+	otherAddress = "1JfbZRwdDHKZmuiZgYArJZhcuuzuw2HuMu"
+)
+
+func TestNewSpend(t *testing.T) {
+	// given:
+	draft := givenDraft(t, 10,
+		&response.TransactionOutput{To: "alice@example.com", Satoshis: 100, PaymailP4: &response.PaymailP4{Alias: "alice", Domain: "example.com"}},
+		&response.TransactionOutput{To: address, Satoshis: 50},
+		&response.TransactionOutput{OpReturn: &response.OpReturn{StringParts: []string{"hello"}}},
+		&response.TransactionOutput{To: otherAddress, Satoshis: 999, UseForChange: true},
+	)
+
+	// when:
+	spend, err := policy.NewSpend(draft, time.Time{})
+
+	// then:
+	require.NoError(t, err)
+	require.Equal(t, uint64(150), spend.Satoshis)
+	require.Equal(t, uint64(160), spend.Total())
+	require.Equal(t, []policy.Recipient{
+		{To: "alice@example.com", Paymail: "alice@example.com", Satoshis: 100},
+		{To: address, Satoshis: 50},
+		{Data: true},
+	}, spend.Recipients)
+	require.Greater(t, spend.Size, 148)
+}
+
+func TestNewSpend_IgnoresDraftConfiguration(t *testing.T) {
+	// given:
+	draft := givenDraft(t, 10,
+		&response.TransactionOutput{To: address, Satoshis: 100},
+		&response.TransactionOutput{To: otherAddress, Satoshis: 500},
+	)
+	draft.Configuration.Fee = 0
+	draft.Configuration.Outputs[0].Satoshis = 1
+	draft.Configuration.Outputs[1].UseForChange = true
+
+	// when:
+	spend, err := policy.NewSpend(draft, time.Time{})
+
+	// then:
+	require.NoError(t, err)
+	require.Equal(t, uint64(600), spend.Satoshis)
+	require.Equal(t, uint64(10), spend.Fee)
+	require.Equal(t, []policy.Recipient{
+		{To: address, Satoshis: 100},
+		{To: otherAddress, Satoshis: 500},
+	}, spend.Recipients)
+}
+
+func TestNewSpend_UnknownInput(t *testing.T) {
+	// given:
+	draft := givenDraft(t, 10, &response.TransactionOutput{To: address, Satoshis: 100})
+	draft.Configuration.Inputs = nil
+
+	// when:
+	spend, err := policy.NewSpend(draft, time.Time{})
+
+	// then:
+	require.ErrorIs(t, err, goclienterr.ErrInvalidDraftTransaction)
+	require.Nil(t, spend)
+}
+
+func TestEngine_Authorize(t *testing.T) {
+	t.Run("MaxPerTransaction", func(t *testing.T) {
+		// given:
+		engine := policy.NewEngine([]policy.Policy{policy.MaxPerTransaction(100)})
+
+		// when:
+		allowed := engine.Authorize(context.Background(), givenDraft(t, 1, &response.TransactionOutput{To: address, Satoshis: 99}))
+		rejected := engine.Authorize(context.Background(), givenDraft(t, 1, &response.TransactionOutput{To: address, Satoshis: 100}))
+
+		// then:
+		require.NoError(t, allowed)
+		require.ErrorIs(t, rejected, goclienterr.ErrPolicyViolation)
+		require.ErrorIs(t, rejected, goclienterr.ErrSpendingLimitExceeded)
+		var violation *policy.Violation
+		require.ErrorAs(t, rejected, &violation)
+		require.Equal(t, policy.NameMaxPerTransaction, violation.Policy)
+		require.Equal(t, "spend of 101 satoshis exceeds the limit of 100 satoshis", violation.Message)
+	})
+
+	t.Run("DailyLimit within the rolling window", func(t *testing.T) {
+		// given:
+		now := time.Date(2024, 11, 20, 12, 0, 0, 0, time.UTC)
+		engine := policy.NewEngine([]policy.Policy{policy.DailyLimit(1000, policy.NewMemoryWindowStore())},
+			policy.WithClock(func() time.Time { return now }))
+		draft := givenDraft(t, 0, &response.TransactionOutput{To: address, Satoshis: 600})
+		next := givenDraft(t, 0, &response.TransactionOutput{To: address, Satoshis: 600})
+		next.ID = "draft-2"
+
+		// when:
+		first := engine.Authorize(context.Background(), draft)
+		checked := engine.Check(context.Background(), next)
+		second := engine.Authorize(context.Background(), next)
+		now = now.Add(24*time.Hour + time.Second)
+		nextDay := engine.Authorize(context.Background(), next)
+
+		// then:
+		require.NoError(t, first)
+		require.ErrorIs(t, checked, goclienterr.ErrSpendingLimitExceeded)
+		require.ErrorIs(t, second, goclienterr.ErrSpendingLimitExceeded)
+		require.ErrorContains(t, second, "spend of 600 satoshis with 600 satoshis spent within 24h0m0s exceeds the limit of 1000 satoshis")
+		require.NoError(t, nextDay)
+	})
+
+	t.Run("DailyLimit records a draft once", func(t *testing.T) {
+		// given:
+		engine := policy.NewEngine([]policy.Policy{policy.DailyLimit(1000, policy.NewMemoryWindowStore())})
+		draft := givenDraft(t, 0, &response.TransactionOutput{To: address, Satoshis: 600})
+		next := givenDraft(t, 0, &response.TransactionOutput{To: address, Satoshis: 400})
+		next.ID = "draft-2"
+
+		// when:
+		first := engine.Authorize(context.Background(), draft)
+		resumed := engine.Authorize(context.Background(), draft)
+		second := engine.Authorize(context.Background(), next)
+
+		// then:
+		require.NoError(t, first)
+		require.NoError(t, resumed)
+		require.NoError(t, second)
+	})
+
+	t.Run("Check doesn't record the spend", func(t *testing.T) {
+		// given:
+		engine := policy.NewEngine([]policy.Policy{policy.DailyLimit(1000, policy.NewMemoryWindowStore())})
+		draft := givenDraft(t, 0, &response.TransactionOutput{To: address, Satoshis: 600})
+
+		// when:
+		checked := engine.Check(context.Background(), draft)
+		authorized := engine.Authorize(context.Background(), draft)
+
+		// then:
+		require.NoError(t, checked)
+		require.NoError(t, authorized)
+	})
+
+	t.Run("MaxFeeRate", func(t *testing.T) {
+		// given:
+		engine := policy.NewEngine([]policy.Policy{policy.MaxFeeRate(response.FeeUnit{Satoshis: 1, Bytes: 100})})
+
+		// when:
+		allowed := engine.Authorize(context.Background(), givenDraft(t, 1, &response.TransactionOutput{To: address, Satoshis: 1}))
+		rejected := engine.Authorize(context.Background(), givenDraft(t, 10, &response.TransactionOutput{To: address, Satoshis: 1}))
+
+		// then:
+		require.NoError(t, allowed)
+		require.ErrorIs(t, rejected, goclienterr.ErrFeeRateExceeded)
+	})
+
+	t.Run("All violations are reported", func(t *testing.T) {
+		// given:
+		engine := policy.NewEngine([]policy.Policy{
+			policy.MaxPerTransaction(10),
+			&policy.RecipientAllowlist{Addresses: []string{otherAddress}},
+		})
+
+		// when:
+		err := engine.Authorize(context.Background(), givenDraft(t, 0, &response.TransactionOutput{To: address, Satoshis: 20}))
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrSpendingLimitExceeded)
+		require.ErrorIs(t, err, goclienterr.ErrRecipientNotAllowed)
+	})
+
+	t.Run("Policy which cannot be evaluated", func(t *testing.T) {
+		// given:
+		errUnavailable := errors.New("unavailable")
+		engine := policy.NewEngine([]policy.Policy{policy.DailyLimit(1000, &failingWindowStore{err: errUnavailable})})
+
+		// when:
+		err := engine.Authorize(context.Background(), givenDraft(t, 0, &response.TransactionOutput{To: address, Satoshis: 1}))
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrPolicyStore)
+		require.ErrorIs(t, err, errUnavailable)
+		require.NotErrorIs(t, err, goclienterr.ErrPolicyViolation)
+	})
+}
+
+func TestRecipientAllowlist(t *testing.T) {
+	contacts := fakeContacts{
+		"bob@example.com":   {Paymail: "bob@example.com", Status: response.ContactConfirmed},
+		"carol@example.com": {Paymail: "carol@example.com", Status: response.ContactAwaitAccept},
+	}
+	allowlist := &policy.RecipientAllowlist{
+		Paymails:  []string{"Alice@Example.com"},
+		Addresses: []string{address},
+		Contacts:  contacts,
+	}
+
+	tests := map[string]struct {
+		output  *response.TransactionOutput
+		allowed bool
+	}{
+		"listed paymail": {
+			output:  &response.TransactionOutput{To: "alice@example.com", Satoshis: 1},
+			allowed: true,
+		},
+		"listed address": {
+			output:  &response.TransactionOutput{To: address, Satoshis: 1},
+			allowed: true,
+		},
+		"confirmed contact": {
+			output:  &response.TransactionOutput{To: "bob@example.com", Satoshis: 1},
+			allowed: true,
+		},
+		"data output": {
+			output:  &response.TransactionOutput{OpReturn: &response.OpReturn{StringParts: []string{"hello"}}},
+			allowed: true,
+		},
+		"unconfirmed contact": {
+			output: &response.TransactionOutput{To: "carol@example.com", Satoshis: 1},
+		},
+		"unknown paymail": {
+			output: &response.TransactionOutput{To: "mallory@example.com", Satoshis: 1},
+		},
+		"unknown address": {
+			output: &response.TransactionOutput{To: otherAddress, Satoshis: 1},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given:
+			spend, err := policy.NewSpend(givenDraft(t, 0, tc.output), time.Time{})
+			require.NoError(t, err)
+
+			// when:
+			err = allowlist.Check(context.Background(), spend)
+
+			// then:
+			if tc.allowed {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, goclienterr.ErrRecipientNotAllowed)
+			}
+		})
+	}
+}
+
+// givenDraft returns a draft paying the outputs with the given fee. The outputs flagged with UseForChange
+// pay the change destinations of the draft.
+func givenDraft(t *testing.T, fee uint64, outputs ...*response.TransactionOutput) *response.DraftTransaction {
+	t.Helper()
+
+	const txID = "3c8edde27cb9a9132c22038dac4391496be9db16fd21351565cc1006966fdad5"
+	paymailScript, err := script.NewFromHex("76a914000000000000000000000000000000000000000088ac")
+	require.NoError(t, err)
+
+	tx := trx.NewTransaction()
+	config := response.TransactionConfig{Fee: fee, Outputs: outputs}
+	satoshis := fee
+	for _, output := range outputs {
+		lockingScript := paymailScript
+		switch {
+		case output.OpReturn != nil:
+			lockingScript = &script.Script{}
+			require.NoError(t, lockingScript.AppendOpcodes(script.OpFALSE, script.OpRETURN))
+			require.NoError(t, lockingScript.AppendPushData([]byte(strings.Join(output.OpReturn.StringParts, ""))))
+		case strings.Contains(output.To, "@"):
+			output.Scripts = []*response.ScriptOutput{{Satoshis: output.Satoshis, Script: lockingScript.String()}}
+		default:
+			lockingScript = givenAddressScript(t, output.To)
+		}
+		if output.UseForChange {
+			config.ChangeDestinations = append(config.ChangeDestinations, &response.Destination{LockingScript: lockingScript.String()})
+		}
+		tx.AddOutput(&trx.TransactionOutput{Satoshis: output.Satoshis, LockingScript: lockingScript})
+		satoshis += output.Satoshis
+	}
+	require.NoError(t, tx.AddInputFrom(txID, 0, paymailScript.String(), satoshis, nil))
+	config.Inputs = []*response.TransactionInput{{
+		Utxo: response.Utxo{UtxoPointer: response.UtxoPointer{TransactionID: txID, OutputIndex: 0}, Satoshis: satoshis},
+	}}
+
+	return &response.DraftTransaction{ID: "draft-1", Hex: tx.Hex(), Configuration: config}
+}
+
+func givenAddressScript(t *testing.T, address string) *script.Script {
+	t.Helper()
+	addr, err := script.NewAddressFromString(address)
+	require.NoError(t, err)
+	lockingScript, err := p2pkh.Lock(addr)
+	require.NoError(t, err)
+	return lockingScript
+}
+
+type fakeContacts map[string]*response.Contact
+
+func (f fakeContacts) ContactWithPaymail(_ context.Context, paymail string) (*response.Contact, error) {
+	contact, ok := f[paymail]
+	if !ok {
+		return nil, errors.New("contact not found")
+	}
+	return contact, nil
+}
+
+type failingWindowStore struct {
+	err error
+}
+
+func (f *failingWindowStore) Spent(context.Context, string, time.Time) (uint64, error) {
+	return 0, f.err
+}
+
+func (f *failingWindowStore) Record(context.Context, string, string, time.Time, uint64) error {
+	return f.err
+}
+
+func (f *failingWindowStore) Recorded(context.Context, string, string) (bool, error) {
+	return false, f.err
+}
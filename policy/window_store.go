@@ -0,0 +1,85 @@
+package policy
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WindowStore keeps the spends authorized by the rolling limits.
+// Implementations must be safe for concurrent use.
+type WindowStore interface {
+	// Spent returns the sum of the satoshis recorded under the key since the given time.
+	Spent(ctx context.Context, key string, since time.Time) (uint64, error)
+	// Record adds the satoshis of the draft spent at the given time under the key.
+	// Recording a draft already recorded under the key has no effect.
+	Record(ctx context.Context, key, draftID string, at time.Time, satoshis uint64) error
+	// Recorded reports whether the draft is recorded under the key.
+	Recorded(ctx context.Context, key, draftID string) (bool, error)
+}
+
+type windowEntry struct {
+	draftID  string
+	at       time.Time
+	satoshis uint64
+}
+
+// MemoryWindowStore is an in-memory implementation of the WindowStore interface.
+// It is safe for concurrent use, but the windows are reset when the process exits.
+// The entries older than the time passed to Spent are dropped, so a key must always be used with the same window.
+type MemoryWindowStore struct {
+	mu      sync.Mutex
+	entries map[string][]windowEntry
+}
+
+// NewMemoryWindowStore creates a new, empty MemoryWindowStore instance.
+func NewMemoryWindowStore() *MemoryWindowStore {
+	return &MemoryWindowStore{entries: make(map[string][]windowEntry)}
+}
+
+// Spent returns the sum of the satoshis recorded under the key since the given time.
+func (m *MemoryWindowStore) Spent(_ context.Context, key string, since time.Time) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var spent uint64
+	entries := m.entries[key][:0]
+	for _, entry := range m.entries[key] {
+		if entry.at.Before(since) {
+			continue
+		}
+		spent += entry.satoshis
+		entries = append(entries, entry)
+	}
+	m.entries[key] = entries
+	return spent, nil
+}
+
+// Record adds the satoshis of the draft spent at the given time under the key, unless the draft is already recorded.
+func (m *MemoryWindowStore) Record(_ context.Context, key, draftID string, at time.Time, satoshis uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.recorded(key, draftID) {
+		return nil
+	}
+	m.entries[key] = append(m.entries[key], windowEntry{draftID: draftID, at: at, satoshis: satoshis})
+	return nil
+}
+
+// Recorded reports whether the draft is recorded under the key.
+func (m *MemoryWindowStore) Recorded(_ context.Context, key, draftID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.recorded(key, draftID), nil
+}
+
+func (m *MemoryWindowStore) recorded(key, draftID string) bool {
+	for _, entry := range m.entries[key] {
+		if entry.draftID == draftID {
+			return true
+		}
+	}
+	return false
+}
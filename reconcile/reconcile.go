@@ -0,0 +1,320 @@
+// Package reconcile audits the balance of a wallet against its UTXOs and transaction history.
+//
+// The SPV Wallet keeps the current balance of an xPub as a separate counter, updated whenever a transaction is recorded.
+// Reconcile recomputes the balance from the unspent UTXOs and from the net value of all transactions,
+// and cross-checks the UTXOs with the transactions, so that server-side accounting bugs are caught early:
+//
+//	report, err := reconcile.Reconcile(ctx, userAPI)
+//	if err == nil && !report.OK() {
+//		alert(report.Err())
+//	}
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/queries"
+	"github.com/bitcoin-sv/spv-wallet/models/filter"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// DefaultPageSize is the default size of the UTXO and transaction pages fetched by Reconcile.
+const DefaultPageSize = 100
+
+const statusRejected = "REJECTED"
+
+// Source provides the xPub, the UTXOs and the transactions of the user. It is implemented by the *spvwallet.UserAPI.
+type Source interface {
+	XPub(ctx context.Context) (*response.Xpub, error)
+	UTXOs(ctx context.Context, opts ...queries.QueryOption[filter.UtxoFilter]) (*queries.UtxosPage, error)
+	Transactions(ctx context.Context, opts ...queries.QueryOption[filter.TransactionFilter]) (*queries.TransactionPage, error)
+}
+
+// Kind is the kind of a discrepancy.
+type Kind string
+
+// Kinds of discrepancies.
+const (
+	// KindUTXOBalanceDrift means that the current balance differs from the sum of the unspent UTXOs.
+	KindUTXOBalanceDrift Kind = "utxo_balance_drift"
+	// KindTransactionBalanceDrift means that the current balance differs from the net value of all transactions.
+	KindTransactionBalanceDrift Kind = "transaction_balance_drift"
+	// KindOrphanedUTXO means that an unspent UTXO was created by a transaction missing from the history or rejected.
+	KindOrphanedUTXO Kind = "orphaned_utxo"
+	// KindMissingTransaction means that a UTXO is spent by a transaction missing from the history.
+	KindMissingTransaction Kind = "missing_transaction"
+	// KindSpentByRejected means that a UTXO is still marked as spent by a rejected transaction.
+	KindSpentByRejected Kind = "spent_by_rejected"
+	// KindMissingUTXOs means that an incoming transaction has no UTXOs of the wallet, neither spent nor unspent.
+	KindMissingUTXOs Kind = "missing_utxos"
+)
+
+// Discrepancy is a single inconsistency found by Reconcile.
+type Discrepancy struct {
+	Kind          Kind                  `json:"kind"`
+	Message       string                `json:"message"`
+	TransactionID string                `json:"transactionId,omitempty"`
+	UTXO          *response.UtxoPointer `json:"utxo,omitempty"`
+	Expected      int64                 `json:"expected,omitempty"` // Balance drifts only: the recomputed balance.
+	Actual        int64                 `json:"actual,omitempty"`   // Balance drifts only: the current balance of the xPub.
+}
+
+// Report is the result of Reconcile.
+type Report struct {
+	XPubID              string         `json:"xpubId"`
+	CheckedAt           time.Time      `json:"checkedAt"`
+	CurrentBalance      uint64         `json:"currentBalance"`      // Balance of the xPub reported by the SPV Wallet.
+	UTXOBalance         uint64         `json:"utxoBalance"`         // Sum of the unspent UTXOs, including the ones reserved by drafts.
+	ReservedBalance     uint64         `json:"reservedBalance"`     // Part of the UTXOBalance reserved by draft transactions.
+	TransactionsBalance int64          `json:"transactionsBalance"` // Net value of all transactions which are not rejected.
+	UTXOs               int            `json:"utxos"`
+	UnspentUTXOs        int            `json:"unspentUtxos"`
+	Transactions        int            `json:"transactions"`
+	RejectedTxs         int            `json:"rejectedTransactions"`
+	Discrepancies       []*Discrepancy `json:"discrepancies"`
+}
+
+// OK reports whether no discrepancy was found.
+func (r *Report) OK() bool {
+	return len(r.Discrepancies) == 0
+}
+
+// Err returns an error wrapping goclienterr.ErrBalanceDiscrepancy which lists the kinds of the discrepancies,
+// or nil if the report is OK.
+func (r *Report) Err() error {
+	if r.OK() {
+		return nil
+	}
+
+	counts := make(map[Kind]int)
+	var kinds []Kind
+	for _, d := range r.Discrepancies {
+		if counts[d.Kind] == 0 {
+			kinds = append(kinds, d.Kind)
+		}
+		counts[d.Kind]++
+	}
+	summary := ""
+	for i, kind := range kinds {
+		if i > 0 {
+			summary += ", "
+		}
+		summary += fmt.Sprintf("%d %s", counts[kind], kind)
+	}
+	return fmt.Errorf("%w of xPub %s: %s", goclienterr.ErrBalanceDiscrepancy, r.XPubID, summary)
+}
+
+type options struct {
+	pageSize int
+	now      func() time.Time
+}
+
+// Option configures Reconcile.
+type Option func(*options)
+
+// WithPageSize sets the size of the fetched UTXO and transaction pages.
+func WithPageSize(size int) Option {
+	return func(o *options) {
+		if size > 0 {
+			o.pageSize = size
+		}
+	}
+}
+
+// WithClock sets the function returning the current time; time.Now by default.
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// transaction is the part of a transaction needed to cross-check it with the UTXOs.
+type transaction struct {
+	rejected bool
+	incoming bool
+	hasUTXOs bool
+}
+
+// Reconcile compares the current balance of the xPub with the sum of its unspent UTXOs and the net value
+// of its transactions, and cross-checks the UTXOs with the transactions. The discrepancies are reported
+// in the returned Report; the error is returned only if the data cannot be fetched.
+//
+// The balance, the UTXOs and the transactions are fetched one after another, so a transaction recorded
+// in the meantime may be reported as a discrepancy; such reports should be confirmed by a second run.
+func Reconcile(ctx context.Context, source Source, opts ...Option) (*Report, error) {
+	o := &options{pageSize: DefaultPageSize, now: time.Now}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	xPub, err := source.XPub(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch xPub: %w", err)
+	}
+	report := &Report{XPubID: xPub.ID, CheckedAt: o.now(), CurrentBalance: xPub.CurrentBalance, Discrepancies: []*Discrepancy{}}
+
+	txs := make(map[string]*transaction)
+	err = forEachTransaction(ctx, source, o.pageSize, func(tx *response.Transaction) {
+		t := &transaction{rejected: tx.Status == statusRejected, incoming: tx.OutputValue > 0}
+		txs[tx.ID] = t
+		report.Transactions++
+		if t.rejected {
+			report.RejectedTxs++
+			return
+		}
+		report.TransactionsBalance += tx.OutputValue
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = forEachUTXO(ctx, source, o.pageSize, func(utxo *response.Utxo) {
+		report.UTXOs++
+		origin, known := txs[utxo.TransactionID]
+		if known {
+			origin.hasUTXOs = true
+		}
+
+		if utxo.SpendingTxID != "" {
+			report.checkSpending(utxo, txs[utxo.SpendingTxID])
+			return
+		}
+		report.UnspentUTXOs++
+		report.UTXOBalance += utxo.Satoshis
+		if utxo.DraftID != "" {
+			report.ReservedBalance += utxo.Satoshis
+		}
+		if !known || origin.rejected {
+			report.add(&Discrepancy{
+				Kind:          KindOrphanedUTXO,
+				Message:       fmt.Sprintf("unspent UTXO of %d satoshis created by %s transaction", utxo.Satoshis, describe(origin)),
+				TransactionID: utxo.TransactionID,
+				UTXO:          pointer(utxo),
+			})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.checkIncoming(txs)
+	report.checkBalances()
+	return report, nil
+}
+
+func (r *Report) checkSpending(utxo *response.Utxo, spending *transaction) {
+	switch {
+	case spending == nil:
+		r.add(&Discrepancy{
+			Kind:          KindMissingTransaction,
+			Message:       fmt.Sprintf("UTXO is spent by transaction %s missing from the history", utxo.SpendingTxID),
+			TransactionID: utxo.SpendingTxID,
+			UTXO:          pointer(utxo),
+		})
+	case spending.rejected:
+		r.add(&Discrepancy{
+			Kind:          KindSpentByRejected,
+			Message:       fmt.Sprintf("UTXO of %d satoshis is marked as spent by rejected transaction %s", utxo.Satoshis, utxo.SpendingTxID),
+			TransactionID: utxo.SpendingTxID,
+			UTXO:          pointer(utxo),
+		})
+	}
+}
+
+func (r *Report) checkIncoming(txs map[string]*transaction) {
+	ids := make([]string, 0, len(txs))
+	for id := range txs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if tx := txs[id]; tx.incoming && !tx.rejected && !tx.hasUTXOs {
+			r.add(&Discrepancy{
+				Kind:          KindMissingUTXOs,
+				Message:       "incoming transaction has no UTXOs",
+				TransactionID: id,
+			})
+		}
+	}
+}
+
+func (r *Report) checkBalances() {
+	current, utxos := int64(r.CurrentBalance), int64(r.UTXOBalance) //nolint: gosec // balances are far below the int64 range
+	if utxos != current {
+		r.add(&Discrepancy{
+			Kind:     KindUTXOBalanceDrift,
+			Message:  fmt.Sprintf("current balance differs from the unspent UTXOs by %d satoshis", current-utxos),
+			Expected: utxos,
+			Actual:   current,
+		})
+	}
+	if r.TransactionsBalance != current {
+		r.add(&Discrepancy{
+			Kind:     KindTransactionBalanceDrift,
+			Message:  fmt.Sprintf("current balance differs from the net value of the transactions by %d satoshis", current-r.TransactionsBalance),
+			Expected: r.TransactionsBalance,
+			Actual:   current,
+		})
+	}
+}
+
+func (r *Report) add(d *Discrepancy) {
+	r.Discrepancies = append(r.Discrepancies, d)
+}
+
+func describe(tx *transaction) string {
+	if tx == nil {
+		return "missing"
+	}
+	return "rejected"
+}
+
+func pointer(utxo *response.Utxo) *response.UtxoPointer {
+	p := utxo.UtxoPointer
+	return &p
+}
+
+func forEachTransaction(ctx context.Context, source Source, pageSize int, fn func(tx *response.Transaction)) error {
+	for number := 1; ; number++ {
+		page, err := source.Transactions(ctx,
+			queries.QueryWithPageFilter[filter.TransactionFilter](filter.Page{Number: number, Size: pageSize, SortBy: "created_at", Sort: "asc"}),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to fetch transactions page %d: %w", number, err)
+		}
+
+		for _, tx := range page.Content {
+			if tx != nil {
+				fn(tx)
+			}
+		}
+		if len(page.Content) < pageSize || number >= page.Page.TotalPages {
+			return nil
+		}
+	}
+}
+
+func forEachUTXO(ctx context.Context, source Source, pageSize int, fn func(utxo *response.Utxo)) error {
+	for number := 1; ; number++ {
+		page, err := source.UTXOs(ctx,
+			queries.QueryWithPageFilter[filter.UtxoFilter](filter.Page{Number: number, Size: pageSize, SortBy: "created_at", Sort: "asc"}),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to fetch UTXOs page %d: %w", number, err)
+		}
+
+		for _, utxo := range page.Content {
+			if utxo != nil {
+				fn(utxo)
+			}
+		}
+		if len(page.Content) < pageSize || number >= page.Page.TotalPages {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package reconcile_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/queries"
+	"github.com/bitcoin-sv/spv-wallet-go-client/reconcile"
+	"github.com/bitcoin-sv/spv-wallet/models/filter"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2024, 11, 20, 2, 0, 0, 0, time.UTC)
+
+func TestReconcile(t *testing.T) {
+	t.Run("Reconcile of consistent wallet", func(t *testing.T) {
+		// given:
+		source := givenConsistentWallet()
+
+		// when:
+		report, err := reconcile.Reconcile(context.Background(), source, reconcile.WithPageSize(2), reconcile.WithClock(func() time.Time { return now }))
+
+		// then:
+		require.NoError(t, err)
+		require.True(t, report.OK())
+		require.NoError(t, report.Err())
+		require.Equal(t, &reconcile.Report{
+			XPubID:              "xpub-1",
+			CheckedAt:           now,
+			CurrentBalance:      699,
+			UTXOBalance:         699,
+			ReservedBalance:     200,
+			TransactionsBalance: 699,
+			UTXOs:               4,
+			UnspentUTXOs:        2,
+			Transactions:        4,
+			RejectedTxs:         1,
+			Discrepancies:       []*reconcile.Discrepancy{},
+		}, report)
+	})
+
+	t.Run("Reconcile of balance drift", func(t *testing.T) {
+		// given:
+		source := givenConsistentWallet()
+		source.xPub.CurrentBalance = 799
+
+		// when:
+		report, err := reconcile.Reconcile(context.Background(), source)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, []*reconcile.Discrepancy{
+			{
+				Kind:     reconcile.KindUTXOBalanceDrift,
+				Message:  "current balance differs from the unspent UTXOs by 100 satoshis",
+				Expected: 699,
+				Actual:   799,
+			},
+			{
+				Kind:     reconcile.KindTransactionBalanceDrift,
+				Message:  "current balance differs from the net value of the transactions by 100 satoshis",
+				Expected: 699,
+				Actual:   799,
+			},
+		}, report.Discrepancies)
+		require.ErrorIs(t, report.Err(), goclienterr.ErrBalanceDiscrepancy)
+		require.EqualError(t, report.Err(), "balance discrepancy of xPub xpub-1: 1 utxo_balance_drift, 1 transaction_balance_drift")
+	})
+
+	t.Run("Reconcile of inconsistent UTXOs", func(t *testing.T) {
+		// given:
+		source := givenConsistentWallet()
+		source.utxos = append(source.utxos,
+			givenUTXO("tx-unknown", 0, 50, "", ""),
+			givenUTXO("tx-rejected", 1, 60, "", ""),
+			givenUTXO("tx-in", 2, 70, "tx-spending-unknown", ""),
+			givenUTXO("tx-in", 3, 80, "tx-rejected", ""),
+		)
+		source.transactions = append(source.transactions, givenTransaction("tx-in-lost", "MINED", 10))
+
+		// when:
+		report, err := reconcile.Reconcile(context.Background(), source)
+
+		// then:
+		require.NoError(t, err)
+		kinds := make([]reconcile.Kind, len(report.Discrepancies))
+		for i, d := range report.Discrepancies {
+			kinds[i] = d.Kind
+		}
+		require.Equal(t, []reconcile.Kind{
+			reconcile.KindOrphanedUTXO,
+			reconcile.KindOrphanedUTXO,
+			reconcile.KindMissingTransaction,
+			reconcile.KindSpentByRejected,
+			reconcile.KindMissingUTXOs,
+			reconcile.KindUTXOBalanceDrift,
+			reconcile.KindTransactionBalanceDrift,
+		}, kinds)
+		require.Equal(t, &response.UtxoPointer{TransactionID: "tx-unknown", OutputIndex: 0}, report.Discrepancies[0].UTXO)
+		require.Equal(t, "unspent UTXO of 50 satoshis created by missing transaction", report.Discrepancies[0].Message)
+		require.Equal(t, "unspent UTXO of 60 satoshis created by rejected transaction", report.Discrepancies[1].Message)
+		require.Equal(t, "tx-spending-unknown", report.Discrepancies[2].TransactionID)
+		require.Equal(t, "tx-in-lost", report.Discrepancies[4].TransactionID)
+	})
+
+	t.Run("Reconcile with failing source", func(t *testing.T) {
+		// given:
+		source := givenConsistentWallet()
+		source.err = errors.New("unavailable")
+
+		// when:
+		report, err := reconcile.Reconcile(context.Background(), source)
+
+		// then:
+		require.ErrorIs(t, err, source.err)
+		require.Nil(t, report)
+	})
+}
+
+// givenConsistentWallet returns a wallet which received 1000 satoshis and sent 300 satoshis with a fee of 1,
+// leaving the change of 499 and another incoming UTXO of 200 reserved by a draft.
+func givenConsistentWallet() *fakeSource {
+	return &fakeSource{
+		xPub: &response.Xpub{ID: "xpub-1", CurrentBalance: 699},
+		transactions: []*response.Transaction{
+			givenTransaction("tx-in", "MINED", 800),
+			givenTransaction("tx-out", "MINED", -301),
+			givenTransaction("tx-rejected", "REJECTED", -500),
+			givenTransaction("tx-in-2", "SEEN_ON_NETWORK", 200),
+		},
+		utxos: []*response.Utxo{
+			givenUTXO("tx-in", 0, 500, "tx-out", ""),
+			givenUTXO("tx-in", 1, 300, "tx-out", ""),
+			givenUTXO("tx-out", 1, 499, "", ""),
+			givenUTXO("tx-in-2", 0, 200, "", "draft-1"),
+		},
+	}
+}
+
+func givenTransaction(id, status string, value int64) *response.Transaction {
+	return &response.Transaction{ID: id, Status: status, OutputValue: value}
+}
+
+func givenUTXO(txID string, index uint32, satoshis uint64, spendingTxID, draftID string) *response.Utxo {
+	return &response.Utxo{
+		UtxoPointer:  response.UtxoPointer{TransactionID: txID, OutputIndex: index},
+		Satoshis:     satoshis,
+		SpendingTxID: spendingTxID,
+		DraftID:      draftID,
+	}
+}
+
+type fakeSource struct {
+	xPub         *response.Xpub
+	transactions []*response.Transaction
+	utxos        []*response.Utxo
+	err          error
+}
+
+func (f *fakeSource) XPub(context.Context) (*response.Xpub, error) {
+	return f.xPub, nil
+}
+
+func (f *fakeSource) UTXOs(_ context.Context, opts ...queries.QueryOption[filter.UtxoFilter]) (*queries.UtxosPage, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	page := queries.NewQuery(opts...).PageFilter
+	res := &queries.UtxosPage{Content: paginate(f.utxos, page)}
+	res.Page.TotalPages = (len(f.utxos) + page.Size - 1) / page.Size
+	return res, nil
+}
+
+func (f *fakeSource) Transactions(_ context.Context, opts ...queries.QueryOption[filter.TransactionFilter]) (*queries.TransactionPage, error) {
+	page := queries.NewQuery(opts...).PageFilter
+	res := &queries.TransactionPage{Content: paginate(f.transactions, page)}
+	res.Page.TotalPages = (len(f.transactions) + page.Size - 1) / page.Size
+	return res, nil
+}
+
+func paginate[T any](items []T, page filter.Page) []T {
+	start := min((page.Number-1)*page.Size, len(items))
+	return items[start:min(start+page.Size, len(items))]
+}
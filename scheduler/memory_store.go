@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// MemoryStore is an in-memory implementation of the Store interface.
+// It is safe for concurrent use, but jobs are lost when the process exits.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	jobs map[string]Job
+}
+
+// NewMemoryStore creates a new, empty MemoryStore instance.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]Job)}
+}
+
+// Jobs returns copies of all stored jobs, ordered by ID.
+func (m *MemoryStore) Jobs(_ context.Context) ([]*Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	jobs := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, &job)
+	}
+	slices.SortFunc(jobs, func(a, b *Job) int { return strings.Compare(a.ID, b.ID) })
+	return jobs, nil
+}
+
+// SaveJob stores a copy of the job, replacing any previous job with the same ID.
+func (m *MemoryStore) SaveJob(_ context.Context, job *Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.jobs[job.ID] = *job
+	return nil
+}
+
+// DeleteJob removes the job with the given ID.
+func (m *MemoryStore) DeleteJob(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.jobs, id)
+	return nil
+}
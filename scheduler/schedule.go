@@ -0,0 +1,196 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+)
+
+// Schedule describes when a job runs. A schedule with neither Interval nor Cron set runs once, at At.
+// Recurring schedules run every Interval starting at At, or at the times matching the Cron expression
+// (evaluated in UTC) which are not earlier than At.
+type Schedule struct {
+	At       time.Time     `json:"at"`                 // Time of the one-off run or the start of the recurring schedule.
+	Interval time.Duration `json:"interval,omitempty"` // Interval between the runs of an interval-based schedule.
+	Cron     string        `json:"cron,omitempty"`     // Standard 5-field cron expression: minute, hour, day of month, month, day of week.
+}
+
+// Validate checks that the schedule is consistent and its cron expression, if any, is valid.
+func (s Schedule) Validate() error {
+	switch {
+	case s.Interval < 0:
+		return fmt.Errorf("%w: negative interval %s", goclienterr.ErrInvalidSchedule, s.Interval)
+	case s.Interval > 0 && s.Cron != "":
+		return fmt.Errorf("%w: interval and cron expression are mutually exclusive", goclienterr.ErrInvalidSchedule)
+	case s.Cron != "":
+		_, err := parseCron(s.Cron)
+		return err
+	case s.At.IsZero():
+		return fmt.Errorf("%w: time of the one-off run is required", goclienterr.ErrInvalidSchedule)
+	default:
+		return nil
+	}
+}
+
+// Recurring reports whether the schedule has more than one run.
+func (s Schedule) Recurring() bool {
+	return s.Interval > 0 || s.Cron != ""
+}
+
+// First returns the time of the first run of the schedule.
+func (s Schedule) First() (time.Time, bool) {
+	return s.next(s.At, true)
+}
+
+// Next returns the time of the first run strictly after the given time.
+// It returns false if the schedule has no more runs.
+func (s Schedule) Next(after time.Time) (time.Time, bool) {
+	return s.next(after, false)
+}
+
+func (s Schedule) next(t time.Time, inclusive bool) (time.Time, bool) {
+	switch {
+	case s.Interval > 0:
+		if t.Before(s.At) || (inclusive && t.Equal(s.At)) {
+			return s.At, true
+		}
+		n := t.Sub(s.At)/s.Interval + 1
+		return s.At.Add(n * s.Interval), true
+	case s.Cron != "":
+		expr, err := parseCron(s.Cron)
+		if err != nil {
+			return time.Time{}, false
+		}
+		if t.Before(s.At) {
+			t, inclusive = s.At, true
+		}
+		return expr.next(t, inclusive)
+	default:
+		if t.Before(s.At) || (inclusive && t.Equal(s.At)) {
+			return s.At, true
+		}
+		return time.Time{}, false
+	}
+}
+
+// cronExpr holds the allowed values of the cron fields as bit sets.
+type cronExpr struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+func parseCron(s string) (*cronExpr, error) {
+	fields := strings.Fields(s)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("%w: cron expression %q must have %d fields", goclienterr.ErrInvalidSchedule, s, len(cronFields))
+	}
+
+	sets := make([]uint64, len(fields))
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i].min, cronFields[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cron %s field %q: %w", goclienterr.ErrInvalidSchedule, cronFields[i].name, field, err)
+		}
+		sets[i] = set
+	}
+
+	return &cronExpr{
+		minute: sets[0], hour: sets[1], dom: sets[2], month: sets[3], dow: sets[4],
+		domAny: strings.HasPrefix(fields[2], "*"), dowAny: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parseCronField parses a comma-separated list of values, ranges (a-b) and steps (*/n or a-b/n).
+func parseCronField(field string, low, high int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+		}
+
+		from, to := low, high
+		if rng != "*" {
+			fromStr, toStr, isRange := strings.Cut(rng, "-")
+			var err error
+			if from, err = strconv.Atoi(fromStr); err != nil {
+				return 0, fmt.Errorf("invalid value %q", fromStr)
+			}
+			to = from
+			if isRange {
+				if to, err = strconv.Atoi(toStr); err != nil {
+					return 0, fmt.Errorf("invalid value %q", toStr)
+				}
+			} else if hasStep {
+				to = high
+			}
+		}
+		if from < low || to > high || from > to {
+			return 0, fmt.Errorf("value out of range %d-%d", low, high)
+		}
+
+		for v := from; v <= to; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// cronSearchLimit bounds the search for the next matching time of expressions which never match, e.g., 30 February.
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+func (c *cronExpr) next(t time.Time, inclusive bool) (time.Time, bool) {
+	t = t.UTC()
+	if !inclusive || t.Second() != 0 || t.Nanosecond() != 0 {
+		t = t.Truncate(time.Minute).Add(time.Minute)
+	}
+
+	limit := t.Add(cronSearchLimit)
+	for t.Before(limit) {
+		switch {
+		case !has(c.month, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !has(c.hour, t.Hour()):
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case !has(c.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// dayMatches follows the cron convention: if both the day of month and the day of week are restricted,
+// the day matches either of them.
+func (c *cronExpr) dayMatches(t time.Time) bool {
+	dom, dow := has(c.dom, t.Day()), has(c.dow, int(t.Weekday()))
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+func has(set uint64, v int) bool {
+	return set&(1<<uint(v)) != 0
+}
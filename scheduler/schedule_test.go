@@ -0,0 +1,98 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/scheduler"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule_Next(t *testing.T) {
+	start := time.Date(2025, time.January, 31, 10, 30, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		schedule scheduler.Schedule
+		after    time.Time
+		expected time.Time
+		done     bool
+	}{
+		"one-off before the run": {
+			schedule: scheduler.Schedule{At: start},
+			after:    start.Add(-time.Second),
+			expected: start,
+		},
+		"one-off after the run": {
+			schedule: scheduler.Schedule{At: start},
+			after:    start,
+			done:     true,
+		},
+		"interval": {
+			schedule: scheduler.Schedule{At: start, Interval: time.Hour},
+			after:    start.Add(90 * time.Minute),
+			expected: start.Add(2 * time.Hour),
+		},
+		"cron every day at noon": {
+			schedule: scheduler.Schedule{At: start, Cron: "0 12 * * *"},
+			after:    start,
+			expected: time.Date(2025, time.January, 31, 12, 0, 0, 0, time.UTC),
+		},
+		"cron on the first day of the month": {
+			schedule: scheduler.Schedule{At: start, Cron: "0 0 1 * *"},
+			after:    start,
+			expected: time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"cron every 15 minutes on weekdays": {
+			schedule: scheduler.Schedule{At: start, Cron: "*/15 9-17 * * 1-5"},
+			after:    time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC), // Saturday
+			expected: time.Date(2025, time.February, 3, 9, 0, 0, 0, time.UTC),
+		},
+		"cron with day of month or day of week": {
+			schedule: scheduler.Schedule{At: start, Cron: "0 0 15 * 0"},
+			after:    start,
+			expected: time.Date(2025, time.February, 2, 0, 0, 0, 0, time.UTC), // Sunday
+		},
+		"cron with a stepped day of month restricted by day of week": {
+			schedule: scheduler.Schedule{At: start, Cron: "0 0 */2 * 1"},
+			after:    start,
+			expected: time.Date(2025, time.February, 3, 0, 0, 0, 0, time.UTC), // Monday
+		},
+		"cron starts at the schedule start": {
+			schedule: scheduler.Schedule{At: start, Cron: "30 10 * * *"},
+			after:    start.Add(-48 * time.Hour),
+			expected: start,
+		},
+		"cron which never matches": {
+			schedule: scheduler.Schedule{At: start, Cron: "0 0 30 2 *"},
+			after:    start,
+			done:     true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// when:
+			next, ok := tc.schedule.Next(tc.after)
+
+			// then:
+			require.Equal(t, !tc.done, ok)
+			require.Equal(t, tc.expected, next)
+		})
+	}
+}
+
+func TestSchedule_Validate(t *testing.T) {
+	tests := map[string]scheduler.Schedule{
+		"one-off without time":      {},
+		"negative interval":         {At: time.Now(), Interval: -time.Minute},
+		"interval and cron":         {At: time.Now(), Interval: time.Minute, Cron: "* * * * *"},
+		"cron with too few fields":  {Cron: "* * * *"},
+		"cron with value too large": {Cron: "60 * * * *"},
+		"cron with invalid step":    {Cron: "*/0 * * * *"},
+	}
+	for name, schedule := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, schedule.Validate(), goclienterr.ErrInvalidSchedule)
+		})
+	}
+}
@@ -0,0 +1,323 @@
+// Package scheduler runs one-off and recurring payments made with the SPV Wallet client.
+//
+// A Job describes the recipients of a payment and the Schedule of its runs: a single time, a fixed interval
+// or a cron expression. Jobs are persisted in a pluggable Store, and every run is sent as an idempotent
+// payment (see the payments package) whose payment ID is derived from the job ID and the scheduled time
+// of the run. A scheduler restarted after a crash therefore resumes the interrupted run instead of
+// repeating the payment.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v1/queryparams"
+	"github.com/bitcoin-sv/spv-wallet-go-client/payments"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// Transaction metadata keys set on every scheduled payment.
+const (
+	// MetadataJobIDKey is the key of the ID of the job which made the payment.
+	MetadataJobIDKey = "scheduled_job_id"
+
+	// MetadataRunAtKey is the key of the scheduled time of the run, in RFC 3339 format.
+	MetadataRunAtKey = "scheduled_run_at"
+)
+
+// Defaults of the Scheduler options.
+const (
+	DefaultPollInterval   = time.Minute
+	DefaultGracePeriod    = 5 * time.Minute
+	DefaultMaxCatchUpRuns = 100
+)
+
+// MissedRunPolicy defines how the runs which were due while the scheduler wasn't running are handled.
+type MissedRunPolicy string
+
+// Missed run policies.
+const (
+	// MissedRunSkip skips the runs which are overdue by more than the grace period.
+	MissedRunSkip MissedRunPolicy = "skip"
+
+	// MissedRunCatchUp executes all overdue runs, the oldest first.
+	MissedRunCatchUp MissedRunPolicy = "catch_up"
+)
+
+// Sender sends idempotent payments. It is implemented by the *spvwallet.UserAPI.
+type Sender interface {
+	SendToRecipientsIdempotent(ctx context.Context, cmd *commands.IdempotentSendToRecipients, store payments.Store) (*response.Transaction, error)
+}
+
+// Job is a scheduled payment.
+type Job struct {
+	ID         string                 `json:"id"`                  // Unique identifier of the job.
+	Schedule   Schedule               `json:"schedule"`            // Schedule of the runs.
+	Recipients []*commands.Recipients `json:"recipients"`          // Recipients paid by every run, e.g., paymails of the user contacts.
+	Metadata   queryparams.Metadata   `json:"metadata"`            // Metadata associated with every payment of the job.
+	MissedRuns MissedRunPolicy        `json:"missedRuns"`          // Policy of the missed runs; MissedRunSkip if not set.
+	NextRun    time.Time              `json:"nextRun"`             // Scheduled time of the next run; zero if the job is done.
+	LastRun    time.Time              `json:"lastRun"`             // Scheduled time of the last completed run.
+	LastTxID   string                 `json:"lastTxId,omitempty"`  // ID of the transaction of the last completed run.
+	LastError  string                 `json:"lastError,omitempty"` // Error of the last failed attempt, cleared by the next completed run.
+}
+
+// Done reports whether the job has no more runs.
+func (j *Job) Done() bool {
+	return j.NextRun.IsZero()
+}
+
+// Store persists the scheduled jobs.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Jobs returns all stored jobs.
+	Jobs(ctx context.Context) ([]*Job, error)
+	// SaveJob creates or replaces the job with the same ID.
+	SaveJob(ctx context.Context, job *Job) error
+	// DeleteJob removes the job with the given ID. Deleting a missing job is not an error.
+	DeleteJob(ctx context.Context, id string) error
+}
+
+// Run is the outcome of a single run of a job.
+type Run struct {
+	JobID       string                // ID of the job.
+	ScheduledAt time.Time             // Scheduled time of the run.
+	PaymentID   string                // Payment ID of the run; empty if the run was skipped.
+	Skipped     bool                  // True if the run was skipped by the MissedRunSkip policy.
+	Transaction *response.Transaction // Transaction of the payment; nil if the run failed or was skipped.
+	Err         error                 // Error of the run; nil if the run completed or was skipped.
+}
+
+// Scheduler executes the due runs of the stored jobs.
+type Scheduler struct {
+	sender         Sender
+	jobs           Store
+	payments       payments.Store
+	now            func() time.Time
+	pollInterval   time.Duration
+	gracePeriod    time.Duration
+	maxCatchUpRuns int
+	onRun          func(Run)
+	onError        func(error)
+}
+
+// Option configures the Scheduler.
+type Option func(*Scheduler)
+
+// WithClock sets the function returning the current time; time.Now by default.
+func WithClock(now func() time.Time) Option {
+	return func(s *Scheduler) {
+		s.now = now
+	}
+}
+
+// WithPollInterval sets how often Start checks for due runs.
+func WithPollInterval(d time.Duration) Option {
+	return func(s *Scheduler) {
+		if d > 0 {
+			s.pollInterval = d
+		}
+	}
+}
+
+// WithGracePeriod sets how late a run may be executed under the MissedRunSkip policy.
+func WithGracePeriod(d time.Duration) Option {
+	return func(s *Scheduler) {
+		if d >= 0 {
+			s.gracePeriod = d
+		}
+	}
+}
+
+// WithMaxCatchUpRuns limits the number of overdue runs of a job executed by a single check
+// under the MissedRunCatchUp policy. The remaining runs are executed by the following checks.
+func WithMaxCatchUpRuns(n int) Option {
+	return func(s *Scheduler) {
+		if n > 0 {
+			s.maxCatchUpRuns = n
+		}
+	}
+}
+
+// WithRunHook sets the function called by Start after every run, e.g., to log its outcome.
+func WithRunHook(hook func(Run)) Option {
+	return func(s *Scheduler) {
+		s.onRun = hook
+	}
+}
+
+// WithErrorHook sets the function called by Start when a check fails, e.g., because the store is unavailable.
+// The failed check is repeated after the poll interval.
+func WithErrorHook(hook func(error)) Option {
+	return func(s *Scheduler) {
+		s.onError = hook
+	}
+}
+
+// New creates a new Scheduler sending the payments of the jobs stored in the jobs store.
+// The progress of every payment is persisted in the payments store.
+func New(sender Sender, jobs Store, paymentsStore payments.Store, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		sender:         sender,
+		jobs:           jobs,
+		payments:       paymentsStore,
+		now:            time.Now,
+		pollInterval:   DefaultPollInterval,
+		gracePeriod:    DefaultGracePeriod,
+		maxCatchUpRuns: DefaultMaxCatchUpRuns,
+		onRun:          func(Run) {},
+		onError:        func(error) {},
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Add validates the job, schedules its first run and saves it in the store, replacing any job with the same ID.
+// A recurring schedule without the start time starts when the job is added.
+func (s *Scheduler) Add(ctx context.Context, job *Job) error {
+	if job.ID == "" {
+		return fmt.Errorf("%w: job ID is required", goclienterr.ErrInvalidSchedule)
+	}
+	if len(job.Recipients) == 0 {
+		return fmt.Errorf("%w: job %s has no recipients", goclienterr.ErrInvalidSchedule, job.ID)
+	}
+	if job.Schedule.Recurring() && job.Schedule.At.IsZero() {
+		job.Schedule.At = s.now()
+	}
+	if err := job.Schedule.Validate(); err != nil {
+		return err
+	}
+
+	first, ok := job.Schedule.First()
+	if !ok {
+		return fmt.Errorf("%w: schedule of job %s has no runs", goclienterr.ErrInvalidSchedule, job.ID)
+	}
+	job.NextRun = first
+	return s.saveJob(ctx, job)
+}
+
+// Remove deletes the job, so that it has no more runs.
+func (s *Scheduler) Remove(ctx context.Context, id string) error {
+	if err := s.jobs.DeleteJob(ctx, id); err != nil {
+		return fmt.Errorf("%w: %w", goclienterr.ErrSchedulerStore, err)
+	}
+	return nil
+}
+
+// RunDue executes the runs of all jobs which are due at the current time and returns their outcomes.
+// A failed run is retried by the next call; the runs of a job following the failed one wait until it completes.
+func (s *Scheduler) RunDue(ctx context.Context) ([]Run, error) {
+	jobs, err := s.jobs.Jobs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrSchedulerStore, err)
+	}
+
+	var runs []Run
+	for _, job := range jobs {
+		jobRuns, err := s.runJob(ctx, job)
+		runs = append(runs, jobRuns...)
+		if err != nil {
+			return runs, err
+		}
+	}
+	return runs, nil
+}
+
+// Start checks for due runs every poll interval until the context is canceled, and returns the context's error.
+// The outcome of every run is passed to the run hook. A failed check, e.g., because the store is temporarily
+// unavailable, doesn't stop the scheduler; its error is passed to the error hook.
+func (s *Scheduler) Start(ctx context.Context) error {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		runs, err := s.RunDue(ctx)
+		for _, run := range runs {
+			s.onRun(run)
+		}
+		if err != nil && ctx.Err() == nil {
+			s.onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runJob(ctx context.Context, job *Job) ([]Run, error) {
+	var runs []Run
+	for paid := 0; !job.Done() && paid < s.maxCatchUpRuns; {
+		now := s.now()
+		if job.NextRun.After(now) {
+			break
+		}
+
+		run := Run{JobID: job.ID, ScheduledAt: job.NextRun}
+		if job.MissedRuns != MissedRunCatchUp && now.Sub(job.NextRun) > s.gracePeriod {
+			// All runs overdue by more than the grace period are skipped at once.
+			run.Skipped = true
+			runs = append(runs, run)
+			job.NextRun, _ = job.Schedule.Next(now.Add(-s.gracePeriod))
+			if err := s.saveJob(ctx, job); err != nil {
+				return runs, err
+			}
+			continue
+		}
+
+		run.Transaction, run.PaymentID, run.Err = s.pay(ctx, job)
+		runs = append(runs, run)
+		if run.Err != nil {
+			job.LastError = run.Err.Error()
+			return runs, s.saveJob(ctx, job)
+		}
+		paid++
+
+		job.LastRun = job.NextRun
+		job.LastTxID = run.Transaction.ID
+		job.LastError = ""
+		job.NextRun, _ = job.Schedule.Next(job.NextRun)
+		if err := s.saveJob(ctx, job); err != nil {
+			return runs, err
+		}
+	}
+	return runs, nil
+}
+
+// pay sends the payment of the job's next run. The payment ID identifies the run, so a run interrupted
+// before the job was saved is completed, not repeated, by the next attempt.
+func (s *Scheduler) pay(ctx context.Context, job *Job) (*response.Transaction, string, error) {
+	runAt := job.NextRun.UTC().Format(time.RFC3339Nano)
+	metadata := make(queryparams.Metadata, len(job.Metadata)+2)
+	maps.Copy(metadata, job.Metadata)
+	metadata[MetadataJobIDKey] = job.ID
+	metadata[MetadataRunAtKey] = runAt
+
+	cmd := &commands.IdempotentSendToRecipients{
+		PaymentID: fmt.Sprintf("%s@%s", job.ID, runAt),
+		SendToRecipients: commands.SendToRecipients{
+			Recipients: job.Recipients,
+			Metadata:   metadata,
+		},
+	}
+	tx, err := s.sender.SendToRecipientsIdempotent(ctx, cmd, s.payments)
+	if err != nil {
+		return nil, cmd.PaymentID, fmt.Errorf("failed to pay run %s of job %s: %w", runAt, job.ID, err)
+	}
+	return tx, cmd.PaymentID, nil
+}
+
+func (s *Scheduler) saveJob(ctx context.Context, job *Job) error {
+	if err := s.jobs.SaveJob(ctx, job); err != nil {
+		return fmt.Errorf("%w: %w", goclienterr.ErrSchedulerStore, err)
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/payments"
+	"github.com/bitcoin-sv/spv-wallet-go-client/scheduler"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler_RunDue(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	newJob := func(policy scheduler.MissedRunPolicy) *scheduler.Job {
+		return &scheduler.Job{
+			ID:         "rent",
+			Schedule:   scheduler.Schedule{At: start, Interval: 24 * time.Hour},
+			Recipients: []*commands.Recipients{{To: "landlord@example.com", Satoshis: 1000}},
+			MissedRuns: policy,
+		}
+	}
+
+	t.Run("RunDue pays the due run once", func(t *testing.T) {
+		// given:
+		clock := &fakeClock{now: start}
+		sender := &fakeSender{}
+		s := scheduler.New(sender, scheduler.NewMemoryStore(), payments.NewMemoryStore(), scheduler.WithClock(clock.Now))
+		require.NoError(t, s.Add(context.Background(), newJob("")))
+
+		// when:
+		runs, err := s.RunDue(context.Background())
+
+		// then:
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		require.Equal(t, "rent@2025-01-01T00:00:00Z", runs[0].PaymentID)
+		require.Equal(t, "rent", sender.sent[0].Metadata[scheduler.MetadataJobIDKey])
+
+		// when:
+		runs, err = s.RunDue(context.Background())
+
+		// then:
+		require.NoError(t, err)
+		require.Empty(t, runs)
+		require.Len(t, sender.sent, 1)
+	})
+
+	t.Run("RunDue skips missed runs", func(t *testing.T) {
+		// given:
+		clock := &fakeClock{now: start}
+		sender := &fakeSender{}
+		store := scheduler.NewMemoryStore()
+		s := scheduler.New(sender, store, payments.NewMemoryStore(), scheduler.WithClock(clock.Now))
+		require.NoError(t, s.Add(context.Background(), newJob(scheduler.MissedRunSkip)))
+		clock.now = start.Add(72*time.Hour + time.Minute)
+
+		// when:
+		runs, err := s.RunDue(context.Background())
+
+		// then:
+		require.NoError(t, err)
+		require.Len(t, runs, 2)
+		require.True(t, runs[0].Skipped)
+		require.Equal(t, start.Add(72*time.Hour), runs[1].ScheduledAt)
+		require.Len(t, sender.sent, 1)
+
+		jobs, err := store.Jobs(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, start.Add(96*time.Hour), jobs[0].NextRun)
+	})
+
+	t.Run("RunDue catches up missed runs", func(t *testing.T) {
+		// given:
+		clock := &fakeClock{now: start}
+		sender := &fakeSender{}
+		s := scheduler.New(sender, scheduler.NewMemoryStore(), payments.NewMemoryStore(), scheduler.WithClock(clock.Now))
+		require.NoError(t, s.Add(context.Background(), newJob(scheduler.MissedRunCatchUp)))
+		clock.now = start.Add(72*time.Hour + time.Minute)
+
+		// when:
+		runs, err := s.RunDue(context.Background())
+
+		// then:
+		require.NoError(t, err)
+		require.Len(t, runs, 4)
+		require.Len(t, sender.sent, 4)
+	})
+
+	t.Run("RunDue after a restart doesn't repeat the payment", func(t *testing.T) {
+		// given:
+		clock := &fakeClock{now: start}
+		sender := &fakeSender{}
+		paymentsStore := payments.NewMemoryStore()
+		jobs := &failingSaveStore{MemoryStore: scheduler.NewMemoryStore()}
+		s := scheduler.New(sender, jobs, paymentsStore, scheduler.WithClock(clock.Now))
+		require.NoError(t, s.Add(context.Background(), newJob("")))
+		jobs.fail = true
+
+		// when:
+		runs, err := s.RunDue(context.Background())
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrSchedulerStore)
+		require.NotNil(t, runs[0].Transaction)
+
+		// when:
+		jobs.fail = false
+		s = scheduler.New(sender, jobs, paymentsStore, scheduler.WithClock(clock.Now))
+		runs, err = s.RunDue(context.Background())
+
+		// then:
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		require.Len(t, sender.sent, 1)
+	})
+
+	t.Run("RunDue retries the failed run", func(t *testing.T) {
+		// given:
+		clock := &fakeClock{now: start}
+		sender := &fakeSender{err: errors.New("payment failed")}
+		store := scheduler.NewMemoryStore()
+		s := scheduler.New(sender, store, payments.NewMemoryStore(), scheduler.WithClock(clock.Now))
+		require.NoError(t, s.Add(context.Background(), &scheduler.Job{
+			ID:         "invoice",
+			Schedule:   scheduler.Schedule{At: start},
+			Recipients: []*commands.Recipients{{To: "bob@example.com", Satoshis: 1000}},
+		}))
+
+		// when:
+		runs, err := s.RunDue(context.Background())
+
+		// then:
+		require.NoError(t, err)
+		require.ErrorIs(t, runs[0].Err, sender.err)
+		jobs, err := store.Jobs(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "failed to pay run 2025-01-01T00:00:00Z of job invoice: payment failed", jobs[0].LastError)
+
+		// when:
+		sender.err = nil
+		runs, err = s.RunDue(context.Background())
+
+		// then:
+		require.NoError(t, err)
+		require.NoError(t, runs[0].Err)
+		jobs, err = store.Jobs(context.Background())
+		require.NoError(t, err)
+		require.True(t, jobs[0].Done())
+		require.Empty(t, jobs[0].LastError)
+	})
+
+	t.Run("Add without recipients", func(t *testing.T) {
+		// given:
+		s := scheduler.New(&fakeSender{}, scheduler.NewMemoryStore(), payments.NewMemoryStore())
+
+		// when:
+		err := s.Add(context.Background(), &scheduler.Job{ID: "empty", Schedule: scheduler.Schedule{At: start}})
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrInvalidSchedule)
+	})
+}
+
+func TestScheduler_Start(t *testing.T) {
+	// given:
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	store := &failingJobsStore{MemoryStore: scheduler.NewMemoryStore(), failures: 2}
+	var errs []error
+	var runs []scheduler.Run
+	s := scheduler.New(&fakeSender{}, store, payments.NewMemoryStore(),
+		scheduler.WithClock((&fakeClock{now: start}).Now),
+		scheduler.WithPollInterval(time.Millisecond),
+		scheduler.WithErrorHook(func(err error) { errs = append(errs, err) }),
+		scheduler.WithRunHook(func(run scheduler.Run) {
+			runs = append(runs, run)
+			cancel()
+		}),
+	)
+	require.NoError(t, s.Add(ctx, &scheduler.Job{
+		ID:         "rent",
+		Schedule:   scheduler.Schedule{At: start},
+		Recipients: []*commands.Recipients{{To: "landlord@example.com", Satoshis: 1000}},
+	}))
+
+	// when:
+	err := s.Start(ctx)
+
+	// then:
+	require.ErrorIs(t, err, context.Canceled)
+	require.Len(t, errs, 2)
+	require.ErrorIs(t, errs[0], goclienterr.ErrSchedulerStore)
+	require.Len(t, runs, 1)
+	require.NoError(t, runs[0].Err)
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+// fakeSender records the payments in the store, like the idempotent payments of the SPV Wallet client,
+// and sends each payment ID only once.
+type fakeSender struct {
+	sent []*commands.IdempotentSendToRecipients
+	err  error
+}
+
+func (f *fakeSender) SendToRecipientsIdempotent(ctx context.Context, cmd *commands.IdempotentSendToRecipients, store payments.Store) (*response.Transaction, error) {
+	record, err := store.Load(ctx, cmd.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+	if record != nil {
+		return &response.Transaction{ID: record.TransactionID}, nil
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	f.sent = append(f.sent, cmd)
+	txID := "tx-" + cmd.PaymentID
+	if err := store.Save(ctx, &payments.Record{PaymentID: cmd.PaymentID, State: payments.StateRecorded, TransactionID: txID}); err != nil {
+		return nil, err
+	}
+	return &response.Transaction{ID: txID}, nil
+}
+
+type failingSaveStore struct {
+	*scheduler.MemoryStore
+	fail bool
+}
+
+func (f *failingSaveStore) SaveJob(ctx context.Context, job *scheduler.Job) error {
+	if f.fail {
+		return errors.New("store unavailable")
+	}
+	return f.MemoryStore.SaveJob(ctx, job)
+}
+
+// failingJobsStore fails to list the jobs the given number of times.
+type failingJobsStore struct {
+	*scheduler.MemoryStore
+	failures int
+}
+
+func (f *failingJobsStore) Jobs(ctx context.Context) ([]*scheduler.Job, error) {
+	if f.failures > 0 {
+		f.failures--
+		return nil, errors.New("store unavailable")
+	}
+	return f.MemoryStore.Jobs(ctx)
+}
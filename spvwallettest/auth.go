@@ -0,0 +1,145 @@
+package spvwallettest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	bip32 "github.com/bitcoin-sv/go-sdk/compat/bip32"
+	bsm "github.com/bitcoin-sv/go-sdk/compat/bsm"
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/cryptoutil"
+	"github.com/bitcoin-sv/spv-wallet/models"
+)
+
+// user returns the http.Handler serving the request of an authenticated user with the handler.
+func (s *Server) user(h handler) http.Handler {
+	return s.serve(h, false)
+}
+
+// admin returns the http.Handler serving the request of the admin with the handler.
+func (s *Server) admin(h handler) http.Handler {
+	return s.serve(h, true)
+}
+
+func (s *Server) serve(h handler, admin bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, errBadRequest("failed to read request body: %s", err))
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		req := &request{Request: r, body: body}
+		if req.user, err = s.authenticate(req, admin); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		result, err := h(req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	})
+}
+
+// authenticate verifies the X-Auth headers of the request the same way the SPV Wallet does
+// and returns the user signing it; the admin is not a user, so nil is returned for the admin requests.
+func (s *Server) authenticate(r *request, admin bool) (*user, error) {
+	xPub := r.Header.Get(models.AuthHeader)
+	accessKey := r.Header.Get(models.AuthAccessKey)
+
+	switch {
+	case admin:
+		if s.adminXPub == "" || xPub != s.adminXPub {
+			return nil, errUnauthorized("admin xPub required")
+		}
+		return nil, s.verifyXPub(r, xPub)
+
+	case xPub != "":
+		u, ok := s.users[cryptoutil.Hash(xPub)]
+		if !ok {
+			return nil, errUnauthorized("unknown xPub")
+		}
+		if r.Header.Get(models.AuthSignature) == "" && s.unsigned {
+			return u, nil
+		}
+		return u, s.verifyXPub(r, xPub)
+
+	case accessKey != "":
+		u, ok := s.accessKeys[accessKey]
+		if !ok {
+			return nil, errUnauthorized("unknown access key")
+		}
+		pubKey, err := ec.PublicKeyFromString(accessKey)
+		if err != nil {
+			return nil, errUnauthorized("invalid access key: %s", err)
+		}
+		return u, s.verifySignature(r, accessKey, func(string) (*ec.PublicKey, error) { return pubKey, nil })
+
+	default:
+		return nil, errUnauthorized("missing %s or %s header", models.AuthHeader, models.AuthAccessKey)
+	}
+}
+
+// verifyXPub verifies the request signed with the key derived from the xPub along the path given by the nonce.
+func (s *Server) verifyXPub(r *request, xPub string) error {
+	key, err := bip32.NewKeyFromString(xPub)
+	if err != nil {
+		return errUnauthorized("invalid xPub: %s", err)
+	}
+	return s.verifySignature(r, xPub, func(nonce string) (*ec.PublicKey, error) {
+		child, err := cryptoutil.DeriveChildKeyFromHex(key, nonce)
+		if err != nil {
+			return nil, err
+		}
+		return child.ECPubKey()
+	})
+}
+
+func (s *Server) verifySignature(r *request, signer string, pubKey func(nonce string) (*ec.PublicKey, error)) error {
+	signature := r.Header.Get(models.AuthSignature)
+	if signature == "" {
+		return errUnauthorized("missing %s header", models.AuthSignature)
+	}
+
+	// The client hashes a missing body of a request other than GET or HEAD as JSON null.
+	hash := r.Header.Get(models.AuthHeaderHash)
+	if hash != cryptoutil.Hash(string(r.body)) && (len(r.body) > 0 || hash != cryptoutil.Hash("null")) {
+		return errUnauthorized("%s does not match the request body", models.AuthHeaderHash)
+	}
+
+	authTime, err := strconv.ParseInt(r.Header.Get(models.AuthHeaderTime), 10, 64)
+	if err != nil {
+		return errUnauthorized("invalid %s header", models.AuthHeaderTime)
+	}
+	if age := time.Since(time.UnixMilli(authTime)); age > models.AuthSignatureTTL || age < -models.AuthSignatureTTL {
+		return errUnauthorized("signature expired")
+	}
+
+	nonce := r.Header.Get(models.AuthHeaderNonce)
+	expected, err := pubKey(nonce)
+	if err != nil {
+		return errUnauthorized("invalid %s header: %s", models.AuthHeaderNonce, err)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return errUnauthorized("invalid %s header", models.AuthSignature)
+	}
+	message := fmt.Sprintf("%s%s%s%d", signer, hash, nonce, authTime)
+	recovered, _, err := bsm.PubKeyFromSignature(sig, []byte(message))
+	if err != nil || !bytes.Equal(recovered.Compressed(), expected.Compressed()) {
+		return errUnauthorized("invalid signature")
+	}
+	return nil
+}
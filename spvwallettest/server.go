@@ -0,0 +1,455 @@
+// Package spvwallettest provides an in-process fake of the SPV Wallet server for the integration tests
+// of the code built on the client. The server keeps the users, paymails, contacts, UTXOs and transactions
+// in memory, verifies the signatures of the requests and drafts and records real transactions,
+// so complete flows, e.g., UserAPI.SendToRecipients between two users, work offline.
+package spvwallettest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/go-sdk/chainhash"
+	bip32 "github.com/bitcoin-sv/go-sdk/compat/bip32"
+	"github.com/bitcoin-sv/go-sdk/script"
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/bitcoin-sv/go-sdk/transaction/template/p2pkh"
+	"github.com/bitcoin-sv/spv-wallet-go-client/coinselect"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/cryptoutil"
+	"github.com/bitcoin-sv/spv-wallet-go-client/txwait"
+	"github.com/bitcoin-sv/spv-wallet/models"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// DefaultPaymailDomain is the paymail domain handled by the server if none is configured.
+const DefaultPaymailDomain = "example.com"
+
+// DefaultDraftExpiration is the time after which an unrecorded draft transaction expires, as in the SPV Wallet.
+const DefaultDraftExpiration = 20 * time.Second
+
+// Option configures the Server.
+type Option func(*Server)
+
+// WithAdminXPub sets the xPub of the admin allowed to call the admin endpoints.
+func WithAdminXPub(xPub string) Option {
+	return func(s *Server) {
+		s.adminXPub = xPub
+	}
+}
+
+// WithPaymailDomains sets the paymail domains handled by the server, returned in the shared configuration.
+func WithPaymailDomains(domains ...string) Option {
+	return func(s *Server) {
+		s.domains = domains
+	}
+}
+
+// WithExperimentalFeatures sets the experimental features returned in the shared configuration.
+func WithExperimentalFeatures(features map[string]bool) Option {
+	return func(s *Server) {
+		s.features = features
+	}
+}
+
+// WithFeeUnit sets the fee unit of the draft transactions which don't configure one;
+// coinselect.DefaultFeeUnit if not set.
+func WithFeeUnit(unit response.FeeUnit) Option {
+	return func(s *Server) {
+		s.feeUnit = unit
+	}
+}
+
+// WithDraftExpiration sets the time after which an unrecorded draft transaction expires
+// and releases the reserved UTXOs; DefaultDraftExpiration if not set.
+func WithDraftExpiration(d time.Duration) Option {
+	return func(s *Server) {
+		if d > 0 {
+			s.draftExpiration = d
+		}
+	}
+}
+
+// WithUnsignedRequests accepts the requests authenticated only with the xPub header, without the signature,
+// as sent by the UserAPI created with an xPub.
+func WithUnsignedRequests() Option {
+	return func(s *Server) {
+		s.unsigned = true
+	}
+}
+
+// Server is a stateful fake of the SPV Wallet server listening on a local httptest.Server.
+// The clients are pointed at it with the URL as the config.Config.Addr.
+// It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	adminXPub       string
+	domains         []string
+	features        map[string]bool
+	feeUnit         response.FeeUnit
+	draftExpiration time.Duration
+	unsigned        bool
+
+	mu           sync.Mutex
+	users        map[string]*user
+	accessKeys   map[string]*user
+	paymails     map[string]*response.PaymailAddress
+	destinations map[string]*response.Destination
+	utxos        []*response.Utxo
+	transactions map[string]*transaction
+	txOrder      []string
+	drafts       map[string]*response.DraftTransaction
+	height       uint64
+}
+
+// NewServer starts a new, empty Server; it should be closed with Close when the test is done.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		domains:         []string{DefaultPaymailDomain},
+		features:        map[string]bool{},
+		feeUnit:         coinselect.DefaultFeeUnit,
+		draftExpiration: DefaultDraftExpiration,
+		users:           make(map[string]*user),
+		accessKeys:      make(map[string]*user),
+		paymails:        make(map[string]*response.PaymailAddress),
+		destinations:    make(map[string]*response.Destination),
+		transactions:    make(map[string]*transaction),
+		drafts:          make(map[string]*response.DraftTransaction),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.Server = httptest.NewServer(s.routes())
+	return s
+}
+
+// AddUser registers the user with the xPub and the paymail addresses, e.g., "alice@example.com",
+// which must belong to the paymail domains of the server.
+func (s *Server) AddUser(xPub string, paymails ...string) error {
+	key, err := bip32.NewKeyFromString(xPub)
+	if err != nil {
+		return fmt.Errorf("invalid xPub: %w", err)
+	}
+	if key.IsPrivate() {
+		return errors.New("invalid xPub: extended private key given")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.addUser(key, xPub, nil)
+	for _, address := range paymails {
+		if _, err := s.addPaymail(u, address, "", ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddPaymail registers the paymail address of the user with the xPub.
+func (s *Server) AddPaymail(xPub, address string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[cryptoutil.Hash(xPub)]
+	if !ok {
+		return errors.New("user with the xPub not found")
+	}
+	_, err := s.addPaymail(u, address, "", "")
+	return err
+}
+
+// AddAccessKey registers the hex-encoded compressed public key of an access key of the user with the xPub,
+// so the requests signed with the access key are authenticated as the user.
+func (s *Server) AddAccessKey(xPub, pubKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[cryptoutil.Hash(xPub)]
+	if !ok {
+		return errors.New("user with the xPub not found")
+	}
+	s.accessKeys[pubKey] = u
+	return nil
+}
+
+// Fund records a mined transaction paying the satoshis to a new destination of the user with the xPub,
+// as if it was received from outside of the wallet, and returns its ID.
+func (s *Server) Fund(xPub string, satoshis uint64) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[cryptoutil.Hash(xPub)]
+	if !ok {
+		return "", errors.New("user with the xPub not found")
+	}
+	dst, err := s.newDestination(u, cryptoutil.ChainExternal)
+	if err != nil {
+		return "", err
+	}
+	lockingScript, err := script.NewFromHex(dst.LockingScript)
+	if err != nil {
+		return "", fmt.Errorf("invalid locking script of destination %s: %w", dst.ID, err)
+	}
+
+	// The funding input spends an unknown output, which makes every funding transaction unique.
+	s.height++
+	tx := trx.NewTransaction()
+	tx.AddInput(&trx.TransactionInput{
+		SourceTXID:       &chainhash.Hash{},
+		SourceTxOutIndex: uint32(s.height), //nolint: gosec // the number of funding transactions fits in uint32
+		UnlockingScript:  &script.Script{script.OpTRUE},
+		SequenceNumber:   trx.DefaultSequenceNumber,
+	})
+	tx.AddOutput(&trx.TransactionOutput{Satoshis: satoshis, LockingScript: lockingScript})
+
+	t := s.record(tx, "", nil, nil)
+	t.mine(s.height)
+	return t.id, nil
+}
+
+// Mine marks the recorded transactions which are not mined yet as mined in a new block.
+func (s *Server) Mine() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.height++
+	for _, id := range s.txOrder {
+		if t := s.transactions[id]; t.status == txwait.StatusSeenOnNetwork {
+			t.mine(s.height)
+		}
+	}
+}
+
+type user struct {
+	id              string
+	xPub            *bip32.ExtendedKey
+	createdAt       time.Time
+	updatedAt       time.Time
+	metadata        map[string]any
+	nextInternalNum uint32
+	nextExternalNum uint32
+	paymails        []*response.PaymailAddress
+	contacts        map[string]*response.Contact
+}
+
+func (s *Server) addUser(key *bip32.ExtendedKey, xPub string, metadata map[string]any) *user {
+	id := cryptoutil.Hash(xPub)
+	if u, ok := s.users[id]; ok {
+		return u
+	}
+	now := time.Now()
+	u := &user{
+		id:        id,
+		xPub:      key,
+		createdAt: now,
+		updatedAt: now,
+		metadata:  metadata,
+		contacts:  make(map[string]*response.Contact),
+	}
+	s.users[id] = u
+	return u
+}
+
+func (s *Server) addPaymail(u *user, address, publicName, avatar string) (*response.PaymailAddress, error) {
+	alias, domain, ok := strings.Cut(address, "@")
+	if !ok || alias == "" || !s.handles(domain) {
+		return nil, fmt.Errorf("invalid paymail %q: the domain must be one of %v", address, s.domains)
+	}
+	if _, ok := s.paymails[address]; ok {
+		return nil, fmt.Errorf("paymail %q already exists", address)
+	}
+
+	now := time.Now()
+	paymail := &response.PaymailAddress{
+		Model:      response.Model{CreatedAt: now, UpdatedAt: now},
+		ID:         cryptoutil.Hash(address),
+		XpubID:     u.id,
+		Alias:      alias,
+		Domain:     domain,
+		PublicName: publicName,
+		Avatar:     avatar,
+		Address:    address,
+	}
+	s.paymails[address] = paymail
+	u.paymails = append(u.paymails, paymail)
+	return paymail, nil
+}
+
+func (s *Server) handles(domain string) bool {
+	for _, d := range s.domains {
+		if d == domain {
+			return true
+		}
+	}
+	return false
+}
+
+// newDestination derives the next P2PKH destination of the user on the chain.
+func (s *Server) newDestination(u *user, chain uint32) (*response.Destination, error) {
+	num := &u.nextExternalNum
+	if chain == cryptoutil.ChainInternal {
+		num = &u.nextInternalNum
+	}
+	key, err := bip32.GetHDKeyByPath(u.xPub, chain, *num)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive destination %d/%d: %w", chain, *num, err)
+	}
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive destination %d/%d: %w", chain, *num, err)
+	}
+	address, err := script.NewAddressFromPublicKey(pubKey, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive destination %d/%d: %w", chain, *num, err)
+	}
+	lockingScript, err := p2pkh.Lock(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive destination %d/%d: %w", chain, *num, err)
+	}
+
+	now := time.Now()
+	dst := &response.Destination{
+		Model:         response.Model{CreatedAt: now, UpdatedAt: now},
+		ID:            cryptoutil.Hash(lockingScript.String()),
+		XpubID:        u.id,
+		LockingScript: lockingScript.String(),
+		Type:          "pubkeyhash",
+		Chain:         chain,
+		Num:           *num,
+		Address:       address.AddressString,
+	}
+	*num++
+	s.destinations[dst.LockingScript] = dst
+	return dst, nil
+}
+
+func (s *Server) balance(u *user) uint64 {
+	var balance uint64
+	for _, utxo := range s.utxos {
+		if utxo.XpubID == u.id && utxo.SpendingTxID == "" {
+			balance += utxo.Satoshis
+		}
+	}
+	return balance
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.Handle("GET /api/v1/configs/shared", s.user(s.sharedConfig))
+	mux.Handle("GET /api/v1/users/current", s.user(s.currentUser))
+	mux.Handle("PATCH /api/v1/users/current", s.user(s.updateCurrentUser))
+	mux.Handle("GET /api/v1/paymails", s.user(s.userPaymails))
+
+	mux.Handle("GET /api/v1/contacts", s.user(s.contacts))
+	mux.Handle("GET /api/v1/contacts/{paymail}", s.user(s.contact))
+	mux.Handle("PUT /api/v1/contacts/{paymail}", s.user(s.upsertContact))
+	mux.Handle("DELETE /api/v1/contacts/{paymail}", s.user(s.removeContact))
+	mux.Handle("POST /api/v1/contacts/{paymail}/confirmation", s.user(s.confirmContact))
+	mux.Handle("DELETE /api/v1/contacts/{paymail}/confirmation", s.user(s.unconfirmContact))
+	mux.Handle("POST /api/v1/invitations/{paymail}/contacts", s.user(s.acceptInvitation))
+	mux.Handle("DELETE /api/v1/invitations/{paymail}", s.user(s.rejectInvitation))
+
+	mux.Handle("GET /api/v1/utxos", s.user(s.userUTXOs))
+	mux.Handle("POST /api/v1/transactions/drafts", s.user(s.draftTransaction))
+	mux.Handle("POST /api/v1/transactions", s.user(s.recordTransaction))
+	mux.Handle("GET /api/v1/transactions", s.user(s.userTransactions))
+	mux.Handle("GET /api/v1/transactions/{id}", s.user(s.userTransaction))
+	mux.Handle("PATCH /api/v1/transactions/{id}", s.user(s.updateTransactionMetadata))
+
+	mux.Handle("POST /api/v1/admin/users", s.admin(s.createXPub))
+	mux.Handle("POST /api/v1/admin/paymails", s.admin(s.createPaymail))
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, errNotFound("route %s %s not found", r.Method, r.URL.Path))
+	})
+	return mux
+}
+
+// request is an authenticated request with its body.
+type request struct {
+	*http.Request
+	user *user
+	body []byte
+}
+
+func (r *request) decode(v any) error {
+	if err := json.Unmarshal(r.body, v); err != nil {
+		return errBadRequest("invalid request body: %s", err)
+	}
+	return nil
+}
+
+// handler handles an authenticated request and returns the result written as the JSON response.
+type handler func(r *request) (any, error)
+
+// apiError is an error written as the models.SPVError response.
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func errBadRequest(format string, args ...any) error {
+	return &apiError{status: http.StatusBadRequest, code: "error-bad-request", message: fmt.Sprintf(format, args...)}
+}
+
+func errNotFound(format string, args ...any) error {
+	return &apiError{status: http.StatusNotFound, code: "error-not-found", message: fmt.Sprintf(format, args...)}
+}
+
+func errUnauthorized(format string, args ...any) error {
+	return &apiError{status: http.StatusUnauthorized, code: "error-unauthorized", message: fmt.Sprintf(format, args...)}
+}
+
+func errConflict(format string, args ...any) error {
+	return &apiError{status: http.StatusConflict, code: "error-conflict", message: fmt.Sprintf(format, args...)}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) {
+		apiErr = &apiError{status: http.StatusInternalServerError, code: models.UnknownErrorCode, message: err.Error()}
+	}
+	writeJSON(w, apiErr.status, models.SPVError{Code: apiErr.code, Message: apiErr.message, StatusCode: apiErr.status})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// page returns the page of the items selected by the page and size query parameters.
+func page[T any](r *request, items []*T) *response.PageModel[T] {
+	number, size := 1, 50
+	if n, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && n > 0 {
+		number = n
+	}
+	if n, err := strconv.Atoi(r.URL.Query().Get("size")); err == nil && n > 0 {
+		size = n
+	}
+
+	start := min((number-1)*size, len(items))
+	end := min(start+size, len(items))
+	return &response.PageModel[T]{
+		Content: items[start:end],
+		Page: response.PageDescription{
+			Size:          size,
+			Number:        number,
+			TotalElements: len(items),
+			TotalPages:    (len(items) + size - 1) / size,
+		},
+	}
+}
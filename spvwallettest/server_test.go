@@ -0,0 +1,251 @@
+package spvwallettest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	spvwallet "github.com/bitcoin-sv/spv-wallet-go-client"
+	"github.com/bitcoin-sv/spv-wallet-go-client/capabilities"
+	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
+	"github.com/bitcoin-sv/spv-wallet-go-client/config"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/cryptoutil"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/testutils"
+	"github.com/bitcoin-sv/spv-wallet-go-client/spvwallettest"
+	"github.com/bitcoin-sv/spv-wallet/models"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	alicePaymail = "alice@example.com"
+	bobPaymail   = "bob@example.com"
+)
+
+func TestServer_SendToRecipients(t *testing.T) {
+	// given:
+	ctx := context.Background()
+	server := givenServer(t)
+	_, err := server.Fund(testutils.AliceXPub, 10_000)
+	require.NoError(t, err)
+	alice := givenUserAPI(t, server, testutils.AliceXPriv)
+	bob := givenUserAPI(t, server, testutils.BobXPriv)
+
+	// when:
+	tx, err := alice.SendToRecipients(ctx, &commands.SendToRecipients{
+		Recipients: []*commands.Recipients{
+			{To: bobPaymail, Satoshis: 1_000},
+			{OpReturn: &response.OpReturn{StringParts: []string{"hello", "bob"}}},
+		},
+		Metadata: map[string]any{"note": "lunch"},
+	})
+
+	// then:
+	require.NoError(t, err)
+	require.Equal(t, "outgoing", tx.TransactionDirection)
+	require.Equal(t, "lunch", tx.Metadata["note"])
+	require.NotZero(t, tx.Fee)
+	require.Equal(t, -int64(1_000+tx.Fee), tx.OutputValue)
+
+	aliceXPub, err := alice.XPub(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 10_000-1_000-tx.Fee, aliceXPub.CurrentBalance)
+
+	bobXPub, err := bob.XPub(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1_000), bobXPub.CurrentBalance)
+
+	received, err := bob.Transaction(ctx, tx.ID)
+	require.NoError(t, err)
+	require.Equal(t, "incoming", received.TransactionDirection)
+	require.Equal(t, int64(1_000), received.OutputValue)
+	require.Empty(t, received.Metadata)
+}
+
+func TestServer_UpdateTransactionMetadata_PerUser(t *testing.T) {
+	// given:
+	ctx := context.Background()
+	server := givenServer(t)
+	_, err := server.Fund(testutils.AliceXPub, 10_000)
+	require.NoError(t, err)
+	alice := givenUserAPI(t, server, testutils.AliceXPriv)
+	bob := givenUserAPI(t, server, testutils.BobXPriv)
+	tx, err := alice.SendToRecipients(ctx, &commands.SendToRecipients{
+		Recipients: []*commands.Recipients{{To: bobPaymail, Satoshis: 1_000}},
+		Metadata:   map[string]any{"note": "lunch"},
+	})
+	require.NoError(t, err)
+
+	// when:
+	updated, err := bob.UpdateTransactionMetadata(ctx, &commands.UpdateTransactionMetadata{
+		ID:       tx.ID,
+		Metadata: map[string]any{"note": "paid back"},
+	})
+
+	// then:
+	require.NoError(t, err)
+	require.Equal(t, "paid back", updated.Metadata["note"])
+
+	sent, err := alice.Transaction(ctx, tx.ID)
+	require.NoError(t, err)
+	require.Equal(t, "lunch", sent.Metadata["note"])
+}
+
+func TestServer_SendToRecipients_SpendsReceivedUTXOs(t *testing.T) {
+	// given:
+	ctx := context.Background()
+	server := givenServer(t)
+	_, err := server.Fund(testutils.AliceXPub, 10_000)
+	require.NoError(t, err)
+	alice := givenUserAPI(t, server, testutils.AliceXPriv)
+	bob := givenUserAPI(t, server, testutils.BobXPriv)
+	_, err = alice.SendToRecipients(ctx, &commands.SendToRecipients{Recipients: []*commands.Recipients{{To: bobPaymail, Satoshis: 5_000}}})
+	require.NoError(t, err)
+
+	// when:
+	tx, err := bob.SendToRecipients(ctx, &commands.SendToRecipients{Recipients: []*commands.Recipients{{To: alicePaymail, Satoshis: 2_000}}})
+
+	// then:
+	require.NoError(t, err)
+	bobXPub, err := bob.XPub(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 5_000-2_000-tx.Fee, bobXPub.CurrentBalance)
+}
+
+func TestServer_SendToRecipients_NotEnoughFunds(t *testing.T) {
+	// given:
+	ctx := context.Background()
+	server := givenServer(t)
+	_, err := server.Fund(testutils.AliceXPub, 500)
+	require.NoError(t, err)
+	alice := givenUserAPI(t, server, testutils.AliceXPriv)
+
+	// when:
+	tx, err := alice.SendToRecipients(ctx, &commands.SendToRecipients{Recipients: []*commands.Recipients{{To: bobPaymail, Satoshis: 1_000}}})
+
+	// then:
+	var spvErr *models.SPVError
+	require.ErrorAs(t, err, &spvErr)
+	require.Equal(t, http.StatusBadRequest, spvErr.StatusCode)
+	require.Nil(t, tx)
+}
+
+func TestServer_Authentication(t *testing.T) {
+	t.Run("rejects a request of an unknown user", func(t *testing.T) {
+		// given:
+		server := givenServer(t)
+		unknown := givenUserAPI(t, server, testutils.UserXPriv)
+
+		// when:
+		xPub, err := unknown.XPub(context.Background())
+
+		// then:
+		require.ErrorIs(t, err, testutils.NewUnauthorizedAccessSPVError())
+		require.Nil(t, xPub)
+	})
+
+	t.Run("rejects a request with an invalid signature", func(t *testing.T) {
+		// given:
+		server := givenServer(t)
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/users/current", nil)
+		require.NoError(t, err)
+		req.Header.Set(models.AuthHeader, testutils.AliceXPub)
+		req.Header.Set(models.AuthHeaderHash, cryptoutil.Hash(""))
+		req.Header.Set(models.AuthHeaderNonce, "00")
+		req.Header.Set(models.AuthHeaderTime, "0")
+		req.Header.Set(models.AuthSignature, "c2lnbmF0dXJl")
+
+		// when:
+		res, err := server.Client().Do(req)
+
+		// then:
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("accepts an unsigned request if allowed", func(t *testing.T) {
+		// given:
+		server := givenServer(t, spvwallettest.WithUnsignedRequests())
+		cfg := config.New(config.WithAddr(server.URL), config.WithTimeout(5*time.Second))
+		alice, err := spvwallet.NewUserAPIWithXPub(cfg, testutils.AliceXPub)
+		require.NoError(t, err)
+
+		// when:
+		xPub, err := alice.XPub(context.Background())
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, cryptoutil.Hash(testutils.AliceXPub), xPub.ID)
+	})
+}
+
+func TestServer_Contacts(t *testing.T) {
+	// given:
+	ctx := context.Background()
+	server := givenServer(t, spvwallettest.WithExperimentalFeatures(map[string]bool{string(capabilities.FeaturePikeContacts): true}))
+	alice := givenUserAPI(t, server, testutils.AliceXPriv)
+	bob := givenUserAPI(t, server, testutils.BobXPriv)
+
+	// when:
+	contact, err := alice.UpsertContact(ctx, commands.UpsertContact{ContactPaymail: bobPaymail, FullName: "Bob", RequesterPaymail: alicePaymail})
+
+	// then:
+	require.NoError(t, err)
+	require.Equal(t, response.ContactNotConfirmed, contact.Status)
+
+	invitation, err := bob.ContactWithPaymail(ctx, alicePaymail)
+	require.NoError(t, err)
+	require.Equal(t, response.ContactAwaitAccept, invitation.Status)
+
+	require.NoError(t, bob.AcceptInvitation(ctx, alicePaymail))
+	accepted, err := bob.ContactWithPaymail(ctx, alicePaymail)
+	require.NoError(t, err)
+	require.Equal(t, response.ContactNotConfirmed, accepted.Status)
+}
+
+func TestServer_Admin(t *testing.T) {
+	// given:
+	ctx := context.Background()
+	server := givenServer(t, spvwallettest.WithAdminXPub(testutils.UserXPub))
+	cfg := config.New(config.WithAddr(server.URL), config.WithTimeout(5*time.Second))
+	admin, err := spvwallet.NewAdminAPIWithXPriv(cfg, testutils.UserXPriv)
+	require.NoError(t, err)
+
+	// when:
+	_, err = admin.CreateXPub(ctx, &commands.CreateUserXpub{XPub: testutils.UserXPub})
+	require.NoError(t, err)
+	paymail, err := admin.CreatePaymail(ctx, &commands.CreatePaymail{Key: testutils.UserXPub, Address: "user@example.com", PublicName: "User"})
+
+	// then:
+	require.NoError(t, err)
+	require.Equal(t, "user", paymail.Alias)
+	require.Equal(t, cryptoutil.Hash(testutils.UserXPub), paymail.XpubID)
+
+	_, err = server.Fund(testutils.UserXPub, 1_000)
+	require.NoError(t, err)
+	user := givenUserAPI(t, server, testutils.UserXPriv)
+	xPub, err := user.XPub(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1_000), xPub.CurrentBalance)
+}
+
+func givenServer(t *testing.T, opts ...spvwallettest.Option) *spvwallettest.Server {
+	t.Helper()
+
+	server := spvwallettest.NewServer(opts...)
+	t.Cleanup(server.Close)
+	require.NoError(t, server.AddUser(testutils.AliceXPub, alicePaymail))
+	require.NoError(t, server.AddUser(testutils.BobXPub, bobPaymail))
+	return server
+}
+
+func givenUserAPI(t *testing.T, server *spvwallettest.Server, xPriv string) *spvwallet.UserAPI {
+	t.Helper()
+
+	cfg := config.New(config.WithAddr(server.URL), config.WithTimeout(5*time.Second))
+	api, err := spvwallet.NewUserAPIWithXPriv(cfg, xPriv)
+	require.NoError(t, err)
+	return api
+}
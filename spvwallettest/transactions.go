@@ -0,0 +1,546 @@
+package spvwallettest
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bitcoin-sv/go-sdk/chainhash"
+	"github.com/bitcoin-sv/go-sdk/script"
+	"github.com/bitcoin-sv/go-sdk/script/interpreter"
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/bitcoin-sv/go-sdk/transaction/template/p2pkh"
+	"github.com/bitcoin-sv/spv-wallet-go-client/coinselect"
+	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/cryptoutil"
+	"github.com/bitcoin-sv/spv-wallet-go-client/opreturn"
+	"github.com/bitcoin-sv/spv-wallet-go-client/txwait"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+const (
+	// p2pkhInputSize is the estimated size of an input unlocking a P2PKH output.
+	p2pkhInputSize = 148
+	// p2pkhOutputSize is the size of an output with a P2PKH locking script.
+	p2pkhOutputSize = 34
+)
+
+// transaction is a recorded transaction with its effect on the balances of the users.
+type transaction struct {
+	id          string
+	hex         string
+	draftID     string
+	createdAt   time.Time
+	updatedAt   time.Time
+	metadata    map[string]map[string]any // Metadata of the transaction by the ID of the user who set it.
+	values      map[string]int64
+	xpubInIDs   []string
+	xpubOutIDs  []string
+	fee         uint64
+	inputs      uint32
+	outputs     uint32
+	status      string
+	blockHeight uint64
+	blockHash   string
+}
+
+func (t *transaction) mine(height uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], height)
+	t.status = txwait.StatusMined
+	t.blockHeight = height
+	t.blockHash = chainhash.DoubleHashH(b[:]).String()
+	t.updatedAt = time.Now()
+}
+
+// view returns the transaction as seen by the user, with the metadata set by the user only.
+func (t *transaction) view(u *user) *response.Transaction {
+	value := t.values[u.id]
+	direction, total := "incoming", uint64(value) //nolint: gosec // the value received is not negative
+	if slices.Contains(t.xpubInIDs, u.id) {
+		direction, total = "outgoing", uint64(-value) //nolint: gosec // the value sent is not positive
+	}
+	return &response.Transaction{
+		Model:                response.Model{CreatedAt: t.createdAt, UpdatedAt: t.updatedAt, Metadata: t.metadata[u.id]},
+		ID:                   t.id,
+		Hex:                  t.hex,
+		XpubInIDs:            t.xpubInIDs,
+		XpubOutIDs:           t.xpubOutIDs,
+		BlockHash:            t.blockHash,
+		BlockHeight:          t.blockHeight,
+		Fee:                  t.fee,
+		NumberOfInputs:       t.inputs,
+		NumberOfOutputs:      t.outputs,
+		DraftID:              t.draftID,
+		TotalValue:           total,
+		OutputValue:          value,
+		Outputs:              maps.Clone(t.values),
+		Status:               t.status,
+		TransactionDirection: direction,
+	}
+}
+
+// record applies the transaction to the wallet: it spends the UTXOs of its inputs
+// and adds the UTXOs of its outputs paying to the destinations of the users.
+// The metadata is kept as the metadata of the user recording the transaction, if any.
+func (s *Server) record(tx *trx.Transaction, draftID string, recorder *user, metadata map[string]any) *transaction {
+	now := time.Now()
+	t := &transaction{
+		id:        tx.TxID().String(),
+		hex:       tx.Hex(),
+		draftID:   draftID,
+		createdAt: now,
+		updatedAt: now,
+		metadata:  make(map[string]map[string]any),
+		values:    make(map[string]int64),
+		inputs:    uint32(len(tx.Inputs)),  //nolint: gosec // the number of inputs fits in uint32
+		outputs:   uint32(len(tx.Outputs)), //nolint: gosec // the number of outputs fits in uint32
+		status:    txwait.StatusSeenOnNetwork,
+	}
+	if recorder != nil && metadata != nil {
+		t.metadata[recorder.id] = metadata
+	}
+
+	var spent uint64
+	known := len(tx.Inputs) > 0
+	for _, input := range tx.Inputs {
+		utxo := s.utxo(input.SourceTXID.String(), input.SourceTxOutIndex)
+		if utxo == nil {
+			known = false
+			continue
+		}
+		utxo.SpendingTxID = t.id
+		utxo.UpdatedAt = now
+		spent += utxo.Satoshis
+		t.values[utxo.XpubID] -= int64(utxo.Satoshis) //nolint: gosec // satoshis fit in int64
+		if !slices.Contains(t.xpubInIDs, utxo.XpubID) {
+			t.xpubInIDs = append(t.xpubInIDs, utxo.XpubID)
+		}
+	}
+
+	for i, output := range tx.Outputs {
+		dst, ok := s.destinations[output.LockingScript.String()]
+		if !ok {
+			continue
+		}
+		s.utxos = append(s.utxos, &response.Utxo{
+			Model:        response.Model{CreatedAt: now, UpdatedAt: now},
+			UtxoPointer:  response.UtxoPointer{TransactionID: t.id, OutputIndex: uint32(i)}, //nolint: gosec // the number of outputs fits in uint32
+			ID:           cryptoutil.Hash(t.id + strconv.Itoa(i)),
+			XpubID:       dst.XpubID,
+			Satoshis:     output.Satoshis,
+			ScriptPubKey: dst.LockingScript,
+			Type:         dst.Type,
+		})
+		t.values[dst.XpubID] += int64(output.Satoshis) //nolint: gosec // satoshis fit in int64
+		if !slices.Contains(t.xpubOutIDs, dst.XpubID) {
+			t.xpubOutIDs = append(t.xpubOutIDs, dst.XpubID)
+		}
+	}
+
+	if total := tx.TotalOutputSatoshis(); known && spent >= total {
+		t.fee = spent - total
+	}
+	s.transactions[t.id] = t
+	s.txOrder = append(s.txOrder, t.id)
+	return t
+}
+
+func (s *Server) utxo(txID string, index uint32) *response.Utxo {
+	for _, utxo := range s.utxos {
+		if utxo.TransactionID == txID && utxo.OutputIndex == index {
+			return utxo
+		}
+	}
+	return nil
+}
+
+// expireDrafts expires the draft transactions which were not recorded in time and releases their UTXOs.
+func (s *Server) expireDrafts() {
+	now := time.Now()
+	for _, draft := range s.drafts {
+		if draft.Status != response.DraftStatusDraft || now.Before(draft.ExpiresAt) {
+			continue
+		}
+		draft.Status = response.DraftStatusExpired
+		for _, utxo := range s.utxos {
+			if utxo.DraftID == draft.ID && utxo.SpendingTxID == "" {
+				utxo.DraftID = ""
+				utxo.ReservedAt = time.Time{}
+			}
+		}
+	}
+}
+
+func (s *Server) userUTXOs(r *request) (any, error) {
+	s.expireDrafts()
+
+	query := r.URL.Query()
+	utxos := make([]*response.Utxo, 0)
+	for _, utxo := range s.utxos {
+		if utxo.XpubID != r.user.id {
+			continue
+		}
+		if txID := query.Get("transactionId"); txID != "" && utxo.TransactionID != txID {
+			continue
+		}
+		if index := query.Get("outputIndex"); index != "" && strconv.FormatUint(uint64(utxo.OutputIndex), 10) != index {
+			continue
+		}
+		utxos = append(utxos, utxo)
+	}
+	return page(r, utxos), nil
+}
+
+func (s *Server) userTransactions(r *request) (any, error) {
+	transactions := make([]*response.Transaction, 0)
+	for _, id := range s.txOrder {
+		if t := s.transactions[id]; t.involves(r.user) {
+			transactions = append(transactions, t.view(r.user))
+		}
+	}
+	return page(r, transactions), nil
+}
+
+func (s *Server) userTransaction(r *request) (any, error) {
+	t, err := s.findTransaction(r.user, r.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+	return t.view(r.user), nil
+}
+
+func (s *Server) updateTransactionMetadata(r *request) (any, error) {
+	var cmd commands.UpdateTransactionMetadata
+	if err := r.decode(&cmd); err != nil {
+		return nil, err
+	}
+	t, err := s.findTransaction(r.user, r.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+	t.metadata[r.user.id] = mergeMetadata(t.metadata[r.user.id], cmd.Metadata)
+	t.updatedAt = time.Now()
+	return t.view(r.user), nil
+}
+
+func (s *Server) findTransaction(u *user, id string) (*transaction, error) {
+	t, ok := s.transactions[id]
+	if !ok || !t.involves(u) {
+		return nil, errNotFound("transaction %s not found", id)
+	}
+	return t, nil
+}
+
+func (t *transaction) involves(u *user) bool {
+	_, ok := t.values[u.id]
+	return ok
+}
+
+// draftTransaction drafts the transaction paying the outputs of the configuration with the UTXOs of the user,
+// either the ones given in the configuration or the first free ones covering the outputs and the fee.
+// The remaining satoshis are sent back to new internal destinations of the user.
+func (s *Server) draftTransaction(r *request) (any, error) {
+	var cmd commands.DraftTransaction
+	if err := r.decode(&cmd); err != nil {
+		return nil, err
+	}
+	config := cmd.Config
+	if config.SendAllTo != nil {
+		return nil, errBadRequest("sendAllTo is not supported")
+	}
+	if config.FeeUnit == nil {
+		feeUnit := s.feeUnit
+		config.FeeUnit = &feeUnit
+	}
+	if config.ExpiresIn <= 0 {
+		config.ExpiresIn = s.draftExpiration
+	}
+	s.expireDrafts()
+
+	tx := trx.NewTransaction()
+	var total uint64
+	for _, output := range config.Outputs {
+		lockingScript, err := s.outputScript(output)
+		if err != nil {
+			return nil, err
+		}
+		tx.AddOutput(&trx.TransactionOutput{Satoshis: output.Satoshis, LockingScript: lockingScript})
+		total += output.Satoshis
+	}
+
+	candidates, err := s.candidates(r.user, config.FromUtxos)
+	if err != nil {
+		return nil, err
+	}
+	changes := max(config.ChangeNumberOfDestinations, 1)
+	fee := func(inputs int) uint64 {
+		return coinselect.Fee(config.FeeUnit, tx.Size()+inputs*p2pkhInputSize+changes*p2pkhOutputSize)
+	}
+
+	var selected []*response.Utxo
+	var sum uint64
+	for _, utxo := range candidates {
+		if config.FromUtxos == nil && len(selected) > 0 && sum >= total+fee(len(selected)) {
+			break
+		}
+		selected = append(selected, utxo)
+		sum += utxo.Satoshis
+	}
+	config.Fee = fee(len(selected))
+	if len(selected) == 0 || sum < total+config.Fee {
+		return nil, errBadRequest("not enough funds: %d satoshis available, %d required", sum, total+config.Fee)
+	}
+
+	config.ChangeSatoshis = sum - total - config.Fee
+	changes = int(min(uint64(changes), config.ChangeSatoshis)) //nolint: gosec // the number of changes is an int
+	config.ChangeDestinations = nil
+	for i := range changes {
+		dst, err := s.newDestination(r.user, cryptoutil.ChainInternal)
+		if err != nil {
+			return nil, err
+		}
+		satoshis := config.ChangeSatoshis / uint64(changes) //nolint: gosec // the number of changes is positive
+		if i == 0 {
+			satoshis += config.ChangeSatoshis % uint64(changes) //nolint: gosec // the number of changes is positive
+		}
+		lockingScript, err := script.NewFromHex(dst.LockingScript)
+		if err != nil {
+			return nil, err
+		}
+		tx.AddOutput(&trx.TransactionOutput{Satoshis: satoshis, LockingScript: lockingScript})
+		config.ChangeDestinations = append(config.ChangeDestinations, dst)
+	}
+
+	config.Inputs = nil
+	for _, utxo := range selected {
+		if err := tx.AddInputFrom(utxo.TransactionID, utxo.OutputIndex, utxo.ScriptPubKey, utxo.Satoshis, nil); err != nil {
+			return nil, err
+		}
+		config.Inputs = append(config.Inputs, &response.TransactionInput{Utxo: *utxo, Destination: *s.destinations[utxo.ScriptPubKey]})
+	}
+
+	id, err := cryptoutil.RandomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, utxo := range selected {
+		utxo.DraftID = id
+		utxo.ReservedAt = now
+	}
+	draft := &response.DraftTransaction{
+		Model:         response.Model{CreatedAt: now, UpdatedAt: now, Metadata: cmd.Metadata},
+		ID:            id,
+		Hex:           tx.Hex(),
+		XpubID:        r.user.id,
+		ExpiresAt:     now.Add(config.ExpiresIn),
+		Configuration: config,
+		Status:        response.DraftStatusDraft,
+	}
+	s.drafts[id] = draft
+	return draft, nil
+}
+
+// candidates returns the UTXOs of the user given by the pointers, or all free UTXOs of the user if there are none.
+func (s *Server) candidates(u *user, pointers []*response.UtxoPointer) ([]*response.Utxo, error) {
+	free := func(utxo *response.Utxo) bool {
+		return utxo.XpubID == u.id && utxo.SpendingTxID == "" && utxo.DraftID == ""
+	}
+	if pointers == nil {
+		var candidates []*response.Utxo
+		for _, utxo := range s.utxos {
+			if free(utxo) {
+				candidates = append(candidates, utxo)
+			}
+		}
+		return candidates, nil
+	}
+
+	candidates := make([]*response.Utxo, 0, len(pointers))
+	for _, pointer := range pointers {
+		utxo := s.utxo(pointer.TransactionID, pointer.OutputIndex)
+		if utxo == nil || !free(utxo) {
+			return nil, errBadRequest("UTXO %s:%d cannot be spent", pointer.TransactionID, pointer.OutputIndex)
+		}
+		candidates = append(candidates, utxo)
+	}
+	return candidates, nil
+}
+
+// outputScript returns the locking script of the output, resolving its recipient: a paymail of a user
+// of the server, an address or a script, or the OP_RETURN data. The scripts of the output are set accordingly.
+func (s *Server) outputScript(output *response.TransactionOutput) (*script.Script, error) {
+	var lockingScript *script.Script
+	var address string
+	var err error
+
+	switch {
+	case output.OpReturn != nil:
+		lockingScript, err = opReturnScript(output.OpReturn)
+	case output.Script != "":
+		lockingScript, err = script.NewFromHex(output.Script)
+	case strings.Contains(output.To, "@"):
+		paymail, ok := s.paymails[strings.ToLower(output.To)]
+		if !ok {
+			return nil, errBadRequest("paymail %s not found", output.To)
+		}
+		var dst *response.Destination
+		if dst, err = s.newDestination(s.users[paymail.XpubID], cryptoutil.ChainExternal); err == nil {
+			address = dst.Address
+			lockingScript, err = script.NewFromHex(dst.LockingScript)
+		}
+	case output.To != "":
+		var addr *script.Address
+		if addr, err = script.NewAddressFromString(output.To); err == nil {
+			address = addr.AddressString
+			lockingScript, err = p2pkh.Lock(addr)
+		}
+	default:
+		return nil, errBadRequest("output without a recipient")
+	}
+	if err != nil {
+		return nil, errBadRequest("invalid output to %q: %s", output.To, err)
+	}
+
+	scriptType := "nonstandard"
+	switch {
+	case lockingScript.IsP2PKH():
+		scriptType = "pubkeyhash"
+	case lockingScript.IsData():
+		scriptType = "nulldata"
+	}
+	output.Script = lockingScript.String()
+	output.Scripts = []*response.ScriptOutput{{
+		Address:    address,
+		Satoshis:   output.Satoshis,
+		Script:     output.Script,
+		ScriptType: scriptType,
+	}}
+	return lockingScript, nil
+}
+
+func opReturnScript(opReturn *response.OpReturn) (*script.Script, error) {
+	switch {
+	case opReturn.Hex != "":
+		return script.NewFromHex(opReturn.Hex)
+	case len(opReturn.HexParts) > 0:
+		parts := make([][]byte, len(opReturn.HexParts))
+		for i, part := range opReturn.HexParts {
+			b, err := hex.DecodeString(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid hex part %q: %w", part, err)
+			}
+			parts[i] = b
+		}
+		return opreturn.NewBuilder().Push(parts...).Script()
+	case len(opReturn.StringParts) > 0:
+		return opreturn.NewBuilder().PushString(opReturn.StringParts...).Script()
+	default:
+		return nil, errors.New("unsupported op_return")
+	}
+}
+
+// recordTransaction records the signed transaction of a draft transaction of the user, given as the reference ID,
+// or a transaction paying to the destinations of the users without spending any of their UTXOs.
+func (s *Server) recordTransaction(r *request) (any, error) {
+	var cmd commands.RecordTransaction
+	if err := r.decode(&cmd); err != nil {
+		return nil, err
+	}
+	tx, err := trx.NewTransactionFromHex(cmd.Hex)
+	if err != nil {
+		return nil, errBadRequest("invalid transaction hex: %s", err)
+	}
+	if _, ok := s.transactions[tx.TxID().String()]; ok {
+		return nil, errConflict("transaction %s already recorded", tx.TxID())
+	}
+	s.expireDrafts()
+
+	var draft *response.DraftTransaction
+	if cmd.ReferenceID != "" {
+		var ok bool
+		if draft, ok = s.drafts[cmd.ReferenceID]; !ok || draft.XpubID != r.user.id {
+			return nil, errNotFound("draft transaction %s not found", cmd.ReferenceID)
+		}
+		if draft.Status != response.DraftStatusDraft {
+			return nil, errBadRequest("draft transaction %s is %s", draft.ID, draft.Status)
+		}
+		if err := s.verifyDraftTransaction(tx, draft); err != nil {
+			return nil, err
+		}
+	} else if err := s.verifyExternalTransaction(tx); err != nil {
+		return nil, err
+	}
+
+	t := s.record(tx, cmd.ReferenceID, r.user, cmd.Metadata)
+	if draft != nil {
+		draft.Status = response.DraftStatusComplete
+		draft.FinalTxID = t.id
+		draft.UpdatedAt = t.createdAt
+	}
+	return t.view(r.user), nil
+}
+
+// verifyDraftTransaction verifies that the transaction has the outputs of the draft transaction
+// and validly spends exactly the UTXOs reserved by it.
+func (s *Server) verifyDraftTransaction(tx *trx.Transaction, draft *response.DraftTransaction) error {
+	drafted, err := trx.NewTransactionFromHex(draft.Hex)
+	if err != nil {
+		return err
+	}
+	if len(tx.Outputs) != len(drafted.Outputs) {
+		return errBadRequest("transaction has %d outputs, draft transaction %s has %d", len(tx.Outputs), draft.ID, len(drafted.Outputs))
+	}
+	for i, output := range tx.Outputs {
+		if output.Satoshis != drafted.Outputs[i].Satoshis || !output.LockingScript.Equals(drafted.Outputs[i].LockingScript) {
+			return errBadRequest("output %d does not match draft transaction %s", i, draft.ID)
+		}
+	}
+
+	if len(tx.Inputs) != len(draft.Configuration.Inputs) {
+		return errBadRequest("transaction has %d inputs, draft transaction %s has %d", len(tx.Inputs), draft.ID, len(draft.Configuration.Inputs))
+	}
+	for i, input := range tx.Inputs {
+		utxo := s.utxo(input.SourceTXID.String(), input.SourceTxOutIndex)
+		if utxo == nil || utxo.DraftID != draft.ID || utxo.SpendingTxID != "" {
+			return errBadRequest("input %d does not spend a UTXO reserved by draft transaction %s", i, draft.ID)
+		}
+		lockingScript, err := script.NewFromHex(utxo.ScriptPubKey)
+		if err != nil {
+			return err
+		}
+		input.SetSourceTxOutput(&trx.TransactionOutput{Satoshis: utxo.Satoshis, LockingScript: lockingScript})
+		err = interpreter.NewEngine().Execute(
+			interpreter.WithTx(tx, i, input.SourceTxOutput()),
+			interpreter.WithForkID(),
+			interpreter.WithAfterGenesis(),
+		)
+		if err != nil {
+			return errBadRequest("input %d is not validly signed: %s", i, err)
+		}
+	}
+	return nil
+}
+
+// verifyExternalTransaction verifies that the transaction recorded without a draft transaction
+// pays to the destinations of the users without spending their UTXOs.
+func (s *Server) verifyExternalTransaction(tx *trx.Transaction) error {
+	for i, input := range tx.Inputs {
+		if s.utxo(input.SourceTXID.String(), input.SourceTxOutIndex) != nil {
+			return errBadRequest("input %d spends a UTXO of the wallet without a draft transaction", i)
+		}
+	}
+	for _, output := range tx.Outputs {
+		if _, ok := s.destinations[output.LockingScript.String()]; ok {
+			return nil
+		}
+	}
+	return errBadRequest("transaction does not pay to any destination of the wallet")
+}
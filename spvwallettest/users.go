@@ -0,0 +1,230 @@
+package spvwallettest
+
+import (
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	bip32 "github.com/bitcoin-sv/go-sdk/compat/bip32"
+	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/cryptoutil"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+func (s *Server) sharedConfig(*request) (any, error) {
+	return &response.SharedConfig{PaymailDomains: s.domains, ExperimentalFeatures: s.features}, nil
+}
+
+func (s *Server) currentUser(r *request) (any, error) {
+	return s.xPub(r.user), nil
+}
+
+func (s *Server) updateCurrentUser(r *request) (any, error) {
+	var cmd commands.UpdateXPubMetadata
+	if err := r.decode(&cmd); err != nil {
+		return nil, err
+	}
+	r.user.metadata = mergeMetadata(r.user.metadata, cmd.Metadata)
+	r.user.updatedAt = time.Now()
+	return s.xPub(r.user), nil
+}
+
+func (s *Server) xPub(u *user) *response.Xpub {
+	return &response.Xpub{
+		Model:           response.Model{CreatedAt: u.createdAt, UpdatedAt: u.updatedAt, Metadata: u.metadata},
+		ID:              u.id,
+		CurrentBalance:  s.balance(u),
+		NextInternalNum: u.nextInternalNum,
+		NextExternalNum: u.nextExternalNum,
+	}
+}
+
+func (s *Server) userPaymails(r *request) (any, error) {
+	return page(r, r.user.paymails), nil
+}
+
+func (s *Server) contacts(r *request) (any, error) {
+	contacts := make([]*response.Contact, 0, len(r.user.contacts))
+	for _, contact := range r.user.contacts {
+		contacts = append(contacts, contact)
+	}
+	slices.SortFunc(contacts, func(a, b *response.Contact) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return page(r, contacts), nil
+}
+
+func (s *Server) contact(r *request) (any, error) {
+	return s.findContact(r.user, r.PathValue("paymail"))
+}
+
+// upsertContact adds the contact or updates its name and metadata. A new contact of another user of the server
+// is unconfirmed, and the user becomes the awaiting contact of the other user, as after a PIKE invitation.
+func (s *Server) upsertContact(r *request) (any, error) {
+	var cmd commands.UpsertContact
+	if err := r.decode(&cmd); err != nil {
+		return nil, err
+	}
+	address := r.PathValue("paymail")
+
+	if contact, ok := r.user.contacts[address]; ok {
+		contact.FullName = cmd.FullName
+		contact.Metadata = mergeMetadata(contact.Metadata, cmd.Metadata)
+		contact.UpdatedAt = time.Now()
+		return &response.CreateContactResponse{Contact: contact}, nil
+	}
+
+	paymail, ok := s.paymails[address]
+	if !ok {
+		return nil, errNotFound("paymail %s not found", address)
+	}
+	requester, err := s.requesterPaymail(r.user, cmd.RequesterPaymail)
+	if err != nil {
+		return nil, err
+	}
+
+	contact, err := s.newContact(s.users[paymail.XpubID], address, cmd.FullName, response.ContactNotConfirmed)
+	if err != nil {
+		return nil, err
+	}
+	contact.Metadata = cmd.Metadata
+	r.user.contacts[address] = contact
+
+	other := s.users[paymail.XpubID]
+	if _, ok := other.contacts[requester.Address]; !ok {
+		invitation, err := s.newContact(r.user, requester.Address, requester.PublicName, response.ContactAwaitAccept)
+		if err != nil {
+			return nil, err
+		}
+		other.contacts[requester.Address] = invitation
+	}
+	return &response.CreateContactResponse{Contact: contact}, nil
+}
+
+func (s *Server) requesterPaymail(u *user, address string) (*response.PaymailAddress, error) {
+	for _, paymail := range u.paymails {
+		if address == "" || paymail.Address == address {
+			return paymail, nil
+		}
+	}
+	return nil, errBadRequest("requester paymail %q not found", address)
+}
+
+// newContact returns the contact with the paymail of the user, identified by the public key of the user.
+func (s *Server) newContact(u *user, paymail, fullName string, status response.ContactStatus) (*response.Contact, error) {
+	key, err := bip32.GetHDKeyByPath(u.xPub, cryptoutil.ChainExternal, 0)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &response.Contact{
+		Model:    response.Model{CreatedAt: now, UpdatedAt: now},
+		ID:       cryptoutil.Hash(u.id + paymail),
+		FullName: fullName,
+		Paymail:  paymail,
+		PubKey:   hex.EncodeToString(pubKey.Compressed()),
+		Status:   status,
+	}, nil
+}
+
+func (s *Server) removeContact(r *request) (any, error) {
+	contact, err := s.findContact(r.user, r.PathValue("paymail"))
+	if err != nil {
+		return nil, err
+	}
+	delete(r.user.contacts, contact.Paymail)
+	return nil, nil
+}
+
+func (s *Server) confirmContact(r *request) (any, error) {
+	return nil, s.setContactStatus(r.user, r.PathValue("paymail"), response.ContactNotConfirmed, response.ContactConfirmed)
+}
+
+func (s *Server) unconfirmContact(r *request) (any, error) {
+	return nil, s.setContactStatus(r.user, r.PathValue("paymail"), response.ContactConfirmed, response.ContactNotConfirmed)
+}
+
+func (s *Server) acceptInvitation(r *request) (any, error) {
+	return nil, s.setContactStatus(r.user, r.PathValue("paymail"), response.ContactAwaitAccept, response.ContactNotConfirmed)
+}
+
+func (s *Server) rejectInvitation(r *request) (any, error) {
+	address := r.PathValue("paymail")
+	if err := s.setContactStatus(r.user, address, response.ContactAwaitAccept, response.ContactRejected); err != nil {
+		return nil, err
+	}
+	delete(r.user.contacts, address)
+	return nil, nil
+}
+
+func (s *Server) setContactStatus(u *user, paymail string, from, to response.ContactStatus) error {
+	contact, err := s.findContact(u, paymail)
+	if err != nil {
+		return err
+	}
+	if contact.Status != from {
+		return errBadRequest("contact %s is %s, not %s", paymail, contact.Status, from)
+	}
+	contact.Status = to
+	contact.UpdatedAt = time.Now()
+	return nil
+}
+
+func (s *Server) findContact(u *user, paymail string) (*response.Contact, error) {
+	contact, ok := u.contacts[paymail]
+	if !ok {
+		return nil, errNotFound("contact %s not found", paymail)
+	}
+	return contact, nil
+}
+
+func (s *Server) createXPub(r *request) (any, error) {
+	var cmd commands.CreateUserXpub
+	if err := r.decode(&cmd); err != nil {
+		return nil, err
+	}
+	key, err := bip32.NewKeyFromString(cmd.XPub)
+	if err != nil || key.IsPrivate() {
+		return nil, errBadRequest("invalid xPub")
+	}
+	if _, ok := s.users[cryptoutil.Hash(cmd.XPub)]; ok {
+		return nil, errConflict("xPub already exists")
+	}
+	return s.xPub(s.addUser(key, cmd.XPub, cmd.Metadata)), nil
+}
+
+func (s *Server) createPaymail(r *request) (any, error) {
+	var cmd commands.CreatePaymail
+	if err := r.decode(&cmd); err != nil {
+		return nil, err
+	}
+	u, ok := s.users[cryptoutil.Hash(cmd.Key)]
+	if !ok {
+		return nil, errNotFound("xPub not found")
+	}
+	paymail, err := s.addPaymail(u, strings.ToLower(cmd.Address), cmd.PublicName, cmd.Avatar)
+	if err != nil {
+		return nil, errBadRequest("%s", err)
+	}
+	paymail.Metadata = cmd.Metadata
+	return paymail, nil
+}
+
+// mergeMetadata returns the metadata updated with the keys of the update; a nil value removes the key.
+func mergeMetadata(metadata, update map[string]any) map[string]any {
+	if metadata == nil {
+		metadata = make(map[string]any, len(update))
+	}
+	for k, v := range update {
+		if v == nil {
+			delete(metadata, k)
+		} else {
+			metadata[k] = v
+		}
+	}
+	return metadata
+}
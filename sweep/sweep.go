@@ -0,0 +1,199 @@
+// Package sweep provides building blocks for moving the funds locked to an external private key,
+// e.g., a paper wallet or a legacy hot key, into the SPV Wallet.
+//
+// The SPV Wallet doesn't know the UTXOs of keys it doesn't manage, so they are taken from a pluggable
+// UTXOSource, e.g., a blockchain explorer. The funds are sent to a destination of the wallet provided
+// by a pluggable DestinationResolver in a single P2PKH transaction signed locally with the swept key.
+package sweep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bitcoin-sv/go-sdk/script"
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	sighash "github.com/bitcoin-sv/go-sdk/transaction/sighash"
+	"github.com/bitcoin-sv/go-sdk/transaction/template/p2pkh"
+	"github.com/bitcoin-sv/spv-wallet-go-client/coinselect"
+	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/cryptoutil"
+)
+
+// UTXO is an unspent output locked to the swept key.
+type UTXO struct {
+	TransactionID string // ID of the transaction which created the output.
+	OutputIndex   uint32 // Index of the output in the transaction.
+	Satoshis      uint64 // Value of the output.
+	LockingScript string // Hex encoded locking script; it must be the P2PKH script of the key's address if set.
+}
+
+// UTXOSource provides the unspent outputs locked to the P2PKH address of the swept key.
+type UTXOSource interface {
+	UTXOs(ctx context.Context, address *script.Address) ([]*UTXO, error)
+}
+
+// UTXOSourceFunc is an adapter which allows to use an ordinary function as a UTXOSource.
+type UTXOSourceFunc func(ctx context.Context, address *script.Address) ([]*UTXO, error)
+
+// UTXOs calls f(ctx, address).
+func (f UTXOSourceFunc) UTXOs(ctx context.Context, address *script.Address) ([]*UTXO, error) {
+	return f(ctx, address)
+}
+
+// DestinationResolver provides a fresh locking script of the wallet which receives the swept funds.
+// The destination must be known to the SPV Wallet, e.g., resolved through the paymail P2P payment
+// destination endpoint of the user's paymail, otherwise the recorded transaction won't be
+// attributed to the user.
+type DestinationResolver interface {
+	Destination(ctx context.Context, satoshis uint64) (*script.Script, error)
+}
+
+// DestinationResolverFunc is an adapter which allows to use an ordinary function as a DestinationResolver.
+type DestinationResolverFunc func(ctx context.Context, satoshis uint64) (*script.Script, error)
+
+// Destination calls f(ctx, satoshis).
+func (f DestinationResolverFunc) Destination(ctx context.Context, satoshis uint64) (*script.Script, error) {
+	return f(ctx, satoshis)
+}
+
+// BuildTransaction builds and signs the transaction which spends all UTXOs of the private key
+// into a single output locked to the destination provided by the resolver.
+// The fee is deducted from the swept value.
+func BuildTransaction(ctx context.Context, cmd *commands.SweepPrivateKey, source UTXOSource, resolver DestinationResolver) (*trx.Transaction, error) {
+	key, err := cryptoutil.PrivateKeyFromHexOrWIF(cmd.PrivateKey, !cmd.Testnet)
+	if err != nil {
+		return nil, errors.Join(goclienterr.ErrInvalidPrivateKey, err)
+	}
+
+	address, err := script.NewAddressFromPublicKeyWithCompression(key.PubKey(), key.Mainnet, key.CompressPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create address of the private key: %w", err)
+	}
+
+	utxos, err := source.UTXOs(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch UTXOs of address %s: %w", address.AddressString, err)
+	}
+	if len(utxos) == 0 {
+		return nil, fmt.Errorf("%w: %s", goclienterr.ErrNothingToSweep, address.AddressString)
+	}
+
+	tx, err := unsignedTransaction(key, address, utxos)
+	if err != nil {
+		return nil, err
+	}
+
+	var total uint64
+	for _, utxo := range utxos {
+		total += utxo.Satoshis
+	}
+	unit := cmd.FeeUnit
+	if unit == nil {
+		unit = &coinselect.DefaultFeeUnit
+	}
+
+	// The destination is resolved with the value left after the fee of a P2PKH output,
+	// and the value is adjusted to the fee of the transaction with the resolved output.
+	fee := coinselect.Fee(unit, estimateSize(tx)+coinselect.P2PKHOutputSize)
+	if total <= fee {
+		return nil, fmt.Errorf("%w: swept value %d doesn't cover the fee %d", goclienterr.ErrInsufficientFunds, total, fee)
+	}
+
+	lockingScript, err := resolver.Destination(ctx, total-fee)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve destination: %w", err)
+	}
+	if lockingScript == nil || len(*lockingScript) == 0 {
+		return nil, goclienterr.ErrMissingDestination
+	}
+
+	output := &trx.TransactionOutput{LockingScript: lockingScript}
+	tx.AddOutput(output)
+	fee = coinselect.Fee(unit, estimateSize(tx))
+	if total <= fee {
+		return nil, fmt.Errorf("%w: swept value %d doesn't cover the fee %d", goclienterr.ErrInsufficientFunds, total, fee)
+	}
+	output.Satoshis = total - fee
+
+	if err := tx.Sign(); err != nil {
+		return nil, errors.Join(goclienterr.ErrSignTransaction, err)
+	}
+
+	return tx, nil
+}
+
+func unsignedTransaction(key *cryptoutil.PrivateKey, address *script.Address, utxos []*UTXO) (*trx.Transaction, error) {
+	p2pkhScript, err := p2pkh.Lock(address)
+	if err != nil {
+		return nil, errors.Join(goclienterr.ErrCreateLockingScript, err)
+	}
+
+	unlockScript := &unlocker{key: key, sigHashFlag: sighash.AllForkID}
+
+	tx := trx.NewTransaction()
+	for _, utxo := range utxos {
+		if utxo.LockingScript != "" {
+			lockingScript, err := script.NewFromHex(utxo.LockingScript)
+			if err != nil || !lockingScript.Equals(p2pkhScript) {
+				return nil, fmt.Errorf("%w: output %s:%d isn't locked to address %s",
+					goclienterr.ErrUnsupportedLockingScript, utxo.TransactionID, utxo.OutputIndex, address.AddressString)
+			}
+		}
+
+		err = tx.AddInputFrom(utxo.TransactionID, utxo.OutputIndex, p2pkhScript.String(), utxo.Satoshis, unlockScript)
+		if err != nil {
+			return nil, errors.Join(goclienterr.ErrAddInputsToTransaction, err)
+		}
+	}
+	return tx, nil
+}
+
+// estimateSize returns the size of the transaction once its inputs are unlocked by their templates.
+func estimateSize(tx *trx.Transaction) int {
+	size := tx.Size()
+	for i, input := range tx.Inputs {
+		size += int(input.UnlockingScriptTemplate.EstimateLength(tx, uint32(i))) //nolint: gosec // the number of inputs fits in uint32
+	}
+	return size
+}
+
+// unlocker signs the P2PKH inputs with the public key serialized in the form the swept key was imported with;
+// p2pkh.P2PKH always uses the compressed public key, which doesn't match the address of an uncompressed WIF key.
+type unlocker struct {
+	key         *cryptoutil.PrivateKey
+	sigHashFlag sighash.Flag
+}
+
+// Sign returns the unlocking script of the input: the signature followed by the public key.
+func (u *unlocker) Sign(tx *trx.Transaction, inputIndex uint32) (*script.Script, error) {
+	if tx.Inputs[inputIndex].SourceTxOutput() == nil {
+		return nil, trx.ErrEmptyPreviousTx
+	}
+
+	sh, err := tx.CalcInputSignatureHash(inputIndex, u.sigHashFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := u.key.Sign(sh)
+	if err != nil {
+		return nil, err
+	}
+
+	unlockingScript := &script.Script{}
+	if err := unlockingScript.AppendPushData(append(sig.Serialize(), uint8(u.sigHashFlag))); err != nil {
+		return nil, err
+	}
+	if err := unlockingScript.AppendPushData(u.key.PubKeyBytes()); err != nil {
+		return nil, err
+	}
+	return unlockingScript, nil
+}
+
+// EstimateLength returns the maximum length of the unlocking script:
+// the pushes of a DER signature of up to 72 bytes with the sighash flag, and of the public key.
+func (u *unlocker) EstimateLength(*trx.Transaction, uint32) uint32 {
+	return uint32(1 + 73 + 1 + len(u.key.PubKeyBytes())) //nolint: gosec // the public key has at most 65 bytes
+}
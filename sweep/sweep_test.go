@@ -0,0 +1,199 @@
+package sweep_test
+
+import (
+	"context"
+	"testing"
+
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/bitcoin-sv/go-sdk/script"
+	"github.com/bitcoin-sv/go-sdk/script/interpreter"
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/bitcoin-sv/go-sdk/transaction/template/p2pkh"
+	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
+	"github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/sweep"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// uncompressedWIF is the uncompressed mainnet WIF of the private key 0C28FCA386C7A227600B2FE50B7CAE11EC86D3BF1F643F1FE2FA43E6B1F7C4B7.
+	uncompressedWIF     = "5HueCGU8rMjxEXxiPuD5BDku4MkFqeZyd4dZ1jvhTVqvbTLvyTJ"
+	uncompressedAddress = "1GAehh7TsJAHuUAeKZcXf5CnwuGuGgyX2S"
+)
+
+func TestBuildTransaction(t *testing.T) {
+	key, err := ec.PrivateKeyFromHex("0C28FCA386C7A227600B2FE50B7CAE11EC86D3BF1F643F1FE2FA43E6B1F7C4B7")
+	require.NoError(t, err)
+	compressedAddress, err := script.NewAddressFromPublicKey(key.PubKey(), true)
+	require.NoError(t, err)
+	testnetAddress, err := script.NewAddressFromPublicKey(key.PubKey(), false)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		cmd             *commands.SweepPrivateKey
+		expectedAddress string
+	}{
+		"compressed WIF": {
+			cmd:             &commands.SweepPrivateKey{PrivateKey: key.Wif()},
+			expectedAddress: compressedAddress.AddressString,
+		},
+		"uncompressed WIF": {
+			cmd:             &commands.SweepPrivateKey{PrivateKey: uncompressedWIF},
+			expectedAddress: uncompressedAddress,
+		},
+		"testnet WIF": {
+			cmd:             &commands.SweepPrivateKey{PrivateKey: key.WifPrefix(0xef)},
+			expectedAddress: testnetAddress.AddressString,
+		},
+		"hex key on testnet": {
+			cmd:             &commands.SweepPrivateKey{PrivateKey: "0C28FCA386C7A227600B2FE50B7CAE11EC86D3BF1F643F1FE2FA43E6B1F7C4B7", Testnet: true},
+			expectedAddress: testnetAddress.AddressString,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given:
+			var lockingScript *script.Script
+			source := sweep.UTXOSourceFunc(func(_ context.Context, address *script.Address) ([]*sweep.UTXO, error) {
+				require.Equal(t, tc.expectedAddress, address.AddressString)
+				lockingScript, err = p2pkh.Lock(address)
+				require.NoError(t, err)
+				return givenUTXOs(), nil
+			})
+			destination := givenDestination(t)
+			resolver := sweep.DestinationResolverFunc(func(_ context.Context, satoshis uint64) (*script.Script, error) {
+				require.Equal(t, uint64(1499), satoshis)
+				return destination, nil
+			})
+
+			// when:
+			tx, err := sweep.BuildTransaction(context.Background(), tc.cmd, source, resolver)
+
+			// then:
+			require.NoError(t, err)
+			require.Len(t, tx.Inputs, 2)
+			require.Len(t, tx.Outputs, 1)
+			require.Equal(t, uint64(1499), tx.Outputs[0].Satoshis)
+			require.Equal(t, destination, tx.Outputs[0].LockingScript)
+			for i, utxo := range givenUTXOs() {
+				prevOutput := &trx.TransactionOutput{Satoshis: utxo.Satoshis, LockingScript: lockingScript}
+				require.NoError(t, interpreter.NewEngine().Execute(interpreter.WithTx(tx, i, prevOutput), interpreter.WithForkID(), interpreter.WithAfterGenesis()))
+			}
+		})
+	}
+}
+
+func TestBuildTransaction_Fee(t *testing.T) {
+	// given:
+	unit := &response.FeeUnit{Satoshis: 50, Bytes: 1000}
+	source := sweep.UTXOSourceFunc(func(context.Context, *script.Address) ([]*sweep.UTXO, error) { return givenUTXOs(), nil })
+	resolver := sweep.DestinationResolverFunc(func(context.Context, uint64) (*script.Script, error) { return givenDestination(t), nil })
+
+	tests := map[string]struct {
+		privateKey       string
+		expectedSatoshis uint64
+		maxSize          int
+	}{
+		"compressed WIF": {
+			privateKey:       "KwdMAjGmerYanjeui5SHS7JkmpZvVipYvB2LJGU1ZxJwYvP98617",
+			expectedSatoshis: 1500 - 18, // 342 bytes with 108 bytes unlocking scripts
+			maxSize:          342,
+		},
+		"uncompressed WIF": {
+			privateKey:       uncompressedWIF,
+			expectedSatoshis: 1500 - 21, // 406 bytes with 140 bytes unlocking scripts
+			maxSize:          406,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// when:
+			tx, err := sweep.BuildTransaction(context.Background(), &commands.SweepPrivateKey{PrivateKey: tc.privateKey, FeeUnit: unit}, source, resolver)
+
+			// then:
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedSatoshis, tx.Outputs[0].Satoshis)
+			require.LessOrEqual(t, tx.Size(), tc.maxSize)
+		})
+	}
+}
+
+func TestBuildTransaction_Errors(t *testing.T) {
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	source := sweep.UTXOSourceFunc(func(context.Context, *script.Address) ([]*sweep.UTXO, error) { return givenUTXOs(), nil })
+	resolver := sweep.DestinationResolverFunc(func(context.Context, uint64) (*script.Script, error) { return givenDestination(t), nil })
+
+	tests := map[string]struct {
+		cmd         *commands.SweepPrivateKey
+		source      sweep.UTXOSource
+		resolver    sweep.DestinationResolver
+		expectedErr error
+	}{
+		"invalid private key": {
+			cmd:         &commands.SweepPrivateKey{PrivateKey: "invalid"},
+			source:      source,
+			resolver:    resolver,
+			expectedErr: errors.ErrInvalidPrivateKey,
+		},
+		"no UTXOs": {
+			cmd:         &commands.SweepPrivateKey{PrivateKey: key.Wif()},
+			source:      sweep.UTXOSourceFunc(func(context.Context, *script.Address) ([]*sweep.UTXO, error) { return nil, nil }),
+			resolver:    resolver,
+			expectedErr: errors.ErrNothingToSweep,
+		},
+		"swept value doesn't cover the fee": {
+			cmd: &commands.SweepPrivateKey{PrivateKey: key.Wif()},
+			source: sweep.UTXOSourceFunc(func(context.Context, *script.Address) ([]*sweep.UTXO, error) {
+				return []*sweep.UTXO{{TransactionID: givenUTXOs()[0].TransactionID, Satoshis: 1}}, nil
+			}),
+			resolver:    resolver,
+			expectedErr: errors.ErrInsufficientFunds,
+		},
+		"UTXO not locked to the key's address": {
+			cmd: &commands.SweepPrivateKey{PrivateKey: key.Wif()},
+			source: sweep.UTXOSourceFunc(func(context.Context, *script.Address) ([]*sweep.UTXO, error) {
+				utxo := givenUTXOs()[0]
+				utxo.LockingScript = givenDestination(t).String()
+				return []*sweep.UTXO{utxo}, nil
+			}),
+			resolver:    resolver,
+			expectedErr: errors.ErrUnsupportedLockingScript,
+		},
+		"missing destination": {
+			cmd:         &commands.SweepPrivateKey{PrivateKey: key.Wif()},
+			source:      source,
+			resolver:    sweep.DestinationResolverFunc(func(context.Context, uint64) (*script.Script, error) { return nil, nil }),
+			expectedErr: errors.ErrMissingDestination,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// when:
+			tx, err := sweep.BuildTransaction(context.Background(), tc.cmd, tc.source, tc.resolver)
+
+			// then:
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Nil(t, tx)
+		})
+	}
+}
+
+func givenUTXOs() []*sweep.UTXO {
+	return []*sweep.UTXO{
+		{TransactionID: "f365f6973db944fdbd0dba8e94ca63f2f365f6973db944fdbd0dba8e94ca63f2", OutputIndex: 0, Satoshis: 1000},
+		{TransactionID: "54ed5bcba96447af892b1054065c28a854ed5bcba96447af892b1054065c28a8", OutputIndex: 3, Satoshis: 500},
+	}
+}
+
+func givenDestination(t *testing.T) *script.Script {
+	t.Helper()
+	destination, err := script.NewAddressFromString("1MB8MfCyA5mGt3UBhxYr1exBfsFWgL1gCm")
+	require.NoError(t, err)
+	lockingScript, err := p2pkh.Lock(destination)
+	require.NoError(t, err)
+	return lockingScript
+}
@@ -0,0 +1,250 @@
+package txexport
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+)
+
+// Format is the output format of the export.
+type Format string
+
+// Supported formats. CSV and JSON lines express the amounts in satoshis,
+// while OFX and QIF, consumed by the accounting software, express them in BSV.
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+	FormatOFX   Format = "ofx"
+	FormatQIF   Format = "qif"
+)
+
+// CurrencyCode is the currency of the amounts of the OFX statements.
+const CurrencyCode = "BSV"
+
+const satoshisPerBSV = 100_000_000
+
+// writer writes the statement in a single format.
+type writer interface {
+	header(statement *Statement) error
+	row(row *Row) error
+	footer(summary *Summary, statement *Statement) error
+}
+
+func (e *Exporter) newWriter(w io.Writer, format Format) (writer, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w), metadataColumns: e.metadataColumns, sanitize: !e.rawCSV}, nil
+	case FormatJSONL:
+		return &jsonlWriter{enc: json.NewEncoder(w)}, nil
+	case FormatOFX:
+		return &ofxWriter{w: w}, nil
+	case FormatQIF:
+		return &qifWriter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", goclienterr.ErrTransactionExport, format)
+	}
+}
+
+// CSVHeader is the header of the CSV export, followed by the configured metadata columns and the metadata column.
+var CSVHeader = []string{"transaction_id", "created_at", "status", "block_height", "direction", "counterparty", "amount", "fee", "balance"}
+
+type csvWriter struct {
+	w               *csv.Writer
+	metadataColumns []string
+	sanitize        bool
+}
+
+func (c *csvWriter) header(*Statement) error {
+	header := append(append(append([]string{}, CSVHeader...), c.metadataColumns...), "metadata")
+	return c.w.Write(header)
+}
+
+func (c *csvWriter) row(row *Row) error {
+	record := []string{
+		c.text(row.TransactionID),
+		row.CreatedAt.UTC().Format(time.RFC3339),
+		c.text(row.Status),
+		strconv.FormatUint(row.BlockHeight, 10),
+		c.text(row.Direction),
+		c.text(row.Counterparty),
+		strconv.FormatInt(row.Amount, 10),
+		strconv.FormatUint(row.Fee, 10),
+		strconv.FormatInt(row.Balance, 10),
+	}
+	for _, key := range c.metadataColumns {
+		value, err := metadataValue(row.Metadata[key])
+		if err != nil {
+			return err
+		}
+		record = append(record, c.text(value))
+	}
+
+	metadata := ""
+	if len(row.Metadata) > 0 {
+		b, err := json.Marshal(row.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata of transaction %s: %w", row.TransactionID, err)
+		}
+		metadata = string(b)
+	}
+	return c.w.Write(append(record, c.text(metadata)))
+}
+
+// text returns the text cell, prefixed with "'" if a spreadsheet would evaluate it as a formula.
+// The numeric cells, e.g., the negative amounts, are written by the exporter itself and are never prefixed.
+func (c *csvWriter) text(cell string) string {
+	if c.sanitize && cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func (c *csvWriter) footer(*Summary, *Statement) error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func metadataValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode metadata value: %w", err)
+		}
+		return string(b), nil
+	}
+}
+
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func (j *jsonlWriter) header(*Statement) error {
+	return nil
+}
+
+func (j *jsonlWriter) row(row *Row) error {
+	return j.enc.Encode(row)
+}
+
+func (j *jsonlWriter) footer(*Summary, *Statement) error {
+	return nil
+}
+
+// ofxWriter writes an OFX 2.2 bank statement, with the transaction IDs as the FITIDs.
+type ofxWriter struct {
+	w io.Writer
+}
+
+func (o *ofxWriter) header(statement *Statement) error {
+	_, err := fmt.Fprintf(o.w, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><TRNUID>0</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<STMTRS><CURDEF>%s</CURDEF>
+<BANKACCTFROM><BANKID>SPVWALLET</BANKID><ACCTID>%s</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>
+`, ofxTime(statement.To), CurrencyCode, xmlText(statement.Account), ofxTime(statement.From), ofxTime(statement.To))
+	return err
+}
+
+func (o *ofxWriter) row(row *Row) error {
+	trnType := "CREDIT"
+	if row.Amount < 0 {
+		trnType = "DEBIT"
+	}
+	name := ""
+	if row.Counterparty != "" {
+		// NAME is limited to 32 characters by the OFX specification.
+		name = "<NAME>" + xmlText(truncate(row.Counterparty, 32)) + "</NAME>"
+	}
+	_, err := fmt.Fprintf(o.w, "<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%s</FITID>%s<MEMO>%s</MEMO></STMTTRN>\n",
+		trnType, ofxTime(row.CreatedAt), formatBSV(row.Amount), xmlText(row.TransactionID), name, xmlText(memo(row)))
+	return err
+}
+
+func (o *ofxWriter) footer(summary *Summary, statement *Statement) error {
+	_, err := fmt.Fprintf(o.w, `</BANKTRANLIST>
+<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`, formatBSV(summary.ClosingBalance), ofxTime(statement.To))
+	return err
+}
+
+// qifWriter writes a QIF bank account history.
+type qifWriter struct {
+	w io.Writer
+}
+
+func (q *qifWriter) header(*Statement) error {
+	_, err := io.WriteString(q.w, "!Type:Bank\n")
+	return err
+}
+
+func (q *qifWriter) row(row *Row) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "D%s\nT%s\n", row.CreatedAt.UTC().Format("01/02/2006"), formatBSV(row.Amount))
+	if row.Counterparty != "" {
+		fmt.Fprintf(&b, "P%s\n", qifText(row.Counterparty))
+	}
+	fmt.Fprintf(&b, "M%s\n^\n", qifText(memo(row)))
+	_, err := io.WriteString(q.w, b.String())
+	return err
+}
+
+func (q *qifWriter) footer(*Summary, *Statement) error {
+	return nil
+}
+
+// memo describes the transaction in the accounting formats, which don't have a field for its ID and fee.
+func memo(row *Row) string {
+	if row.Fee > 0 {
+		return fmt.Sprintf("%s (fee %s %s)", row.TransactionID, formatBSV(int64(row.Fee)), CurrencyCode) //nolint: gosec // fees are far below the int64 range
+	}
+	return row.TransactionID
+}
+
+// formatBSV formats the satoshis as BSV with all 8 decimal places.
+func formatBSV(satoshis int64) string {
+	sign := ""
+	whole, fraction := satoshis/satoshisPerBSV, satoshis%satoshisPerBSV
+	if satoshis < 0 {
+		sign = "-"
+		whole, fraction = -whole, -fraction
+	}
+	return fmt.Sprintf("%s%d.%08d", sign, whole, fraction)
+}
+
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
+
+func xmlText(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func qifText(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
@@ -0,0 +1,334 @@
+// Package txexport exports the transaction history of a wallet to CSV, JSON lines and the OFX and QIF accounting formats.
+//
+// The Exporter pages through the transactions created within a date range, oldest first, and writes every page
+// to the io.Writer before fetching the next one, so that the full history is never held in memory:
+//
+//	summary, err := txexport.New(txexport.FromUser(userAPI)).Export(ctx, file, txexport.FormatCSV, from, to)
+//
+// Every exported row carries the direction, the counterparty paymail, the signed amount, the fee,
+// the running balance and the metadata of the transaction. Rejected transactions are skipped,
+// as they don't affect the balance.
+package txexport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/queries"
+	"github.com/bitcoin-sv/spv-wallet/models/filter"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// DefaultPageSize is the default size of the transaction pages fetched by the Exporter.
+const DefaultPageSize = 100
+
+// DefaultAccount is the default account identifier of the OFX statements.
+const DefaultAccount = "spv-wallet"
+
+// Transaction directions.
+const (
+	DirectionIncoming = "incoming"
+	DirectionOutgoing = "outgoing"
+)
+
+const statusRejected = "REJECTED"
+
+// Source provides the pages of transactions created within the time range.
+type Source interface {
+	TransactionsPage(ctx context.Context, page filter.Page, created filter.TimeRange) (*queries.TransactionPage, error)
+}
+
+// SourceFunc is an adapter which allows to use an ordinary function as a Source.
+type SourceFunc func(ctx context.Context, page filter.Page, created filter.TimeRange) (*queries.TransactionPage, error)
+
+// TransactionsPage calls f(ctx, page, created).
+func (f SourceFunc) TransactionsPage(ctx context.Context, page filter.Page, created filter.TimeRange) (*queries.TransactionPage, error) {
+	return f(ctx, page, created)
+}
+
+// UserTransactions lists the transactions of the user. It is implemented by the *spvwallet.UserAPI.
+type UserTransactions interface {
+	Transactions(ctx context.Context, opts ...queries.QueryOption[filter.TransactionFilter]) (*queries.TransactionPage, error)
+}
+
+// AdminTransactions lists the transactions of all xPubs. It is implemented by the *spvwallet.AdminAPI.
+type AdminTransactions interface {
+	Transactions(ctx context.Context, opts ...queries.QueryOption[filter.AdminTransactionFilter]) (*queries.TransactionPage, error)
+}
+
+// FromUser returns the Source of the transactions of the user.
+func FromUser(api UserTransactions) Source {
+	return SourceFunc(func(ctx context.Context, page filter.Page, created filter.TimeRange) (*queries.TransactionPage, error) {
+		return api.Transactions(ctx,
+			queries.QueryWithFilter(filter.TransactionFilter{ModelFilter: filter.ModelFilter{CreatedRange: &created}}),
+			queries.QueryWithPageFilter[filter.TransactionFilter](page),
+		)
+	})
+}
+
+// FromAdmin returns the Source of the transactions of a single xPub, listed with the admin API.
+func FromAdmin(api AdminTransactions, xPubID string) Source {
+	return SourceFunc(func(ctx context.Context, page filter.Page, created filter.TimeRange) (*queries.TransactionPage, error) {
+		return api.Transactions(ctx,
+			queries.QueryWithFilter(filter.AdminTransactionFilter{
+				TransactionFilter: filter.TransactionFilter{ModelFilter: filter.ModelFilter{CreatedRange: &created}},
+				XPubID:            &xPubID,
+			}),
+			queries.QueryWithPageFilter[filter.AdminTransactionFilter](page),
+		)
+	})
+}
+
+// Row is a single exported transaction.
+type Row struct {
+	TransactionID string         `json:"transactionId"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	Status        string         `json:"status"`
+	BlockHeight   uint64         `json:"blockHeight"`
+	Direction     string         `json:"direction"`
+	Counterparty  string         `json:"counterparty,omitempty"` // Paymail of the other party, if known.
+	Amount        int64          `json:"amount"`                 // Change of the balance in satoshis, including the fee of outgoing transactions.
+	Fee           uint64         `json:"fee"`                    // Fee in satoshis, paid by the wallet for outgoing transactions only.
+	Balance       int64          `json:"balance"`                // Running balance in satoshis after the transaction.
+	Metadata      map[string]any `json:"metadata,omitempty"`
+}
+
+// Statement describes the exported history, passed to the format before the first row.
+type Statement struct {
+	Account        string
+	From           time.Time // Start of the exported range, or the creation time of the first transaction if the range is open.
+	To             time.Time // End of the exported range, or the time of the export if the range is open.
+	OpeningBalance int64
+}
+
+// Summary sums up the exported history.
+type Summary struct {
+	Rows           int
+	Incoming       int64 // Sum of the incoming amounts.
+	Outgoing       int64 // Sum of the outgoing amounts, as a negative number.
+	Fees           uint64
+	OpeningBalance int64
+	ClosingBalance int64
+}
+
+// Exporter writes the transaction history in the chosen format.
+type Exporter struct {
+	source          Source
+	pageSize        int
+	openingBalance  int64
+	account         string
+	counterparty    func(tx *response.Transaction) string
+	metadataColumns []string
+	rawCSV          bool
+	now             func() time.Time
+}
+
+// Option configures the Exporter.
+type Option func(*Exporter)
+
+// WithPageSize sets the size of the fetched transaction pages.
+func WithPageSize(size int) Option {
+	return func(e *Exporter) {
+		if size > 0 {
+			e.pageSize = size
+		}
+	}
+}
+
+// WithOpeningBalance sets the balance in satoshis before the first exported transaction, zero by default.
+// The running balance is correct only if it matches the balance at the start of the exported range.
+func WithOpeningBalance(satoshis int64) Option {
+	return func(e *Exporter) {
+		e.openingBalance = satoshis
+	}
+}
+
+// WithAccount sets the account identifier of the statement; DefaultAccount by default.
+func WithAccount(account string) Option {
+	return func(e *Exporter) {
+		if account != "" {
+			e.account = account
+		}
+	}
+}
+
+// WithCounterparty sets the function resolving the counterparty paymail of a transaction; Counterparty by default.
+func WithCounterparty(counterparty func(tx *response.Transaction) string) Option {
+	return func(e *Exporter) {
+		if counterparty != nil {
+			e.counterparty = counterparty
+		}
+	}
+}
+
+// WithMetadataColumns adds a CSV column for each of the metadata keys, next to the column with the whole metadata.
+func WithMetadataColumns(keys ...string) Option {
+	return func(e *Exporter) {
+		e.metadataColumns = append(e.metadataColumns, keys...)
+	}
+}
+
+// WithoutCSVSanitizing writes the text cells of the CSV export as they are. By default, the cells starting
+// with "=", "+", "-", "@", a tab or a carriage return, which spreadsheets evaluate as formulas, are prefixed with "'".
+func WithoutCSVSanitizing() Option {
+	return func(e *Exporter) {
+		e.rawCSV = true
+	}
+}
+
+// WithClock sets the function returning the current time; time.Now by default.
+func WithClock(now func() time.Time) Option {
+	return func(e *Exporter) {
+		e.now = now
+	}
+}
+
+// New creates a new Exporter of the transactions of the source.
+func New(source Source, opts ...Option) *Exporter {
+	e := &Exporter{
+		source:       source,
+		pageSize:     DefaultPageSize,
+		account:      DefaultAccount,
+		counterparty: Counterparty,
+		now:          time.Now,
+	}
+	for _, o := range opts {
+		o(e)
+	}
+	return e
+}
+
+// Export writes the transactions created within [from, to] to w in the given format, oldest first.
+// A zero from or to leaves the range open on that side. On error, w may hold a partial export.
+func (e *Exporter) Export(ctx context.Context, w io.Writer, format Format, from, to time.Time) (*Summary, error) {
+	out, err := e.newWriter(w, format)
+	if err != nil {
+		return nil, err
+	}
+
+	created := filter.TimeRange{}
+	if !from.IsZero() {
+		created.From = &from
+	}
+	if !to.IsZero() {
+		created.To = &to
+	}
+
+	statement := &Statement{Account: e.account, From: from, To: to, OpeningBalance: e.openingBalance}
+	if statement.To.IsZero() {
+		statement.To = e.now()
+	}
+	summary := &Summary{OpeningBalance: e.openingBalance, ClosingBalance: e.openingBalance}
+	started := false
+	// The IDs of the previous page skip the transactions repeated at the page boundary.
+	var previous map[string]struct{}
+
+	for number := 1; ; number++ {
+		page, err := e.source.TransactionsPage(ctx, filter.Page{Number: number, Size: e.pageSize, SortBy: "created_at", Sort: "asc"}, created)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch transactions page %d: %w", number, err)
+		}
+
+		current := make(map[string]struct{}, len(page.Content))
+		for _, tx := range page.Content {
+			if tx == nil || tx.Status == statusRejected {
+				continue
+			}
+			if _, ok := previous[tx.ID]; ok {
+				continue
+			}
+			current[tx.ID] = struct{}{}
+
+			if !started {
+				if statement.From.IsZero() {
+					statement.From = tx.CreatedAt
+				}
+				if err := out.header(statement); err != nil {
+					return nil, fmt.Errorf("%w: %w", goclienterr.ErrTransactionExport, err)
+				}
+				started = true
+			}
+			if err := out.row(e.row(tx, summary)); err != nil {
+				return nil, fmt.Errorf("%w: %w", goclienterr.ErrTransactionExport, err)
+			}
+		}
+		previous = current
+
+		if len(page.Content) < e.pageSize || number >= page.Page.TotalPages {
+			break
+		}
+	}
+
+	if !started {
+		if statement.From.IsZero() {
+			statement.From = statement.To
+		}
+		if err := out.header(statement); err != nil {
+			return nil, fmt.Errorf("%w: %w", goclienterr.ErrTransactionExport, err)
+		}
+	}
+	if err := out.footer(summary, statement); err != nil {
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrTransactionExport, err)
+	}
+	return summary, nil
+}
+
+func (e *Exporter) row(tx *response.Transaction, summary *Summary) *Row {
+	summary.Rows++
+	summary.ClosingBalance += tx.OutputValue
+	if tx.OutputValue >= 0 {
+		summary.Incoming += tx.OutputValue
+	} else {
+		summary.Outgoing += tx.OutputValue
+	}
+
+	row := &Row{
+		TransactionID: tx.ID,
+		CreatedAt:     tx.CreatedAt,
+		Status:        tx.Status,
+		BlockHeight:   tx.BlockHeight,
+		Direction:     direction(tx),
+		Counterparty:  e.counterparty(tx),
+		Amount:        tx.OutputValue,
+		Balance:       summary.ClosingBalance,
+		Metadata:      tx.Metadata,
+	}
+	if row.Direction == DirectionOutgoing {
+		row.Fee = tx.Fee
+		summary.Fees += tx.Fee
+	}
+	return row
+}
+
+func direction(tx *response.Transaction) string {
+	switch {
+	case tx.TransactionDirection == DirectionIncoming || tx.TransactionDirection == DirectionOutgoing:
+		return tx.TransactionDirection
+	case tx.OutputValue < 0:
+		return DirectionOutgoing
+	default:
+		return DirectionIncoming
+	}
+}
+
+// Counterparty returns the paymail of the other party of the transaction, read from the metadata set by the SPV Wallet:
+// the "receiver" of outgoing and the "sender" of incoming transactions, falling back to the sender of the P2P metadata.
+// It returns an empty string if the counterparty is unknown.
+func Counterparty(tx *response.Transaction) string {
+	key := "sender"
+	if direction(tx) == DirectionOutgoing {
+		key = "receiver"
+	}
+	if paymail, ok := tx.Metadata[key].(string); ok && paymail != "" {
+		return paymail
+	}
+	if p2p, ok := tx.Metadata["p2p_tx_metadata"].(map[string]any); ok && direction(tx) == DirectionIncoming {
+		if paymail, ok := p2p["sender"].(string); ok {
+			return paymail
+		}
+	}
+	return ""
+}
@@ -0,0 +1,256 @@
+package txexport_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/queries"
+	"github.com/bitcoin-sv/spv-wallet-go-client/txexport"
+	"github.com/bitcoin-sv/spv-wallet/models/filter"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	from = time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+	to   = time.Date(2024, 11, 30, 0, 0, 0, 0, time.UTC)
+)
+
+func TestExporter_Export(t *testing.T) {
+	t.Run("Export to CSV with running balance", func(t *testing.T) {
+		// given:
+		source := givenSource(givenHistory())
+		var out bytes.Buffer
+
+		// when:
+		summary, err := txexport.New(source, txexport.WithPageSize(2), txexport.WithOpeningBalance(1000), txexport.WithMetadataColumns("note")).
+			Export(context.Background(), &out, txexport.FormatCSV, from, to)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, &txexport.Summary{Rows: 3, Incoming: 700, Outgoing: -301, Fees: 1, OpeningBalance: 1000, ClosingBalance: 1399}, summary)
+
+		records, err := csv.NewReader(&out).ReadAll()
+		require.NoError(t, err)
+		require.Equal(t, [][]string{
+			{"transaction_id", "created_at", "status", "block_height", "direction", "counterparty", "amount", "fee", "balance", "note", "metadata"},
+			{"tx1", "2024-11-02T10:00:00Z", "MINED", "871000", "incoming", "jane@example.com", "500", "0", "1500", "rent", `{"note":"rent","sender":"jane@example.com"}`},
+			{"tx2", "2024-11-03T10:00:00Z", "MINED", "871100", "outgoing", "john@example.com", "-301", "1", "1199", "", `{"receiver":"john@example.com"}`},
+			{"tx4", "2024-11-05T10:00:00Z", "SEEN_ON_NETWORK", "0", "incoming", "bob@handcash.io", "200", "0", "1399", "", `{"p2p_tx_metadata":{"sender":"bob@handcash.io"}}`},
+		}, records)
+
+		require.Equal(t, []filter.Page{
+			{Number: 1, Size: 2, SortBy: "created_at", Sort: "asc"},
+			{Number: 2, Size: 2, SortBy: "created_at", Sort: "asc"},
+		}, source.pages)
+		require.Equal(t, from, *source.created.From)
+		require.Equal(t, to, *source.created.To)
+	})
+
+	t.Run("Export to CSV sanitizes the cells evaluated as formulas", func(t *testing.T) {
+		// given:
+		history := []*response.Transaction{
+			givenTransaction("tx1", 2, "MINED", 871000, "incoming", 500, 0, map[string]any{"sender": "=HYPERLINK(\"http://evil.example\")", "note": "@SUM(A1)"}),
+			givenTransaction("tx2", 3, "MINED", 871100, "outgoing", -301, 1, map[string]any{"receiver": "+1-555", "note": "-2+3"}),
+		}
+
+		tests := map[string]struct {
+			opts     []txexport.Option
+			expected [][]string
+		}{
+			"sanitized by default": {
+				expected: [][]string{
+					{"tx1", "2024-11-02T10:00:00Z", "MINED", "871000", "incoming", `'=HYPERLINK("http://evil.example")`, "500", "0", "500", "'@SUM(A1)"},
+					{"tx2", "2024-11-03T10:00:00Z", "MINED", "871100", "outgoing", "'+1-555", "-301", "1", "199", "'-2+3"},
+				},
+			},
+			"sanitizing disabled": {
+				opts: []txexport.Option{txexport.WithoutCSVSanitizing()},
+				expected: [][]string{
+					{"tx1", "2024-11-02T10:00:00Z", "MINED", "871000", "incoming", `=HYPERLINK("http://evil.example")`, "500", "0", "500", "@SUM(A1)"},
+					{"tx2", "2024-11-03T10:00:00Z", "MINED", "871100", "outgoing", "+1-555", "-301", "1", "199", "-2+3"},
+				},
+			},
+		}
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				// given:
+				var out bytes.Buffer
+				opts := append([]txexport.Option{txexport.WithMetadataColumns("note")}, tc.opts...)
+
+				// when:
+				_, err := txexport.New(givenSource(history), opts...).Export(context.Background(), &out, txexport.FormatCSV, from, to)
+
+				// then:
+				require.NoError(t, err)
+				records, err := csv.NewReader(&out).ReadAll()
+				require.NoError(t, err)
+				require.Len(t, records, 3)
+				for i, record := range records[1:] {
+					require.Equal(t, tc.expected[i], record[:len(record)-1])
+				}
+			})
+		}
+	})
+
+	t.Run("Export to JSON lines", func(t *testing.T) {
+		// given:
+		var out bytes.Buffer
+
+		// when:
+		_, err := txexport.New(givenSource(givenHistory())).Export(context.Background(), &out, txexport.FormatJSONL, time.Time{}, time.Time{})
+
+		// then:
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.Len(t, lines, 3)
+
+		var row txexport.Row
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &row))
+		require.Equal(t, "tx2", row.TransactionID)
+		require.Equal(t, txexport.DirectionOutgoing, row.Direction)
+		require.Equal(t, int64(-301), row.Amount)
+		require.Equal(t, uint64(1), row.Fee)
+		require.Equal(t, int64(199), row.Balance)
+	})
+
+	t.Run("Export to OFX", func(t *testing.T) {
+		// given:
+		var out bytes.Buffer
+
+		// when:
+		_, err := txexport.New(givenSource(givenHistory()), txexport.WithAccount("xpub-1")).
+			Export(context.Background(), &out, txexport.FormatOFX, from, to)
+
+		// then:
+		require.NoError(t, err)
+		got := out.String()
+		require.Contains(t, got, "<ACCTID>xpub-1</ACCTID>")
+		require.Contains(t, got, "<DTSTART>20241101000000.000[0:GMT]</DTSTART><DTEND>20241130000000.000[0:GMT]</DTEND>")
+		require.Contains(t, got, "<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20241103100000.000[0:GMT]</DTPOSTED><TRNAMT>-0.00000301</TRNAMT>"+
+			"<FITID>tx2</FITID><NAME>john@example.com</NAME><MEMO>tx2 (fee 0.00000001 BSV)</MEMO></STMTTRN>")
+		require.Contains(t, got, "<LEDGERBAL><BALAMT>0.00000399</BALAMT>")
+		require.True(t, strings.HasSuffix(got, "</OFX>\n"))
+	})
+
+	t.Run("Export to QIF", func(t *testing.T) {
+		// given:
+		var out bytes.Buffer
+
+		// when:
+		_, err := txexport.New(givenSource(givenHistory())).Export(context.Background(), &out, txexport.FormatQIF, from, to)
+
+		// then:
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(out.String(), "!Type:Bank\nD11/02/2024\nT0.00000500\nPjane@example.com\nMtx1\n^\n"))
+	})
+
+	t.Run("Export of empty history", func(t *testing.T) {
+		// given:
+		var out bytes.Buffer
+
+		// when:
+		summary, err := txexport.New(givenSource(nil), txexport.WithOpeningBalance(10)).Export(context.Background(), &out, txexport.FormatCSV, from, to)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, &txexport.Summary{OpeningBalance: 10, ClosingBalance: 10}, summary)
+		require.Equal(t, strings.Join(txexport.CSVHeader, ",")+",metadata\n", out.String())
+	})
+
+	t.Run("Export with unsupported format", func(t *testing.T) {
+		// when:
+		summary, err := txexport.New(givenSource(nil)).Export(context.Background(), &bytes.Buffer{}, "xlsx", from, to)
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrTransactionExport)
+		require.Nil(t, summary)
+	})
+
+	t.Run("Export with failing source", func(t *testing.T) {
+		// given:
+		errUnavailable := errors.New("unavailable")
+		source := txexport.SourceFunc(func(context.Context, filter.Page, filter.TimeRange) (*queries.TransactionPage, error) {
+			return nil, errUnavailable
+		})
+
+		// when:
+		summary, err := txexport.New(source).Export(context.Background(), &bytes.Buffer{}, txexport.FormatJSONL, from, to)
+
+		// then:
+		require.ErrorIs(t, err, errUnavailable)
+		require.Nil(t, summary)
+	})
+}
+
+func TestFromAdmin(t *testing.T) {
+	// given:
+	api := &fakeAdminAPI{}
+
+	// when:
+	_, err := txexport.FromAdmin(api, "xpub-1").TransactionsPage(context.Background(), filter.Page{Number: 3, Size: 10}, filter.TimeRange{From: &from})
+
+	// then:
+	require.NoError(t, err)
+	require.Equal(t, "xpub-1", *api.query.Filter.XPubID)
+	require.Equal(t, from, *api.query.Filter.CreatedRange.From)
+	require.Equal(t, filter.Page{Number: 3, Size: 10}, api.query.PageFilter)
+}
+
+func givenHistory() []*response.Transaction {
+	return []*response.Transaction{
+		givenTransaction("tx1", 2, "MINED", 871000, "incoming", 500, 0, map[string]any{"sender": "jane@example.com", "note": "rent"}),
+		givenTransaction("tx2", 3, "MINED", 871100, "outgoing", -301, 1, map[string]any{"receiver": "john@example.com"}),
+		givenTransaction("tx3", 4, "REJECTED", 0, "outgoing", -100, 1, nil),
+		givenTransaction("tx4", 5, "SEEN_ON_NETWORK", 0, "incoming", 200, 0, map[string]any{"p2p_tx_metadata": map[string]any{"sender": "bob@handcash.io"}}),
+	}
+}
+
+func givenTransaction(id string, day int, status string, height uint64, direction string, value int64, fee uint64, metadata map[string]any) *response.Transaction {
+	return &response.Transaction{
+		Model:                response.Model{CreatedAt: time.Date(2024, 11, day, 10, 0, 0, 0, time.UTC), Metadata: metadata},
+		ID:                   id,
+		Status:               status,
+		BlockHeight:          height,
+		TransactionDirection: direction,
+		OutputValue:          value,
+		Fee:                  fee,
+	}
+}
+
+type fakeSource struct {
+	transactions []*response.Transaction
+	pages        []filter.Page
+	created      filter.TimeRange
+}
+
+func givenSource(transactions []*response.Transaction) *fakeSource {
+	return &fakeSource{transactions: transactions}
+}
+
+func (f *fakeSource) TransactionsPage(_ context.Context, page filter.Page, created filter.TimeRange) (*queries.TransactionPage, error) {
+	f.pages = append(f.pages, page)
+	f.created = created
+
+	start := min((page.Number-1)*page.Size, len(f.transactions))
+	end := min(start+page.Size, len(f.transactions))
+	res := &queries.TransactionPage{Content: f.transactions[start:end]}
+	res.Page.TotalPages = (len(f.transactions) + page.Size - 1) / page.Size
+	return res, nil
+}
+
+type fakeAdminAPI struct {
+	query *queries.Query[filter.AdminTransactionFilter]
+}
+
+func (f *fakeAdminAPI) Transactions(_ context.Context, opts ...queries.QueryOption[filter.AdminTransactionFilter]) (*queries.TransactionPage, error) {
+	f.query = queries.NewQuery(opts...)
+	return &queries.TransactionPage{}, nil
+}
@@ -0,0 +1,83 @@
+package txinspect
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Fprint writes the human-readable form of the decoded transaction to the writer.
+func Fprint(w io.Writer, tx *Transaction) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	p := printer{w: tw}
+
+	p.printf("Transaction:\t%s\n", tx.ID)
+	p.printf("Version:\t%d\n", tx.Version)
+	p.printf("Lock time:\t%d\n", tx.LockTime)
+	p.printf("Size:\t%d bytes\n", tx.Size)
+	if tx.InputsKnown {
+		p.printf("Total input:\t%d sat\n", tx.TotalInput)
+	} else {
+		p.printf("Total input:\tunknown\n")
+	}
+	p.printf("Total output:\t%d sat\n", tx.TotalOutput)
+	if tx.InputsKnown {
+		p.printf("Fee:\t%d sat (%.3f sat/byte)\n", tx.Fee, tx.FeePerByte)
+	} else {
+		p.printf("Fee:\tunknown\n")
+	}
+
+	p.printf("\nInputs (%d):\n", len(tx.Inputs))
+	for _, in := range tx.Inputs {
+		value := "unknown"
+		if in.ValueKnown {
+			value = fmt.Sprintf("%d sat", in.Satoshis)
+		}
+		p.printf("  #%d\t%s:%d\t%s\t%s\n", in.Index, in.SourceTxID, in.SourceOutputIndex, value, describe(string(in.SourceScriptType), in.SourceAddress))
+	}
+
+	p.printf("\nOutputs (%d):\n", len(tx.Outputs))
+	for _, out := range tx.Outputs {
+		p.printf("  #%d\t%d sat\t%s\n", out.Index, out.Satoshis, describe(string(out.Type), out.Address))
+		for i, push := range out.Data {
+			if push.Text != "" {
+				p.printf("\t\tdata[%d] %q\n", i, push.Text)
+			} else {
+				p.printf("\t\tdata[%d] 0x%s\n", i, push.Hex)
+			}
+		}
+	}
+
+	if p.err != nil {
+		return p.err
+	}
+	return tw.Flush()
+}
+
+// String returns the human-readable form of the decoded transaction.
+func (t *Transaction) String() string {
+	var sb strings.Builder
+	_ = Fprint(&sb, t)
+	return sb.String()
+}
+
+func describe(scriptType, address string) string {
+	if address != "" {
+		return fmt.Sprintf("%s %s", scriptType, address)
+	}
+	return scriptType
+}
+
+// printer remembers the first write error, so that the printing code isn't cluttered with error checks.
+type printer struct {
+	w   io.Writer
+	err error
+}
+
+func (p *printer) printf(format string, args ...any) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.w, format, args...)
+}
@@ -0,0 +1,287 @@
+// Package txinspect decodes raw transactions into a structured, human-readable form.
+//
+// It is meant for inspecting the hex of the transactions and draft transactions returned by the SPV Wallet API:
+// the inputs and outputs with their script types, the decoded OP_RETURN data, the totals, the fee and the size.
+// The value of the inputs isn't part of a raw transaction, so the totals and the fee are only known if the hex
+// is in the Extended Format (BRC-30), the input values are provided with WithInputValues,
+// or the draft transaction is decoded with DecodeDraft.
+package txinspect
+
+import (
+	"encoding/hex"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bitcoin-sv/go-sdk/script"
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/bitcoin-sv/spv-wallet-go-client/beef"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// ScriptType identifies the template of a locking script.
+type ScriptType string
+
+// Script types recognized by the inspector.
+const (
+	ScriptTypeP2PKH       ScriptType = "p2pkh"
+	ScriptTypeP2PK        ScriptType = "p2pk"
+	ScriptTypeP2SH        ScriptType = "p2sh"
+	ScriptTypeMultiSig    ScriptType = "multisig"
+	ScriptTypeOpReturn    ScriptType = "op_return"
+	ScriptTypeNonStandard ScriptType = "nonstandard"
+)
+
+// Transaction is the decoded form of a transaction.
+type Transaction struct {
+	ID          string    `json:"id"`          // Transaction ID.
+	Version     uint32    `json:"version"`     // Transaction version.
+	LockTime    uint32    `json:"lockTime"`    // Transaction lock time.
+	Size        int       `json:"size"`        // Size of the raw transaction in bytes.
+	Inputs      []*Input  `json:"inputs"`      // Decoded inputs.
+	Outputs     []*Output `json:"outputs"`     // Decoded outputs.
+	TotalInput  uint64    `json:"totalInput"`  // Total value of the inputs; zero if InputsKnown is false.
+	TotalOutput uint64    `json:"totalOutput"` // Total value of the outputs.
+	Fee         uint64    `json:"fee"`         // Fee paid by the transaction; zero if InputsKnown is false.
+	FeePerByte  float64   `json:"feePerByte"`  // Fee rate in satoshis per byte; zero if InputsKnown is false.
+	InputsKnown bool      `json:"inputsKnown"` // True if the values of all inputs are known.
+}
+
+// Input is a decoded transaction input.
+type Input struct {
+	Index              int        `json:"index"`                      // Index of the input in the transaction.
+	SourceTxID         string     `json:"sourceTxId"`                 // ID of the transaction of the spent output.
+	SourceOutputIndex  uint32     `json:"sourceOutputIndex"`          // Index of the spent output.
+	Sequence           uint32     `json:"sequence"`                   // Sequence number of the input.
+	UnlockingScript    string     `json:"unlockingScript"`            // Hex of the unlocking script; empty for unsigned inputs.
+	UnlockingScriptASM string     `json:"unlockingScriptAsm"`         // Unlocking script in ASM notation.
+	Satoshis           uint64     `json:"satoshis"`                   // Value of the spent output; zero if ValueKnown is false.
+	ValueKnown         bool       `json:"valueKnown"`                 // True if the value of the spent output is known.
+	SourceScriptType   ScriptType `json:"sourceScriptType,omitempty"` // Type of the locking script of the spent output, if known.
+	SourceAddress      string     `json:"sourceAddress,omitempty"`    // Address of the spent P2PKH output, if known.
+}
+
+// Output is a decoded transaction output.
+type Output struct {
+	Index            int        `json:"index"`             // Index of the output in the transaction.
+	Satoshis         uint64     `json:"satoshis"`          // Value of the output.
+	LockingScript    string     `json:"lockingScript"`     // Hex of the locking script.
+	LockingScriptASM string     `json:"lockingScriptAsm"`  // Locking script in ASM notation.
+	Type             ScriptType `json:"type"`              // Type of the locking script.
+	Address          string     `json:"address,omitempty"` // Address of a P2PKH output.
+	Data             []DataPush `json:"data,omitempty"`    // Data pushed after the OP_RETURN of a data output.
+}
+
+// DataPush is a single data push of an OP_RETURN output.
+type DataPush struct {
+	Hex  string `json:"hex"`            // Hex of the pushed data.
+	Text string `json:"text,omitempty"` // Pushed data as text; empty if the data isn't printable UTF-8.
+}
+
+type options struct {
+	inputValues map[response.UtxoPointer]uint64
+	mainnet     bool
+}
+
+// Option configures the decoding.
+type Option func(*options)
+
+// WithInputValues provides the values of the outputs spent by the inputs, keyed by their pointers.
+func WithInputValues(values map[response.UtxoPointer]uint64) Option {
+	return func(o *options) {
+		o.inputValues = values
+	}
+}
+
+// WithTestnet encodes the addresses of the P2PKH scripts for the testnet; the mainnet is used by default.
+func WithTestnet() Option {
+	return func(o *options) {
+		o.mainnet = false
+	}
+}
+
+// Decode decodes a transaction hex, either raw, in the Extended Format or in the BEEF format.
+// The input values of a BEEF transaction are taken from its ancestors; the BEEF isn't verified, see beef.Verify.
+func Decode(txHex string, opts ...Option) (*Transaction, error) {
+	if beef.IsBEEFHex(txHex) {
+		tx, err := beef.DecodeHex(txHex)
+		if err != nil {
+			return nil, err
+		}
+		return Inspect(tx, opts...), nil
+	}
+
+	tx, err := trx.NewTransactionFromHex(txHex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrInvalidTransactionHex, err)
+	}
+	return Inspect(tx, opts...), nil
+}
+
+// DecodeDraft decodes the hex of a draft transaction, taking the input values from its configuration.
+func DecodeDraft(draft *response.DraftTransaction, opts ...Option) (*Transaction, error) {
+	values := make(map[response.UtxoPointer]uint64, len(draft.Configuration.Inputs))
+	for _, input := range draft.Configuration.Inputs {
+		values[input.UtxoPointer] = input.Satoshis
+	}
+	return Decode(draft.Hex, append([]Option{WithInputValues(values)}, opts...)...)
+}
+
+// Inspect returns the decoded form of a parsed transaction.
+func Inspect(tx *trx.Transaction, opts ...Option) *Transaction {
+	o := options{mainnet: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	res := &Transaction{
+		ID:          tx.TxID().String(),
+		Version:     tx.Version,
+		LockTime:    tx.LockTime,
+		Size:        tx.Size(),
+		InputsKnown: true,
+	}
+	for i, in := range tx.Inputs {
+		input := inspectInput(i, in, &o)
+		res.Inputs = append(res.Inputs, input)
+		res.TotalInput += input.Satoshis
+		res.InputsKnown = res.InputsKnown && input.ValueKnown
+	}
+	for i, out := range tx.Outputs {
+		output := inspectOutput(i, out.Satoshis, out.LockingScript, o.mainnet)
+		res.Outputs = append(res.Outputs, output)
+		res.TotalOutput += output.Satoshis
+	}
+
+	if !res.InputsKnown {
+		res.TotalInput = 0
+		return res
+	}
+	if res.TotalInput >= res.TotalOutput {
+		res.Fee = res.TotalInput - res.TotalOutput
+	}
+	if res.Size > 0 {
+		res.FeePerByte = float64(res.Fee) / float64(res.Size)
+	}
+	return res
+}
+
+func inspectInput(index int, in *trx.TransactionInput, o *options) *Input {
+	input := &Input{
+		Index:             index,
+		SourceTxID:        in.SourceTXID.String(),
+		SourceOutputIndex: in.SourceTxOutIndex,
+		Sequence:          in.SequenceNumber,
+	}
+	if in.UnlockingScript != nil {
+		input.UnlockingScript = in.UnlockingScript.String()
+		input.UnlockingScriptASM = in.UnlockingScript.ToASM()
+	}
+
+	if source := in.SourceTxOutput(); source != nil {
+		input.Satoshis = source.Satoshis
+		input.ValueKnown = true
+		if source.LockingScript != nil {
+			spent := inspectOutput(0, source.Satoshis, source.LockingScript, o.mainnet)
+			input.SourceScriptType = spent.Type
+			input.SourceAddress = spent.Address
+		}
+		return input
+	}
+
+	pointer := response.UtxoPointer{TransactionID: input.SourceTxID, OutputIndex: input.SourceOutputIndex}
+	input.Satoshis, input.ValueKnown = o.inputValues[pointer]
+	return input
+}
+
+func inspectOutput(index int, satoshis uint64, lockingScript *script.Script, mainnet bool) *Output {
+	output := &Output{
+		Index:    index,
+		Satoshis: satoshis,
+		Type:     ScriptTypeNonStandard,
+	}
+	if lockingScript == nil {
+		return output
+	}
+
+	output.LockingScript = lockingScript.String()
+	output.LockingScriptASM = lockingScript.ToASM()
+	output.Type = TypeOf(lockingScript)
+	switch output.Type {
+	case ScriptTypeP2PKH:
+		if pkh, err := lockingScript.PublicKeyHash(); err == nil {
+			if addr, err := script.NewAddressFromPublicKeyHash(pkh, mainnet); err == nil {
+				output.Address = addr.AddressString
+			}
+		}
+	case ScriptTypeOpReturn:
+		output.Data = DataPushes(lockingScript)
+	}
+	return output
+}
+
+// TypeOf returns the type of the locking script.
+func TypeOf(lockingScript *script.Script) ScriptType {
+	switch {
+	case lockingScript.IsData():
+		return ScriptTypeOpReturn
+	case lockingScript.IsP2PKH():
+		return ScriptTypeP2PKH
+	case lockingScript.IsP2PK():
+		return ScriptTypeP2PK
+	case lockingScript.IsP2SH():
+		return ScriptTypeP2SH
+	case lockingScript.IsMultiSigOut():
+		return ScriptTypeMultiSig
+	default:
+		return ScriptTypeNonStandard
+	}
+}
+
+// DataPushes returns the data pushed after the OP_RETURN of a data output script.
+// Parsing stops at the first malformed push, and non-push opcodes are skipped.
+func DataPushes(lockingScript *script.Script) []DataPush {
+	b := []byte(*lockingScript)
+	start := 1
+	if len(b) > 1 && b[0] == script.OpFALSE {
+		start = 2
+	}
+	if len(b) < start {
+		return nil
+	}
+
+	data := script.Script(b[start:])
+	var pushes []DataPush
+	for pos := 0; pos < len(data); {
+		op, err := data.ReadOp(&pos)
+		if err != nil {
+			break
+		}
+		if op.Op > script.OpPUSHDATA4 {
+			continue
+		}
+		pushes = append(pushes, newDataPush(op.Data))
+	}
+	return pushes
+}
+
+func newDataPush(data []byte) DataPush {
+	push := DataPush{Hex: hex.EncodeToString(data)}
+	if isPrintable(data) {
+		push.Text = string(data)
+	}
+	return push
+}
+
+func isPrintable(data []byte) bool {
+	if len(data) == 0 || !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
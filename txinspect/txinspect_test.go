@@ -0,0 +1,146 @@
+package txinspect_test
+
+import (
+	"testing"
+
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/bitcoin-sv/go-sdk/script"
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/bitcoin-sv/go-sdk/transaction/template/p2pkh"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/txinspect"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+	"github.com/stretchr/testify/require"
+)
+
+const sourceTxID = "3c8edde27cb9a9132c22038dac4391496be9db16fd21351565cc1006966fdad5"
+
+func TestDecode(t *testing.T) {
+	tx, address := givenTransaction(t)
+	efHex, err := tx.EFHex()
+	require.NoError(t, err)
+
+	t.Run("Decode of the raw hex", func(t *testing.T) {
+		// when:
+		got, err := txinspect.Decode(tx.Hex())
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, tx.TxID().String(), got.ID)
+		require.Equal(t, tx.Size(), got.Size)
+		require.False(t, got.InputsKnown)
+		require.False(t, got.Inputs[0].ValueKnown)
+		require.Zero(t, got.Fee)
+		require.Equal(t, uint64(900), got.TotalOutput)
+
+		require.Len(t, got.Outputs, 2)
+		require.Equal(t, txinspect.ScriptTypeP2PKH, got.Outputs[0].Type)
+		require.Equal(t, address, got.Outputs[0].Address)
+		require.Equal(t, txinspect.ScriptTypeOpReturn, got.Outputs[1].Type)
+		require.Equal(t, []txinspect.DataPush{
+			{Hex: "68656c6c6f", Text: "hello"},
+			{Hex: "00ff"},
+		}, got.Outputs[1].Data)
+	})
+
+	t.Run("Decode of the Extended Format hex", func(t *testing.T) {
+		// when:
+		got, err := txinspect.Decode(efHex)
+
+		// then:
+		require.NoError(t, err)
+		require.True(t, got.InputsKnown)
+		require.Equal(t, uint64(1000), got.TotalInput)
+		require.Equal(t, uint64(100), got.Fee)
+		require.Equal(t, txinspect.ScriptTypeP2PKH, got.Inputs[0].SourceScriptType)
+		require.Equal(t, address, got.Inputs[0].SourceAddress)
+		require.Equal(t, sourceTxID, got.Inputs[0].SourceTxID)
+	})
+
+	t.Run("Decode with the input values", func(t *testing.T) {
+		// when:
+		got, err := txinspect.Decode(tx.Hex(), txinspect.WithInputValues(map[response.UtxoPointer]uint64{
+			{TransactionID: sourceTxID, OutputIndex: 1}: 1000,
+		}))
+
+		// then:
+		require.NoError(t, err)
+		require.True(t, got.InputsKnown)
+		require.Equal(t, uint64(100), got.Fee)
+		require.InDelta(t, 100/float64(tx.Size()), got.FeePerByte, 1e-9)
+	})
+
+	t.Run("DecodeDraft", func(t *testing.T) {
+		// given:
+		draft := &response.DraftTransaction{
+			Hex: tx.Hex(),
+			Configuration: response.TransactionConfig{
+				Inputs: []*response.TransactionInput{{Utxo: response.Utxo{
+					UtxoPointer: response.UtxoPointer{TransactionID: sourceTxID, OutputIndex: 1},
+					Satoshis:    1000,
+				}}},
+			},
+		}
+
+		// when:
+		got, err := txinspect.DecodeDraft(draft)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, uint64(100), got.Fee)
+	})
+
+	t.Run("Decode of invalid hex", func(t *testing.T) {
+		// when:
+		got, err := txinspect.Decode("zz")
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrInvalidTransactionHex)
+		require.Nil(t, got)
+	})
+}
+
+func TestTransaction_String(t *testing.T) {
+	// given:
+	tx, address := givenTransaction(t)
+	efHex, err := tx.EFHex()
+	require.NoError(t, err)
+	decoded, err := txinspect.Decode(efHex)
+	require.NoError(t, err)
+
+	// when:
+	got := decoded.String()
+
+	// then:
+	require.Contains(t, got, "Transaction:   "+tx.TxID().String())
+	require.Contains(t, got, "Fee:           100 sat (")
+	require.Contains(t, got, "#0  900 sat  p2pkh "+address)
+	require.Contains(t, got, `data[0] "hello"`)
+	require.Contains(t, got, "data[1] 0x00ff")
+}
+
+func givenTransaction(t *testing.T) (*trx.Transaction, string) {
+	t.Helper()
+
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	addr, err := script.NewAddressFromPublicKey(key.PubKey(), true)
+	require.NoError(t, err)
+	lockingScript, err := p2pkh.Lock(addr)
+	require.NoError(t, err)
+	unlocker, err := p2pkh.Unlock(key, nil)
+	require.NoError(t, err)
+
+	tx := trx.NewTransaction()
+	require.NoError(t, tx.AddInputFrom(sourceTxID, 1, lockingScript.String(), 1000, unlocker))
+	tx.AddOutput(&trx.TransactionOutput{Satoshis: 900, LockingScript: lockingScript})
+
+	data := &script.Script{}
+	require.NoError(t, data.AppendOpcodes(script.OpFALSE, script.OpRETURN))
+	require.NoError(t, data.AppendPushDataString("hello"))
+	require.NoError(t, data.AppendPushData([]byte{0x00, 0xff}))
+	tx.AddOutput(&trx.TransactionOutput{LockingScript: data})
+	require.NoError(t, tx.Sign())
+
+	return tx, addr.AddressString
+}
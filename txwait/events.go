@@ -0,0 +1,55 @@
+package txwait
+
+import (
+	"sync"
+
+	"github.com/bitcoin-sv/spv-wallet/models"
+)
+
+// Events dispatches the transaction events of the notifications webhook to the waiting calls.
+// It is safe for concurrent use.
+type Events struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan struct{}]struct{}
+}
+
+// NewEvents creates a new Events instance without subscribers.
+func NewEvents() *Events {
+	return &Events{subscribers: make(map[string]map[chan struct{}]struct{})}
+}
+
+// Handle notifies the calls waiting for the transaction of the event.
+// It has the signature of the handlers accepted by notifications.RegisterHandler.
+func (e *Events) Handle(event *models.TransactionEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for ch := range e.subscribers[event.TransactionID] {
+		// The waiting call refetches the transaction, so a single pending notification is enough.
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (e *Events) subscribe(id string) (<-chan struct{}, func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ch := make(chan struct{}, 1)
+	if e.subscribers[id] == nil {
+		e.subscribers[id] = make(map[chan struct{}]struct{})
+	}
+	e.subscribers[id][ch] = struct{}{}
+
+	return ch, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		delete(e.subscribers[id], ch)
+		if len(e.subscribers[id]) == 0 {
+			delete(e.subscribers, id)
+		}
+	}
+}
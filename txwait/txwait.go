@@ -0,0 +1,184 @@
+// Package txwait blocks until a transaction known to the SPV Wallet reaches the expected state,
+// e.g., until it is broadcasted or mined.
+//
+// The transaction is polled with an exponential backoff. If the transaction events of the notifications
+// webhook are routed to an Events instance, every event of the awaited transaction triggers an immediate
+// check, and polling only guards against lost events:
+//
+//	events := txwait.NewEvents()
+//	notifications.RegisterHandler(webhook, events.Handle)
+//	tx, err := userAPI.WaitForTransaction(ctx, txID, txwait.Mined(), txwait.WithEvents(events))
+package txwait
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// Defaults of the waiting options.
+const (
+	DefaultInitialInterval = time.Second
+	DefaultMaxInterval     = 30 * time.Second
+	DefaultMultiplier      = 2
+)
+
+// Transaction statuses reported by the SPV Wallet.
+const (
+	StatusSentToNetwork       = "SENT_TO_NETWORK"
+	StatusAcceptedByNetwork   = "ACCEPTED_BY_NETWORK"
+	StatusSeenOnNetwork       = "SEEN_ON_NETWORK"
+	StatusSeenInOrphanMempool = "SEEN_IN_ORPHAN_MEMPOOL"
+	StatusMined               = "MINED"
+	StatusConfirmed           = "CONFIRMED"
+	StatusRejected            = "REJECTED"
+)
+
+// Predicate reports whether the transaction reached the awaited state.
+type Predicate func(tx *response.Transaction) bool
+
+// StatusIn returns a predicate satisfied by a transaction with any of the given statuses.
+func StatusIn(statuses ...string) Predicate {
+	return func(tx *response.Transaction) bool {
+		return slices.Contains(statuses, tx.Status)
+	}
+}
+
+// Broadcasted returns a predicate satisfied once the transaction was sent to the network.
+func Broadcasted() Predicate {
+	return StatusIn(StatusSentToNetwork, StatusAcceptedByNetwork, StatusSeenOnNetwork, StatusSeenInOrphanMempool, StatusMined, StatusConfirmed)
+}
+
+// SeenOnNetwork returns a predicate satisfied once the transaction was accepted by the network.
+func SeenOnNetwork() Predicate {
+	return StatusIn(StatusAcceptedByNetwork, StatusSeenOnNetwork, StatusMined, StatusConfirmed)
+}
+
+// Mined returns a predicate satisfied once the transaction was included in a block.
+func Mined() Predicate {
+	return func(tx *response.Transaction) bool {
+		return tx.BlockHeight > 0 || tx.Status == StatusMined || tx.Status == StatusConfirmed
+	}
+}
+
+// TransactionGetter retrieves a transaction by its ID. It is implemented by the *spvwallet.UserAPI.
+type TransactionGetter interface {
+	Transaction(ctx context.Context, ID string) (*response.Transaction, error)
+}
+
+// Options configure the waiting for a transaction.
+type Options struct {
+	InitialInterval time.Duration // Interval before the second check; the first check is immediate.
+	MaxInterval     time.Duration // Upper bound of the interval between the checks.
+	Multiplier      float64       // Factor by which the interval grows after every check.
+	Timeout         time.Duration // Maximum waiting time; zero relies on the context deadline only.
+	Events          *Events       // Source of the transaction events triggering immediate checks; nil to poll only.
+}
+
+// Option configures the waiting for a transaction.
+type Option func(*Options)
+
+// WithBackoff sets the initial and the maximum interval between the checks, and the growth factor of the interval.
+func WithBackoff(initial, maxInterval time.Duration, multiplier float64) Option {
+	return func(o *Options) {
+		if initial > 0 {
+			o.InitialInterval = initial
+		}
+		if maxInterval > 0 {
+			o.MaxInterval = maxInterval
+		}
+		if multiplier >= 1 {
+			o.Multiplier = multiplier
+		}
+	}
+}
+
+// WithTimeout limits the waiting time.
+func WithTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = d
+	}
+}
+
+// WithEvents makes every transaction event of the awaited transaction trigger an immediate check.
+func WithEvents(events *Events) Option {
+	return func(o *Options) {
+		o.Events = events
+	}
+}
+
+// WaitForTransaction checks the transaction until it satisfies the predicate and returns its last state.
+// Failed checks, e.g., because the transaction isn't known to the SPV Wallet yet, are retried.
+// It returns ErrTransactionRejected if the transaction is rejected without satisfying the predicate,
+// and ErrTransactionWaitTimeout, wrapping the context error, if the timeout elapses or the context is done first.
+func WaitForTransaction(ctx context.Context, getter TransactionGetter, id string, predicate Predicate, opts ...Option) (*response.Transaction, error) {
+	o := Options{
+		InitialInterval: DefaultInitialInterval,
+		MaxInterval:     DefaultMaxInterval,
+		Multiplier:      DefaultMultiplier,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+	}
+
+	var notified <-chan struct{}
+	if o.Events != nil {
+		var unsubscribe func()
+		notified, unsubscribe = o.Events.subscribe(id)
+		defer unsubscribe()
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	interval := o.InitialInterval
+	var lastErr error
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, timeoutErr(ctx, id, lastErr)
+		case <-notified:
+		case <-timer.C:
+		}
+
+		tx, err := getter.Transaction(ctx, id)
+		switch {
+		case err != nil:
+			if ctx.Err() == nil {
+				lastErr = err
+			}
+		case predicate(tx):
+			return tx, nil
+		case tx.Status == StatusRejected:
+			return tx, fmt.Errorf("%w: %s", goclienterr.ErrTransactionRejected, id)
+		default:
+			lastErr = nil
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(interval)
+		interval = min(time.Duration(float64(interval)*o.Multiplier), o.MaxInterval)
+	}
+}
+
+func timeoutErr(ctx context.Context, id string, lastErr error) error {
+	if lastErr != nil {
+		return fmt.Errorf("%w %s: %w, last error: %w", goclienterr.ErrTransactionWaitTimeout, id, ctx.Err(), lastErr)
+	}
+	return fmt.Errorf("%w %s: %w", goclienterr.ErrTransactionWaitTimeout, id, ctx.Err())
+}
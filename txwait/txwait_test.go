@@ -0,0 +1,110 @@
+package txwait_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/txwait"
+	"github.com/bitcoin-sv/spv-wallet/models"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForTransaction(t *testing.T) {
+	fastBackoff := txwait.WithBackoff(time.Millisecond, time.Millisecond, 1)
+
+	t.Run("WaitForTransaction returns the broadcasted transaction", func(t *testing.T) {
+		// given:
+		getter := &fakeGetter{statuses: []string{"STORED", "SENT_TO_NETWORK", "SEEN_ON_NETWORK"}}
+
+		// when:
+		tx, err := txwait.WaitForTransaction(context.Background(), getter, "tx-1", txwait.Broadcasted(), fastBackoff)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, "SENT_TO_NETWORK", tx.Status)
+		require.Equal(t, 2, getter.callCount())
+	})
+
+	t.Run("WaitForTransaction returns the rejected transaction", func(t *testing.T) {
+		// given:
+		getter := &fakeGetter{statuses: []string{"STORED", "REJECTED"}}
+
+		// when:
+		tx, err := txwait.WaitForTransaction(context.Background(), getter, "tx-1", txwait.Mined(), fastBackoff)
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrTransactionRejected)
+		require.Equal(t, "REJECTED", tx.Status)
+	})
+
+	t.Run("WaitForTransaction times out with the last error", func(t *testing.T) {
+		// given:
+		getter := &fakeGetter{err: errors.New("transaction not found")}
+
+		// when:
+		tx, err := txwait.WaitForTransaction(context.Background(), getter, "tx-1", txwait.Mined(), fastBackoff, txwait.WithTimeout(10*time.Millisecond))
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrTransactionWaitTimeout)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorIs(t, err, getter.err)
+		require.Nil(t, tx)
+	})
+
+	t.Run("WaitForTransaction checks the transaction on events", func(t *testing.T) {
+		// given:
+		getter := &fakeGetter{statuses: []string{"SEEN_ON_NETWORK", "MINED"}}
+		events := txwait.NewEvents()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		// when:
+		var tx *response.Transaction
+		var err error
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			tx, err = txwait.WaitForTransaction(ctx, getter, "tx-1", txwait.Mined(), txwait.WithBackoff(time.Hour, time.Hour, 1), txwait.WithEvents(events))
+		}()
+		require.Eventually(t, func() bool { return getter.callCount() == 1 }, time.Second, time.Millisecond)
+		events.Handle(&models.TransactionEvent{TransactionID: "tx-2", Status: "MINED"})
+		events.Handle(&models.TransactionEvent{TransactionID: "tx-1", Status: "MINED"})
+
+		// then:
+		<-done
+		require.NoError(t, err)
+		require.Equal(t, "MINED", tx.Status)
+		require.Equal(t, 2, getter.callCount())
+	})
+}
+
+// fakeGetter returns the transaction with the consecutive statuses, repeating the last one.
+type fakeGetter struct {
+	mu       sync.Mutex
+	statuses []string
+	calls    int
+	err      error
+}
+
+func (f *fakeGetter) Transaction(_ context.Context, id string) (*response.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	status := f.statuses[min(f.calls, len(f.statuses))-1]
+	return &response.Transaction{ID: id, Status: status}, nil
+}
+
+func (f *fakeGetter) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
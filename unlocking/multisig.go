@@ -0,0 +1,219 @@
+package unlocking
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/bitcoin-sv/go-sdk/script"
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	sighash "github.com/bitcoin-sv/go-sdk/transaction/sighash"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// PartialSignature is a signature of a multisig input made by one of its parties.
+// It applies only to the input of the draft transaction spending the outpoint.
+type PartialSignature struct {
+	DraftID   string `json:"draftId"`   // ID of the draft transaction.
+	Outpoint  string `json:"outpoint"`  // Output spent by the input, as "<transaction id>:<output index>".
+	Input     uint32 `json:"input"`     // Index of the input in the transaction.
+	PublicKey string `json:"publicKey"` // Hex-encoded public key of the party, as in the locking script.
+	Signature string `json:"signature"` // Hex-encoded DER signature followed by the sighash flag.
+}
+
+// appliesTo reports whether the partial signature was made for the input.
+func (p *PartialSignature) appliesTo(input *Input) bool {
+	return p.DraftID == input.DraftID && input.Draft != nil &&
+		p.Outpoint == outpoint(input.Draft.TransactionID, input.Draft.OutputIndex)
+}
+
+// Multisig unlocks the bare multisig (P2MS) outputs with the signatures of their parties.
+// When the input is signed, the signature of the key derived for its destination, if it is one of the parties,
+// is combined with the partial signatures collected from the other parties, e.g., with SignPartial.
+// Only the partial signatures made for the draft transaction and the outpoint of the input are used,
+// so a single Multisig template may hold the signatures of several drafts. They are verified against the transaction,
+// and the first signatures in the order of the keys in the locking script are used to unlock the input.
+type Multisig struct {
+	Signatures []PartialSignature
+}
+
+// Unlocker returns the unlocking script template of the multisig input.
+func (m *Multisig) Unlocker(input *Input) (trx.UnlockingScriptTemplate, error) {
+	ms, err := parseMultisig(input.LockingScript)
+	if err != nil {
+		return nil, err
+	}
+	unlocker := &multisigUnlocker{multisig: ms, key: input.Key, flag: input.SigHashFlag}
+	for _, sig := range m.Signatures {
+		if sig.appliesTo(input) {
+			unlocker.signatures = append(unlocker.signatures, sig)
+		}
+	}
+	return unlocker, nil
+}
+
+// SignPartial signs every multisig input of the draft transaction of which the key is one of the parties,
+// and returns the partial signatures to be passed to the party finalizing the transaction.
+// The sighash flag must be the same as the one the transaction is finalized with.
+func SignPartial(draft *response.DraftTransaction, key *ec.PrivateKey, flag sighash.Flag) ([]PartialSignature, error) {
+	tx, inputs, err := NewTransaction(draft, WithSigHashFlag(flag))
+	if err != nil {
+		return nil, err
+	}
+
+	var signatures []PartialSignature
+	for _, input := range inputs {
+		if !input.LockingScript.IsMultiSigOut() {
+			continue
+		}
+		ms, err := parseMultisig(input.LockingScript)
+		if err != nil {
+			return nil, err
+		}
+		i := ms.party(key.PubKey())
+		if i < 0 {
+			continue
+		}
+		sig, err := sign(tx, input.Index, key, input.SigHashFlag)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign multisig input %d: %w", input.Index, err)
+		}
+		signatures = append(signatures, PartialSignature{
+			DraftID:   draft.ID,
+			Outpoint:  outpoint(input.Draft.TransactionID, input.Draft.OutputIndex),
+			Input:     input.Index,
+			PublicKey: hex.EncodeToString(ms.keys[i]),
+			Signature: hex.EncodeToString(sig),
+		})
+	}
+	return signatures, nil
+}
+
+// multisig is a parsed bare multisig locking script: OP_m <key 1> ... <key n> OP_n OP_CHECKMULTISIG.
+type multisig struct {
+	required int
+	keys     [][]byte
+}
+
+func parseMultisig(lockingScript *script.Script) (*multisig, error) {
+	if !lockingScript.IsMultiSigOut() {
+		return nil, fmt.Errorf("%w: not a multisig script", goclienterr.ErrUnsupportedLockingScript)
+	}
+	chunks, err := script.DecodeScript(*lockingScript)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", goclienterr.ErrUnsupportedLockingScript, err)
+	}
+
+	ms := &multisig{}
+	if op := chunks[0].Op; op >= script.OpONE {
+		ms.required = int(op-script.OpONE) + 1
+	}
+	for _, chunk := range chunks[1 : len(chunks)-2] {
+		ms.keys = append(ms.keys, chunk.Data)
+	}
+	if ms.required < 1 || ms.required > len(ms.keys) {
+		return nil, fmt.Errorf("%w: %d signatures required out of %d keys", goclienterr.ErrUnsupportedLockingScript, ms.required, len(ms.keys))
+	}
+	return ms, nil
+}
+
+// party returns the index of the public key in the locking script, in either encoding, or -1.
+func (m *multisig) party(pub *ec.PublicKey) int {
+	for i, key := range m.keys {
+		if bytes.Equal(key, pub.Compressed()) || bytes.Equal(key, pub.Uncompressed()) {
+			return i
+		}
+	}
+	return -1
+}
+
+type multisigUnlocker struct {
+	*multisig
+	key        *ec.PrivateKey
+	flag       sighash.Flag
+	signatures []PartialSignature
+}
+
+func (m *multisigUnlocker) Sign(tx *trx.Transaction, inputIndex uint32) (*script.Script, error) {
+	byParty := make([][]byte, len(m.keys))
+	if m.key != nil {
+		if i := m.party(m.key.PubKey()); i >= 0 {
+			sig, err := sign(tx, inputIndex, m.key, m.flag)
+			if err != nil {
+				return nil, err
+			}
+			byParty[i] = sig
+		}
+	}
+	for _, partial := range m.signatures {
+		i, sig, err := m.verify(tx, inputIndex, partial)
+		if err != nil {
+			return nil, err
+		}
+		if byParty[i] == nil {
+			byParty[i] = sig
+		}
+	}
+
+	// OP_0 works around the extra item popped by OP_CHECKMULTISIG.
+	s := &script.Script{}
+	if err := s.AppendOpcodes(script.OpZERO); err != nil {
+		return nil, err
+	}
+	signed := 0
+	for _, sig := range byParty {
+		if sig == nil || signed == m.required {
+			continue
+		}
+		if err := s.AppendPushData(sig); err != nil {
+			return nil, err
+		}
+		signed++
+	}
+	if signed < m.required {
+		return nil, fmt.Errorf("%w: input %d has %d of %d signatures", goclienterr.ErrMultisigIncomplete, inputIndex, signed, m.required)
+	}
+	return s, nil
+}
+
+func (m *multisigUnlocker) EstimateLength(*trx.Transaction, uint32) uint32 {
+	return 1 + uint32(m.required)*(maxSignatureSize+1) //nolint: gosec // at most 16 signatures are required
+}
+
+// verify checks the partial signature against the transaction and returns the index of its party and the signature.
+func (m *multisigUnlocker) verify(tx *trx.Transaction, inputIndex uint32, partial PartialSignature) (int, []byte, error) {
+	invalid := func(reason string, args ...any) (int, []byte, error) {
+		return -1, nil, fmt.Errorf("%w: input %d, key %s: %s", goclienterr.ErrInvalidPartialSignature, inputIndex, partial.PublicKey, fmt.Sprintf(reason, args...))
+	}
+
+	pubBytes, err := hex.DecodeString(partial.PublicKey)
+	if err != nil {
+		return invalid("malformed public key: %s", err)
+	}
+	pub, err := ec.ParsePubKey(pubBytes)
+	if err != nil {
+		return invalid("malformed public key: %s", err)
+	}
+	i := m.party(pub)
+	if i < 0 {
+		return invalid("not a party of the multisig")
+	}
+	sig, err := hex.DecodeString(partial.Signature)
+	if err != nil || len(sig) < 2 {
+		return invalid("malformed signature")
+	}
+	der, err := ec.ParseDERSignature(sig[:len(sig)-1])
+	if err != nil {
+		return invalid("malformed signature: %s", err)
+	}
+	hash, err := tx.CalcInputSignatureHash(inputIndex, sighash.Flag(sig[len(sig)-1]))
+	if err != nil {
+		return -1, nil, err
+	}
+	if !der.Verify(hash, pub) {
+		return invalid("signature doesn't match the transaction")
+	}
+	return i, sig, nil
+}
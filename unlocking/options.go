@@ -0,0 +1,118 @@
+package unlocking
+
+import (
+	"fmt"
+	"slices"
+
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	sighash "github.com/bitcoin-sv/go-sdk/transaction/sighash"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+)
+
+// SignOptions controls which inputs of a draft transaction are signed and with which sighash flags.
+type SignOptions struct {
+	SigHashFlag sighash.Flag            // Flag of the inputs without their own flag; sighash.AllForkID by default.
+	InputFlags  map[uint32]sighash.Flag // Flags of the individual inputs, by their index in the transaction.
+	Inputs      []uint32                // Indexes of the inputs to sign; all inputs of the draft configuration if empty.
+	// KeepForeignInputs keeps the inputs of the draft transaction which aren't inputs of its configuration,
+	// e.g., added by a counterparty. By default, the inputs are rebuilt from the draft configuration.
+	KeepForeignInputs bool
+}
+
+// SignOption configures the SignOptions.
+type SignOption func(*SignOptions)
+
+// WithSigHashFlag sets the sighash flag of the inputs without their own flag, e.g., sighash.AllForkID|sighash.AnyOneCanPay.
+func WithSigHashFlag(flag sighash.Flag) SignOption {
+	return func(o *SignOptions) {
+		o.SigHashFlag = flag
+	}
+}
+
+// WithInputSigHashFlag sets the sighash flag of the input at the index, e.g., sighash.SingleForkID|sighash.AnyOneCanPay.
+func WithInputSigHashFlag(index uint32, flag sighash.Flag) SignOption {
+	return func(o *SignOptions) {
+		if o.InputFlags == nil {
+			o.InputFlags = make(map[uint32]sighash.Flag)
+		}
+		o.InputFlags[index] = flag
+	}
+}
+
+// WithInputs limits the signing to the inputs at the indexes, leaving the other inputs unsigned.
+// The transaction is returned partially signed, e.g., to be completed by another party.
+func WithInputs(indexes ...uint32) SignOption {
+	return func(o *SignOptions) {
+		o.Inputs = append(o.Inputs, indexes...)
+	}
+}
+
+// WithForeignInputs keeps the inputs of the draft transaction which aren't inputs of its configuration,
+// e.g., added by a counterparty, with their unlocking scripts, and keeps the order of the inputs of the draft transaction.
+// The indexes of the inputs selected by the other options are their indexes in the draft transaction.
+func WithForeignInputs() SignOption {
+	return func(o *SignOptions) {
+		o.KeepForeignInputs = true
+	}
+}
+
+// NewSignOptions returns the SignOptions configured with the options.
+func NewSignOptions(opts ...SignOption) *SignOptions {
+	o := &SignOptions{SigHashFlag: sighash.AllForkID}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Flag returns the sighash flag of the input at the index.
+func (o *SignOptions) Flag(index uint32) sighash.Flag {
+	if flag, ok := o.InputFlags[index]; ok {
+		return flag
+	}
+	return o.SigHashFlag
+}
+
+// Signs reports whether the input at the index is signed.
+func (o *SignOptions) Signs(index uint32) bool {
+	return len(o.Inputs) == 0 || slices.Contains(o.Inputs, index)
+}
+
+// validate checks that the options select only the signable inputs of the transaction, with valid sighash flags.
+func (o *SignOptions) validate(tx *trx.Transaction, signable map[uint32]bool) error {
+	for _, index := range o.Inputs {
+		if !signable[index] {
+			return fmt.Errorf("%w: input %d isn't an input of the draft configuration", goclienterr.ErrInvalidSigningOptions, index)
+		}
+	}
+	for index := range o.InputFlags {
+		if !signable[index] {
+			return fmt.Errorf("%w: sighash flag set for input %d which isn't an input of the draft configuration", goclienterr.ErrInvalidSigningOptions, index)
+		}
+	}
+	for index := range signable {
+		if !o.Signs(index) {
+			continue
+		}
+		if err := validateFlag(tx, index, o.Flag(index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateFlag(tx *trx.Transaction, index uint32, flag sighash.Flag) error {
+	base := flag & sighash.Mask
+	switch {
+	case base != sighash.All && base != sighash.None && base != sighash.Single:
+		return fmt.Errorf("%w: unknown sighash type %s of input %d", goclienterr.ErrInvalidSigningOptions, flag, index)
+	case !flag.Has(sighash.ForkID):
+		return fmt.Errorf("%w: sighash flag %s of input %d lacks FORKID", goclienterr.ErrInvalidSigningOptions, flag, index)
+	case flag&^(sighash.Mask|sighash.ForkID|sighash.AnyOneCanPay) != 0:
+		return fmt.Errorf("%w: sighash flag %s of input %d has unknown bits", goclienterr.ErrInvalidSigningOptions, flag, index)
+	case base == sighash.Single && int(index) >= len(tx.Outputs):
+		// Without the corresponding output, SINGLE signs a constant hash, making the signature reusable by anyone.
+		return fmt.Errorf("%w: SINGLE sighash of input %d without a corresponding output", goclienterr.ErrInvalidSigningOptions, index)
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+// Package unlocking provides the registry of the unlocking script templates used to sign the draft transactions.
+//
+// The signer of the UserAPI classifies the locking script of every input of a draft transaction,
+// found in its Destination.LockingScript, and unlocks the input with the template registered for the script type.
+// The default registry unlocks P2PKH and P2PK outputs with the key derived for the destination.
+// Bare multisig (P2MS) outputs are unlocked with the Multisig template combining the signature of the wallet's key
+// with the partial signatures collected from the other parties, and arbitrary scripts can be unlocked
+// by the user templates registered for custom script types:
+//
+//	registry := unlocking.NewRegistry()
+//	registry.Register(unlocking.TypeMultisig, &unlocking.Multisig{Signatures: cosignerSignatures})
+//	registry.RegisterType("hashlock", isHashlock, unlocking.TemplateFunc(unlockHashlock))
+//	userAPI.SetUnlockingTemplates(registry)
+package unlocking
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/bitcoin-sv/go-sdk/script"
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	sighash "github.com/bitcoin-sv/go-sdk/transaction/sighash"
+	"github.com/bitcoin-sv/go-sdk/transaction/template/p2pkh"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// ScriptType is the type of a locking script, to which the unlocking templates are registered.
+type ScriptType string
+
+// Types of the standard locking scripts.
+const (
+	TypeP2PKH       ScriptType = "pubkeyhash"
+	TypeP2PK        ScriptType = "pubkey"
+	TypeMultisig    ScriptType = "multisig"
+	TypeNonStandard ScriptType = "nonstandard"
+)
+
+// Input is an input of a draft transaction to unlock.
+type Input struct {
+	Index         uint32                     // Index of the input in the transaction.
+	DraftID       string                     // ID of the draft transaction.
+	Draft         *response.TransactionInput // Input of the draft transaction configuration, with its destination.
+	LockingScript *script.Script             // Locking script of the spent output.
+	Type          ScriptType                 // Type of the locking script, set by the Registry.
+	Key           *ec.PrivateKey             // Key derived for the destination of the input; nil if the signer has no keys.
+	SigHashFlag   sighash.Flag               // Sighash flag to sign the input with.
+}
+
+// Template creates the unlocking script template of an input.
+type Template interface {
+	Unlocker(input *Input) (trx.UnlockingScriptTemplate, error)
+}
+
+// TemplateFunc is an adapter to allow the use of ordinary functions as the Template.
+type TemplateFunc func(input *Input) (trx.UnlockingScriptTemplate, error)
+
+// Unlocker calls f(input).
+func (f TemplateFunc) Unlocker(input *Input) (trx.UnlockingScriptTemplate, error) {
+	return f(input)
+}
+
+// Matcher reports whether the locking script is of a custom type.
+type Matcher func(lockingScript *script.Script) bool
+
+type customType struct {
+	scriptType ScriptType
+	match      Matcher
+}
+
+// Registry maps the locking script types to the unlocking templates. It is safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	custom    []customType
+	templates map[ScriptType]Template
+}
+
+// NewRegistry creates a new Registry unlocking the P2PKH and P2PK outputs with the key of the input.
+// The multisig outputs require the Multisig template to be registered with the partial signatures of the other parties.
+func NewRegistry() *Registry {
+	return &Registry{
+		templates: map[ScriptType]Template{
+			TypeP2PKH: TemplateFunc(unlockP2PKH),
+			TypeP2PK:  TemplateFunc(unlockP2PK),
+		},
+	}
+}
+
+// Register sets the template of the script type, replacing the previous one.
+func (r *Registry) Register(scriptType ScriptType, template Template) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.templates[scriptType] = template
+}
+
+// RegisterType registers a custom script type recognized by the matcher, with its template.
+// The custom types are matched in the order of registration, before the standard types.
+func (r *Registry) RegisterType(scriptType ScriptType, match Matcher, template Template) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.custom = append(r.custom, customType{scriptType: scriptType, match: match})
+	r.templates[scriptType] = template
+}
+
+// Classify returns the type of the locking script.
+func (r *Registry) Classify(lockingScript *script.Script) ScriptType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, c := range r.custom {
+		if c.match(lockingScript) {
+			return c.scriptType
+		}
+	}
+	switch {
+	case lockingScript.IsP2PKH():
+		return TypeP2PKH
+	case lockingScript.IsP2PK():
+		return TypeP2PK
+	case lockingScript.IsMultiSigOut():
+		return TypeMultisig
+	default:
+		return TypeNonStandard
+	}
+}
+
+// Unlocker classifies the locking script of the input, sets its Type and returns the unlocking script template
+// created by the template registered for the type. It returns goclienterr.ErrUnsupportedLockingScript
+// if no template is registered for the type.
+func (r *Registry) Unlocker(input *Input) (trx.UnlockingScriptTemplate, error) {
+	input.Type = r.Classify(input.LockingScript)
+
+	r.mu.RLock()
+	template, ok := r.templates[input.Type]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: no template for %s script of input %d", goclienterr.ErrUnsupportedLockingScript, input.Type, input.Index)
+	}
+
+	unlocker, err := template.Unlocker(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s unlocker of input %d: %w", input.Type, input.Index, err)
+	}
+	return unlocker, nil
+}
+
+// NewTransaction parses the draft transaction and rebuilds its inputs from the inputs of the draft configuration,
+// enriched with their source outputs, so they can be signed. With WithForeignInputs, the inputs of the draft
+// transaction are kept instead: the inputs of the configuration are enriched, the ones missing from the transaction
+// are added to it, and the inputs of other parties are left as they are.
+// It returns the transaction, without templates, and the description of each input to sign, selected
+// and flagged according to the options. Without options, all inputs of the configuration are signed with sighash.AllForkID.
+// It returns goclienterr.ErrInvalidSigningOptions if the options select an input which cannot be signed
+// or an invalid sighash flag.
+func NewTransaction(draft *response.DraftTransaction, opts ...SignOption) (*trx.Transaction, []*Input, error) {
+	tx, err := trx.NewTransactionFromHex(draft.Hex)
+	if err != nil {
+		return nil, nil, errors.Join(goclienterr.ErrFailedToParseHex, err)
+	}
+	options := NewSignOptions(opts...)
+	if !options.KeepForeignInputs {
+		tx.Inputs = make([]*trx.TransactionInput, 0, len(draft.Configuration.Inputs))
+	}
+
+	draftInputs := make(map[string]*response.TransactionInput, len(draft.Configuration.Inputs))
+	for _, draftInput := range draft.Configuration.Inputs {
+		draftInputs[outpoint(draftInput.TransactionID, draftInput.OutputIndex)] = draftInput
+	}
+
+	var inputs []*Input
+	enrich := func(index int, draftInput *response.TransactionInput) error {
+		lockingScript, err := script.NewFromHex(draftInput.Destination.LockingScript)
+		if err != nil {
+			return errors.Join(goclienterr.ErrCreateLockingScript, err)
+		}
+		if index == len(tx.Inputs) {
+			err = tx.AddInputFrom(draftInput.TransactionID, draftInput.OutputIndex, lockingScript.String(), draftInput.Satoshis, nil)
+			if err != nil {
+				return errors.Join(goclienterr.ErrAddInputsToTransaction, err)
+			}
+		} else {
+			tx.Inputs[index].SetSourceTxOutput(&trx.TransactionOutput{Satoshis: draftInput.Satoshis, LockingScript: lockingScript})
+		}
+		inputs = append(inputs, &Input{
+			Index:         uint32(index), //nolint: gosec // the number of inputs fits in uint32
+			DraftID:       draft.ID,
+			Draft:         draftInput,
+			LockingScript: lockingScript,
+		})
+		delete(draftInputs, outpoint(draftInput.TransactionID, draftInput.OutputIndex))
+		return nil
+	}
+
+	for i, txInput := range tx.Inputs {
+		draftInput, ok := draftInputs[outpoint(txInput.SourceTXID.String(), txInput.SourceTxOutIndex)]
+		if !ok {
+			continue
+		}
+		if err := enrich(i, draftInput); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, draftInput := range draft.Configuration.Inputs {
+		if _, missing := draftInputs[outpoint(draftInput.TransactionID, draftInput.OutputIndex)]; missing {
+			if err := enrich(len(tx.Inputs), draftInput); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	signable := make(map[uint32]bool, len(inputs))
+	for _, input := range inputs {
+		signable[input.Index] = true
+	}
+	if err := options.validate(tx, signable); err != nil {
+		return nil, nil, err
+	}
+
+	selected := inputs[:0]
+	for _, input := range inputs {
+		if options.Signs(input.Index) {
+			input.SigHashFlag = options.Flag(input.Index)
+			selected = append(selected, input)
+		}
+	}
+	return tx, selected, nil
+}
+
+func outpoint(txID string, index uint32) string {
+	return fmt.Sprintf("%s:%d", txID, index)
+}
+
+func unlockP2PKH(input *Input) (trx.UnlockingScriptTemplate, error) {
+	if input.Key == nil {
+		return nil, errors.New("private key is required")
+	}
+	flag := input.SigHashFlag
+	return p2pkh.Unlock(input.Key, &flag)
+}
+
+func unlockP2PK(input *Input) (trx.UnlockingScriptTemplate, error) {
+	if input.Key == nil {
+		return nil, errors.New("private key is required")
+	}
+	return &p2pk{key: input.Key, flag: input.SigHashFlag}, nil
+}
+
+// p2pk unlocks the P2PK outputs with the signature alone.
+type p2pk struct {
+	key  *ec.PrivateKey
+	flag sighash.Flag
+}
+
+func (p *p2pk) Sign(tx *trx.Transaction, inputIndex uint32) (*script.Script, error) {
+	sig, err := sign(tx, inputIndex, p.key, p.flag)
+	if err != nil {
+		return nil, err
+	}
+	s := &script.Script{}
+	if err := s.AppendPushData(sig); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (p *p2pk) EstimateLength(*trx.Transaction, uint32) uint32 {
+	return maxSignatureSize + 1
+}
+
+// maxSignatureSize is the maximum size of a DER signature with the sighash flag.
+const maxSignatureSize = 73
+
+// sign returns the DER signature of the input followed by the sighash flag.
+func sign(tx *trx.Transaction, inputIndex uint32, key *ec.PrivateKey, flag sighash.Flag) ([]byte, error) {
+	if tx.Inputs[inputIndex].SourceTxOutput() == nil {
+		return nil, trx.ErrEmptyPreviousTx
+	}
+	hash, err := tx.CalcInputSignatureHash(inputIndex, flag)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := key.Sign(hash)
+	if err != nil {
+		return nil, err
+	}
+	return append(sig.Serialize(), byte(flag)), nil
+}
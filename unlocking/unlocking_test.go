@@ -0,0 +1,423 @@
+package unlocking_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/bitcoin-sv/go-sdk/script"
+	"github.com/bitcoin-sv/go-sdk/script/interpreter"
+	trx "github.com/bitcoin-sv/go-sdk/transaction"
+	sighash "github.com/bitcoin-sv/go-sdk/transaction/sighash"
+	"github.com/bitcoin-sv/go-sdk/transaction/template/p2pkh"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
+	"github.com/bitcoin-sv/spv-wallet-go-client/unlocking"
+	"github.com/bitcoin-sv/spv-wallet/models/response"
+	"github.com/stretchr/testify/require"
+)
+
+const sourceTxID = "3c8edde27cb9a9132c22038dac4391496be9db16fd21351565cc1006966fdad5"
+
+func TestRegistry_Classify(t *testing.T) {
+	key := givenKey(t)
+	registry := unlocking.NewRegistry()
+	registry.RegisterType("hashlock", isHashlock, unlocking.TemplateFunc(unlockHashlock))
+
+	tests := map[string]struct {
+		lockingScript *script.Script
+		expected      unlocking.ScriptType
+	}{
+		"P2PKH":    {lockingScript: givenP2PKH(t, key), expected: unlocking.TypeP2PKH},
+		"P2PK":     {lockingScript: givenP2PK(t, key), expected: unlocking.TypeP2PK},
+		"multisig": {lockingScript: givenMultisig(t, 1, key), expected: unlocking.TypeMultisig},
+		"custom":   {lockingScript: givenHashlock(t), expected: "hashlock"},
+		"data":     {lockingScript: &script.Script{script.OpFALSE, script.OpRETURN}, expected: unlocking.TypeNonStandard},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// when:
+			scriptType := registry.Classify(tc.lockingScript)
+
+			// then:
+			require.Equal(t, tc.expected, scriptType)
+		})
+	}
+}
+
+func TestRegistry_Unlocker(t *testing.T) {
+	t.Run("Standard scripts unlocked with the key of the input", func(t *testing.T) {
+		// given:
+		key := givenKey(t)
+		draft := givenDraft(t, givenP2PKH(t, key), givenP2PK(t, key))
+
+		// when:
+		tx, err := signDraft(unlocking.NewRegistry(), draft, key)
+
+		// then:
+		require.NoError(t, err)
+		requireUnlocked(t, tx)
+	})
+
+	t.Run("Custom script unlocked with the user template", func(t *testing.T) {
+		// given:
+		registry := unlocking.NewRegistry()
+		registry.RegisterType("hashlock", isHashlock, unlocking.TemplateFunc(unlockHashlock))
+		draft := givenDraft(t, givenHashlock(t))
+
+		// when:
+		tx, err := signDraft(registry, draft, nil)
+
+		// then:
+		require.NoError(t, err)
+		requireUnlocked(t, tx)
+	})
+
+	t.Run("Script without template", func(t *testing.T) {
+		// given:
+		draft := givenDraft(t, givenHashlock(t))
+
+		// when:
+		_, err := signDraft(unlocking.NewRegistry(), draft, nil)
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrUnsupportedLockingScript)
+	})
+}
+
+func TestMultisig(t *testing.T) {
+	alice, bob, carol := givenKey(t), givenKey(t), givenKey(t)
+	lockingScript := givenMultisig(t, 2, alice, bob, carol)
+
+	t.Run("Signature of the wallet combined with a partial signature", func(t *testing.T) {
+		// given:
+		draft := givenDraft(t, lockingScript, givenP2PKH(t, alice))
+		partial, err := unlocking.SignPartial(draft, carol, sighash.AllForkID)
+		require.NoError(t, err)
+		registry := unlocking.NewRegistry()
+		registry.Register(unlocking.TypeMultisig, &unlocking.Multisig{Signatures: partial})
+
+		// when:
+		tx, err := signDraft(registry, draft, alice)
+
+		// then:
+		require.NoError(t, err)
+		require.Len(t, partial, 1)
+		require.Equal(t, uint32(0), partial[0].Input)
+		requireUnlocked(t, tx)
+	})
+
+	t.Run("Partial signatures of the other parties only", func(t *testing.T) {
+		// given:
+		draft := givenDraft(t, lockingScript)
+		fromBob, err := unlocking.SignPartial(draft, bob, sighash.AllForkID)
+		require.NoError(t, err)
+		fromCarol, err := unlocking.SignPartial(draft, carol, sighash.AllForkID)
+		require.NoError(t, err)
+		registry := unlocking.NewRegistry()
+		registry.Register(unlocking.TypeMultisig, &unlocking.Multisig{Signatures: append(fromCarol, fromBob...)})
+
+		// when:
+		tx, err := signDraft(registry, draft, givenKey(t))
+
+		// then:
+		require.NoError(t, err)
+		requireUnlocked(t, tx)
+	})
+
+	t.Run("Not enough signatures", func(t *testing.T) {
+		// given:
+		draft := givenDraft(t, lockingScript)
+		registry := unlocking.NewRegistry()
+		registry.Register(unlocking.TypeMultisig, &unlocking.Multisig{})
+
+		// when:
+		_, err := signDraft(registry, draft, alice)
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrMultisigIncomplete)
+	})
+
+	t.Run("Partial signature of another transaction", func(t *testing.T) {
+		// given:
+		draft := givenDraft(t, lockingScript)
+		other := givenDraft(t, lockingScript)
+		other.Configuration.Inputs[0].Satoshis++
+		partial, err := unlocking.SignPartial(other, bob, sighash.AllForkID)
+		require.NoError(t, err)
+		registry := unlocking.NewRegistry()
+		registry.Register(unlocking.TypeMultisig, &unlocking.Multisig{Signatures: partial})
+
+		// when:
+		_, err = signDraft(registry, draft, alice)
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrInvalidPartialSignature)
+	})
+
+	t.Run("Partial signatures of other drafts are skipped", func(t *testing.T) {
+		// given:
+		draft := givenDraft(t, lockingScript)
+		other := givenDraft(t, lockingScript)
+		other.ID = "draft-2"
+		other.Configuration.Inputs[0].Satoshis++
+		fromBob, err := unlocking.SignPartial(draft, bob, sighash.AllForkID)
+		require.NoError(t, err)
+		fromCarol, err := unlocking.SignPartial(other, carol, sighash.AllForkID)
+		require.NoError(t, err)
+		registry := unlocking.NewRegistry()
+		registry.Register(unlocking.TypeMultisig, &unlocking.Multisig{Signatures: append(fromCarol, fromBob...)})
+
+		// when:
+		tx, err := signDraft(registry, draft, alice)
+
+		// then:
+		require.NoError(t, err)
+		require.Equal(t, "draft-2", fromCarol[0].DraftID)
+		requireUnlocked(t, tx)
+	})
+
+	t.Run("Partial signature of another outpoint is skipped", func(t *testing.T) {
+		// given:
+		draft := givenDraft(t, lockingScript)
+		partial, err := unlocking.SignPartial(draft, bob, sighash.AllForkID)
+		require.NoError(t, err)
+		partial[0].Outpoint = "0000000000000000000000000000000000000000000000000000000000000000:0"
+		registry := unlocking.NewRegistry()
+		registry.Register(unlocking.TypeMultisig, &unlocking.Multisig{Signatures: partial})
+
+		// when:
+		_, err = signDraft(registry, draft, alice)
+
+		// then:
+		require.ErrorIs(t, err, goclienterr.ErrMultisigIncomplete)
+	})
+
+	t.Run("Partial signature of a key outside of the multisig", func(t *testing.T) {
+		// given:
+		draft := givenDraft(t, lockingScript)
+
+		// when:
+		partial, err := unlocking.SignPartial(draft, givenKey(t), sighash.AllForkID)
+
+		// then:
+		require.NoError(t, err)
+		require.Empty(t, partial)
+	})
+}
+
+func TestNewTransaction_SignOptions(t *testing.T) {
+	t.Run("Subset of the inputs signed", func(t *testing.T) {
+		// given:
+		key := givenKey(t)
+		draft := givenDraft(t, givenP2PKH(t, key), givenP2PKH(t, key))
+
+		// when:
+		tx, err := signDraft(unlocking.NewRegistry(), draft, key, unlocking.WithInputs(1))
+
+		// then:
+		require.NoError(t, err)
+		require.Nil(t, tx.Inputs[0].UnlockingScript)
+		requireUnlocked(t, tx, 1)
+	})
+
+	t.Run("ALL|ANYONECANPAY signature stays valid when another party adds an input", func(t *testing.T) {
+		// given:
+		key := givenKey(t)
+		draft := givenDraft(t, givenP2PKH(t, key))
+
+		// when:
+		tx, err := signDraft(unlocking.NewRegistry(), draft, key, unlocking.WithSigHashFlag(sighash.AllForkID|sighash.AnyOneCanPay))
+		require.NoError(t, err)
+		require.NoError(t, tx.AddInputFrom(sourceTxID, 7, givenP2PKH(t, givenKey(t)).String(), 500, nil))
+
+		// then:
+		requireUnlocked(t, tx, 0)
+	})
+
+	t.Run("SINGLE|ANYONECANPAY signature stays valid when another party adds an input and an output", func(t *testing.T) {
+		// given:
+		key := givenKey(t)
+		draft := givenDraft(t, givenP2PKH(t, key))
+
+		// when:
+		tx, err := signDraft(unlocking.NewRegistry(), draft, key, unlocking.WithInputSigHashFlag(0, sighash.SingleForkID|sighash.AnyOneCanPay))
+		require.NoError(t, err)
+		require.NoError(t, tx.AddInputFrom(sourceTxID, 7, givenP2PKH(t, givenKey(t)).String(), 500, nil))
+		tx.AddOutput(&trx.TransactionOutput{Satoshis: 400, LockingScript: givenP2PKH(t, givenKey(t))})
+
+		// then:
+		requireUnlocked(t, tx, 0)
+	})
+
+	t.Run("Inputs of other parties kept", func(t *testing.T) {
+		// given:
+		key := givenKey(t)
+		draft := givenDraft(t, givenP2PKH(t, key))
+		tx, err := trx.NewTransactionFromHex(draft.Hex)
+		require.NoError(t, err)
+		counterparty := &trx.TransactionInput{SourceTxOutIndex: 9, UnlockingScript: &script.Script{script.OpTRUE}, SequenceNumber: trx.DefaultSequenceNumber}
+		counterparty.SourceTXID = tx.Inputs[0].SourceTXID
+		tx.Inputs = append([]*trx.TransactionInput{counterparty}, tx.Inputs...)
+		draft.Hex = tx.Hex()
+
+		// when:
+		signed, err := signDraft(unlocking.NewRegistry(), draft, key, unlocking.WithForeignInputs())
+
+		// then:
+		require.NoError(t, err)
+		require.Len(t, signed.Inputs, 2)
+		require.Equal(t, &script.Script{script.OpTRUE}, signed.Inputs[0].UnlockingScript)
+		requireUnlocked(t, signed, 1)
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		key := givenKey(t)
+		draft := givenDraft(t, givenP2PKH(t, key), givenP2PKH(t, key))
+
+		tests := map[string]unlocking.SignOption{
+			"input outside of the draft":         unlocking.WithInputs(2),
+			"flag of input outside of the draft": unlocking.WithInputSigHashFlag(2, sighash.AllForkID),
+			"flag without FORKID":                unlocking.WithSigHashFlag(sighash.All),
+			"flag with unknown bits":             unlocking.WithSigHashFlag(sighash.AllForkID | 0x20),
+			"unknown sighash type":               unlocking.WithSigHashFlag(sighash.ForkID | 0x04),
+			"SINGLE without output":              unlocking.WithInputSigHashFlag(1, sighash.SingleForkID),
+		}
+		for name, opt := range tests {
+			t.Run(name, func(t *testing.T) {
+				// when:
+				_, _, err := unlocking.NewTransaction(draft, opt)
+
+				// then:
+				require.ErrorIs(t, err, goclienterr.ErrInvalidSigningOptions)
+			})
+		}
+	})
+}
+
+// signDraft signs the draft transaction like the signer of the UserAPI, with the same key for all inputs.
+func signDraft(registry *unlocking.Registry, draft *response.DraftTransaction, key *ec.PrivateKey, opts ...unlocking.SignOption) (*trx.Transaction, error) {
+	tx, inputs, err := unlocking.NewTransaction(draft, opts...)
+	if err != nil {
+		return nil, err
+	}
+	for _, input := range inputs {
+		input.Key = key
+		unlocker, err := registry.Unlocker(input)
+		if err != nil {
+			return nil, err
+		}
+		tx.Inputs[input.Index].UnlockingScriptTemplate = unlocker
+	}
+	return tx, tx.Sign()
+}
+
+func requireUnlocked(t *testing.T, tx *trx.Transaction, indexes ...int) {
+	t.Helper()
+
+	if len(indexes) == 0 {
+		for i := range tx.Inputs {
+			indexes = append(indexes, i)
+		}
+	}
+	for _, i := range indexes {
+		err := interpreter.NewEngine().Execute(
+			interpreter.WithTx(tx, i, tx.Inputs[i].SourceTxOutput()),
+			interpreter.WithForkID(),
+			interpreter.WithAfterGenesis(),
+		)
+		require.NoError(t, err, "input %d", i)
+	}
+}
+
+func givenDraft(t *testing.T, lockingScripts ...*script.Script) *response.DraftTransaction {
+	t.Helper()
+
+	tx := trx.NewTransaction()
+	draft := &response.DraftTransaction{ID: "draft-1"}
+	for i, lockingScript := range lockingScripts {
+		input := &response.TransactionInput{
+			Utxo: response.Utxo{
+				UtxoPointer: response.UtxoPointer{TransactionID: sourceTxID, OutputIndex: uint32(i)}, //nolint: gosec // small index in tests
+				Satoshis:    1000,
+			},
+			Destination: response.Destination{LockingScript: lockingScript.String()},
+		}
+		require.NoError(t, tx.AddInputFrom(sourceTxID, input.OutputIndex, lockingScript.String(), input.Satoshis, nil))
+		draft.Configuration.Inputs = append(draft.Configuration.Inputs, input)
+	}
+	tx.AddOutput(&trx.TransactionOutput{Satoshis: 900, LockingScript: givenP2PKH(t, givenKey(t))})
+	draft.Hex = tx.Hex()
+	return draft
+}
+
+func givenKey(t *testing.T) *ec.PrivateKey {
+	t.Helper()
+
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	return key
+}
+
+func givenP2PKH(t *testing.T, key *ec.PrivateKey) *script.Script {
+	t.Helper()
+
+	address, err := script.NewAddressFromPublicKey(key.PubKey(), true)
+	require.NoError(t, err)
+	lockingScript, err := p2pkh.Lock(address)
+	require.NoError(t, err)
+	return lockingScript
+}
+
+func givenP2PK(t *testing.T, key *ec.PrivateKey) *script.Script {
+	t.Helper()
+
+	lockingScript := &script.Script{}
+	require.NoError(t, lockingScript.AppendPushData(key.PubKey().Compressed()))
+	require.NoError(t, lockingScript.AppendOpcodes(script.OpCHECKSIG))
+	return lockingScript
+}
+
+func givenMultisig(t *testing.T, required int, keys ...*ec.PrivateKey) *script.Script {
+	t.Helper()
+
+	lockingScript := &script.Script{}
+	require.NoError(t, lockingScript.AppendOpcodes(script.Op1+byte(required-1))) //nolint: gosec // small number in tests
+	for _, key := range keys {
+		require.NoError(t, lockingScript.AppendPushData(key.PubKey().Compressed()))
+	}
+	require.NoError(t, lockingScript.AppendOpcodes(script.Op1+byte(len(keys)-1), script.OpCHECKMULTISIG)) //nolint: gosec // small number in tests
+	return lockingScript
+}
+
+var preimage = []byte("open sesame")
+
+func givenHashlock(t *testing.T) *script.Script {
+	t.Helper()
+
+	hash := sha256.Sum256(preimage)
+	lockingScript := &script.Script{}
+	require.NoError(t, lockingScript.AppendOpcodes(script.OpSHA256))
+	require.NoError(t, lockingScript.AppendPushData(hash[:]))
+	require.NoError(t, lockingScript.AppendOpcodes(script.OpEQUAL))
+	return lockingScript
+}
+
+func isHashlock(lockingScript *script.Script) bool {
+	b := []byte(*lockingScript)
+	return len(b) == 35 && b[0] == script.OpSHA256 && b[1] == script.OpDATA32 && b[34] == script.OpEQUAL
+}
+
+func unlockHashlock(*unlocking.Input) (trx.UnlockingScriptTemplate, error) {
+	return &hashlock{}, nil
+}
+
+type hashlock struct{}
+
+func (*hashlock) Sign(*trx.Transaction, uint32) (*script.Script, error) {
+	s := &script.Script{}
+	return s, s.AppendPushData(preimage)
+}
+
+func (*hashlock) EstimateLength(*trx.Transaction, uint32) uint32 {
+	return uint32(len(preimage)) + 1
+}
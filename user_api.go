@@ -6,8 +6,12 @@ import (
 	"fmt"
 	"net/url"
 
+	"github.com/bitcoin-sv/go-sdk/transaction/chaintracker"
+	"github.com/bitcoin-sv/spv-wallet-go-client/capabilities"
+	"github.com/bitcoin-sv/spv-wallet-go-client/coinselect"
 	"github.com/bitcoin-sv/spv-wallet-go-client/commands"
 	"github.com/bitcoin-sv/spv-wallet-go-client/config"
+	goclienterr "github.com/bitcoin-sv/spv-wallet-go-client/errors"
 	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v1/configs"
 	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v1/errutil"
 	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v1/user/accesskeys"
@@ -22,7 +26,13 @@ import (
 	"github.com/bitcoin-sv/spv-wallet-go-client/internal/auth"
 	"github.com/bitcoin-sv/spv-wallet-go-client/internal/constants"
 	"github.com/bitcoin-sv/spv-wallet-go-client/internal/restyutil"
+	"github.com/bitcoin-sv/spv-wallet-go-client/payments"
+	"github.com/bitcoin-sv/spv-wallet-go-client/policy"
 	"github.com/bitcoin-sv/spv-wallet-go-client/queries"
+	"github.com/bitcoin-sv/spv-wallet-go-client/reconcile"
+	"github.com/bitcoin-sv/spv-wallet-go-client/sweep"
+	"github.com/bitcoin-sv/spv-wallet-go-client/txwait"
+	"github.com/bitcoin-sv/spv-wallet-go-client/unlocking"
 	"github.com/bitcoin-sv/spv-wallet/models"
 	"github.com/bitcoin-sv/spv-wallet/models/filter"
 	"github.com/bitcoin-sv/spv-wallet/models/response"
@@ -50,6 +60,37 @@ type UserAPI struct {
 	utxosAPI        *utxos.API
 	paymailsAPI     *paymails.API
 	totpAPI         *totp.API //only available when using xPriv
+	v2              *UserAPIV2
+	capabilities    *capabilities.Capabilities
+}
+
+// Capabilities returns the capabilities of the SPV Wallet server, learned from its shared configuration
+// on the first call of a method depending on an optional feature, or with LoadCapabilities.
+func (u *UserAPI) Capabilities() *capabilities.Capabilities {
+	return u.capabilities
+}
+
+// LoadCapabilities retrieves the shared configuration of the SPV Wallet server and caches its capabilities,
+// e.g., right after the construction, so the methods depending on the unavailable features fail fast
+// with a *capabilities.UnavailableError. Otherwise, the capabilities are retrieved lazily.
+func (u *UserAPI) LoadCapabilities(ctx context.Context) error {
+	return u.capabilities.Load(ctx)
+}
+
+// requireFeature returns the *capabilities.UnavailableError if the server doesn't enable the feature.
+// If the shared configuration cannot be retrieved, the request is sent anyway, and the server decides.
+func requireFeature(ctx context.Context, caps *capabilities.Capabilities, feature capabilities.Feature) error {
+	err := caps.Require(ctx, feature)
+	var unavailable *capabilities.UnavailableError
+	if errors.As(err, &unavailable) {
+		return err
+	}
+	return nil
+}
+
+// V2 returns the client of the v2 user APIs, sharing the configuration and the authentication of the UserAPI.
+func (u *UserAPI) V2() *UserAPIV2 {
+	return u.v2
 }
 
 // Contacts retrieves a paginated list of user contacts from the user contacts API.
@@ -82,7 +123,12 @@ func (u *UserAPI) ContactWithPaymail(ctx context.Context, paymail string) (*resp
 // UpsertContact adds or updates a user contact via the user contacts API.
 // The response is unmarshaled into a *response.Contact.
 // Returns an error if the API request fails or the response cannot be decoded.
+// It fails fast with a *capabilities.UnavailableError if the server doesn't enable the PIKE contacts.
 func (u *UserAPI) UpsertContact(ctx context.Context, cmd commands.UpsertContact) (*response.Contact, error) {
+	if err := requireFeature(ctx, u.capabilities, capabilities.FeaturePikeContacts); err != nil {
+		return nil, err
+	}
+
 	res, err := u.contactsAPI.UpsertContact(ctx, cmd)
 	if err != nil {
 		return nil, errutil.NewHTTPErrorFormatter(constants.UserContactsAPI, "upsert contact", err).FormatPutErr()
@@ -130,7 +176,12 @@ func (u *UserAPI) UnconfirmContact(ctx context.Context, paymail string) error {
 
 // AcceptInvitation accepts a user contact with the given paymail via the user contacts API.
 // Returns an error if the API request fails or the response cannot be decoded. A nil error indicates the acceptation was successful.
+// It fails fast with a *capabilities.UnavailableError if the server doesn't enable the PIKE contacts.
 func (u *UserAPI) AcceptInvitation(ctx context.Context, paymail string) error {
+	if err := requireFeature(ctx, u.capabilities, capabilities.FeaturePikeContacts); err != nil {
+		return err
+	}
+
 	err := u.invitationsAPI.AcceptInvitation(ctx, paymail)
 	if err != nil {
 		return errutil.NewHTTPErrorFormatter(constants.UserInvitationsAPI, "accept invitation", err).FormatPostErr()
@@ -142,7 +193,12 @@ func (u *UserAPI) AcceptInvitation(ctx context.Context, paymail string) error {
 // RejectInvitation rejects a user contact with the given paymail via the user contacts API.
 // Returns an error if the API request fails or the response cannot be decoded.
 // A nil error indicates the rejection was successful.
+// It fails fast with a *capabilities.UnavailableError if the server doesn't enable the PIKE contacts.
 func (u *UserAPI) RejectInvitation(ctx context.Context, paymail string) error {
+	if err := requireFeature(ctx, u.capabilities, capabilities.FeaturePikeContacts); err != nil {
+		return err
+	}
+
 	err := u.invitationsAPI.RejectInvitation(ctx, paymail)
 	if err != nil {
 		return errutil.NewHTTPErrorFormatter(constants.UserInvitationsAPI, "reject invitation", err).FormatDeleteErr()
@@ -176,6 +232,9 @@ func (u *UserAPI) DraftTransaction(ctx context.Context, cmd *commands.DraftTrans
 }
 
 // RecordTransaction submits a transaction for recording via the user transactions API.
+// The hex may be either a raw transaction hex or a BEEF hex; a BEEF transaction is verified with SPV
+// against the merkle roots set with SetMerkleRoots and recorded as its raw hex. An invalid BEEF fails with
+// goclienterr.ErrInvalidBEEF and a BEEF failing the verification fails with goclienterr.ErrBEEFVerification.
 // The response is unmarshaled into a *response.Transaction.
 // Returns an error if the request fails or the response cannot be decoded.
 func (u *UserAPI) RecordTransaction(ctx context.Context, cmd *commands.RecordTransaction) (*response.Transaction, error) {
@@ -233,8 +292,9 @@ func (u *UserAPI) Transaction(ctx context.Context, ID string) (*response.Transac
 // FinalizeTransaction finalizes a draft transaction and returns its signed hex representation.
 // It uses the draft transaction details to construct, enrich, and sign the transaction
 // through the `transactionsigner.TransactionSignedHex` utility function.
+// If a spending policy is set with SetSpendingPolicy, the draft is authorized by it before signing.
 // The response is the signed transaction in hex format.
-// Returns an error if the transaction cannot be finalized.
+// Returns an error if the transaction violates the spending policy or cannot be finalized.
 func (u *UserAPI) FinalizeTransaction(draft *response.DraftTransaction) (string, error) {
 	res, err := u.transactionsAPI.FinalizeTransaction(draft)
 	if err != nil {
@@ -244,6 +304,54 @@ func (u *UserAPI) FinalizeTransaction(draft *response.DraftTransaction) (string,
 	return res, nil
 }
 
+// SignTransaction signs a draft transaction according to the signing options and returns its hex representation.
+// Unlike FinalizeTransaction, which signs all inputs of the draft with SIGHASH_ALL|FORKID, it allows setting
+// the sighash flags per input, e.g., SINGLE|ANYONECANPAY for crowdfunding, with unlocking.WithInputSigHashFlag,
+// and signing only a subset of the inputs with unlocking.WithInputs, returning a partially signed transaction
+// to be completed by another party. With unlocking.WithForeignInputs, the inputs added to the draft hex by other parties,
+// e.g., in an atomic swap, are kept as they are.
+// If a spending policy is set with SetSpendingPolicy, the draft is authorized by it before signing.
+// Returns goclienterr.ErrInvalidSigningOptions if the options select an input which isn't an input of the draft
+// or an invalid sighash flag.
+func (u *UserAPI) SignTransaction(draft *response.DraftTransaction, opts ...unlocking.SignOption) (string, error) {
+	res, err := u.transactionsAPI.SignTransaction(draft, opts...)
+	if err != nil {
+		return "", fmt.Errorf("couldn't sign transaction with ID: %s, %w", draft.ID, err)
+	}
+
+	return res, nil
+}
+
+// SetSpendingPolicy sets the policy engine authorizing every draft transaction before it is signed
+// by FinalizeTransaction, the sending methods and the UTXO operations; nil disables the policy.
+// A draft violating the policy fails with a *policy.Violation wrapping goclienterr.ErrPolicyViolation.
+// The dry runs aren't authorized, as they are never recorded.
+// It must not be called concurrently with the methods signing transactions.
+func (u *UserAPI) SetSpendingPolicy(engine *policy.Engine) {
+	if engine == nil {
+		u.transactionsAPI.SetSpendingPolicy(nil)
+		return
+	}
+	u.transactionsAPI.SetSpendingPolicy(engine)
+}
+
+// SetUnlockingTemplates sets the registry of the unlocking templates used to sign the inputs of the draft transactions
+// by FinalizeTransaction, the sending methods and the UTXO operations; nil restores the default registry,
+// which unlocks only the P2PKH and P2PK outputs. Registering the unlocking.Multisig template with the partial
+// signatures of the other parties allows spending the bare multisig outputs, and custom templates allow
+// spending arbitrary locking scripts. An input without a registered template fails with goclienterr.ErrUnsupportedLockingScript.
+// It must not be called concurrently with the methods signing transactions.
+func (u *UserAPI) SetUnlockingTemplates(templates *unlocking.Registry) {
+	u.transactionsAPI.SetUnlockingTemplates(templates)
+}
+
+// SetMerkleRoots sets the merkle roots the BEEF transactions passed to RecordTransaction are verified against,
+// e.g., a beef.MemoryMerkleRoots kept up to date with SyncMerkleRoots; nil disables recording the BEEF transactions.
+// It must not be called concurrently with RecordTransaction.
+func (u *UserAPI) SetMerkleRoots(roots chaintracker.ChainTracker) {
+	u.transactionsAPI.SetMerkleRoots(roots)
+}
+
 // SendToRecipients creates, finalizes, and broadcasts a transaction to multiple recipients.
 // This method handles the complete process of drafting, finalizing, and recording the transaction
 // using the recipient details provided in the command.
@@ -258,6 +366,153 @@ func (u *UserAPI) SendToRecipients(ctx context.Context, cmd *commands.SendToReci
 	return res, nil
 }
 
+// SendToRecipientsIdempotent sends a transaction to multiple recipients exactly once per payment ID.
+// The client-supplied payment ID is stored in the transaction metadata under payments.MetadataKey,
+// and the progress of the payment (drafted, signed, recorded) is persisted in the given store.
+//
+// If a previous attempt failed, e.g., because recording the transaction timed out after the SPV Wallet
+// had already accepted it, calling this method again with the same payment ID resumes from the last
+// completed step or returns the already recorded transaction instead of creating a second payment.
+// The response is unmarshalled into a *response.Transaction struct.
+// Returns an error if the transaction fails at any step or the store cannot be accessed.
+func (u *UserAPI) SendToRecipientsIdempotent(ctx context.Context, cmd *commands.IdempotentSendToRecipients, store payments.Store) (*response.Transaction, error) {
+	res, err := u.transactionsAPI.IdempotentSendToRecipients(ctx, cmd, store)
+	if err != nil {
+		msg := fmt.Sprintf("send to recipients with payment ID: %s", cmd.PaymentID)
+		return nil, errutil.NewHTTPErrorFormatter(constants.UserTransactionsAPI, msg, err).FormatPostErr()
+	}
+
+	return res, nil
+}
+
+// DryRunSendToRecipients previews a transaction to multiple recipients without recording it.
+// It creates a draft transaction and signs it locally to report the inputs selected by the SPV Wallet,
+// the change, the exact size of the final transaction, its fee and the fee required by the draft's fee unit.
+// For a UserAPI created without an xPriv, the transaction cannot be signed, so its size is estimated
+// assuming P2PKH unlocking scripts and the SizeExact field of the result is false.
+//
+// Note: the inputs of the draft stay reserved by the SPV Wallet until the draft expires.
+// The response is a *payments.FeeEstimate.
+// Returns an error if the draft cannot be created or the transaction cannot be signed.
+func (u *UserAPI) DryRunSendToRecipients(ctx context.Context, cmd *commands.SendToRecipients) (*payments.FeeEstimate, error) {
+	res, err := u.transactionsAPI.DryRunSendToRecipients(ctx, cmd)
+	if err != nil {
+		return nil, errutil.NewHTTPErrorFormatter(constants.UserTransactionsAPI, "dry run send to recipients", err).FormatPostErr()
+	}
+
+	return res, nil
+}
+
+// EstimateFee returns the fee, in satoshis, of a transaction to multiple recipients without recording it.
+// It is a shorthand for DryRunSendToRecipients, which provides the full details of the estimation.
+func (u *UserAPI) EstimateFee(ctx context.Context, cmd *commands.SendToRecipients) (uint64, error) {
+	res, err := u.DryRunSendToRecipients(ctx, cmd)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.Fee, nil
+}
+
+// ConsolidateUTXOs merges the spendable UTXOs matching the command's filter into fewer outputs sent back
+// to the user. The UTXOs are spent in batches, one transaction per batch, so that the estimated size
+// of every transaction stays under the command's size limit. Each transaction has cmd.Outputs change
+// outputs, and batches which wouldn't reduce the number of UTXOs are skipped.
+//
+// The response is a slice of the recorded consolidation transactions. If a batch fails, the transactions
+// recorded for the previous batches are returned along with the error.
+func (u *UserAPI) ConsolidateUTXOs(ctx context.Context, cmd *commands.ConsolidateUTXOs) ([]*response.Transaction, error) {
+	utxos, err := coinselect.NewBuilder(u.utxosAPI, nil, coinselect.WithFilter(cmd.Filter)).Candidates(ctx)
+	if err != nil {
+		return nil, errutil.NewHTTPErrorFormatter(constants.UserUtxosAPI, "retrieve UTXOs to consolidate", err).FormatGetErr()
+	}
+
+	res, err := u.transactionsAPI.ConsolidateUTXOs(ctx, cmd, utxos)
+	if err != nil {
+		return res, errutil.NewHTTPErrorFormatter(constants.UserTransactionsAPI, "consolidate UTXOs", err).FormatPostErr()
+	}
+
+	return res, nil
+}
+
+// SplitUTXOs fans a single UTXO out into cmd.Outputs equal outputs sent back to the user,
+// so that they can be spent by parallel transactions. If the command doesn't point to the UTXO,
+// the largest spendable UTXO of the user is split.
+// The response is unmarshalled into a *response.Transaction struct.
+// Returns an error if the UTXO is not spendable or the transaction fails at any step.
+func (u *UserAPI) SplitUTXOs(ctx context.Context, cmd *commands.SplitUTXOs) (*response.Transaction, error) {
+	var opts []coinselect.Option
+	if cmd.UTXO != nil {
+		opts = append(opts, coinselect.WithFilter(filter.UtxoFilter{TransactionID: &cmd.UTXO.TransactionID, OutputIndex: &cmd.UTXO.OutputIndex}))
+	}
+
+	utxos, err := coinselect.NewBuilder(u.utxosAPI, nil, opts...).Candidates(ctx)
+	if err != nil {
+		return nil, errutil.NewHTTPErrorFormatter(constants.UserUtxosAPI, "retrieve UTXO to split", err).FormatGetErr()
+	}
+
+	var largest *response.Utxo
+	for _, utxo := range utxos {
+		// Zero-valued filter fields are not sent to the SPV Wallet API, so the pointer is matched locally too.
+		if cmd.UTXO != nil && utxo.UtxoPointer != *cmd.UTXO {
+			continue
+		}
+		if largest == nil || utxo.Satoshis > largest.Satoshis {
+			largest = utxo
+		}
+	}
+	if largest == nil {
+		return nil, goclienterr.ErrUTXONotFound
+	}
+
+	res, err := u.transactionsAPI.SplitUTXOs(ctx, cmd, largest)
+	if err != nil {
+		return nil, errutil.NewHTTPErrorFormatter(constants.UserTransactionsAPI, "split UTXO", err).FormatPostErr()
+	}
+
+	return res, nil
+}
+
+// SweepPrivateKey moves all funds locked to an external private key, e.g., a paper wallet, into the wallet.
+// The UTXOs of the key's P2PKH address are taken from the source, and the funds, reduced by the fee,
+// are sent in a single transaction to the wallet destination provided by the resolver.
+// The transaction is signed locally with the swept key, which is never sent to the SPV Wallet API,
+// and recorded via the user transactions API.
+// The response is unmarshalled into a *response.Transaction struct.
+// Returns an error if the key is invalid, there is nothing to sweep or the transaction cannot be recorded.
+func (u *UserAPI) SweepPrivateKey(ctx context.Context, cmd *commands.SweepPrivateKey, source sweep.UTXOSource, resolver sweep.DestinationResolver) (*response.Transaction, error) {
+	tx, err := sweep.BuildTransaction(ctx, cmd, source, resolver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build sweeping transaction: %w", err)
+	}
+
+	res, err := u.transactionsAPI.RecordTransaction(ctx, &commands.RecordTransaction{
+		Metadata: cmd.Metadata,
+		Hex:      tx.Hex(),
+	})
+	if err != nil {
+		return nil, errutil.NewHTTPErrorFormatter(constants.UserTransactionsAPI, "record sweeping transaction", err).FormatPostErr()
+	}
+
+	return res, nil
+}
+
+// WaitForTransaction blocks until the transaction with the given ID satisfies the predicate, e.g., txwait.Mined(),
+// and returns its last state. The transaction is polled via the user transactions API with an exponential backoff,
+// and checked immediately on every webhook event routed through the txwait.Events passed with txwait.WithEvents.
+// Returns an error if the transaction is rejected, or if the timeout elapses or the context is done first.
+func (u *UserAPI) WaitForTransaction(ctx context.Context, ID string, predicate txwait.Predicate, opts ...txwait.Option) (*response.Transaction, error) {
+	return txwait.WaitForTransaction(ctx, u, ID, predicate, opts...)
+}
+
+// ReconcileBalance compares the current balance of the user's xPub with the sum of its unspent UTXOs
+// and the net value of its transactions, and cross-checks the UTXOs with the transactions.
+// The discrepancies are listed in the returned *reconcile.Report.
+// Returns an error if the xPub, the UTXOs or the transactions cannot be fetched.
+func (u *UserAPI) ReconcileBalance(ctx context.Context, opts ...reconcile.Option) (*reconcile.Report, error) {
+	return reconcile.Reconcile(ctx, u, opts...)
+}
+
 // XPub retrieves the full xpub information for the current user via the users API.
 // The response is unmarshaled into a *response.Xpub.
 // Returns an error if the request fails or the response cannot be decoded.
@@ -486,7 +741,11 @@ func initUserAPIWithXPriv(cfg config.Config, xPriv string, auth authenticator) (
 		return nil, fmt.Errorf("failed to parse addr to url.URL: %w", err)
 	}
 
-	httpClient := restyutil.NewHTTPClient(cfg, auth)
+	httpClient, err := restyutil.NewHTTPClient(cfg, auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize HTTP client: %w", err)
+	}
+
 	transactionsAPI, err := transactions.NewAPIWithXPriv(url, httpClient, xPriv)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create transactionsAPI: %w", err)
@@ -497,7 +756,7 @@ func initUserAPIWithXPriv(cfg config.Config, xPriv string, auth authenticator) (
 		return nil, fmt.Errorf("failed to create totpAPI: %w", err)
 	}
 
-	return &UserAPI{
+	userAPI := &UserAPI{
 		merkleRootsAPI:  merkleroots.NewAPI(url, httpClient),
 		configsAPI:      configs.NewAPI(url, httpClient),
 		transactionsAPI: transactionsAPI,
@@ -508,7 +767,10 @@ func initUserAPIWithXPriv(cfg config.Config, xPriv string, auth authenticator) (
 		invitationsAPI:  invitations.NewAPI(url, httpClient),
 		paymailsAPI:     paymails.NewAPI(url, httpClient),
 		totpAPI:         totpAPI,
-	}, nil
+	}
+	userAPI.capabilities = capabilities.New(userAPI)
+	userAPI.v2 = newUserAPIV2(url, httpClient, userAPI.capabilities)
+	return userAPI, nil
 }
 
 func initUserAPI(cfg config.Config, auth authenticator) (*UserAPI, error) {
@@ -517,9 +779,9 @@ func initUserAPI(cfg config.Config, auth authenticator) (*UserAPI, error) {
 		return nil, fmt.Errorf("failed to parse addr to url.URL: %w", err)
 	}
 
-	httpClient := restyutil.NewHTTPClient(cfg, auth)
-	if httpClient == nil {
-		return nil, fmt.Errorf("failed to initialize HTTP client - nil value")
+	httpClient, err := restyutil.NewHTTPClient(cfg, auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize HTTP client: %w", err)
 	}
 
 	transactionsAPI, err := transactions.NewAPI(url, httpClient)
@@ -527,7 +789,7 @@ func initUserAPI(cfg config.Config, auth authenticator) (*UserAPI, error) {
 		return nil, fmt.Errorf("failed to create transactionsAPI: %w", err)
 	}
 
-	return &UserAPI{
+	userAPI := &UserAPI{
 		merkleRootsAPI:  merkleroots.NewAPI(url, httpClient),
 		configsAPI:      configs.NewAPI(url, httpClient),
 		transactionsAPI: transactionsAPI,
@@ -537,5 +799,8 @@ func initUserAPI(cfg config.Config, auth authenticator) (*UserAPI, error) {
 		contactsAPI:     contacts.NewAPI(url, httpClient),
 		invitationsAPI:  invitations.NewAPI(url, httpClient),
 		paymailsAPI:     paymails.NewAPI(url, httpClient),
-	}, nil
+	}
+	userAPI.capabilities = capabilities.New(userAPI)
+	userAPI.v2 = newUserAPIV2(url, httpClient, userAPI.capabilities)
+	return userAPI, nil
 }
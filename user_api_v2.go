@@ -0,0 +1,149 @@
+package spvwallet
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/bitcoin-sv/spv-wallet-go-client/capabilities"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v1/errutil"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v2/user/data"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v2/user/operations"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v2/user/outlines"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/api/v2/user/users"
+	"github.com/bitcoin-sv/spv-wallet-go-client/internal/constants"
+	"github.com/bitcoin-sv/spv-wallet-go-client/v2/commands"
+	"github.com/bitcoin-sv/spv-wallet-go-client/v2/queries"
+	"github.com/bitcoin-sv/spv-wallet-go-client/v2/response"
+	"github.com/bitcoin-sv/spv-wallet/models/transaction"
+	"github.com/go-resty/resty/v2"
+)
+
+// UserAPIV2 provides methods for interacting with the v2 user APIs of the SPV Wallet:
+// the transaction outlines, the operations history, the users and the data records.
+// It shares the configuration, the authentication and the error handling with the UserAPI it is obtained from
+// with UserAPI.V2, so the callers can migrate from the v1 endpoints to the v2 endpoints gradually.
+//
+// The v2 API is an optional feature of the SPV Wallet server, which the shared configuration doesn't publish.
+// Before the first request, UserAPIV2 probes the v2 current user endpoint and caches the result:
+// if the server responds with 404 Not Found, UserAPIV2 methods fail fast with a *capabilities.UnavailableError.
+// If the probe fails, e.g., with 401 Unauthorized or a timeout, the requests are sent anyway, and the probe is retried
+// by the first request after capabilities.DefaultProbeRetryInterval, costing one more request to the server.
+// The check can be turned off with SetFeatureCheck.
+//
+// UserAPIV2 methods may return wrapped errors, including models.SPVError or
+// ErrUnrecognizedAPIResponse, depending on the behavior of the SPV Wallet API.
+type UserAPIV2 struct {
+	outlinesAPI   *outlines.API
+	operationsAPI *operations.API
+	usersAPI      *users.API
+	dataAPI       *data.API
+	capabilities  *capabilities.Capabilities
+	skipCheck     bool
+}
+
+// SetFeatureCheck turns the check of the availability of the v2 API before the requests on or off; it is on by default.
+// With the check off, the requests are sent to the server regardless of the cached result of the probe.
+// It must not be called concurrently with the requests.
+func (u *UserAPIV2) SetFeatureCheck(enabled bool) {
+	u.skipCheck = !enabled
+}
+
+// requireV2 returns the *capabilities.UnavailableError if the server doesn't provide the v2 API.
+func (u *UserAPIV2) requireV2(ctx context.Context) error {
+	if u.skipCheck {
+		return nil
+	}
+	return requireFeature(ctx, u.capabilities, capabilities.FeatureV2)
+}
+
+// TransactionOutline requests the outline of a transaction with the outputs specified in the command
+// via the v2 user transactions API. The outline is an unsigned transaction in the BEEF format
+// funded with the UTXOs of the user, with the annotations of its outputs.
+// Once signed, it can be recorded with RecordTransaction.
+// The response is unmarshaled into a *transaction.AnnotatedTransaction.
+func (u *UserAPIV2) TransactionOutline(ctx context.Context, cmd *commands.TransactionOutline) (*transaction.AnnotatedTransaction, error) {
+	if err := u.requireV2(ctx); err != nil {
+		return nil, err
+	}
+
+	res, err := u.outlinesAPI.TransactionOutline(ctx, cmd)
+	if err != nil {
+		return nil, errutil.NewHTTPErrorFormatter(constants.UserTransactionsV2API, "create a transaction outline", err).FormatPostErr()
+	}
+
+	return res, nil
+}
+
+// RecordTransaction submits a signed transaction in the BEEF format with the annotations of its outputs
+// for recording via the v2 user transactions API.
+// The response is unmarshaled into a *response.RecordedTransaction.
+func (u *UserAPIV2) RecordTransaction(ctx context.Context, cmd *commands.RecordTransaction) (*response.RecordedTransaction, error) {
+	if err := u.requireV2(ctx); err != nil {
+		return nil, err
+	}
+
+	res, err := u.outlinesAPI.RecordTransaction(ctx, cmd)
+	if err != nil {
+		return nil, errutil.NewHTTPErrorFormatter(constants.UserTransactionsV2API, "record a transaction", err).FormatPostErr()
+	}
+
+	return res, nil
+}
+
+// Operations retrieves a paginated list of the operations of the user, the changes of its balance made by the transactions,
+// via the v2 user operations API. Optional pagination can be provided using query options.
+// The response is unmarshaled into a *queries.OperationsPage.
+func (u *UserAPIV2) Operations(ctx context.Context, opts ...queries.OperationsQueryOption) (*queries.OperationsPage, error) {
+	if err := u.requireV2(ctx); err != nil {
+		return nil, err
+	}
+
+	res, err := u.operationsAPI.Operations(ctx, opts...)
+	if err != nil {
+		return nil, errutil.NewHTTPErrorFormatter(constants.UserOperationsV2API, "retrieve operations page", err).FormatGetErr()
+	}
+
+	return res, nil
+}
+
+// CurrentUser retrieves the authenticated user with its paymails and current balance via the v2 users API.
+// The response is unmarshaled into a *response.User.
+func (u *UserAPIV2) CurrentUser(ctx context.Context) (*response.User, error) {
+	if err := u.requireV2(ctx); err != nil {
+		return nil, err
+	}
+
+	res, err := u.usersAPI.CurrentUser(ctx)
+	if err != nil {
+		return nil, errutil.NewHTTPErrorFormatter(constants.UserUsersV2API, "retrieve current user", err).FormatGetErr()
+	}
+
+	return res, nil
+}
+
+// Data retrieves the data record with the ID, the outpoint of its data output, via the v2 user data API.
+// The response is unmarshaled into a *response.Data.
+func (u *UserAPIV2) Data(ctx context.Context, id string) (*response.Data, error) {
+	if err := u.requireV2(ctx); err != nil {
+		return nil, err
+	}
+
+	res, err := u.dataAPI.Data(ctx, id)
+	if err != nil {
+		return nil, errutil.NewHTTPErrorFormatter(constants.UserDataV2API, "retrieve data", err).FormatGetErr()
+	}
+
+	return res, nil
+}
+
+func newUserAPIV2(url *url.URL, httpClient *resty.Client, caps *capabilities.Capabilities) *UserAPIV2 {
+	usersAPI := users.NewAPI(url, httpClient)
+	caps.SetProbe(capabilities.FeatureV2, usersAPI.Available)
+	return &UserAPIV2{
+		outlinesAPI:   outlines.NewAPI(url, httpClient),
+		operationsAPI: operations.NewAPI(url, httpClient),
+		usersAPI:      usersAPI,
+		dataAPI:       data.NewAPI(url, httpClient),
+		capabilities:  caps,
+	}
+}
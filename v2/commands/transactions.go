@@ -0,0 +1,16 @@
+// Package commands defines the commands sent to the v2 endpoints of the SPV Wallet API.
+// It mirrors the v1 commands package, so the callers can migrate to the v2 API one command at a time.
+package commands
+
+import (
+	"github.com/bitcoin-sv/spv-wallet/models/request"
+	"github.com/bitcoin-sv/spv-wallet/models/transaction"
+)
+
+// TransactionOutline specifies the outputs of the transaction to be outlined by the SPV Wallet API,
+// e.g., the paymail outputs (request/paymail.Output) and the data outputs (request/opreturn.Output).
+type TransactionOutline = request.TransactionSpecification
+
+// RecordTransaction is the signed transaction in the BEEF format with the annotations of its outputs,
+// usually the ones returned with its outline, to be recorded by the SPV Wallet API.
+type RecordTransaction = transaction.AnnotatedTransaction
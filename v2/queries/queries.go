@@ -0,0 +1,37 @@
+// Package queries defines the queries of the search endpoints of the v2 SPV Wallet API.
+package queries
+
+import (
+	"github.com/bitcoin-sv/spv-wallet-go-client/v2/response"
+	"github.com/bitcoin-sv/spv-wallet/models/filter"
+	models "github.com/bitcoin-sv/spv-wallet/models/response"
+)
+
+// OperationsPage is an alias for the operations response page model returned by the v2 SPV Wallet API.
+// It provides a paginated list of operations along with pagination metadata.
+type OperationsPage = models.PageModel[response.Operation]
+
+// OperationsQuery aggregates the query parameters of the operations search.
+type OperationsQuery struct {
+	PageFilter filter.Page // Pagination details, including page number, size, and sorting.
+}
+
+// OperationsQueryOption defines a functional option for configuring an OperationsQuery.
+type OperationsQueryOption func(*OperationsQuery)
+
+// OperationsQueryWithPageFilter adds pagination filters, such as page number, size, and sorting options,
+// to the search URL as query parameters.
+func OperationsQueryWithPageFilter(f filter.Page) OperationsQueryOption {
+	return func(q *OperationsQuery) {
+		q.PageFilter = f
+	}
+}
+
+// NewOperationsQuery creates a new OperationsQuery instance, applying the provided functional options.
+func NewOperationsQuery(opts ...OperationsQueryOption) *OperationsQuery {
+	var q OperationsQuery
+	for _, o := range opts {
+		o(&q)
+	}
+	return &q
+}
@@ -0,0 +1,49 @@
+// Package response defines the models returned by the v2 endpoints of the SPV Wallet API.
+package response
+
+import (
+	"time"
+
+	"github.com/bitcoin-sv/spv-wallet/models/bsv"
+)
+
+// RecordedTransaction is the transaction recorded by the SPV Wallet API.
+type RecordedTransaction struct {
+	TxID string `json:"txID"` // ID of the recorded transaction.
+}
+
+// Operation is the change of the balance of the user made by a transaction.
+type Operation struct {
+	CreatedAt    time.Time `json:"createdAt"`    // Time the operation was created.
+	Value        int64     `json:"value"`        // Change of the balance in satoshis, negative for the outgoing transactions.
+	TxID         string    `json:"txID"`         // ID of the transaction.
+	Type         string    `json:"type"`         // Type of the operation, either incoming or outgoing.
+	Counterparty string    `json:"counterparty"` // Paymail of the other party of the transaction, if known.
+	TxStatus     string    `json:"txStatus"`     // Status of the transaction, e.g., BROADCASTED or MINED.
+}
+
+// User is the user of the SPV Wallet, identified by its public key.
+type User struct {
+	ID             string       `json:"id"`             // ID of the user.
+	CreatedAt      time.Time    `json:"createdAt"`      // Time the user was created.
+	UpdatedAt      time.Time    `json:"updatedAt"`      // Time the user was last updated.
+	PubKey         string       `json:"pubKey"`         // Hex-encoded public key of the user.
+	Paymails       []*Paymail   `json:"paymails"`       // Paymail addresses of the user.
+	CurrentBalance bsv.Satoshis `json:"currentBalance"` // Current balance of the user in satoshis.
+}
+
+// Paymail is a paymail address of the user.
+type Paymail struct {
+	ID         uint   `json:"id"`         // ID of the paymail address.
+	Alias      string `json:"alias"`      // Alias, the part of the address before the @.
+	Domain     string `json:"domain"`     // Domain, the part of the address after the @.
+	Paymail    string `json:"paymail"`    // Full paymail address.
+	PublicName string `json:"publicName"` // Public name shown to the counterparties.
+	Avatar     string `json:"avatar"`     // URL of the avatar shown to the counterparties.
+}
+
+// Data is a data record stored by the user in the data output of a transaction.
+type Data struct {
+	ID   string `json:"id"`   // ID of the data record: the outpoint of its output, <txID>-<vout>.
+	Blob string `json:"blob"` // Hex-encoded data.
+}